@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: bundle.go
+// Brief: Create bug bundles and prepare the replay of a bundle
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package advoc
+
+import (
+	"advocate/utils/bundle"
+	"advocate/utils/flags"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"fmt"
+	"path/filepath"
+)
+
+// modeBundle creates a bundle for a bug report
+func modeBundle() error {
+	if len(flags.ModeArgs) != 1 && len(flags.ModeArgs) != 2 {
+		log.Error("bundle requires the path to a bug report: ./advocate bundle [bug.md] [out.tar.gz]")
+		return fmt.Errorf("bundle requires one or two arguments, got %d", len(flags.ModeArgs))
+	}
+
+	// needed to find the files of the patched runtime
+	paths.BuildPaths(false)
+
+	out := ""
+	if len(flags.ModeArgs) == 2 {
+		out = flags.ModeArgs[1]
+	}
+
+	out, meta, err := bundle.CreateBundle(flags.ModeArgs[0], out)
+	if err != nil {
+		log.Error("Could not create bundle: ", err.Error())
+		return err
+	}
+
+	log.Importantf("Created bundle %s for %s in %s with %d source files",
+		out, meta.Bug, meta.Test, len(meta.Sources))
+	if !meta.Rewritten {
+		log.Important("The bug has no rewritten trace, the bundle contains the recorded trace")
+	}
+
+	return nil
+}
+
+// prepareBundleReplay verifies the source files of the bundle set with
+// -bundle and extracts its trace into the folder of the test or main file.
+// flags.TracePath is set to the extracted trace. If the test name is not set
+// with -exec, the test of the bundle is used.
+//
+// Parameter:
+//   - mode string: main for main function, test for test function
+//
+// Returns:
+//   - error: if the bundle could not be read or the source files have changed
+func prepareBundleReplay(mode string) error {
+	meta, err := bundle.ReadMeta(flags.Bundle)
+	if err != nil {
+		log.Error("Could not read bundle: ", err.Error())
+		return err
+	}
+
+	if (mode == "main") != (meta.Test == "Main") {
+		return fmt.Errorf("The bundle was created for %s, -main must be set if and only if the bundle is for a main program", meta.Test)
+	}
+
+	rootPath := flags.RootPath
+	if rootPath == "" {
+		rootPath = flags.ProgPath
+	}
+	root := bundle.ModuleRoot(paths.CleanPathHome(rootPath))
+	if root == "" {
+		return fmt.Errorf("Could not find go.mod for %s", rootPath)
+	}
+
+	if version := bundle.GoVersion(); version != meta.GoVersion {
+		log.Importantf("The bundle was created with %s, the installed runtime is %s", meta.GoVersion, version)
+	}
+
+	changes := bundle.Verify(meta, root)
+	if len(changes) > 0 {
+		log.Errorf("%d source file(s) differ from the files the bundle was created with:", len(changes))
+		for _, change := range changes {
+			log.Error("  ", change.String())
+		}
+		return fmt.Errorf("source files of bundle %s have changed", flags.Bundle)
+	}
+	log.Infof("Verified %d source files of the bundle", len(meta.Sources))
+
+	flags.TracePath, err = bundle.ExtractTrace(flags.Bundle, meta, filepath.Join(root, filepath.Dir(filepath.FromSlash(meta.File))))
+	if err != nil {
+		log.Error("Could not extract trace from bundle: ", err.Error())
+		return err
+	}
+
+	if mode == "test" && flags.ExecName == "" {
+		flags.ExecName = meta.Test
+	}
+
+	return nil
+}
@@ -37,21 +37,34 @@ func CommandLine() bool {
 	flag.StringVar(&flags.ExecName, "exec", "", "Name of the executable or test")
 
 	flag.StringVar(&flags.TracePath, "trace", "", "Path to the trace folder to replay")
+	flag.StringVar(&flags.Bundle, "bundle", "", "Path to a bundle created with the bundle mode to replay instead of -trace")
 
 	flag.IntVar(&flags.Timeout, "timeoutRec", 180, "Set the timeout in seconds for the recording. Default: 600s. To disable set to -1")
 	flag.IntVar(&flags.TimeoutFuzzing, "timeoutFuz", 420, "Timeout of fuzzing per test/program in seconds. Default: 7min. To Disable, set to -1")
 	flag.IntVar(&flags.MaxFuzzingRun, "maxFuzzingRuns", -1, "Maximum number of fuzzing runs per test/prog. Default: -1. To Disable, set to -1")
+	flag.IntVar(&flags.MaxFlakyRuns, "flakyRuns", 100, "Maximum number of runs in flaky mode. Default: 100")
 	flag.IntVar(&flags.MaxNumberElements, "maxNumberElements", 10000000, "Set the maximum number of elements in a trace. Traces with more elements will be skipped. To disable set -1. Default: 10000000")
+	flag.IntVar(&flags.FlightRecorder, "flightRecorder", 0, "Only keep the last n elements of each routine while recording (flight recorder). To disable set 0. Default: 0")
+	flag.StringVar(&flags.RecordFilter, "filter", "", "Comma separated package patterns to record, e.g. \"example.com/app/...,-example.com/app/gen/...\". Patterns starting with - are excluded. Default: record all non internal packages")
+	flag.StringVar(&flags.SyncConfig, "syncConfig", "", "Path to a file that maps functions of user defined synchronization primitives to the roles lock, unlock, send, recv, signal and wait")
+	flag.IntVar(&flags.Stacks, "stacks", 0, "Record call stacks with at most n frames for channel, mutex, wait group and cond operations. To disable set 0. Default: 0")
+
+	flag.StringVar(&flags.ProgArgs, "args", "", "Command line arguments for the main program, e.g. \"-v 'file name'\". Only for main")
+	flag.StringVar(&flags.ProgStdin, "stdin", "", "Path to a file whose content is given to stdin of the main program. Only for main")
+	flag.StringVar(&flags.ProgEnv, "env", "", "Comma separated environment variables recorded for the main program, e.g. \"HOME,PORT=8080\". Only for main")
 
 	flag.BoolVar(&flags.MeasureTime, "time", false, "measure the runtime")
 	flag.BoolVar(&flags.CreateStatistics, "stats", false, "Create statistics.")
-	flag.BoolVar(&flags.NotExecuted, "notExec", false, "Find never executed operations")
+	flag.BoolVar(&flags.NotExecuted, "notExec", false, "Find never executed operations and create a concurrency coverage report")
 
 	flag.BoolVar(&flags.IgnoreCriticalSection, "ignoreCritSec", false, "Ignore happens before relations of critical sections (default false)")
 	flag.BoolVar(&flags.IgnoreAtomics, "ignoreAtomics", false, "Ignore atomic operations (default false). Use to reduce memory header for large traces.")
 	flag.BoolVar(&flags.OnlyAPanicAndLeak, "onlyActual", false, "only test for actual bugs leading to panic and actual leaks. This will overwrite `scen`")
 
 	flag.BoolVar(&flags.NoSkipRewrite, "replayAll", false, "Replay a bug even if it has already been confirmed")
+	flag.BoolVar(&flags.PartialOrder, "partialOrder", false, "Replay by only enforcing the order of operations on the same primitive. Independent operations run freely")
+	flag.BoolVar(&flags.Minimize, "minimize", false, "Minimize confirmed rewritten traces to the orderings required to reproduce the bug")
+	flag.IntVar(&flags.MaxMinimizeRuns, "minimizeRuns", 50, "Maximum number of replays used to minimize one rewritten trace. Default: 50")
 	flag.BoolVar(&flags.NoRewrite, "noRewrite", false, "Do not rewrite the trace file (default false)")
 	flag.BoolVar(&flags.DeleteTraces, "deleteTrace", false, "If set, the traces are deleted after analysis.")
 	flag.BoolVar(&flags.SkipExisting, "skipExisting", false, "If set, all tests that already have a results folder will be skipped. Also skips failed tests.")
@@ -77,11 +90,17 @@ func CommandLine() bool {
 		"\tl: Leaking routine\n"+
 		"\tu: Unlock of unlocked mutex\n"+
 		"\tc: Cyclic deadlock\n"+
-		"\tm: Mixed deadlock\n",
+		"\tm: Mixed deadlock\n"+
+		"\tq: Matches of the queries set with -queries\n"+
+		"Additional registered scenarios are selected with the letter they were registered with\n",
 	)
 
+	flag.StringVar(&flags.QueryConfig, "queries", "", "Path to a file with trace queries, one per line. Matches of the queries are reported as results of the analysis")
+
+	flag.BoolVar(&flags.Static, "static", false, "Run the static blocking analysis before the tests to record the tests with possibly blocking operations first and to guide the GoPie and Guided mutations towards those operations")
+
 	flag.StringVar(&flags.FuzzingMode, "mode", "",
-		"Mode for fuzzing. Possible values are:\n\tGuided\n\tGFuzz\n\tGFuzzHB\n\tGFuzzHBFlow\n\tFlow\n\tGoPie\n\tGoCR\n\tGoCRHB\n\tDefault: Guided")
+		"Mode for fuzzing. Possible values are:\n\t"+strings.Join(append(f_base.StrategyNames(), f_base.Adaptive), "\n\t")+"\n\tDefault: Guided")
 
 	flag.BoolVar(&flags.ModeMain, "main", false, "set to run on main function")
 
@@ -89,6 +108,10 @@ func CommandLine() bool {
 
 	flag.BoolVar(&flags.CheckBenign, "benign", false, "Check if blocking bug is benign")
 
+	flag.BoolVar(&flags.FlakyFuzzing, "flakyFuzz", false, "In flaky mode, run the test under fuzzing mutations (set with -mode) instead of plain recordings")
+
+	flag.BoolVar(&flags.JSON, "json", false, "Print the result of diff, query and static as json")
+
 	// for experiments
 	flag.BoolVar(&f_base.FinishIfBugFound, "finishIfBugFound", false, "Finish fuzzing as soon as a bug was found")
 	flag.BoolVar(&flags.CancelTestIfBugFound, "cancelTestIfBugFound", false, "Skip further fuzzing runs of a test if one bug has been found")
@@ -98,6 +121,12 @@ func CommandLine() bool {
 	if len(os.Args) >= 2 && !strings.HasPrefix(os.Args[1], "-") {
 		flags.Mode = os.Args[1]
 		flag.CommandLine.Parse(os.Args[2:])
+
+		// collect positional arguments, flags may also be given after them
+		for flag.NArg() > 0 {
+			flags.ModeArgs = append(flags.ModeArgs, flag.Arg(0))
+			flag.CommandLine.Parse(flag.Args()[1:])
+		}
 		if help {
 			helper.PrintHelpMode(flags.Mode)
 			return false
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: flaky.go
+// Brief: Mode to find the root cause of flaky tests
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package advoc
+
+import (
+	"advocate/advoc/toolchain"
+	"advocate/fuzzing/f_fuzzing"
+	"advocate/utils/flags"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"advocate/utils/results/flaky"
+	"fmt"
+	"path/filepath"
+)
+
+// minimum number of passing and failing runs before the runs are compared
+const minRunsPerGroup = 3
+
+// modeFlaky runs a test repeatedly, either under recording or under fuzzing
+// mutations, until it has both passing and failing executions. It then
+// compares the traces of the two groups and ranks the ordering decisions
+// that separate them.
+//
+// Parameter:
+//   - mode string: main for main function, test for test function
+//
+// Returns:
+//   - error
+func modeFlaky(mode string) error {
+	if mode == "test" && flags.ExecName == "" {
+		log.Error("Flaky mode requires the name of the test. Set with -exec [TestName]")
+		return fmt.Errorf("Flaky mode requires -exec")
+	}
+
+	flags.DeleteTraces = false
+
+	runs := make([]flaky.Run, 0)
+	numberFailed := 0
+
+	// store the result of a run and check if enough runs have been executed
+	addRun := func(traceID int) bool {
+		tracePath := filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID))
+		failed, reason := flaky.Classify(tracePath, paths.ResultOutput)
+		runs = append(runs, flaky.Run{TraceID: traceID, TracePath: tracePath, Failed: failed, Reason: reason})
+
+		if failed {
+			numberFailed++
+			log.Infof("Flaky run %d failed: %s", len(runs), reason)
+		} else {
+			log.Infof("Flaky run %d passed", len(runs))
+		}
+
+		return numberFailed >= minRunsPerGroup && len(runs)-numberFailed >= minRunsPerGroup
+	}
+
+	if flags.FlakyFuzzing {
+		if flags.MaxFuzzingRun == -1 {
+			flags.MaxFuzzingRun = flags.MaxFlakyRuns
+		}
+		f_fuzzing.AfterRun = addRun
+		err := f_fuzzing.Fuzzing()
+		f_fuzzing.AfterRun = nil
+		if err != nil {
+			log.Error("Fuzzing Failed: ", err.Error())
+			return err
+		}
+	} else {
+		for i := 0; i < flags.MaxFlakyRuns; i++ {
+			log.Progressf("Flaky run %d/%d", i+1, flags.MaxFlakyRuns)
+			traceID, _, err := toolchain.Run(mode, "", record, !analysis, !replay,
+				-1, "", i == 0, 1, 0)
+			if err != nil {
+				log.Error("Flaky run failed: ", err.Error())
+				continue
+			}
+
+			if addRun(traceID) {
+				break
+			}
+		}
+	}
+
+	if numberFailed == 0 || numberFailed == len(runs) {
+		log.Importantf("Could not find passing and failing runs in %d runs (%d failed)", len(runs), numberFailed)
+		return nil
+	}
+
+	report, err := flaky.Analyze(runs)
+	if err != nil {
+		return err
+	}
+	report.Test = flags.ExecName
+
+	err = report.Write(paths.CurrentResult)
+	if err != nil {
+		return err
+	}
+
+	log.Importantf("Found %d ordering decisions separating %d passing and %d failing runs",
+		len(report.Decisions), report.NumberPass, report.NumberFail)
+	for i, d := range report.Decisions {
+		if i >= 5 {
+			break
+		}
+		log.Importantf("%d. %s %s (separation %.2f): failing runs %s", i+1, d.Kind, d.Op, d.Separation, d.FailValue)
+	}
+	log.Importantf("Full report: %s", filepath.Join(paths.CurrentResult, "flaky.md"))
+
+	return nil
+}
@@ -11,11 +11,19 @@ package advoc
 
 import (
 	"advocate/advoc/toolchain"
+	"advocate/analysis/a_analysis"
+	"advocate/analysis/a_base"
+	"advocate/analysis/a_query"
+	"advocate/analysis/s_blocking"
 	"advocate/fuzzing/f_fuzzing"
+	"advocate/utils/diff"
 	"advocate/utils/flags"
+	"advocate/utils/io"
 	"advocate/utils/log"
 	"advocate/utils/paths"
 	"advocate/utils/results/stats"
+	"advocate/utils/timer"
+	"fmt"
 )
 
 // modeFuzzing starts the fuzzing
@@ -45,6 +53,13 @@ func modeFuzzing() error {
 // Note:
 //   - If recording is false, but analysis or replay is set, -trace must be set
 func modeToolchain(mode string, record bool, analysis bool, replay bool) (err error) {
+	if flags.Bundle != "" && !record && replay {
+		err = prepareBundleReplay(mode)
+		if err != nil {
+			return err
+		}
+	}
+
 	if !record && (analysis || replay) {
 		flags.TracePath, err = paths.CheckPath(flags.TracePath)
 		if err != nil {
@@ -75,3 +90,107 @@ func modeToolchain(mode string, record bool, analysis bool, replay bool) (err er
 
 	return nil
 }
+
+// modeDiff compares two recorded traces and prints the first divergence
+// of each routine
+func modeDiff() error {
+	if len(flags.ModeArgs) != 2 {
+		log.Error("diff requires exactly two traces: ./advocate diff [traceA] [traceB]")
+		return fmt.Errorf("diff requires exactly two traces, got %d", len(flags.ModeArgs))
+	}
+
+	timer.Init("")
+
+	res, err := diff.DiffTraces(flags.ModeArgs[0], flags.ModeArgs[1])
+	if err != nil {
+		return err
+	}
+
+	if flags.JSON {
+		resJSON, err := res.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(resJSON)
+	} else {
+		fmt.Print(res.String())
+	}
+
+	return nil
+}
+
+// modeQuery evaluates a query on a recorded trace and prints all matches
+func modeQuery() error {
+	if len(flags.ModeArgs) != 1 {
+		log.Error("query requires exactly one query: ./advocate query -trace [trace] '[query]'")
+		return fmt.Errorf("query requires exactly one query, got %d", len(flags.ModeArgs))
+	}
+
+	if flags.TracePath == "" {
+		log.Error("query requires a trace: ./advocate query -trace [trace] '[query]'")
+		return fmt.Errorf("no trace given, set with -trace [folder]")
+	}
+
+	q, err := a_query.Parse("query", flags.ModeArgs[0])
+	if err != nil {
+		return fmt.Errorf("invalid query: %s", err.Error())
+	}
+
+	timer.Init("")
+
+	_, _, err = io.CreateTraceFromFiles(flags.TracePath)
+	if err != nil {
+		return fmt.Errorf("could not read trace %s: %s", flags.TracePath, err.Error())
+	}
+
+	// only run the query scenario, it evaluates the query after the vector clocks are calculated
+	a_query.SetModeQuery(q)
+	flags.Scenarios = "q"
+	a_base.AnalysisCasesMap, err = flags.ParseAnalysisCases()
+	if err != nil {
+		return err
+	}
+	a_analysis.RunHBAnalysis(false)
+
+	matches := a_query.GetModeMatches()
+
+	if flags.JSON {
+		resJSON, err := a_query.MatchesJSON(&a_base.MainTrace, q, matches)
+		if err != nil {
+			return err
+		}
+		fmt.Println(resJSON)
+	} else {
+		fmt.Print(a_query.MatchesString(&a_base.MainTrace, q, matches))
+	}
+
+	return nil
+}
+
+// modeStatic runs the static blocking analysis on a program and prints the
+// possibly blocking operations and the tests ordered by their priority
+func modeStatic() error {
+	if flags.ProgPath == "" {
+		log.Error("static requires a program: ./advocate static -path [folder]")
+		return fmt.Errorf("no program given, set with -path [folder]")
+	}
+
+	timer.Init("")
+
+	res, err := s_blocking.Analyze(paths.GetDirectory(flags.ProgPath))
+	if err != nil {
+		return fmt.Errorf("static analysis failed: %s", err.Error())
+	}
+
+	if flags.JSON {
+		resJSON, err := res.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(resJSON)
+	} else {
+		fmt.Print(res.String())
+	}
+
+	return nil
+}
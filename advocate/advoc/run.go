@@ -11,6 +11,7 @@ package advoc
 
 import (
 	"advocate/analysis/a_base"
+	"advocate/analysis/s_blocking"
 	"advocate/fuzzing/f_base"
 	"advocate/fuzzing/f_fuzzing"
 	"advocate/utils/command"
@@ -33,6 +34,17 @@ const (
 
 // Run starts the execution of advocate
 func Run() error {
+	// modes that only work on existing traces and do not need the runtime
+	switch flags.Mode {
+	case "diff":
+		return modeDiff()
+	case "bundle":
+		return modeBundle()
+	case "query":
+		return modeQuery()
+	case "static":
+		return modeStatic()
+	}
 
 	// If -main is set, the path needs to be the path to the main file
 	// If the given path is to a folder, check if a main.go file exists in this folder
@@ -62,6 +74,12 @@ func Run() error {
 	timer.Start(timer.Total)
 	defer timer.Stop(timer.Total)
 
+	if flags.Static {
+		if err := s_blocking.Run(progPathDir); err != nil {
+			log.Error("Static blocking analysis failed: ", err)
+		}
+	}
+
 	control.SetMaxNumberElem()
 	if !flags.NoMemorySupervisor {
 		go control.Supervisor(a_base.ClearTrace, a_base.ClearData, f_fuzzing.ResetFuzzing) // cancel analysis if not enough ram
@@ -107,13 +125,11 @@ func Run() error {
 		err = modeToolchain(modeMainTest, record, !analysis, !replay)
 	case "replay":
 		err = modeToolchain(modeMainTest, !record, !analysis, replay)
-	// case "static": // TODO: only for testing, when working move into analysis
-	// 	flags.DeleteTraces = false
-	// 	err = modeToolchain(modeMainTest, record, analysis, !replay)
-	// 	err = s_blocking.BuildStaticBlockingAnalysis()
+	case "flaky":
+		err = modeFlaky(modeMainTest)
 	default:
 		log.Errorf("Unknown mode %s\n", os.Args[1])
-		log.Error("Select one mode from  'analysis', 'fuzzing', 'replay' or 'record'")
+		log.Error("Select one mode from  'analysis', 'fuzzing', 'replay', 'record', 'flaky', 'diff', 'bundle', 'query' or 'static'")
 		err = fmt.Errorf("Unknown mode %s", os.Args[1])
 		helper.PrintHelp()
 	}
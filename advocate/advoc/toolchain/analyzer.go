@@ -13,6 +13,7 @@ import (
 	"advocate/analysis/a_analysis"
 	"advocate/analysis/a_base"
 	"advocate/fuzzing/f_active"
+	"advocate/utils/anchor"
 	"advocate/utils/consts"
 	"advocate/utils/control"
 	"advocate/utils/flags"
@@ -74,6 +75,14 @@ func runAnalyzer(pathTrace string,
 	}
 
 	log.Infof("Read trace with %d elements in %d routines", numberElems, numberOfRoutines)
+	if a_base.TraceTruncated {
+		log.Infof("Trace was recorded by the flight recorder with the last %d elements per routine", a_base.GetFlightRecorderSize())
+	}
+	if filter := a_base.GetRecordFilter(); filter != "" {
+		log.Infof("Trace was recorded with package filter %s", filter)
+	} else if a_base.TraceFiltered {
+		log.Info("Trace contains regions that were ignored with advocatego.Ignore")
+	}
 
 	a_analysis.RunAnalysis(fuzzingRun >= 0)
 
@@ -102,6 +111,12 @@ func runAnalyzer(pathTrace string,
 		return nil
 	}
 
+	// a truncated trace does not start at the program start and can therefore not be replayed
+	if a_base.TraceTruncated {
+		log.Info("Skip rewrite for truncated trace")
+		return nil
+	}
+
 	numberRewrittenTrace := 0
 	failedRewrites := 0
 	notNeededRewrites := 0
@@ -125,8 +140,8 @@ func runAnalyzer(pathTrace string,
 	}
 
 	for resultIndex := 0; resultIndex < numberOfResults; resultIndex++ {
-		needed, err := rewriteTrace(outMachine,
-			newTrace+"_"+strconv.Itoa(resultIndex+1)+consts.Sep, resultIndex, &rewrittenBugs)
+		rewrittenPath := newTrace + "_" + strconv.Itoa(resultIndex+1) + consts.Sep
+		needed, err := rewriteTrace(outMachine, rewrittenPath, resultIndex, &rewrittenBugs)
 
 		if !needed {
 			notNeededRewrites++
@@ -136,6 +151,11 @@ func runAnalyzer(pathTrace string,
 			fmt.Printf("Bugreport info: %s_%d,fail\n", rewriteNr, resultIndex+1)
 		} else { // needed && err == nil
 			numberRewrittenTrace++
+			copyFuzzInput(pathTrace, rewrittenPath)
+			copyProgInput(pathTrace, rewrittenPath)
+			if err := anchor.CopyAnchors(pathTrace, rewrittenPath); err != nil {
+				log.Error("Could not copy position anchors: ", err.Error())
+			}
 			fmt.Printf("Bugreport info: %s_%d,suc\n", rewriteNr, resultIndex+1)
 		}
 
@@ -189,7 +209,7 @@ func rewriteTrace(outMachine string, newTrace string, resultIndex int,
 	// the same bug was found and confirmed by replay in an earlier run,
 	// either in fuzzing or in another test
 	// It is therefore not needed to rewrite it again
-	if !flags.NoSkipRewrite && results.WasAlreadyConfirmed(bug.GetBugString()) {
+	if !flags.NoSkipRewrite && results.WasAlreadyConfirmed(bug.GetBugString(&a_base.MainTrace)) {
 		return false, nil
 	}
 
@@ -209,7 +229,7 @@ func rewriteTrace(outMachine string, newTrace string, resultIndex int,
 		return rewriteNeeded, err
 	}
 
-	err = io.WriteRewriteInfoFile(newTrace, bug, code, resultIndex)
+	err = io.WriteRewriteInfoFile(newTrace, bug, &traceCopy, code, resultIndex)
 	if err != nil {
 		return rewriteNeeded, err
 	}
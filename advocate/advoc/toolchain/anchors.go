@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: anchors.go
+// Brief: Anchor the positions of recorded traces and remap them before a
+//    replay, so that small changes of the source do not break the replay
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package toolchain
+
+import (
+	"advocate/utils/anchor"
+	"advocate/utils/log"
+)
+
+// writeAnchors stores the anchors of all positions of a recorded trace.
+// Must be called before the advocate header is removed.
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+func writeAnchors(tracePath string) {
+	if err := anchor.WriteAnchors(tracePath); err != nil {
+		log.Error("Could not write position anchors: ", err.Error())
+	}
+}
+
+// remapTrace maps the positions of a trace to the current source before it
+// is replayed. Must be called after the advocate header has been added.
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - bool: false if positions could not be mapped and the trace should not be replayed
+func remapTrace(tracePath string) bool {
+	remapped, unmapped, err := anchor.Remap(tracePath)
+	if err != nil {
+		log.Error("Could not remap positions of trace: ", err.Error())
+		return true
+	}
+
+	if len(unmapped) > 0 {
+		log.Errorf("Skip replay of %s: %d position(s) could not be mapped to the changed source:", tracePath, len(unmapped))
+		for _, a := range unmapped {
+			log.Error("  ", a.String())
+		}
+		return false
+	}
+
+	if remapped > 0 {
+		log.Infof("Remapped %d position(s) of %s to the changed source", remapped, tracePath)
+	}
+
+	return true
+}
@@ -38,11 +38,36 @@ func getBuildArg(fileName string, replay bool, tracePath string,
 			buildArg += fmt.Sprintf("-advocatefuzzing -advocatepath=%s -advocatetimeout=%d", tracePath, flags.Timeout)
 		} else {
 			buildArg += fmt.Sprintf("-advocatereplay -advocatepath=%s -advocatetimeout=%d -advocateatomic=%s", tracePath, replayTimeout, atomicReplayStr)
+			if flags.PartialOrder {
+				buildArg += " -advocatepartialorder"
+			}
 		}
 	} else if fuzzing > 0 {
 		buildArg += fmt.Sprintf("-advocatefuzzing -advocatepath=%s -advocatetimeout=%d", fuzzingTrace, replayTimeout)
 	} else { // recording
 		buildArg += fmt.Sprintf("-advocatetrace  -advocatetimeout=%d", replayTimeout)
+		if flags.FlightRecorder > 0 {
+			buildArg += fmt.Sprintf(" -advocateflightrecorder=%d", flags.FlightRecorder)
+		}
+		if flags.Stacks > 0 {
+			buildArg += fmt.Sprintf(" -advocatestacks=%d", flags.Stacks)
+		}
+	}
+
+	// the filter is needed in all modes, so that replay and fuzzing ignore
+	// the same operations as the recording
+	if filter := strings.ReplaceAll(flags.RecordFilter, " ", ""); filter != "" {
+		buildArg += fmt.Sprintf(" -advocatefilter=%s", filter)
+	}
+
+	// the sync config is needed in all modes, so that the calls of user
+	// defined synchronization primitives are replayed in order
+	if flags.SyncConfig != "" {
+		syncConfig, err := filepath.Abs(flags.SyncConfig)
+		if err != nil {
+			syncConfig = flags.SyncConfig
+		}
+		buildArg += fmt.Sprintf(" -advocatesyncconfig=%s", syncConfig)
 	}
 
 	// buildArg += "'"
@@ -99,6 +124,7 @@ func importInsertMain(fileName string, replay bool, replayNumber string,
 	if !static {
 		fmt.Println("FileName: ", fileName)
 		fmt.Println("TestName: Main")
+		fmt.Println("AdvocateCommand: ", strings.Join(os.Args, " "))
 	}
 
 	var lines []string
@@ -288,6 +314,7 @@ func importInsertUnit(fileName, testName string, replay bool, fuzzing int, repla
 
 	fmt.Println("FileName: ", fileName)
 	fmt.Println("TestName: ", testName)
+	fmt.Println("AdvocateCommand: ", strings.Join(os.Args, " "))
 
 	for scanner.Scan() {
 		currentLine++
@@ -391,30 +418,24 @@ func importRemoverUnit(fileName string) error {
 //
 // Parameter:
 //   - fileName string: path to the file
-//   - testName string: name of the test
+//   - testName string: name of the test, for subtests the top level test must exist
 //
 // Returns:
 //   - bool: true if the test exists, false otherwise
 //   - error
 func testExists(fileName string, testName string) (bool, error) {
-	file, err := os.Open(fileName)
+	targets, err := findTestTargets(fileName)
 	if err != nil {
 		return false, err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "func "+testName) && strings.Contains(line, "testing.T") {
+	testName = topLevelTest(testName)
+	for _, target := range targets {
+		if target.name == testName {
 			return true, nil
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return false, err
-	}
-
 	return false, nil
 }
 
@@ -0,0 +1,369 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: discover.go
+// Brief: Discover test packages, tests, benchmarks, fuzz targets, examples
+//    and subtests of a program
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package toolchain
+
+import (
+	"advocate/utils/command"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// kinds of test targets
+const (
+	targetTest      = "test"
+	targetBenchmark = "benchmark"
+	targetFuzz      = "fuzz"
+	targetExample   = "example"
+)
+
+// testTarget is a top level function that can be run by go test
+//
+// Fields:
+//   - name string: name of the function
+//   - kind string: kind of the target (test, benchmark, fuzz, example)
+type testTarget struct {
+	name string
+	kind string
+}
+
+// listPackage contains the fields of the output of go list -json
+// that are needed to find the test files
+type listPackage struct {
+	Dir          string
+	ImportPath   string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// listTestFiles uses go list to find all test files of all packages in dir.
+// This respects the module graph, build tags and external test packages.
+//
+// Parameter:
+//   - dir string: root of the program
+//
+// Returns:
+//   - []string: paths to the test files
+//   - error
+func listTestFiles(dir string) ([]string, error) {
+	out, err := command.RunCommandOutput(command.NoTimeout, "go", "list", "-C", dir, "-e", "-json", "./...")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg listPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, err
+		}
+
+		for _, file := range append(pkg.TestGoFiles, pkg.XTestGoFiles...) {
+			res = append(res, filepath.Join(pkg.Dir, file))
+		}
+	}
+
+	return res, nil
+}
+
+// walkTestFiles finds all _test.go files in dir by walking the directory.
+// Used if the files cannot be determined with go list
+//
+// Parameter:
+//   - dir string: root of the program
+//
+// Returns:
+//   - []string: paths to the test files
+//   - error
+func walkTestFiles(dir string) ([]string, error) {
+	res := make([]string, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(info.Name(), "_test.go") {
+			res = append(res, path)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// findTestTargets parses a test file and returns all tests, benchmarks,
+// fuzz targets and examples with output in the order in which they are declared
+//
+// Parameter:
+//   - file string: path to the test file
+//
+// Returns:
+//   - []testTarget: the targets
+//   - error
+func findTestTargets(file string) ([]testTarget, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	testingName := ""
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != "testing" {
+			continue
+		}
+		testingName = "testing"
+		if imp.Name != nil {
+			testingName = imp.Name.Name
+		}
+	}
+
+	res := make([]testTarget, 0)
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Body == nil {
+			continue
+		}
+
+		name := fn.Name.Name
+		switch {
+		case isTargetName(name, "Test") && name != "TestMain" && hasTestingParam(fn, testingName, "T"):
+			res = append(res, testTarget{name, targetTest})
+		case isTargetName(name, "Benchmark") && hasTestingParam(fn, testingName, "B"):
+			res = append(res, testTarget{name, targetBenchmark})
+		case isTargetName(name, "Fuzz") && hasTestingParam(fn, testingName, "F"):
+			res = append(res, testTarget{name, targetFuzz})
+		case isTargetName(name, "Example") && fn.Type.Params.NumFields() == 0 &&
+			fn.Type.Results.NumFields() == 0 && hasOutputComment(f, fn):
+			res = append(res, testTarget{name, targetExample})
+		}
+	}
+
+	return res, nil
+}
+
+// isTargetName checks if a function name is a test name with the given prefix.
+// As in go test, the prefix must not be followed by a lower case letter.
+//
+// Parameter:
+//   - name string: name of the function
+//   - prefix string: Test, Benchmark, Fuzz or Example
+//
+// Returns:
+//   - bool: true if the name is a valid target name
+func isTargetName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// hasTestingParam checks if a function has exactly one parameter of
+// type *testing.[typeName] and no results
+//
+// Parameter:
+//   - fn *ast.FuncDecl: the function
+//   - testingName string: name under which the testing package is imported, "." for dot imports
+//   - typeName string: T, B or F
+//
+// Returns:
+//   - bool: true if the function has the signature
+func hasTestingParam(fn *ast.FuncDecl, testingName, typeName string) bool {
+	if testingName == "" || testingName == "_" {
+		return false
+	}
+
+	if fn.Type.Params.NumFields() != 1 || fn.Type.Results.NumFields() != 0 {
+		return false
+	}
+
+	star, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+
+	switch t := star.X.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		return ok && pkg.Name == testingName && t.Sel.Name == typeName
+	case *ast.Ident:
+		return testingName == "." && t.Name == typeName
+	}
+
+	return false
+}
+
+// hasOutputComment checks if an example function has an output comment.
+// Examples without output comment are compiled but not run by go test.
+//
+// Parameter:
+//   - f *ast.File: the file containing the example
+//   - fn *ast.FuncDecl: the example function
+//
+// Returns:
+//   - bool: true if the example has an output comment
+func hasOutputComment(f *ast.File, fn *ast.FuncDecl) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() < fn.Body.Lbrace || cg.End() > fn.Body.Rbrace {
+			continue
+		}
+
+		text := strings.ToLower(strings.TrimSpace(cg.Text()))
+		if strings.HasPrefix(text, "output:") || strings.HasPrefix(text, "unordered output:") {
+			return true
+		}
+	}
+	return false
+}
+
+// testSelectArgs returns the go test arguments to run only the given test.
+// The test name can contain subtests separated by /. Each element is
+// matched exactly. Benchmarks are run exactly once.
+//
+// Parameter:
+//   - testName string: name of the test, e.g. TestFoo or TestFoo/case_3
+//
+// Returns:
+//   - []string: the go test arguments
+func testSelectArgs(testName string) []string {
+	elems := strings.Split(testName, "/")
+	for i, elem := range elems {
+		elems[i] = "^" + regexp.QuoteMeta(elem) + "$"
+	}
+	pattern := strings.Join(elems, "/")
+
+	if isTargetName(topLevelTest(testName), "Benchmark") {
+		return []string{"-run=^$", "-bench=" + pattern, "-benchtime=1x"}
+	}
+	return []string{"-run=" + pattern}
+}
+
+// goTestArgs returns the arguments for go test to run a single test
+//
+// Parameter:
+//   - testName string: name of the test, can contain subtests
+//   - pkgPath string: path to the package
+//   - buildFlags ...string: additional flags passed before the test selection
+//
+// Returns:
+//   - []string: the arguments
+func goTestArgs(testName, pkgPath string, buildFlags ...string) []string {
+	res := append([]string{"test"}, buildFlags...)
+	res = append(res, "-v", "-count=1")
+	res = append(res, testSelectArgs(testName)...)
+	return append(res, pkgPath)
+}
+
+// topLevelTest returns the name of the top level test of a (sub)test
+//
+// Parameter:
+//   - testName string: name of the test, e.g. TestFoo/case_3
+//
+// Returns:
+//   - string: the top level test, e.g. TestFoo
+func topLevelTest(testName string) string {
+	top, _, _ := strings.Cut(testName, "/")
+	return top
+}
+
+// findSubtests returns the names of all subtests of a test that were
+// run, based on the verbose output of go test
+//
+// Parameter:
+//   - output string: path to the output of go test -v
+//   - testName string: name of the top level test
+//
+// Returns:
+//   - []string: full names of the subtests in the order in which they were started
+func findSubtests(output, testName string) []string {
+	file, err := os.Open(output)
+	if err != nil {
+		return make([]string, 0)
+	}
+	defer file.Close()
+
+	return scanSubtests(bufio.NewScanner(file), testName)
+}
+
+// scanSubtests returns the names of all subtests of a test that were
+// run, based on the verbose output of go test
+//
+// Parameter:
+//   - scanner *bufio.Scanner: scanner over the output of go test -v
+//   - testName string: name of the top level test
+//
+// Returns:
+//   - []string: full names of the subtests in the order in which they were started
+func scanSubtests(scanner *bufio.Scanner, testName string) []string {
+	res := make([]string, 0)
+
+	seen := make(map[string]struct{})
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "=== RUN") {
+			continue
+		}
+
+		name := strings.TrimSpace(strings.TrimPrefix(line, "=== RUN"))
+		if !strings.HasPrefix(name, testName+"/") {
+			continue
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		res = append(res, name)
+	}
+
+	return res
+}
+
+// recordSubtests writes the subtests of a recorded test into the result
+// folder, so that they can be run separately with -exec Test/sub
+//
+// Parameter:
+//   - output string: path to the output of the recording
+//   - testName string: name of the recorded test
+func recordSubtests(output, testName string) {
+	if strings.Contains(testName, "/") {
+		return
+	}
+
+	subtests := findSubtests(output, testName)
+	if len(subtests) == 0 {
+		return
+	}
+
+	log.Infof("Found %d subtests of %s. Run a single subtest with -exec '%s'", len(subtests), testName, subtests[0])
+
+	err := os.WriteFile(filepath.Join(paths.CurrentResult, paths.NameSubtests),
+		[]byte(strings.Join(subtests, "\n")+"\n"), 0644)
+	if err != nil {
+		log.Error("Could not write subtests: ", err.Error())
+	}
+}
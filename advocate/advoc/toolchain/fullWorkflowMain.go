@@ -13,6 +13,7 @@ package toolchain
 import (
 	"advocate/utils/command"
 	"advocate/utils/flags"
+	"advocate/utils/helper"
 	"advocate/utils/log"
 	"advocate/utils/paths"
 	"advocate/utils/results/complete"
@@ -62,8 +63,11 @@ func runWorkflowMain(
 			return 0, 0, fmt.Errorf("Failed to create advocateResult directory: %v", err)
 		}
 
-		// Remove possibly leftover traces from unexpected aborts that could interfere with replay
-		RemoveTraces(paths.ProgDir)
+		// Remove possibly leftover traces from unexpected aborts that could interfere with replay.
+		// Without recording, the trace to replay is one of them.
+		if runRecord {
+			RemoveTraces(paths.ProgDir)
+		}
 		removeLogs(paths.ProgDir)
 	}
 
@@ -91,6 +95,11 @@ func runWorkflowMain(
 	// Unset GOROOT
 	defer os.Unsetenv("GOROOT")
 	if runRecord {
+		input, err := getProgInputFromFlags()
+		if err != nil {
+			return 0, 0, err
+		}
+
 		// Remove header
 		if err := importRemoveMain(); err != nil {
 			return 0, 0, fmt.Errorf("Error removing header: %v", err)
@@ -110,7 +119,7 @@ func runWorkflowMain(
 			log.Info("Execute Program")
 			timer.Start(timer.Run)
 			execPath := paths.MakePathLocal(flags.ExecName)
-			if err := command.RunCommand(origStdout, origStderr, command.NoTimeout, execPath); err != nil {
+			if err := runProg(origStdout, origStderr, execPath, input); err != nil {
 				importRemoveMain()
 			}
 			timer.Stop(timer.Run)
@@ -133,12 +142,17 @@ func runWorkflowMain(
 		log.Info("Run program for execution")
 		timer.Start(timer.Recording)
 		execPath := paths.MakePathLocal(flags.ExecName)
-		if err := command.RunCommand(origStdout, origStderr, command.NoTimeout, execPath); err != nil {
+		if err := runProg(origStdout, origStderr, execPath, input); err != nil {
 			// log.Error("Error in Run Recording: ", err.Error())
 			importRemoveMain()
 		}
 		timer.Stop(timer.Recording)
 
+		writeProgInput(input, filepath.Join(paths.ProgDir, "advocateTrace"))
+		if fuzzing < 1 {
+			writeAnchors(filepath.Join(paths.ProgDir, "advocateTrace"))
+		}
+
 		// Remove header
 		if err := importRemoveMain(); err != nil {
 			return 0, 0, fmt.Errorf("Error removing header: %v", err)
@@ -181,6 +195,11 @@ func runWorkflowMain(
 				return 0, 0, err
 			}
 
+			if !remapTrace(trace) {
+				importRemoveMain()
+				continue
+			}
+
 			// build the program
 			log.Info("Build program for replay")
 			if err := command.RunCommand(origStdout, origStderr, command.NoTimeout, paths.Go, "build", buildFlags); err != nil {
@@ -192,12 +211,32 @@ func runWorkflowMain(
 			// run the program
 			log.Info("Run program for replay")
 			execPath := paths.MakePathLocal(flags.ExecName)
-			command.RunCommand(origStdout, origStderr, command.NoTimeout, execPath)
+			input := getProgInputForReplay(trace)
+			exitCode := runReplayExitCode(trace, origStdout, func(out *os.File) {
+				runProg(out, out, execPath, input)
+			})
 
 			fmt.Printf("Remove replay header from %s\n", paths.Prog)
 			if err := importRemoveMain(); err != nil {
 				return 0, 0, err
 			}
+
+			logReplayDivergence(trace)
+
+			if runAnalysis && flags.Minimize && exitCode >= helper.MinExitCodeSuc {
+				minimizeTrace(trace, func(traceNum string, out *os.File) {
+					buildFlags, _, _, err := importInsertMain(paths.Prog, true, traceNum, flags.Timeout, false, fuzzing, fuzzingTrace, false)
+					if err != nil {
+						return
+					}
+					defer importRemoveMain()
+
+					if err := command.RunCommand(out, out, command.NoTimeout, paths.Go, "build", buildFlags); err != nil {
+						return
+					}
+					runProg(out, out, execPath, input)
+				})
+			}
 		}
 		timer.Stop(timer.Replay)
 	}
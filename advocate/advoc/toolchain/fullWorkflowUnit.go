@@ -12,6 +12,7 @@ package toolchain
 
 import (
 	"advocate/analysis/a_base"
+	"advocate/analysis/s_blocking"
 	"advocate/utils/command"
 	"advocate/utils/control"
 	"advocate/utils/flags"
@@ -92,6 +93,11 @@ func runWorkflowUnit(runRecord, runAnalysis, runReplay bool,
 		return 0, 0, fmt.Errorf("Failed to find test files: %v", err)
 	}
 
+	// record the tests with possibly blocking operations first
+	if !flags.Continue {
+		testFiles = s_blocking.SortTestFiles(testFiles)
+	}
+
 	attemptedTests, skippedTests, currentFile := 0, 0, fileNumber
 
 	// resultPath := filepath.Join(dir, "advocateResult")
@@ -115,12 +121,20 @@ func runWorkflowUnit(runRecord, runAnalysis, runReplay bool,
 			log.Infof("Could not find test functions in %s: %v", file, err)
 			continue
 		}
+		if !flags.Continue {
+			testFunctions = s_blocking.SortTests(file, testFunctions)
+		}
 
 		for _, testFunc := range testFunctions {
-			if flags.ExecName != "" && flags.ExecName != testFunc {
+			if flags.ExecName != "" && topLevelTest(flags.ExecName) != testFunc {
 				continue
 			}
 
+			// run a single subtest
+			if flags.ExecName != "" {
+				testFunc = flags.ExecName
+			}
+
 			for control.WasCanceledRAM() {
 				log.Error("Wait RAM")
 				time.Sleep(6 * time.Second)
@@ -269,22 +283,21 @@ func FindTestFiles(dir string, cont bool) ([]string, int, int, error) {
 		}
 	}
 
-	totalNumFiles := 0
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	files, err := listTestFiles(dir)
+	if err != nil {
+		log.Infof("Could not list test files with go list, search directory instead: %v", err)
+		files, err = walkTestFiles(dir)
 		if err != nil {
-			return err
+			log.Error(err)
 		}
-		name := info.Name()
-		if strings.HasSuffix(name, "_test.go") {
-			totalNumFiles++
-			if _, ok := alreadyProcessed[name]; !cont || !ok {
-				testFiles = append(testFiles, path)
-			}
+	}
+
+	totalNumFiles := 0
+	for _, path := range files {
+		totalNumFiles++
+		if _, ok := alreadyProcessed[filepath.Base(path)]; !cont || !ok {
+			testFiles = append(testFiles, path)
 		}
-		return nil
-	})
-	if err != nil {
-		log.Error(err)
 	}
 	return testFiles, maxFileNum, totalNumFiles, err
 }
@@ -360,7 +373,8 @@ func getFilesInResult(dir string) (map[string]struct{}, int, error) {
 	return res, maxFileNum, nil
 }
 
-// FindTestFunctions find all test function in the specified file
+// FindTestFunctions find all tests, benchmarks, fuzz targets and examples
+// with output in the specified file
 //
 // Parameter:
 //   - file string: file to search in
@@ -369,19 +383,14 @@ func getFilesInResult(dir string) (map[string]struct{}, int, error) {
 //   - []string: functions
 //   - error
 func FindTestFunctions(file string) ([]string, error) {
-	content, err := os.ReadFile(file)
+	targets, err := findTestTargets(file)
 	if err != nil {
 		return nil, err
 	}
 
-	var testFunctions []string
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "func Test") && strings.Contains(line, "*testing.T") {
-			testFunc := strings.TrimSpace(strings.Split(line, "(")[0])
-			testFunc = strings.TrimPrefix(testFunc, "func ")
-			testFunctions = append(testFunctions, testFunc)
-		}
+	testFunctions := make([]string, 0, len(targets))
+	for _, target := range targets {
+		testFunctions = append(testFunctions, target.name)
 	}
 	return testFunctions, nil
 }
@@ -465,6 +474,10 @@ func unitTestFullWorkflow(
 		if err != nil {
 			log.Error("Recording failed: ", err.Error())
 		}
+
+		if fuzzing < 1 {
+			recordSubtests(paths.NameOutput, testName)
+		}
 	}
 
 	if runAnalysis {
@@ -514,9 +527,9 @@ func unitTestRun(pkg, file, testName string, origStdout, origStderr *os.File) er
 	var err error
 	if flags.Timeout != -1 {
 		timeoutRecString := fmt.Sprintf("%ds", flags.Timeout)
-		err = command.RunCommand(origStdout, origStderr, command.NoTimeout, "go", "test", "-v", "-timeout", timeoutRecString, "-count=1", "-run="+testName, packagePath)
+		err = command.RunCommand(origStdout, origStderr, command.NoTimeout, "go", goTestArgs(testName, packagePath, "-timeout", timeoutRecString)...)
 	} else {
-		err = command.RunCommand(origStdout, origStderr, command.NoTimeout, "go", "test", "-v", "-count=1", "-run="+testName, packagePath)
+		err = command.RunCommand(origStdout, origStderr, command.NoTimeout, "go", goTestArgs(testName, packagePath)...)
 	}
 
 	return err
@@ -563,7 +576,7 @@ func unitTestRecord(pkg, file, testName string,
 	command.RunCommand(osOut, osErr, command.NoTimeout, paths.Go, "version")
 
 	pkgPath := paths.MakePathLocal(pkg)
-	err = command.RunCommand(osOut, osErr, command.NoTimeout, paths.Go, "test", buildFlags, "-v", "-count=1", "-run="+testName, pkgPath)
+	err = command.RunCommand(osOut, osErr, command.NoTimeout, paths.Go, goTestArgs(testName, pkgPath, buildFlags)...)
 	if err != nil {
 		if isFuzzing {
 			if checkForTimeout(output) {
@@ -577,6 +590,10 @@ func unitTestRecord(pkg, file, testName string,
 	}
 	log.Info("Text executed")
 
+	if !isFuzzing {
+		writeAnchors(filepath.Join(paths.Prog, pkg, "advocateTrace"))
+	}
+
 	err = os.Unsetenv("GOROOT")
 
 	if err != nil {
@@ -668,23 +685,41 @@ func unitTestReplay(dir, pkg, file,
 
 		buildFlags, _ := importInsertUnit(file, testName, true, -1, traceNum, record)
 
+		if !remapTrace(trace) {
+			results.AddBug(bugString, false)
+			importRemoverUnit(file)
+			continue
+		}
+
 		os.Setenv("GOROOT", paths.GoPatch)
 
 		log.Infof("Run guided execution %d/%d", i+1, len(rewrittenTraces))
 		pkgPath := paths.MakePathLocal(pkg)
-		command.RunCommand(osOut, osErr, command.NoTimeout, paths.Go, "test", buildFlags, "-v", "-count=1", "-run="+testName, pkgPath)
+		command.RunCommand(osOut, osErr, command.NoTimeout, paths.Go, goTestArgs(testName, pkgPath, buildFlags)...)
 		log.Infof("Finished  guided execution %d/%d", i+1, len(rewrittenTraces))
 
-		if wasReplaySuc(output) {
+		replaySuc := wasReplaySuc(output)
+		if replaySuc {
 			results.AddBug(bugString, true)
 		} else {
 			results.AddBug(bugString, false)
+			logReplayDivergence(trace)
 		}
 
 		os.Unsetenv("GOROOT")
 
 		// Remove reorder header
 		importRemoverUnit(file)
+
+		if replaySuc && fromAnalysis && flags.Minimize {
+			minimizeTrace(trace, func(traceNum string, out *os.File) {
+				buildFlags, _ := importInsertUnit(file, testName, true, -1, traceNum, false)
+				os.Setenv("GOROOT", paths.GoPatch)
+				command.RunCommand(out, out, command.NoTimeout, paths.Go, goTestArgs(testName, pkgPath, buildFlags)...)
+				os.Unsetenv("GOROOT")
+				importRemoverUnit(file)
+			})
+		}
 	}
 
 	return len(rewrittenTraces)
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: fuzzInput.go
+// Brief: Find the inputs of go fuzz targets, to run each input as its own
+//    test under schedule fuzzing
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package toolchain
+
+import (
+	"advocate/utils/command"
+	"advocate/utils/flags"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FindFuzzInputs returns the inputs of a go fuzz target as the names of the
+// subtests that run them. Those are the seeds added with f.Add (FuzzFoo/seed#i)
+// and the files in the corpus in testdata/fuzz/FuzzFoo (FuzzFoo/[fileName]).
+// To also find seeds that are added in loops or helper functions, the fuzz
+// target is run once without fuzzing and the inputs are read from the
+// subtests in its verbose output.
+//
+// Parameter:
+//   - file string: path to the test file containing the fuzz target
+//   - testName string: name of the fuzz target
+//
+// Returns:
+//   - []string: the names of the subtests running the inputs, empty if testName is not a top level fuzz target
+//   - error
+func FindFuzzInputs(file, testName string) ([]string, error) {
+	if !isTargetName(testName, "Fuzz") || topLevelTest(testName) != testName {
+		return []string{}, nil
+	}
+
+	buildFlags := []string{"-C", filepath.Dir(file)}
+	if flags.Timeout != -1 {
+		buildFlags = append(buildFlags, "-timeout", fmt.Sprintf("%ds", flags.Timeout))
+	}
+
+	// failing inputs let go test fail, but are still listed in the output
+	out, err := command.RunCommandOutput(command.NoTimeout, "go", goTestArgs(testName, ".", buildFlags...)...)
+	res := scanSubtests(bufio.NewScanner(bytes.NewReader(out)), testName)
+	if err != nil && len(res) == 0 {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FindFuzzInputsOfTest searches the test file containing a fuzz target in
+// the program and returns the inputs of the target
+//
+// Parameter:
+//   - dir string: path to the program
+//   - testName string: name of the fuzz target
+//
+// Returns:
+//   - []string: the names of the subtests running the inputs, empty if testName is not a top level fuzz target
+//   - error
+func FindFuzzInputsOfTest(dir, testName string) ([]string, error) {
+	if !isTargetName(testName, "Fuzz") || topLevelTest(testName) != testName {
+		return []string{}, nil
+	}
+
+	testFiles, _, _, err := FindTestFiles(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range testFiles {
+		exists, err := testExists(file, testName)
+		if err != nil || !exists {
+			continue
+		}
+		return FindFuzzInputs(file, testName)
+	}
+
+	return []string{}, nil
+}
+
+// copyFuzzInput copies the recorded fuzz input from a trace into a rewritten
+// trace, so that the replay of the rewritten trace contains the input
+// it must be run with
+//
+// Parameter:
+//   - pathTrace string: path to the recorded trace
+//   - newTrace string: path to the rewritten trace
+func copyFuzzInput(pathTrace, newTrace string) {
+	content, err := os.ReadFile(filepath.Join(pathTrace, paths.NameFuzzInput))
+	if err != nil {
+		return
+	}
+
+	err = os.WriteFile(filepath.Join(newTrace, paths.NameFuzzInput), content, 0644)
+	if err != nil {
+		log.Error("Could not copy fuzz input: ", err.Error())
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: minimize.go
+// Brief: Minimize a rewritten trace to the orderings required to reproduce the bug
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package toolchain
+
+import (
+	"advocate/trace"
+	"advocate/utils/flags"
+	"advocate/utils/helper"
+	"advocate/utils/io"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// suffix of the trace folders used for the minimization replays
+const minimizeSuffix = "_min"
+
+// header of the replay active file that tells the replay to ignore all
+// elements of the trace that are not active
+const onlyActiveMarker = "onlyActive"
+
+// replayCandidate runs a replay of the trace in rewrittenTrace_[traceNum]
+// and writes the output into output
+type replayCandidate func(traceNum string, output *os.File)
+
+// scheduleElem is an operation whose execution order is enforced by the replay
+//
+// Fields:
+//   - key string: replay key of the operation (routine:file#line)
+//   - tPre int: time of the operation in the trace
+//   - counter int: the operation is enforced on the counter-th execution of key
+type scheduleElem struct {
+	key     string
+	tPre    int
+	counter int
+}
+
+// String returns the representation of the element as used in the replay active file
+//
+// Returns:
+//   - string: the element as routine:file#line,tPre,counter
+func (this scheduleElem) String() string {
+	return fmt.Sprintf("%s,%d,%d", this.key, this.tPre, this.counter)
+}
+
+// minimizeTrace searches for a minimal set of operations in a rewritten trace
+// whose enforced order still reproduces the bug. All other operations are
+// run freely using the partial replay. The search uses delta debugging and
+// is stopped after flags.MaxMinimizeRuns replays. The resulting schedule is
+// written into the rewritten trace folder.
+//
+// Parameter:
+//   - tracePath string: path to the rewritten trace
+//   - replay replayCandidate: function to run a replay
+func minimizeTrace(tracePath string, replay replayCandidate) {
+	schedule, err := getSchedule(tracePath)
+	if err != nil {
+		log.Errorf("Could not read trace %s for minimization: %s", tracePath, err.Error())
+		return
+	}
+
+	if len(schedule) == 0 {
+		return
+	}
+
+	log.Infof("Minimize rewritten trace %s with %d operations", tracePath, len(schedule))
+
+	numberRuns := 0
+	runSchedule := func(subset []scheduleElem) int {
+		numberRuns++
+		return replaySchedule(tracePath, subset, replay)
+	}
+
+	// check that the bug is still reproduced if all elements are enforced
+	// by the partial replay
+	expectedCode := runSchedule(schedule)
+	if expectedCode < helper.MinExitCodeSuc {
+		log.Infof("Could not minimize %s: replay with all operations enforced did not reproduce the bug", tracePath)
+		return
+	}
+
+	reproduces := func(subset []scheduleElem) bool {
+		if numberRuns >= flags.MaxMinimizeRuns {
+			return false
+		}
+		return runSchedule(subset) == expectedCode
+	}
+
+	minimal := ddmin(schedule, reproduces)
+
+	if numberRuns >= flags.MaxMinimizeRuns {
+		log.Infof("Minimization of %s stopped after %d replays", tracePath, numberRuns)
+	}
+
+	err = writeSchedule(filepath.Join(tracePath, paths.NameMinimalSchedule), minimal)
+	if err != nil {
+		log.Errorf("Could not write minimal schedule for %s: %s", tracePath, err.Error())
+		return
+	}
+
+	log.Importantf("Minimized %s from %d to %d enforced operations in %d replays",
+		tracePath, len(schedule), len(minimal), numberRuns)
+}
+
+// ddmin implements the delta debugging algorithm. It returns a 1-minimal
+// subset of elems for which test returns true. test(elems) is assumed to be true.
+// The result is never empty, because an empty replay active file would
+// enforce all operations.
+//
+// Parameter:
+//   - elems []scheduleElem: the elements to minimize
+//   - test func([]scheduleElem) bool: returns true if the subset still reproduces the bug
+//
+// Returns:
+//   - []scheduleElem: the minimal subset
+func ddmin(elems []scheduleElem, test func([]scheduleElem) bool) []scheduleElem {
+	n := 2
+	for len(elems) >= 2 {
+		chunks := splitSchedule(elems, n)
+		reduced := false
+
+		// try to reduce to a subset
+		for _, chunk := range chunks {
+			if test(chunk) {
+				elems = chunk
+				n = 2
+				reduced = true
+				break
+			}
+		}
+
+		// try to reduce to a complement, for n = 2 the complements are the subsets
+		if !reduced && n > 2 {
+			for i := range chunks {
+				complement := make([]scheduleElem, 0, len(elems)-len(chunks[i]))
+				for j, chunk := range chunks {
+					if i != j {
+						complement = append(complement, chunk...)
+					}
+				}
+
+				if test(complement) {
+					elems = complement
+					n = max(n-1, 2)
+					reduced = true
+					break
+				}
+			}
+		}
+
+		if reduced {
+			continue
+		}
+
+		if n >= len(elems) {
+			break
+		}
+		n = min(2*n, len(elems))
+	}
+
+	return elems
+}
+
+// splitSchedule splits the elements into n chunks of nearly equal size
+//
+// Parameter:
+//   - elems []scheduleElem: the elements
+//   - n int: number of chunks
+//
+// Returns:
+//   - [][]scheduleElem: the chunks
+func splitSchedule(elems []scheduleElem, n int) [][]scheduleElem {
+	res := make([][]scheduleElem, 0, n)
+	start := 0
+	for i := range n {
+		end := start + (len(elems)-start)/(n-i)
+		res = append(res, elems[start:end])
+		start = end
+	}
+	return res
+}
+
+// getSchedule returns all operations in a trace whose order can be enforced
+// by the replay, in the order of the trace
+//
+// Parameter:
+//   - tracePath string: path to the trace
+//
+// Returns:
+//   - []scheduleElem: the operations
+//   - error
+func getSchedule(tracePath string) ([]scheduleElem, error) {
+	tr, err := io.ReadTrace(tracePath)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]scheduleElem, 0)
+	counter := make(map[string]int)
+
+	traceIter := tr.AsIterator()
+	for elem := traceIter.Next(); elem != nil; elem = traceIter.Next() {
+		if !isReplayed(elem) {
+			continue
+		}
+
+		key := fmt.Sprintf("%d:%s", elem.Routine(), elem.Pos())
+		counter[key]++
+		res = append(res, scheduleElem{key, elem.T(trace.Sorting), counter[key]})
+	}
+
+	return res, nil
+}
+
+// isReplayed returns whether the order of an element is enforced by the replay
+//
+// Parameter:
+//   - elem trace.Element: the element
+//
+// Returns:
+//   - bool: true if the replay enforces the order of the element
+func isReplayed(elem trace.Element) bool {
+	switch elem.(type) {
+	case *trace.ElementFork, *trace.ElementChannel, *trace.ElementSelect,
+		*trace.ElementMutex, *trace.ElementOnce, *trace.ElementWait, *trace.ElementCond:
+		return true
+	case *trace.ElementAtomic:
+		return !flags.IgnoreAtomics
+	}
+	return false
+}
+
+// replaySchedule replays a rewritten trace where only the operations in
+// schedule are enforced and returns the exit code of the replay
+//
+// Parameter:
+//   - tracePath string: path to the rewritten trace
+//   - schedule []scheduleElem: the enforced operations
+//   - replay replayCandidate: function to run the replay
+//
+// Returns:
+//   - int: the replay exit code, -1 if it could not be determined
+func replaySchedule(tracePath string, schedule []scheduleElem, replay replayCandidate) int {
+	candidatePath := tracePath + minimizeSuffix
+	defer os.RemoveAll(candidatePath)
+
+	err := copyTraceFolder(tracePath, candidatePath)
+	if err != nil {
+		log.Error("Could not create trace for minimization: ", err.Error())
+		return -1
+	}
+
+	err = writeSchedule(filepath.Join(candidatePath, paths.NameReplayActive), schedule)
+	if err != nil {
+		log.Error("Could not create trace for minimization: ", err.Error())
+		return -1
+	}
+
+	outputPath := candidatePath + ".out"
+	output, err := os.Create(outputPath)
+	if err != nil {
+		log.Error("Could not create output for minimization: ", err.Error())
+		return -1
+	}
+	defer os.Remove(outputPath)
+
+	traceNum := strings.TrimPrefix(filepath.Base(candidatePath), "rewrittenTrace_")
+	replay(traceNum, output)
+	output.Close()
+
+	return getReplayExitCode(outputPath)
+}
+
+// runReplayExitCode runs a replay with its output written into a separate
+// file for the trace, copies the output to echo and returns the exit code of
+// the replay. The output file is removed afterwards.
+//
+// Parameter:
+//   - tracePath string: path to the replayed trace
+//   - echo *os.File: file the output is copied to
+//   - replay func(output *os.File): function to run the replay
+//
+// Returns:
+//   - int: the exit code of the replay, -1 if not found
+func runReplayExitCode(tracePath string, echo *os.File, replay func(output *os.File)) int {
+	outputPath := tracePath + ".out"
+	output, err := os.Create(outputPath)
+	if err != nil {
+		log.Error("Could not create output for replay: ", err.Error())
+		replay(echo)
+		return -1
+	}
+	defer os.Remove(outputPath)
+
+	replay(output)
+	output.Close()
+
+	if content, err := os.ReadFile(outputPath); err == nil {
+		echo.Write(content)
+	}
+
+	return getReplayExitCode(outputPath)
+}
+
+// copyTraceFolder copies all trace files except the replay active file
+//
+// Parameter:
+//   - from string: path to the trace
+//   - to string: path to the copy
+//
+// Returns:
+//   - error
+func copyTraceFolder(from, to string) error {
+	err := os.MkdirAll(to, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || file.Name() == paths.NameReplayActive ||
+			file.Name() == paths.NameMinimalSchedule || file.Name() == paths.NameDivergence {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(from, file.Name()))
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(to, file.Name()), content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSchedule writes a schedule in the format of the replay active file,
+// with partial replay starting at the beginning of the execution. Only the
+// operations in the schedule are read from the trace, all others run freely.
+//
+// Parameter:
+//   - path string: path to the file
+//   - schedule []scheduleElem: the enforced operations
+//
+// Returns:
+//   - error
+func writeSchedule(path string, schedule []scheduleElem) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := bufio.NewWriter(f)
+	writer.WriteString("0\n")
+	writer.WriteString(onlyActiveMarker + "\n")
+	for _, elem := range schedule {
+		writer.WriteString(elem.String() + "\n")
+	}
+
+	return writer.Flush()
+}
+
+// getReplayExitCode returns the exit code of the last replay in an output file
+//
+// Parameter:
+//   - output string: path to the output file
+//
+// Returns:
+//   - int: the exit code, -1 if no replay exit code was found
+func getReplayExitCode(output string) int {
+	content, err := os.ReadFile(output)
+	if err != nil {
+		return -1
+	}
+
+	pref := "Exit Replay with code  "
+	res := -1
+	for line := range strings.SplitSeq(string(content), "\n") {
+		if strings.HasPrefix(line, pref) {
+			line = strings.TrimPrefix(line, pref)
+			exitCode, err := strconv.Atoi(strings.Split(line, " ")[0])
+			if err == nil {
+				res = exitCode
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: progInput.go
+// Brief: Inputs of main programs, i.e. arguments, environment and stdin,
+//    that are recorded into the trace and used again in replay and fuzzing
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package toolchain
+
+import (
+	"advocate/utils/command"
+	"advocate/utils/flags"
+	"advocate/utils/io"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// getProgInputFromFlags returns the input of the main program set with
+// -args, -stdin and -env
+//
+// Returns:
+//   - io.ProgInput: the input
+//   - error
+func getProgInputFromFlags() (io.ProgInput, error) {
+	res := io.ProgInput{}
+
+	args, err := splitArgs(flags.ProgArgs)
+	if err != nil {
+		return res, err
+	}
+	res.Args = args
+
+	if flags.ProgStdin != "" {
+		res.Stdin, err = os.ReadFile(flags.ProgStdin)
+		if err != nil {
+			return res, fmt.Errorf("Could not read stdin file: %s", err.Error())
+		}
+	}
+
+	for env := range strings.SplitSeq(flags.ProgEnv, ",") {
+		env = strings.TrimSpace(env)
+		if env == "" {
+			continue
+		}
+
+		if strings.Contains(env, "=") {
+			res.Env = append(res.Env, env)
+		} else if value, ok := os.LookupEnv(env); ok {
+			res.Env = append(res.Env, env+"="+value)
+		} else {
+			res.Unset = append(res.Unset, env)
+		}
+	}
+
+	return res, nil
+}
+
+// getProgInputForReplay returns the input the main program must be run with
+// to replay a trace. If the trace does not contain an input, the input set
+// with the flags is used.
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - io.ProgInput: the input
+func getProgInputForReplay(tracePath string) io.ProgInput {
+	input, found, err := io.ReadProgInput(tracePath)
+	if err != nil {
+		log.Error("Could not read program input: ", err.Error())
+	}
+	if found && err == nil {
+		return input
+	}
+
+	input, err = getProgInputFromFlags()
+	if err != nil {
+		log.Error(err.Error())
+	}
+	return input
+}
+
+// writeProgInput stores the input of the main program in the recorded trace
+//
+// Parameter:
+//   - input io.ProgInput: the input
+//   - tracePath string: path to the trace folder
+func writeProgInput(input io.ProgInput, tracePath string) {
+	if _, err := os.Stat(tracePath); err != nil {
+		return
+	}
+
+	if err := io.WriteProgInput(input, tracePath); err != nil {
+		log.Error("Could not write program input: ", err.Error())
+	}
+}
+
+// copyProgInput copies the recorded program input from a trace into a
+// rewritten trace, so that the rewritten trace is replayed with the same input
+//
+// Parameter:
+//   - pathTrace string: path to the recorded trace
+//   - newTrace string: path to the rewritten trace
+func copyProgInput(pathTrace, newTrace string) {
+	content, err := os.ReadFile(filepath.Join(pathTrace, paths.NameProgInput))
+	if err != nil {
+		return
+	}
+
+	err = os.WriteFile(filepath.Join(newTrace, paths.NameProgInput), content, 0644)
+	if err != nil {
+		log.Error("Could not copy program input: ", err.Error())
+	}
+}
+
+// runProg runs a main program with the given input
+//
+// Parameter:
+//   - osOut *os.File: file to write the output to
+//   - osErr *os.File: file to write the error output to
+//   - execPath string: path to the executable
+//   - input io.ProgInput: the input
+//
+// Returns:
+//   - error
+func runProg(osOut, osErr *os.File, execPath string, input io.ProgInput) error {
+	if input.IsEmpty() {
+		return command.RunCommand(osOut, osErr, command.NoTimeout, execPath)
+	}
+
+	return command.RunCommandInput(osOut, osErr, command.NoTimeout, input.Stdin, input.Environ(), execPath, input.Args...)
+}
+
+// splitArgs splits command line arguments at white spaces. Parts in single or
+// double quotes are not split, a backslash escapes the next character
+// outside of single quotes.
+//
+// Parameter:
+//   - s string: the arguments
+//
+// Returns:
+//   - []string: the arguments
+//   - error
+func splitArgs(s string) ([]string, error) {
+	res := make([]string, 0)
+
+	current := strings.Builder{}
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				res = append(res, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("Invalid program arguments: %s", s)
+	}
+
+	if inArg {
+		res = append(res, current.String())
+	}
+
+	return res, nil
+}
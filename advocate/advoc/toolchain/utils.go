@@ -12,6 +12,7 @@ package toolchain
 
 import (
 	"advocate/utils/helper"
+	"advocate/utils/log"
 	"advocate/utils/paths"
 	"bufio"
 	"io"
@@ -51,10 +52,11 @@ func extractTraceNumber(trace string) (string, string) {
 
 	scanner := bufio.NewScanner(file)
 	if scanner.Scan() {
+		// the line has the form index#bugString#exitCode, the bug string can contain #
 		line := scanner.Text()
-		elems := strings.Split(line, "#")
-		if len(elems) > 1 {
-			bugString = elems[1]
+		first, last := strings.Index(line, "#"), strings.LastIndex(line, "#")
+		if first != -1 && last > first {
+			bugString = line[first+1 : last]
 		}
 	}
 
@@ -122,3 +124,18 @@ func wasReplaySuc(output string) bool {
 
 	return false
 }
+
+// logReplayDivergence logs if the replay of a trace diverged from the trace
+//
+// Parameter:
+//   - trace string: path to the replayed trace
+func logReplayDivergence(trace string) {
+	path := filepath.Join(trace, paths.NameDivergence)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	kind, _, _ := strings.Cut(strings.TrimPrefix(string(content), "Kind!"), "\n")
+	log.Infof("Replay of %s diverged from the trace (%s). See %s", trace, kind, path)
+}
@@ -13,6 +13,8 @@ package a_analysis
 
 import (
 	"advocate/analysis/a_base"
+	_ "advocate/analysis/a_query" // registers the query scenario
+	"advocate/analysis/a_registry"
 	"advocate/analysis/analysis/a_elements"
 	"advocate/analysis/analysis/a_scenarios"
 	"advocate/analysis/hb/a_cssts"
@@ -25,6 +27,7 @@ import (
 	"advocate/utils/control"
 	"advocate/utils/flags"
 	"advocate/utils/log"
+	"advocate/utils/results/results"
 	"advocate/utils/timer"
 )
 
@@ -46,6 +49,8 @@ func RunAnalysis(fuzzing bool) {
 
 	a_base.AnalysisFuzzingFlow = fuzzing
 
+	results.SetSubtestTrace(&a_base.MainTrace)
+
 	timer.Start(timer.Analysis)
 	defer timer.Stop(timer.Analysis)
 
@@ -55,13 +60,11 @@ func RunAnalysis(fuzzing bool) {
 		RunHBAnalysis(fuzzing)
 	}
 
-	if a_base.AnalysisCasesMap[flags.Leak] || flags.OnlyAPanicAndLeak {
-		err := a_scenarios.Blocked()
-		if err != nil {
+	for _, ba := range a_registry.BlockedAnalyzers() {
+		if err := ba.AnalyzeBlocked(); err != nil {
 			log.Error("Failed to read block info: ", err.Error())
 		}
 	}
-
 }
 
 // RunHBAnalysis runs the full analysis happens before based analysis
@@ -86,6 +89,17 @@ func RunHBAnalysis(fuzzing bool) {
 		}
 	}
 
+	// a truncated or filtered trace does not contain all adds, dones, locks and unlocks
+	if a_base.TraceTruncated || a_base.TraceFiltered {
+		a_base.AnalysisCasesMap[flags.DoneBeforeAdd] = false
+		a_base.AnalysisCasesMap[flags.UnlockBeforeLock] = false
+		a_elements.CollectRecordedSends()
+	}
+
+	if a_base.TraceFiltered {
+		a_elements.CollectSummarizedObjects()
+	}
+
 	if hb.CalcVC {
 		a_vc.InitVC()
 	}
@@ -98,12 +112,13 @@ func RunHBAnalysis(fuzzing bool) {
 		a_cssts.InitCSSTs(a_base.GetTraceLengths())
 	}
 
-	if a_base.AnalysisCasesMap[flags.ResourceDeadlock] {
-		a_scenarios.ResetState()
-	}
+	scenarios := a_registry.Start()
 
-	if a_base.AnalysisCasesMap[flags.MixedDeadlock] {
-		a_scenarios.ResetMixedDeadlockState()
+	hbState := &a_registry.HBState{
+		Fuzzing:   fuzzing,
+		CalcVC:    hb.CalcVC,
+		CalcPog:   hb.CalcPog,
+		CalcCssts: hb.CalcCssts,
 	}
 
 	if hb.CalcVC {
@@ -127,11 +142,16 @@ func RunHBAnalysis(fuzzing bool) {
 		// count how many operations where executed on the underlying structure
 		// do not count for operations that do not have an underlying structure
 		switch e := elem.(type) {
-		case *trace.ElementFork, *trace.ElementAlloc, *trace.ElementReplay, *trace.ElementRoutineEnd:
+		case *trace.ElementFork, *trace.ElementAlloc, *trace.ElementReplay, *trace.ElementRoutineEnd, *trace.ElementSubtest, *trace.ElementSummary, *trace.ElementEvent, *trace.ElementCustomSync:
 		default:
 			a_base.AddOpsPerID(e.ObjID())
 		}
 
+		// recorded operations on objects also used in filtered packages
+		if a_base.TraceFiltered {
+			a_elements.AcquireFromSummaries(elem)
+		}
+
 		switch e := elem.(type) {
 		case *trace.ElementAtomic:
 			a_elements.AnalyzeAtomic(e)
@@ -176,22 +196,18 @@ func RunHBAnalysis(fuzzing bool) {
 			a_elements.AnalyzeRoutineEnd(e)
 		case *trace.ElementAlloc:
 			a_elements.AnalyzeNew(e)
+		case *trace.ElementSummary:
+			a_elements.UpdateSummary(e)
+		case *trace.ElementCustomSync:
+			a_elements.UpdateCustomSync(e)
 		}
 
-		if a_base.AnalysisCasesMap[flags.ResourceDeadlock] {
-			switch e := elem.(type) {
-			case *trace.ElementMutex:
-				a_scenarios.HandleMutexEventForRessourceDeadlock(*e)
-			}
+		if a_base.TraceFiltered {
+			a_elements.ReleaseToSummaries(elem)
 		}
 
-		if a_base.AnalysisCasesMap[flags.MixedDeadlock] {
-			switch e := elem.(type) {
-			case *trace.ElementMutex:
-				a_scenarios.HandleMutexEventForMixedDeadlock(e)
-			case *trace.ElementChannel:
-				a_scenarios.HandleChannelEventForMixedDeadlock(e)
-			}
+		for _, s := range scenarios {
+			s.OnElement(elem, hbState)
 		}
 
 		if control.WasCanceled() {
@@ -212,31 +228,11 @@ func RunHBAnalysis(fuzzing bool) {
 		return
 	}
 
-	if a_base.AnalysisCasesMap[flags.DoneBeforeAdd] {
-		a_scenarios.CheckForDoneBeforeAdd()
-	}
-
-	if control.WasCanceled() {
-		return
-	}
+	for _, s := range scenarios {
+		s.Finish()
 
-	if a_base.AnalysisCasesMap[flags.ResourceDeadlock] {
-		a_scenarios.CheckForResourceDeadlock()
-	}
-
-	if control.WasCanceled() {
-		return
-	}
-
-	if a_base.AnalysisCasesMap[flags.MixedDeadlock] {
-		a_scenarios.CheckForMixedDeadlock()
-	}
-
-	if control.WasCanceled() {
-		return
-	}
-
-	if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-		a_scenarios.CheckForUnlockBeforeLock()
+		if control.WasCanceled() {
+			return
+		}
 	}
 }
@@ -33,6 +33,9 @@ var (
 	WaitingReceive = make([]*trace.ElementChannel, 0)
 	MaxOpID        = make(map[int]int)
 
+	// for truncated traces, the oIDs of the sends on buffered channels in the trace
+	RecordedSends = make(map[int]map[int]struct{}) // id -> oID -> struct{}
+
 	// most recent send, used for detection of send on closed
 	HasSend        = make(map[int]bool)                  // id -> bool
 	MostRecentSend = make(map[int]map[int]ElemWithVcVal) // routine -> id -> vcTID
@@ -99,6 +102,12 @@ var (
 	// vector clocks for last write times
 	LastAtomicWriter = make(map[int]*trace.ElementAtomic)
 
+	// objects on which operations have been summarized because of a package filter
+	SummarizedObjects = make(map[int]struct{})
+
+	// last release on each summarized object, the vc is the join of all releases
+	SummaryRelease = make(map[int]*ElemWithVc)
+
 	// channel creation position
 	NewChan = make(map[int]string) // id -> pos
 )
@@ -42,6 +42,8 @@ func ClearData() {
 	MostRecentAcquireTotal = make(map[int]ElemWithVc)
 	RLockCount = make(map[int]map[int]int)
 	LastAtomicWriter = make(map[int]*trace.ElementAtomic)
+	SummaryRelease = make(map[int]*ElemWithVc)
+	SummarizedObjects = make(map[int]struct{})
 	NewChan = make(map[int]string)
 	CurrentlyWaiting = make(map[int][]*trace.ElementCond)
 	LeakingChannels = make(map[int][]VectorClockTID2)
@@ -56,6 +58,11 @@ func ClearData() {
 	replayTimeoutAck = 0
 	ActiveReleased = 0
 	AllActiveReleased = 0
+	TraceTruncated = false
+	TraceDumpedAlive = false
+	flightRecorderLen = 0
+	TraceFiltered = false
+	recordFilter = ""
 	FuzzingFlowOnce = make([]ConcurrentEntry, 0)
 	FuzzingFlowMutex = make([]ConcurrentEntry, 0)
 	FuzzingFlowSend = make([]ConcurrentEntry, 0)
@@ -72,4 +79,5 @@ func ClearData() {
 
 	WaitingReceive = make([]*trace.ElementChannel, 0)
 	MaxOpID = make(map[int]int)
+	RecordedSends = make(map[int]map[int]struct{})
 }
@@ -37,6 +37,15 @@ var (
 	AllActiveReleased int
 
 	durationInSeconds = -1 // the duration of the recording in seconds
+
+	// flight recorder info
+	TraceTruncated    = false // the trace only contains the last elements of each routine
+	TraceDumpedAlive  = false // the trace was dumped while the program was still running
+	flightRecorderLen = 0     // number of elements kept per routine by the flight recorder
+
+	// package filter info
+	TraceFiltered = false // some operations were not recorded because of a package filter or an ignored region
+	recordFilter  = ""    // the package filter used for the recording
 )
 
 // SetExitInfo stores the exit code and exit position of a run
@@ -85,6 +94,46 @@ func GetTimeoutHappened(ack bool) bool {
 	}
 }
 
+// SetFlightRecorderInfo stores the information about a trace that was
+// recorded with the flight recorder
+//
+// Parameter:
+//   - size int: number of elements kept per routine, 0 if the flight recorder was not used
+//   - reason string: reason for the dump of the trace (deadlock, panic, signal, call, exit)
+func SetFlightRecorderInfo(size int, reason string) {
+	flightRecorderLen = size
+	TraceTruncated = size > 0
+	TraceDumpedAlive = TraceTruncated && (reason == "signal" || reason == "call")
+}
+
+// GetFlightRecorderSize returns the number of elements kept per routine
+// by the flight recorder
+//
+// Returns:
+//   - int: the size, 0 if the trace was not recorded with the flight recorder
+func GetFlightRecorderSize() int {
+	return flightRecorderLen
+}
+
+// SetFilterInfo stores the package filter that was used for the recording
+// and if regions were ignored with advocatego.Ignore
+//
+// Parameter:
+//   - filter string: the package filter, empty if no filter was used
+//   - ignoreRegions bool: true if at least one region was ignored
+func SetFilterInfo(filter string, ignoreRegions bool) {
+	recordFilter = filter
+	TraceFiltered = filter != "" || ignoreRegions
+}
+
+// GetRecordFilter returns the package filter that was used for the recording
+//
+// Returns:
+//   - string: the package filter, empty if no filter was used
+func GetRecordFilter() string {
+	return recordFilter
+}
+
 // SetRuntimeDurationSec is a setter for durationInSeconds
 //
 // Parameter:
@@ -0,0 +1,658 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: eval.go
+// Brief: Evaluate a query over a trace
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_query
+
+import (
+	"advocate/analysis/hb/a_clock"
+	"advocate/trace"
+	"advocate/utils/control"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxVars is the maximum number of variables in a query
+const maxVars = 4
+
+// ========================================================
+// MARK: Fields
+// ========================================================
+
+// fieldNames are the fields of an element that can be used in a query
+var fieldNames = []string{"type", "kind", "obj", "id", "routine", "pos", "file", "line", "name", "tpre", "tpost"}
+
+// numeric fields, they are compared as numbers
+var numericFields = map[string]bool{
+	"id": true, "routine": true, "line": true, "tpre": true, "tpost": true,
+}
+
+// typeNames maps the operation types to the names used with the type field
+var typeNames = map[trace.OperationType]string{
+	trace.ChannelSend:  "send",
+	trace.ChannelRecv:  "recv",
+	trace.ChannelClose: "close",
+
+	trace.MutexLock:     "lock",
+	trace.MutexRLock:    "rlock",
+	trace.MutexTryLock:  "trylock",
+	trace.MutexTryRLock: "tryrlock",
+	trace.MutexUnlock:   "unlock",
+	trace.MutexRUnlock:  "runlock",
+
+	trace.WaitAdd:  "add",
+	trace.WaitDone: "done",
+	trace.WaitWait: "wait",
+
+	trace.CondWait:      "condwait",
+	trace.CondSignal:    "signal",
+	trace.CondBroadcast: "broadcast",
+
+	trace.OnceSuc:  "once",
+	trace.OnceFail: "oncefail",
+
+	trace.AtomicLoad:        "load",
+	trace.AtomicStore:       "store",
+	trace.AtomicAdd:         "atomicadd",
+	trace.AtomicAnd:         "and",
+	trace.AtomicOr:          "or",
+	trace.AtomicSwap:        "swap",
+	trace.AtomicCompAndSwap: "cas",
+
+	trace.SelectOp:   "select",
+	trace.ForkOp:     "go",
+	trace.EndRoutine: "end",
+
+	trace.NewAtomic:  "new",
+	trace.NewChannel: "new",
+	trace.NewCond:    "new",
+	trace.NewMutex:   "new",
+	trace.NewOnce:    "new",
+	trace.NewWait:    "new",
+
+	trace.FuncCall:   "call",
+	trace.FuncReturn: "return",
+
+	trace.EventMarker: "event",
+
+	trace.CustomRelease: "release",
+	trace.CustomAcquire: "acquire",
+
+	trace.SummaryRelease: "summary",
+	trace.SummaryAcquire: "summary",
+	trace.SummaryBoth:    "summary",
+
+	trace.SubtestStart: "subteststart",
+	trace.SubtestEnd:   "subtestend",
+}
+
+// kindNames maps the primitive types to the names used with the kind field
+var kindNames = map[trace.OperationType]string{
+	trace.Atomic:     "atomic",
+	trace.Channel:    "channel",
+	trace.Cond:       "cond",
+	trace.Fork:       "go",
+	trace.End:        "end",
+	trace.Mutex:      "mutex",
+	trace.New:        "new",
+	trace.Once:       "once",
+	trace.Select:     "select",
+	trace.Wait:       "waitgroup",
+	trace.Func:       "func",
+	trace.Subtest:    "subtest",
+	trace.Summary:    "summary",
+	trace.Event:      "event",
+	trace.CustomSync: "custom",
+}
+
+// isField returns whether a name is a field of an element
+//
+// Parameter:
+//   - name string: the name
+//
+// Returns:
+//   - bool: true if name is a field
+func isField(name string) bool {
+	for _, f := range fieldNames {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isPredicate returns whether a name is a predicate
+//
+// Parameter:
+//   - name string: the name
+//
+// Returns:
+//   - bool: true if name is a predicate
+func isPredicate(name string) bool {
+	switch name {
+	case "hb", "concurrent", "sameObj", "sameRoutine", "holds":
+		return true
+	}
+	return false
+}
+
+// globToRegexp converts a pattern with * (any string) and ? (any character)
+// into a regular expression that matches the full string
+//
+// Parameter:
+//   - glob string: the pattern
+//
+// Returns:
+//   - *regexp.Regexp: the regular expression
+func globToRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range glob {
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// ========================================================
+// MARK: Evaluation
+// ========================================================
+
+// Match is one assignment of trace elements to the variables of a query,
+// in the order of Query.Vars, for which the query holds
+type Match []trace.Element
+
+// evaluator evaluates a query on one trace
+//
+// Fields:
+//   - query *Query: the query
+//   - tr *trace.Trace: the trace
+//   - clocks map[trace.Element]*a_clock.VectorClock: the vector clocks of the elements
+//   - index map[trace.Element]int: index of each element in its routine
+//   - release map[trace.Element]int: index of the unlock releasing a lock, -1 if never released
+//   - globs map[string]*regexp.Regexp: compiled patterns
+type evaluator struct {
+	query   *Query
+	tr      *trace.Trace
+	clocks  map[trace.Element]*a_clock.VectorClock
+	index   map[trace.Element]int
+	release map[trace.Element]int
+	globs   map[string]*regexp.Regexp
+}
+
+// Evaluate returns the matches of the query in a trace. The variables of
+// a match are bound to different elements.
+//
+// Parameter:
+//   - tr *trace.Trace: the trace
+//   - clocks map[trace.Element]*a_clock.VectorClock: the vector clocks of the
+//     elements. If nil, hb only orders elements of the same routine and
+//     concurrent is always false
+//   - limit int: maximum number of returned matches, 0 for no limit
+//
+// Returns:
+//   - []Match: the matches
+func (this *Query) Evaluate(tr *trace.Trace, clocks map[trace.Element]*a_clock.VectorClock, limit int) []Match {
+	ev := evaluator{
+		query:   this,
+		tr:      tr,
+		clocks:  clocks,
+		index:   make(map[trace.Element]int),
+		release: make(map[trace.Element]int),
+		globs:   make(map[string]*regexp.Regexp),
+	}
+
+	elems := ev.collectElements()
+
+	// split the query into its conjuncts, so that they can be checked as
+	// soon as all of their variables are bound
+	varIndex := make(map[string]int)
+	for i, v := range this.Vars {
+		varIndex[v] = i
+	}
+
+	clauses := make([][]node, len(this.Vars))
+	for _, c := range conjuncts(this.expr) {
+		last := 0
+		for _, v := range c.vars() {
+			last = max(last, varIndex[v])
+		}
+		clauses[last] = append(clauses[last], c)
+	}
+
+	// candidates of each variable, filtered by the conjuncts that only use this variable
+	candidates := make([][]trace.Element, len(this.Vars))
+	for i, v := range this.Vars {
+		single := make([]node, 0)
+		rest := make([]node, 0)
+		for _, c := range clauses[i] {
+			vars := c.vars()
+			if len(vars) == 1 && vars[0] == v {
+				single = append(single, c)
+			} else {
+				rest = append(rest, c)
+			}
+		}
+		clauses[i] = rest
+
+		for _, elem := range elems {
+			binding := map[string]trace.Element{v: elem}
+			if ev.all(single, binding) {
+				candidates[i] = append(candidates[i], elem)
+			}
+		}
+	}
+
+	res := make([]Match, 0)
+	binding := make(map[string]trace.Element)
+	used := make(map[trace.Element]bool)
+
+	var search func(depth int) bool
+	search = func(depth int) bool {
+		if control.WasCanceled() {
+			return false
+		}
+
+		if depth == len(this.Vars) {
+			m := make(Match, len(this.Vars))
+			for i, v := range this.Vars {
+				m[i] = binding[v]
+			}
+			res = append(res, m)
+			return limit <= 0 || len(res) < limit
+		}
+
+		v := this.Vars[depth]
+		for _, elem := range candidates[depth] {
+			if used[elem] {
+				continue
+			}
+
+			binding[v] = elem
+			if ev.all(clauses[depth], binding) {
+				used[elem] = true
+				cont := search(depth + 1)
+				used[elem] = false
+				if !cont {
+					return false
+				}
+			}
+		}
+		delete(binding, v)
+		return true
+	}
+
+	search(0)
+
+	return res
+}
+
+// collectElements returns all elements of the trace sorted by routine and
+// stores the index of each element in its routine
+//
+// Returns:
+//   - []trace.Element: the elements
+func (this *evaluator) collectElements() []trace.Element {
+	routines := make([]int, 0, len(this.tr.GetTraces()))
+	for id := range this.tr.GetTraces() {
+		routines = append(routines, id)
+	}
+	sort.Ints(routines)
+
+	res := make([]trace.Element, 0)
+	for _, id := range routines {
+		for i, elem := range this.tr.GetRoutineTrace(id).Elems() {
+			this.index[elem] = i
+			if _, ok := elem.(*trace.ElementReplay); ok {
+				continue
+			}
+			res = append(res, elem)
+		}
+	}
+	return res
+}
+
+// conjuncts splits an expression at the top level conjunctions
+//
+// Parameter:
+//   - n node: the expression
+//
+// Returns:
+//   - []node: the conjuncts
+func conjuncts(n node) []node {
+	if and, ok := n.(*andNode); ok {
+		return append(conjuncts(and.left), conjuncts(and.right)...)
+	}
+	return []node{n}
+}
+
+// all returns whether all expressions hold for a binding
+//
+// Parameter:
+//   - exprs []node: the expressions
+//   - binding map[string]trace.Element: the bound variables
+//
+// Returns:
+//   - bool: true if all expressions hold
+func (this *evaluator) all(exprs []node, binding map[string]trace.Element) bool {
+	for _, e := range exprs {
+		if !this.eval(e, binding) {
+			return false
+		}
+	}
+	return true
+}
+
+// eval evaluates an expression for a binding of all its variables
+//
+// Parameter:
+//   - n node: the expression
+//   - binding map[string]trace.Element: the bound variables
+//
+// Returns:
+//   - bool: the value of the expression
+func (this *evaluator) eval(n node, binding map[string]trace.Element) bool {
+	switch e := n.(type) {
+	case *andNode:
+		return this.eval(e.left, binding) && this.eval(e.right, binding)
+	case *orNode:
+		return this.eval(e.left, binding) || this.eval(e.right, binding)
+	case *notNode:
+		return !this.eval(e.expr, binding)
+	case *predNode:
+		return this.predicate(e.name, binding[e.args[0]], binding[e.args[1]])
+	case *cmpNode:
+		return this.compare(e, binding)
+	}
+	return false
+}
+
+// compare evaluates a comparison
+//
+// Parameter:
+//   - cmp *cmpNode: the comparison
+//   - binding map[string]trace.Element: the bound variables
+//
+// Returns:
+//   - bool: the value of the comparison
+func (this *evaluator) compare(cmp *cmpNode, binding map[string]trace.Element) bool {
+	left := this.value(cmp.left, binding)
+	right := this.value(cmp.right, binding)
+
+	// allow the operation codes of the trace, e.g. type == CS
+	if cmp.left.field == "type" && cmp.right.field == "" {
+		if name, ok := typeNames[trace.OperationType(right)]; ok {
+			right = name
+		}
+	}
+
+	switch cmp.op {
+	case "~", "!~":
+		re, ok := this.globs[right]
+		if !ok {
+			re = globToRegexp(right)
+			this.globs[right] = re
+		}
+		return re.MatchString(left) == (cmp.op == "~")
+	}
+
+	numeric := numericFields[cmp.left.field] || numericFields[cmp.right.field]
+	l, errL := strconv.Atoi(left)
+	r, errR := strconv.Atoi(right)
+	if numeric && errL == nil && errR == nil {
+		switch cmp.op {
+		case "==":
+			return l == r
+		case "!=":
+			return l != r
+		case "<":
+			return l < r
+		case "<=":
+			return l <= r
+		case ">":
+			return l > r
+		case ">=":
+			return l >= r
+		}
+		return false
+	}
+
+	switch cmp.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	}
+	return false
+}
+
+// value returns the value of an operand as string
+//
+// Parameter:
+//   - o operand: the operand
+//   - binding map[string]trace.Element: the bound variables
+//
+// Returns:
+//   - string: the value
+func (this *evaluator) value(o operand, binding map[string]trace.Element) string {
+	if o.field == "" {
+		return o.literal
+	}
+
+	elem := binding[o.variable]
+
+	switch o.field {
+	case "type":
+		return typeNames[elem.Type(true)]
+	case "kind":
+		return kindNames[elem.Type(false)]
+	case "obj":
+		if id := this.tr.GetObjectIdentity(elem.ObjID()); id != "" {
+			return id
+		}
+		return strconv.Itoa(elem.ObjID())
+	case "id":
+		return strconv.Itoa(elem.ObjID())
+	case "routine":
+		return strconv.Itoa(elem.Routine())
+	case "pos":
+		return fmt.Sprintf("%s:%d", elem.File(), elem.Line())
+	case "file":
+		return elem.File()
+	case "line":
+		return strconv.Itoa(elem.Line())
+	case "name":
+		return elemName(elem)
+	case "tpre":
+		return strconv.Itoa(elem.T(trace.Request))
+	case "tpost":
+		return strconv.Itoa(elem.T(trace.Commit))
+	}
+
+	return ""
+}
+
+// elemName returns the name of an event or of the function of a call or return
+//
+// Parameter:
+//   - elem trace.Element: the element
+//
+// Returns:
+//   - string: the name, empty for all other elements
+func elemName(elem trace.Element) string {
+	switch e := elem.(type) {
+	case *trace.ElementEvent:
+		return e.Name()
+	case *trace.ElementFunc:
+		return e.Name()
+	case *trace.ElementReturn:
+		if f := e.Function(); f != nil {
+			return f.Name()
+		}
+	}
+	return ""
+}
+
+// ========================================================
+// MARK: Predicates
+// ========================================================
+
+// predicate evaluates a predicate on two elements
+//
+// Parameter:
+//   - name string: the name of the predicate
+//   - x trace.Element: the first element
+//   - y trace.Element: the second element
+//
+// Returns:
+//   - bool: the value of the predicate
+func (this *evaluator) predicate(name string, x, y trace.Element) bool {
+	if x == nil || y == nil {
+		return false
+	}
+
+	switch name {
+	case "hb":
+		return this.hb(x, y)
+	case "concurrent":
+		return this.clocks != nil && x != y && x.Routine() != y.Routine() &&
+			!this.hb(x, y) && !this.hb(y, x)
+	case "sameObj":
+		return trace.IsOp(x) && trace.IsOp(y) && x.ObjID() == y.ObjID() &&
+			x.Type(false) == y.Type(false)
+	case "sameRoutine":
+		return x.Routine() == y.Routine()
+	case "holds":
+		return this.holds(x, y)
+	}
+	return false
+}
+
+// hb returns whether x happens before y. Elements of the same routine are
+// ordered by their position in the routine. Elements without vector clock,
+// e.g. events, are ordered by the next element with a vector clock after x
+// and the last element with a vector clock before y in their routine.
+// The stored clocks are the clocks after the element has been handled, i.e.
+// after the value of the own routine has been increased. Elements that
+// synchronize with x receive the value before the increase, so x happens
+// before y in another routine if the clock of y contains at least this value.
+//
+// Parameter:
+//   - x trace.Element: the first element
+//   - y trace.Element: the second element
+//
+// Returns:
+//   - bool: true if x happens before y
+func (this *evaluator) hb(x, y trace.Element) bool {
+	if x == y {
+		return false
+	}
+
+	if x.Routine() == y.Routine() {
+		return this.index[x] < this.index[y]
+	}
+
+	// elements that were never executed do not happen before other elements
+	if this.clocks == nil || (trace.IsOp(x) && x.T(trace.Commit) == 0) {
+		return false
+	}
+
+	xa := this.anchor(x, 1)
+	ya := this.anchor(y, -1)
+	if xa == nil || ya == nil {
+		return false
+	}
+
+	routine := x.Routine()
+	return this.clocks[xa].GetValue(routine) <= this.clocks[ya].GetValue(routine)+1
+}
+
+// anchor returns the element itself if it has a vector clock, otherwise
+// the next (dir = 1) or previous (dir = -1) element with a vector clock
+// in the same routine
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - dir int: direction of the search
+//
+// Returns:
+//   - trace.Element: the element with vector clock, nil if there is none
+func (this *evaluator) anchor(elem trace.Element, dir int) trace.Element {
+	elems := this.tr.GetRoutineTrace(elem.Routine()).Elems()
+	for i := this.index[elem]; i >= 0 && i < len(elems); i += dir {
+		if _, ok := this.clocks[elems[i]]; ok {
+			return elems[i]
+		}
+	}
+	return nil
+}
+
+// holds returns whether y is executed while the lock x is held, i.e. y is
+// in the same routine after x and the mutex has not been unlocked in between
+//
+// Parameter:
+//   - x trace.Element: the lock
+//   - y trace.Element: the element
+//
+// Returns:
+//   - bool: true if x is held while y is executed
+func (this *evaluator) holds(x, y trace.Element) bool {
+	mu, ok := x.(*trace.ElementMutex)
+	if !ok || x.Routine() != y.Routine() || this.index[x] >= this.index[y] {
+		return false
+	}
+
+	switch mu.Type(true) {
+	case trace.MutexLock, trace.MutexRLock:
+	case trace.MutexTryLock, trace.MutexTryRLock:
+		if !mu.IsSuc() {
+			return false
+		}
+	default:
+		return false
+	}
+
+	rel, ok := this.release[x]
+	if !ok {
+		rel = -1
+		elems := this.tr.GetRoutineTrace(x.Routine()).Elems()
+		for i := this.index[x] + 1; i < len(elems); i++ {
+			if elems[i].ObjID() != x.ObjID() {
+				continue
+			}
+			switch elems[i].Type(true) {
+			case trace.MutexUnlock, trace.MutexRUnlock:
+				rel = i
+			}
+			if rel != -1 {
+				break
+			}
+		}
+		this.release[x] = rel
+	}
+
+	return rel == -1 || this.index[y] < rel
+}
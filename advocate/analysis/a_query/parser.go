@@ -0,0 +1,597 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: parser.go
+// Brief: Parser for the trace query language
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ========================================================
+// MARK: Tokens
+// ========================================================
+
+// tokenType is the type of a token of a query
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp     // ==, !=, <, <=, >, >=, ~, !~
+	tokAnd    // &&, and
+	tokOr     // ||, or
+	tokNot    // !, not
+	tokLParen // (
+	tokRParen // )
+	tokComma  // ,
+	tokDot    // .
+)
+
+// token is one token of a query
+//
+// Fields:
+//   - typ tokenType: the type of the token
+//   - val string: the text of the token, for strings without the quotes
+//   - pos int: the offset of the token in the query
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+// tokenize splits a query into its tokens
+//
+// Parameter:
+//   - src string: the query
+//
+// Returns:
+//   - []token: the tokens, ending with tokEOF
+//   - error
+func tokenize(src string) ([]token, error) {
+	res := make([]token, 0)
+
+	i := 0
+	for i < len(src) {
+		c := src[i]
+
+		if unicode.IsSpace(rune(c)) {
+			i++
+			continue
+		}
+
+		start := i
+
+		switch {
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			val, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %s", start, err.Error())
+			}
+			res = append(res, token{tokString, val, start})
+			i = j + 1
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && isIdentPart(src[j]) {
+				j++
+			}
+			word := src[i:j]
+			switch word {
+			case "and":
+				res = append(res, token{tokAnd, word, start})
+			case "or":
+				res = append(res, token{tokOr, word, start})
+			case "not":
+				res = append(res, token{tokNot, word, start})
+			default:
+				res = append(res, token{tokIdent, word, start})
+			}
+			i = j
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			j := i + 1
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			res = append(res, token{tokNumber, src[i:j], start})
+			i = j
+		default:
+			two := ""
+			if i+1 < len(src) {
+				two = src[i : i+2]
+			}
+
+			switch two {
+			case "==", "!=", "<=", ">=", "!~":
+				res = append(res, token{tokOp, two, start})
+				i += 2
+				continue
+			case "&&":
+				res = append(res, token{tokAnd, two, start})
+				i += 2
+				continue
+			case "||":
+				res = append(res, token{tokOr, two, start})
+				i += 2
+				continue
+			}
+
+			switch c {
+			case '<', '>', '~':
+				res = append(res, token{tokOp, string(c), start})
+			case '!':
+				res = append(res, token{tokNot, "!", start})
+			case '(':
+				res = append(res, token{tokLParen, "(", start})
+			case ')':
+				res = append(res, token{tokRParen, ")", start})
+			case ',':
+				res = append(res, token{tokComma, ",", start})
+			case '.':
+				res = append(res, token{tokDot, ".", start})
+			default:
+				return nil, fmt.Errorf("unexpected character '%c' at %d", c, start)
+			}
+			i++
+		}
+	}
+
+	res = append(res, token{tokEOF, "", len(src)})
+	return res, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// ========================================================
+// MARK: AST
+// ========================================================
+
+// node is a boolean expression of a query
+type node interface {
+	// vars returns the variables used in the expression
+	vars() []string
+}
+
+// andNode is a conjunction
+type andNode struct {
+	left, right node
+}
+
+// orNode is a disjunction
+type orNode struct {
+	left, right node
+}
+
+// notNode is a negation
+type notNode struct {
+	expr node
+}
+
+// predNode is a predicate on two variables, e.g. hb(a, b)
+//
+// Fields:
+//   - name string: name of the predicate
+//   - args []string: the variables
+type predNode struct {
+	name string
+	args []string
+}
+
+// cmpNode is a comparison, e.g. a.type == send
+//
+// Fields:
+//   - op string: the comparison operator
+//   - left operand: the left side
+//   - right operand: the right side
+type cmpNode struct {
+	op    string
+	left  operand
+	right operand
+}
+
+// operand is one side of a comparison. It is either a field of a
+// variable or a literal value.
+//
+// Fields:
+//   - variable string: the variable of a field, empty for literals
+//   - field string: the field, empty for literals
+//   - literal string: the value of a literal
+//   - implicit bool: the field was used without a variable
+type operand struct {
+	variable string
+	field    string
+	literal  string
+	implicit bool
+}
+
+func (this *andNode) vars() []string {
+	return appendUnique(this.left.vars(), this.right.vars()...)
+}
+
+func (this *orNode) vars() []string {
+	return appendUnique(this.left.vars(), this.right.vars()...)
+}
+
+func (this *notNode) vars() []string {
+	return this.expr.vars()
+}
+
+func (this *predNode) vars() []string {
+	return appendUnique(nil, this.args...)
+}
+
+func (this *cmpNode) vars() []string {
+	res := make([]string, 0, 2)
+	for _, o := range []operand{this.left, this.right} {
+		// implicit variables are only known after parsing
+		if o.field != "" && o.variable != "" {
+			res = appendUnique(res, o.variable)
+		}
+	}
+	return res
+}
+
+// appendUnique appends the values that are not yet in the slice
+//
+// Parameter:
+//   - s []string: the slice
+//   - vals ...string: the values to add
+//
+// Returns:
+//   - []string: the slice with the added values
+func appendUnique(s []string, vals ...string) []string {
+	for _, v := range vals {
+		found := false
+		for _, e := range s {
+			if e == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+// ========================================================
+// MARK: Parser
+// ========================================================
+
+// defaultVar is the variable of fields that are used without a variable
+const defaultVar = "a"
+
+// parser is a recursive descent parser for queries
+//
+// Fields:
+//   - tokens []token: the tokens of the query
+//   - index int: the index of the next token
+//   - barePreds []*predNode: predicates used without arguments
+//   - implicit []*operand: fields used without a variable
+type parser struct {
+	tokens    []token
+	index     int
+	barePreds []*predNode
+	implicit  []*operand
+}
+
+// Parse parses a query. The query is a boolean expression over variables
+// that are bound to elements of the trace, e.g.
+//
+//	a.type == send && b.type == close && sameObj(a, b) && hb(b, a)
+//
+// Fields used without a variable belong to the only variable of the query.
+// Predicates used without arguments are applied to the first two variables.
+//
+// Parameter:
+//   - name string: the name of the query, used in the results
+//   - src string: the query
+//
+// Returns:
+//   - *Query: the parsed query
+//   - error: if the query is invalid
+func Parse(name, src string) (*Query, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.typ != tokEOF {
+		return nil, fmt.Errorf("unexpected '%s' at %d", t.val, t.pos)
+	}
+
+	vars := expr.vars()
+
+	if len(p.implicit) != 0 {
+		if len(vars) > 1 {
+			return nil, fmt.Errorf("field %s must be used with a variable, e.g. %s.%s, if the query has more than one variable",
+				p.implicit[0].field, vars[0], p.implicit[0].field)
+		}
+
+		v := defaultVar
+		if len(vars) == 1 {
+			v = vars[0]
+		}
+		for _, o := range p.implicit {
+			o.variable = v
+		}
+	}
+
+	vars = expr.vars()
+
+	if len(p.barePreds) != 0 {
+		if len(vars) != 2 {
+			return nil, fmt.Errorf("predicate %s without arguments requires exactly two variables, got %d",
+				p.barePreds[0].name, len(vars))
+		}
+		for _, pred := range p.barePreds {
+			pred.args = []string{vars[0], vars[1]}
+		}
+	}
+
+	vars = expr.vars()
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("query does not contain a variable")
+	}
+	if len(vars) > maxVars {
+		return nil, fmt.Errorf("query contains %d variables, at most %d are supported", len(vars), maxVars)
+	}
+
+	return &Query{
+		Name:   name,
+		Source: strings.TrimSpace(src),
+		Vars:   vars,
+		expr:   expr,
+	}, nil
+}
+
+func (this *parser) peek() token {
+	return this.tokens[this.index]
+}
+
+func (this *parser) next() token {
+	t := this.tokens[this.index]
+	if t.typ != tokEOF {
+		this.index++
+	}
+	return t
+}
+
+// expect consumes the next token if it has the given type
+//
+// Parameter:
+//   - typ tokenType: the expected type
+//   - what string: description of the expected token for the error
+//
+// Returns:
+//   - token: the token
+//   - error: if the next token has another type
+func (this *parser) expect(typ tokenType, what string) (token, error) {
+	t := this.next()
+	if t.typ != typ {
+		if t.typ == tokEOF {
+			return t, fmt.Errorf("expected %s at end of query", what)
+		}
+		return t, fmt.Errorf("expected %s at %d, got '%s'", what, t.pos, t.val)
+	}
+	return t, nil
+}
+
+// or := and ('||' and)*
+func (this *parser) parseOr() (node, error) {
+	left, err := this.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for this.peek().typ == tokOr {
+		this.next()
+		right, err := this.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+
+	return left, nil
+}
+
+// and := not ('&&' not)*
+func (this *parser) parseAnd() (node, error) {
+	left, err := this.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for this.peek().typ == tokAnd {
+		this.next()
+		right, err := this.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+
+	return left, nil
+}
+
+// not := '!' not | primary
+func (this *parser) parseNot() (node, error) {
+	if this.peek().typ == tokNot {
+		this.next()
+		expr, err := this.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{expr}, nil
+	}
+
+	return this.parsePrimary()
+}
+
+// primary := '(' or ')' | predicate | comparison
+func (this *parser) parsePrimary() (node, error) {
+	t := this.peek()
+
+	if t.typ == tokLParen {
+		this.next()
+		expr, err := this.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := this.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	if t.typ == tokIdent && isPredicate(t.val) {
+		after := this.tokens[this.index+1]
+		if after.typ != tokOp && after.typ != tokDot {
+			return this.parsePredicate()
+		}
+	}
+
+	return this.parseComparison()
+}
+
+// predicate := name ['(' ident ',' ident ')']
+func (this *parser) parsePredicate() (node, error) {
+	name := this.next().val
+
+	pred := &predNode{name: name}
+
+	if this.peek().typ != tokLParen {
+		this.barePreds = append(this.barePreds, pred)
+		return pred, nil
+	}
+	this.next()
+
+	for {
+		arg, err := this.expect(tokIdent, "variable")
+		if err != nil {
+			return nil, err
+		}
+		pred.args = append(pred.args, arg.val)
+
+		if this.peek().typ != tokComma {
+			break
+		}
+		this.next()
+	}
+
+	if _, err := this.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+
+	if len(pred.args) != 2 {
+		return nil, fmt.Errorf("predicate %s requires two variables, got %d", name, len(pred.args))
+	}
+
+	return pred, nil
+}
+
+// comparison := operand op operand
+func (this *parser) parseComparison() (node, error) {
+	left, err := this.parseOperand(true)
+	if err != nil {
+		return nil, err
+	}
+
+	opTok, err := this.expect(tokOp, "comparison operator")
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := this.parseOperand(false)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &cmpNode{op: opTok.val, left: *left, right: *right}
+
+	if left.field == "" && right.field == "" {
+		return nil, fmt.Errorf("comparison at %d does not contain a field", opTok.pos)
+	}
+
+	if cmp.left.implicit {
+		this.implicit = append(this.implicit, &cmp.left)
+	}
+
+	for _, o := range []*operand{&cmp.left, &cmp.right} {
+		if o.field != "" && !isField(o.field) {
+			return nil, fmt.Errorf("unknown field %s, possible fields are %s", o.field, strings.Join(fieldNames, ", "))
+		}
+	}
+
+	if (cmp.op == "~" || cmp.op == "!~") && cmp.right.field != "" {
+		return nil, fmt.Errorf("the right side of %s must be a pattern", cmp.op)
+	}
+
+	return cmp, nil
+}
+
+// operand := ident '.' ident | ident | string | number
+// A single identifier is a field on the left side and a literal on the right side.
+//
+// Parameter:
+//   - left bool: true for the left side of a comparison
+func (this *parser) parseOperand(left bool) (*operand, error) {
+	t := this.next()
+
+	switch t.typ {
+	case tokString, tokNumber:
+		return &operand{literal: t.val}, nil
+	case tokIdent:
+		if this.peek().typ == tokDot {
+			this.next()
+			field, err := this.expect(tokIdent, "field")
+			if err != nil {
+				return nil, err
+			}
+			return &operand{variable: t.val, field: field.val}, nil
+		}
+
+		if left {
+			return &operand{field: t.val, implicit: true}, nil
+		}
+		return &operand{literal: t.val}, nil
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+
+	return nil, fmt.Errorf("unexpected '%s' at %d", t.val, t.pos)
+}
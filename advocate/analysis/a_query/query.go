@@ -0,0 +1,198 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: query.go
+// Brief: Queries over recorded traces and the query config
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_query
+
+import (
+	"advocate/trace"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Query is a parsed query of the trace query language
+//
+// Fields:
+//   - Name string: name of the query, used in the results
+//   - Source string: the query as written by the user
+//   - Vars []string: the variables of the query in the order of their first use
+//   - expr node: the parsed expression
+type Query struct {
+	Name   string
+	Source string
+	Vars   []string
+	expr   node
+}
+
+// named query in the config, e.g. noSendAfterStop: a.type == send && ...
+var namedQuery = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_\-]*)\s*:\s*(.*)$`)
+
+// ReadQueries reads a query config. Each line contains one query, that can
+// be preceded by a name and a colon. Empty lines and lines starting with #
+// are ignored. A line ending with \ is continued in the next line.
+//
+// Parameter:
+//   - path string: path to the config
+//
+// Returns:
+//   - []*Query: the queries in the order of the config
+//   - error: if the file could not be read or a query is invalid
+func ReadQueries(path string) ([]*Query, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	res := make([]*Query, 0)
+
+	scanner := bufio.NewScanner(file)
+	lineNr := 0
+	startLine := 0
+	current := ""
+	for scanner.Scan() {
+		lineNr++
+		line := strings.TrimSpace(scanner.Text())
+
+		if current == "" {
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			startLine = lineNr
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		current += line
+
+		name := fmt.Sprintf("query%d", len(res)+1)
+		src := current
+		if m := namedQuery.FindStringSubmatch(current); m != nil {
+			name, src = m[1], m[2]
+		}
+		current = ""
+
+		q, err := Parse(name, src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query in %s:%d: %s", path, startLine, err.Error())
+		}
+		res = append(res, q)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if current != "" {
+		return nil, fmt.Errorf("incomplete query at the end of %s:%d", path, startLine)
+	}
+
+	return res, nil
+}
+
+// ElemString returns a readable representation of an element of a match
+//
+// Parameter:
+//   - tr *trace.Trace: the trace of the element
+//   - elem trace.Element: the element
+//
+// Returns:
+//   - string: the representation
+func ElemString(tr *trace.Trace, elem trace.Element) string {
+	res := typeNames[elem.Type(true)]
+	if res == "" {
+		res = string(elem.Type(true))
+	}
+
+	if name := elemName(elem); name != "" {
+		res += " " + name
+	}
+
+	if trace.IsOp(elem) {
+		obj := tr.GetObjectIdentity(elem.ObjID())
+		if obj == "" {
+			obj = fmt.Sprintf("%d", elem.ObjID())
+		}
+		res += " on " + obj
+	}
+
+	return fmt.Sprintf("%s at %s:%d in routine %d", res, elem.File(), elem.Line(), elem.Routine())
+}
+
+// MatchesString returns a readable representation of the matches of a query
+//
+// Parameter:
+//   - tr *trace.Trace: the trace of the matches
+//   - q *Query: the query
+//   - matches []Match: the matches of the query
+//
+// Returns:
+//   - string: the representation
+func MatchesString(tr *trace.Trace, q *Query, matches []Match) string {
+	var sb strings.Builder
+	for i, m := range matches {
+		fmt.Fprintf(&sb, "Match %d:\n", i+1)
+		for j, elem := range m {
+			fmt.Fprintf(&sb, "\t%s: %s\n", q.Vars[j], ElemString(tr, elem))
+		}
+	}
+	fmt.Fprintf(&sb, "%d matches\n", len(matches))
+	return sb.String()
+}
+
+// elemJSON is the json representation of an element of a match
+type elemJSON struct {
+	Type    string `json:"type"`
+	Routine int    `json:"routine"`
+	Object  string `json:"object,omitempty"`
+	Pos     string `json:"pos"`
+	TPost   int    `json:"tpost"`
+}
+
+// MatchesJSON returns the matches of a query as json. Each match maps the
+// variables of the query to the bound elements.
+//
+// Parameter:
+//   - tr *trace.Trace: the trace of the matches
+//   - q *Query: the query
+//   - matches []Match: the matches of the query
+//
+// Returns:
+//   - string: the json representation
+//   - error: if the matches could not be converted
+func MatchesJSON(tr *trace.Trace, q *Query, matches []Match) (string, error) {
+	res := make([]map[string]elemJSON, 0, len(matches))
+	for _, m := range matches {
+		match := make(map[string]elemJSON)
+		for j, elem := range m {
+			e := elemJSON{
+				Type:    string(elem.Type(true)),
+				Routine: elem.Routine(),
+				Pos:     fmt.Sprintf("%s:%d", elem.File(), elem.Line()),
+				TPost:   elem.T(trace.Commit),
+			}
+			if trace.IsOp(elem) {
+				e.Object = tr.GetObjectIdentity(elem.ObjID())
+			}
+			match[q.Vars[j]] = e
+		}
+		res = append(res, match)
+	}
+
+	resJSON, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(resJSON), nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: scenario.go
+// Brief: Scenario that evaluates the queries of the query config during
+//    the happens before analysis
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_query
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/analysis/a_registry"
+	"advocate/analysis/hb/a_clock"
+	"advocate/trace"
+	"advocate/utils/consts"
+	"advocate/utils/flags"
+	"advocate/utils/helper"
+	"advocate/utils/log"
+	"advocate/utils/results/results"
+	"strings"
+)
+
+// QueryMatch is the result type for matches of the queries in the query config
+const QueryMatch helper.ResultType = "XQ1"
+
+// maximum number of reported matches per query and trace
+const maxMatchesPerQuery = 20
+
+// the registered scenario
+var scenario = &queryScenario{}
+
+func init() {
+	if err := a_registry.Register('q', scenario); err != nil {
+		log.Error("Could not register scenario: ", err.Error())
+	}
+}
+
+// queryScenario reports the matches of the queries set with -queries
+//
+// Fields:
+//   - loaded bool: true if the query config has been read
+//   - queries []*Query: the queries of the config
+//   - modeQuery *Query: the query of the query mode, its matches are stored instead of reported
+//   - modeMatches []Match: the matches of the query of the query mode
+//   - clocks map[trace.Element]*a_clock.VectorClock: copies of the vector clocks of the elements
+type queryScenario struct {
+	loaded      bool
+	queries     []*Query
+	modeQuery   *Query
+	modeMatches []Match
+	clocks      map[trace.Element]*a_clock.VectorClock
+}
+
+// SetModeQuery sets the query of the query mode. If set, the query config
+// is not read and the matches of the query can be retrieved with
+// GetModeMatches after the happens before analysis.
+//
+// Parameter:
+//   - q *Query: the query
+func SetModeQuery(q *Query) {
+	scenario.modeQuery = q
+	scenario.modeMatches = nil
+	scenario.loaded = true
+}
+
+// GetModeMatches returns the matches of the query set with SetModeQuery
+//
+// Returns:
+//   - []Match: the matches
+func GetModeMatches() []Match {
+	return scenario.modeMatches
+}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *queryScenario) Name() string {
+	return "query"
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *queryScenario) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{
+			Type:   QueryMatch,
+			Name:   "Match of trace query",
+			Crit:   consts.Bug,
+			Actual: true,
+			Explanation: "The recorded execution contains operations that match a query " +
+				"of the query config. The query describes an ordering of operations that should never happen.",
+		},
+	}
+}
+
+// Reset reads the query config when the scenario is run the first time
+// and clears the vector clocks of the last trace
+func (this *queryScenario) Reset() {
+	this.clocks = nil
+
+	if this.loaded {
+		return
+	}
+	this.loaded = true
+
+	if flags.QueryConfig == "" {
+		return
+	}
+
+	queries, err := ReadQueries(flags.QueryConfig)
+	if err != nil {
+		log.Error("Could not read query config: ", err.Error())
+		return
+	}
+	this.queries = queries
+
+	log.Infof("Read %d queries from %s", len(queries), flags.QueryConfig)
+}
+
+// OnElement stores a copy of the vector clock of the element. The vector
+// clock of an element is changed by the following elements of its routine,
+// so it must be copied when the element is handled.
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *queryScenario) OnElement(elem trace.Element, hb *a_registry.HBState) {
+	if !hb.CalcVC || (len(this.queries) == 0 && this.modeQuery == nil) {
+		return
+	}
+
+	vc := elem.GetVC(a_clock.Strong)
+	if vc == nil || vc.GetSize() == 0 {
+		return
+	}
+
+	if this.clocks == nil {
+		this.clocks = make(map[trace.Element]*a_clock.VectorClock)
+	}
+	this.clocks[elem] = vc.Copy()
+}
+
+// Finish evaluates the queries and reports their matches
+func (this *queryScenario) Finish() {
+	if this.modeQuery != nil {
+		this.modeMatches = this.modeQuery.Evaluate(&a_base.MainTrace, this.clocks, 0)
+		return
+	}
+
+	for _, q := range this.queries {
+		matches := q.Evaluate(&a_base.MainTrace, this.clocks, maxMatchesPerQuery)
+		if len(matches) == maxMatchesPerQuery {
+			log.Infof("Query %s has more than %d matches, only the first are reported", q.Name, maxMatchesPerQuery)
+		}
+
+		for _, m := range matches {
+			args := make([][]results.ResultElem, len(m))
+			for i, elem := range m {
+				args[i] = []results.ResultElem{results.TraceElementResult{
+					RoutineID: elem.Routine(),
+					ObjID:     elem.ObjID(),
+					TRequest:  elem.T(trace.Request),
+					ObjType:   elem.Type(true),
+					File:      elem.File(),
+					Line:      elem.Line(),
+				}}
+			}
+
+			arg2 := make([]results.ResultElem, 0)
+			for _, a := range args[1:] {
+				arg2 = append(arg2, a...)
+			}
+
+			results.Result(results.CRITICAL, QueryMatch,
+				q.Name+" "+q.Vars[0], args[0], strings.Join(q.Vars[1:], ", "), arg2)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: registry.go
+// Brief: Interface for scenarios of the happens before analysis and the
+//    registry of all available scenarios
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_registry
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/analysis/hb/a_clock"
+	"advocate/analysis/hb/a_vc"
+	"advocate/trace"
+	"advocate/utils/flags"
+	"advocate/utils/helper"
+	"advocate/utils/results/bugs"
+	"fmt"
+)
+
+// HBState gives a scenario access to the state of the happens before analysis
+// when an element is handled. The vector clocks of the element itself are
+// stored in the element.
+//
+// Fields:
+//   - Fuzzing bool: true if the analysis is run for fuzzing
+//   - CalcVC bool: true if the vector clocks are calculated
+//   - CalcPog bool: true if the partial order graph is calculated
+//   - CalcCssts bool: true if the cssts are calculated
+type HBState struct {
+	Fuzzing   bool
+	CalcVC    bool
+	CalcPog   bool
+	CalcCssts bool
+}
+
+// VC returns the current vector clock of a routine
+//
+// Parameter:
+//   - routine int: the routine id
+//
+// Returns:
+//   - *a_clock.VectorClock: the vector clock, nil if vector clocks are not calculated
+func (this *HBState) VC(routine int) *a_clock.VectorClock {
+	if !this.CalcVC {
+		return nil
+	}
+	return a_vc.CurrentVC[routine]
+}
+
+// WVC returns the current weak vector clock of a routine
+//
+// Parameter:
+//   - routine int: the routine id
+//
+// Returns:
+//   - *a_clock.VectorClock: the weak vector clock, nil if vector clocks are not calculated
+func (this *HBState) WVC(routine int) *a_clock.VectorClock {
+	if !this.CalcVC {
+		return nil
+	}
+	return a_vc.CurrentWVC[routine]
+}
+
+// Scenario is a detector for a type of bug, that is run on the main trace
+// during the happens before analysis
+type Scenario interface {
+	// Name returns the name of the scenario, used as analysis case
+	Name() string
+
+	// Results returns the result types the scenario can report
+	Results() []helper.ResultTypeInfo
+
+	// Reset clears the state of the scenario before the analysis of a trace
+	Reset()
+
+	// OnElement is called for each element of the trace in the order of
+	// the trace, after the happens before information of the element has
+	// been calculated
+	OnElement(elem trace.Element, hb *HBState)
+
+	// Finish is called after all elements have been handled and reports the found bugs
+	Finish()
+}
+
+// Rewriter can optionally be implemented by a scenario to rewrite the trace
+// for the bugs it reports, so that the bug can be confirmed by a replay
+type Rewriter interface {
+	// Rewrite rewrites the trace for a bug of one of the result types of the scenario
+	//
+	// Parameter:
+	//   - tr *trace.Trace: the trace to rewrite
+	//   - bug bugs.Bug: the bug
+	//
+	// Returns:
+	//   - int: the expected exit code of the replay
+	//   - error
+	Rewrite(tr *trace.Trace, bug bugs.Bug) (int, error)
+}
+
+// ChannelOp is the kind of a channel update passed to a ChannelObserver
+type ChannelOp int
+
+// possible kinds of channel updates
+const (
+	// ChannelSend is a send whose vector clocks have been updated. For
+	// buffered channels this can be a send that was held back until its
+	// receive was handled
+	ChannelSend ChannelOp = iota
+	// ChannelRecv is a receive before its vector clocks are updated. This
+	// includes not committed receives, the receive of an unbuffered send
+	// and receives that were held back until their send was handled
+	ChannelRecv
+	// ChannelClose is a close before it is stored as the close of the channel
+	ChannelClose
+	// ChannelClosed is a close after it has been stored as the close of the channel
+	ChannelClosed
+	// ChannelSendOnClosed is an executed send on a closed channel
+	ChannelSendOnClosed
+	// ChannelSelectCase is a case of a select that was not chosen
+	ChannelSelectCase
+)
+
+// ChannelUpdate is a channel update passed to a ChannelObserver
+//
+// Fields:
+//   - Op ChannelOp: the kind of the update
+//   - Elem *trace.ElementChannel: the channel operation or select case
+//   - VC map[int]*a_clock.VectorClock: the vector clocks the operation is
+//     handled with. For held back operations these are not the current vector clocks
+type ChannelUpdate struct {
+	Op   ChannelOp
+	Elem *trace.ElementChannel
+	VC   map[int]*a_clock.VectorClock
+}
+
+// ChannelObserver can optionally be implemented by a scenario that must see
+// the channel operations while the vector clocks of the channels are updated.
+// Contrary to OnElement, this also includes the receive of an unbuffered send,
+// buffered operations that are held back until their partner has been handled
+// and the close of a channel before it is stored
+type ChannelObserver interface {
+	// OnChannelUpdate is called for each channel update in the order in
+	// which the updates are handled
+	//
+	// Parameter:
+	//   - update ChannelUpdate: the update
+	OnChannelUpdate(update ChannelUpdate)
+}
+
+// BlockedAnalyzer can optionally be implemented by a scenario that works on
+// the operations that were still blocked at the end of the recorded run
+type BlockedAnalyzer interface {
+	// AnalyzeBlocked is called after the happens before analysis. It is also
+	// called if only actual panics and leaks are analyzed or if the happens
+	// before analysis is not run
+	//
+	// Returns:
+	//   - error
+	AnalyzeBlocked() error
+}
+
+// registered scenarios in the order of registration
+var (
+	scenarios = make([]Scenario, 0)
+	byName    = make(map[string]Scenario)
+	byResult  = make(map[helper.ResultType]Scenario)
+)
+
+// channel observers of the scenarios started for the current analysis
+var channelObservers = make([]ChannelObserver, 0)
+
+// Register adds a scenario to the registry. The scenario can be selected
+// with -scen and the given letter and is run if its analysis case is enabled.
+// Result types that are not built in are registered with their metadata.
+// Scenarios are run in the order of their registration.
+//
+// Parameter:
+//   - letter rune: the letter used to select the scenario with -scen
+//   - s Scenario: the scenario
+//
+// Returns:
+//   - error: if the name, letter or a result type is already used
+func Register(letter rune, s Scenario) error {
+	name := s.Name()
+	if _, ok := byName[name]; ok {
+		return fmt.Errorf("Scenario %s is already registered", name)
+	}
+
+	for _, info := range s.Results() {
+		if other, ok := byResult[info.Type]; ok {
+			return fmt.Errorf("Result type %s of scenario %s is already reported by %s", info.Type, name, other.Name())
+		}
+	}
+
+	if err := flags.RegisterAnalysisCase(letter, flags.AnalysisCases(name)); err != nil {
+		return err
+	}
+
+	for _, info := range s.Results() {
+		if helper.ResultTypeFromString(string(info.Type)) == helper.Empty {
+			if err := helper.RegisterResultType(info); err != nil {
+				return err
+			}
+		}
+		byResult[info.Type] = s
+	}
+
+	scenarios = append(scenarios, s)
+	byName[name] = s
+	return nil
+}
+
+// Get returns a registered scenario
+//
+// Parameter:
+//   - name string: the name of the scenario
+//
+// Returns:
+//   - Scenario: the scenario
+//   - bool: false if no scenario with this name is registered
+func Get(name string) (Scenario, bool) {
+	s, ok := byName[name]
+	return s, ok
+}
+
+// Scenarios returns all registered scenarios in the order of their registration
+//
+// Returns:
+//   - []Scenario: the scenarios
+func Scenarios() []Scenario {
+	return scenarios
+}
+
+// Enabled returns the registered scenarios whose analysis case is enabled
+//
+// Returns:
+//   - []Scenario: the enabled scenarios in the order of their registration
+func Enabled() []Scenario {
+	res := make([]Scenario, 0, len(scenarios))
+	for _, s := range scenarios {
+		if a_base.AnalysisCasesMap[flags.AnalysisCases(s.Name())] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Start returns the enabled scenarios and resets them before the analysis of
+// a trace. The enabled scenarios that implement ChannelObserver are informed
+// about the channel updates with NotifyChannel until the next call of Start.
+//
+// Returns:
+//   - []Scenario: the enabled scenarios in the order of their registration
+func Start() []Scenario {
+	enabled := Enabled()
+
+	channelObservers = channelObservers[:0]
+	for _, s := range enabled {
+		s.Reset()
+		if co, ok := s.(ChannelObserver); ok {
+			channelObservers = append(channelObservers, co)
+		}
+	}
+
+	return enabled
+}
+
+// NotifyChannel passes a channel update to the channel observers of the
+// scenarios started with Start
+//
+// Parameter:
+//   - op ChannelOp: the kind of the update
+//   - elem *trace.ElementChannel: the channel operation or select case
+//   - vc map[int]*a_clock.VectorClock: the vector clocks the operation is handled with
+func NotifyChannel(op ChannelOp, elem *trace.ElementChannel, vc map[int]*a_clock.VectorClock) {
+	if len(channelObservers) == 0 || elem == nil {
+		return
+	}
+
+	update := ChannelUpdate{Op: op, Elem: elem, VC: vc}
+	for _, co := range channelObservers {
+		co.OnChannelUpdate(update)
+	}
+}
+
+// BlockedAnalyzers returns the registered scenarios that implement
+// BlockedAnalyzer and are enabled. If only actual panics and leaks are
+// analyzed, all of them are returned.
+//
+// Returns:
+//   - []BlockedAnalyzer: the blocked analyzers in the order of their registration
+func BlockedAnalyzers() []BlockedAnalyzer {
+	res := make([]BlockedAnalyzer, 0)
+	for _, s := range scenarios {
+		ba, ok := s.(BlockedAnalyzer)
+		if !ok {
+			continue
+		}
+		if flags.OnlyAPanicAndLeak || a_base.AnalysisCasesMap[flags.AnalysisCases(s.Name())] {
+			res = append(res, ba)
+		}
+	}
+	return res
+}
+
+// GetRewriter returns the rewriter for a result type
+//
+// Parameter:
+//   - rt helper.ResultType: the result type
+//
+// Returns:
+//   - Rewriter: the rewriter of the scenario reporting the result type
+//   - bool: false if no registered scenario can rewrite the result type
+func GetRewriter(rt helper.ResultType) (Rewriter, bool) {
+	s, ok := byResult[rt]
+	if !ok {
+		return nil, false
+	}
+	rw, ok := s.(Rewriter)
+	return rw, ok
+}
@@ -14,12 +14,12 @@ package a_elements
 
 import (
 	"advocate/analysis/a_base"
+	"advocate/analysis/a_registry"
 	"advocate/analysis/analysis/a_scenarios"
 	"advocate/analysis/hb/a_clock"
 	"advocate/analysis/hb/a_hbcalc"
 	"advocate/analysis/hb/a_vc"
 	"advocate/trace"
-	"advocate/utils/flags"
 	"advocate/utils/log"
 	"advocate/utils/results/results"
 )
@@ -50,7 +50,7 @@ func UpdateChannel(ch *trace.ElementChannel) {
 		case trace.ChannelSend:
 			a_base.MaxOpID[id] = oID
 		case trace.ChannelRecv:
-			if oID > a_base.MaxOpID[id] && !cl {
+			if oID > a_base.MaxOpID[id] && !cl && sendIsRecorded(id, oID) {
 				a_base.WaitingReceive = append(a_base.WaitingReceive, ch)
 				return
 			}
@@ -117,6 +117,46 @@ func UpdateChannel(ch *trace.ElementChannel) {
 	}
 }
 
+// CollectRecordedSends stores the oIDs of all sends on buffered channels in
+// the main trace. In a truncated trace, the send of a receive may not be
+// recorded. Such receives must not be held back until their send is processed.
+func CollectRecordedSends() {
+	a_base.RecordedSends = make(map[int]map[int]struct{})
+
+	for _, rout := range a_base.MainTrace.GetTraces() {
+		for _, elem := range rout.Elems() {
+			ch, ok := elem.(*trace.ElementChannel)
+			if !ok || !ch.IsBuffered() || ch.Type(true) != trace.ChannelSend {
+				continue
+			}
+
+			if _, ok := a_base.RecordedSends[ch.ObjID()]; !ok {
+				a_base.RecordedSends[ch.ObjID()] = make(map[int]struct{})
+			}
+			a_base.RecordedSends[ch.ObjID()][ch.GetOID()] = struct{}{}
+		}
+	}
+}
+
+// sendIsRecorded checks if the send matching a receive on a buffered channel
+// is in the trace. This is always the case if the trace is neither truncated
+// nor filtered.
+//
+// Parameter:
+//   - id int: id of the channel
+//   - oID int: oID of the receive
+//
+// Returns:
+//   - bool: true if the send is in the trace
+func sendIsRecorded(id, oID int) bool {
+	if !a_base.TraceTruncated && !a_base.TraceFiltered {
+		return true
+	}
+
+	_, ok := a_base.RecordedSends[id][oID]
+	return ok
+}
+
 // UpdateSelect stores and updates the vector clock of the select element.
 //
 // Parameter:
@@ -142,23 +182,10 @@ func UpdateSelect(se *trace.ElementSelect) {
 		}
 	}
 
-	if a_base.AnalysisCasesMap[flags.SendOnClosed] {
-		chosenIndex := se.GetChosenIndex()
-		for i, c := range cases {
-			if i == chosenIndex {
-				continue
-			}
-
-			opC := c.Type(true)
-
-			if _, ok := a_base.CloseData[c.ObjID()]; ok {
-				switch opC {
-				case trace.ChannelSend:
-					a_scenarios.FoundSendOnClosedChannel(c, false)
-					// case trace.ChannelRecv:
-					// scenarios.FoundReceiveOnClosedChannel(&c, false)
-				}
-			}
+	chosenIndex := se.GetChosenIndex()
+	for i, c := range cases {
+		if i != chosenIndex {
+			a_registry.NotifyChannel(a_registry.ChannelSelectCase, c, a_vc.CurrentVC)
 		}
 	}
 
@@ -183,24 +210,16 @@ func UpdateSelect(se *trace.ElementSelect) {
 //   - tID_send string: the position of the send in the program
 //   - tID_recv string: the position of the receive in the program
 func Unbuffered(sender trace.Element, recv trace.Element) {
-	if a_base.AnalysisCasesMap[flags.ConcurrentRecv] || a_base.AnalysisFuzzingFlow { // or fuzzing
-		switch r := recv.(type) {
-		case *trace.ElementChannel:
-			a_scenarios.CheckForConcurrentRecv(r, a_vc.CurrentVC)
-		case *trace.ElementSelect:
-			a_scenarios.CheckForConcurrentRecv(r.GetChosenCase(), a_vc.CurrentVC)
-		}
-	}
+	senderCh := channelOp(sender)
+	recvCh := channelOp(recv)
 
 	if a_base.AnalysisFuzzingFlow {
-		switch s := sender.(type) {
-		case *trace.ElementChannel:
-			a_scenarios.GetConcurrentSendForFuzzing(s)
-		case *trace.ElementSelect:
-			a_scenarios.GetConcurrentSendForFuzzing(s.GetChosenCase())
-		}
+		a_scenarios.GetConcurrentRecvForFuzzing(recvCh, a_vc.CurrentVC)
+		a_scenarios.GetConcurrentSendForFuzzing(senderCh)
 	}
 
+	a_registry.NotifyChannel(a_registry.ChannelRecv, recvCh, a_vc.CurrentVC)
+
 	if sender.Committed() && recv.Committed() {
 		if a_base.MostRecentReceive[recv.Routine()] == nil {
 			a_base.MostRecentReceive[recv.Routine()] = make(map[int]a_base.ElemWithVcVal)
@@ -224,11 +243,7 @@ func Unbuffered(sender trace.Element, recv trace.Element) {
 		}
 	}
 
-	if a_base.AnalysisCasesMap[flags.SendOnClosed] {
-		if _, ok := a_base.CloseData[sender.ObjID()]; ok {
-			a_scenarios.FoundSendOnClosedChannel(sender, true)
-		}
-	}
+	a_registry.NotifyChannel(a_registry.ChannelSend, senderCh, a_vc.CurrentVC)
 
 	if a_base.ModeIsFuzzing {
 		a_scenarios.CheckForSelectCaseWithPartnerChannel(sender, a_vc.CurrentVC[sender.Routine()], true, false)
@@ -267,11 +282,7 @@ func Send(ch *trace.ElementChannel, vc, wVc map[int]*a_clock.VectorClock) {
 		Val:  id,
 	}
 
-	if a_base.AnalysisCasesMap[flags.SendOnClosed] {
-		if _, ok := a_base.CloseData[id]; ok {
-			a_scenarios.FoundSendOnClosedChannel(ch, true)
-		}
-	}
+	a_registry.NotifyChannel(a_registry.ChannelSend, ch, vc)
 
 	if a_base.ModeIsFuzzing {
 		a_scenarios.CheckForSelectCaseWithPartnerChannel(ch, vc[routine], true, true)
@@ -303,10 +314,12 @@ func Recv(ch *trace.ElementChannel, vc, wVc map[int]*a_clock.VectorClock) {
 	id := ch.ObjID()
 	routine := ch.Routine()
 
-	if a_base.AnalysisCasesMap[flags.ConcurrentRecv] || a_base.AnalysisFuzzingFlow {
-		a_scenarios.CheckForConcurrentRecv(ch, vc)
+	if a_base.AnalysisFuzzingFlow {
+		a_scenarios.GetConcurrentRecvForFuzzing(ch, vc)
 	}
 
+	a_registry.NotifyChannel(a_registry.ChannelRecv, ch, vc)
+
 	if !ch.Committed() {
 		return
 	}
@@ -357,15 +370,11 @@ func Close(ch *trace.ElementChannel) {
 
 	ch.SetClosed(true)
 
-	if a_base.AnalysisCasesMap[flags.CloseOnClosed] {
-		a_scenarios.CheckForClosedOnClosed(ch) // must be called before closePos is updated
-	}
+	a_registry.NotifyChannel(a_registry.ChannelClose, ch, a_vc.CurrentVC)
 
 	a_base.CloseData[id] = ch
 
-	if a_base.AnalysisCasesMap[flags.SendOnClosed] { // || baseA.AnalysisCasesMap[flags.ReceiveOnClosed] {
-		a_scenarios.CheckForCommunicationOnClosedChannel(ch)
-	}
+	a_registry.NotifyChannel(a_registry.ChannelClosed, ch, a_vc.CurrentVC)
 
 	if a_base.ModeIsFuzzing {
 		a_scenarios.CheckForSelectCaseWithPartnerClose(ch, a_vc.CurrentVC[routine])
@@ -381,9 +390,7 @@ func Close(ch *trace.ElementChannel) {
 
 // SendC record an actual send on closed
 func SendC(ch *trace.ElementChannel) {
-	if a_base.AnalysisCasesMap[flags.SendOnClosed] {
-		a_scenarios.FoundSendOnClosedChannel(ch, true)
-	}
+	a_registry.NotifyChannel(a_registry.ChannelSendOnClosed, ch, a_vc.CurrentVC)
 }
 
 // RecvC updates and calculates the vector clocks given a receive on a closed channel.
@@ -474,3 +481,21 @@ func setChannelAsLastReceive(c trace.Element) {
 	}
 	a_base.HasReceived[id] = true
 }
+
+// channelOp returns the channel operation of a channel element or the chosen
+// case of a select element
+//
+// Parameter:
+//   - elem trace.Element: the channel or select element
+//
+// Returns:
+//   - *trace.ElementChannel: the channel operation, nil if there is none
+func channelOp(elem trace.Element) *trace.ElementChannel {
+	switch e := elem.(type) {
+	case *trace.ElementChannel:
+		return e
+	case *trace.ElementSelect:
+		return e.GetChosenCase()
+	}
+	return nil
+}
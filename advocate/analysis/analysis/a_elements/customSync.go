@@ -0,0 +1,23 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: customSync.go
+// Brief: Update functions for user declared releases and acquires
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_elements
+
+import (
+	"advocate/analysis/hb/a_hbcalc"
+	"advocate/trace"
+)
+
+// UpdateCustomSync updates the hb info for a user declared release or acquire
+//
+// Parameter:
+//   - cs *trace.ElementCustomSync: the release or acquire
+func UpdateCustomSync(cs *trace.ElementCustomSync) {
+	a_hbcalc.UpdateHBCustomSync(cs)
+}
@@ -18,7 +18,6 @@ import (
 	"advocate/analysis/hb/a_hbcalc"
 	"advocate/analysis/hb/a_vc"
 	"advocate/trace"
-	"advocate/utils/flags"
 	"advocate/utils/log"
 )
 
@@ -40,28 +39,16 @@ func UpdateMutex(mu *trace.ElementMutex, alt bool) {
 		a_base.CurrentlyHoldLock[id] = mu
 		a_scenarios.IncFuzzingCounter(mu)
 
-		if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-			a_scenarios.CheckForUnlockBeforeLockLock(mu)
-		}
-
 	// --------- READ LOCK (RWMutex RLock) ---------
 	case trace.MutexRLock:
 		a_base.CurrentlyHoldLock[id] = mu
 		a_scenarios.IncFuzzingCounter(mu)
 
-		if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-			a_scenarios.CheckForUnlockBeforeLockLock(mu)
-		}
-
 	// --------- TRY LOCK (write) ---------
 	case trace.MutexTryLock:
 		if mu.IsSuc() {
 			a_base.CurrentlyHoldLock[id] = mu
 			a_scenarios.IncFuzzingCounter(mu)
-
-			if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-				a_scenarios.CheckForUnlockBeforeLockLock(mu)
-			}
 		}
 
 	// --------- TRY RLOCK (read) ---------
@@ -69,10 +56,6 @@ func UpdateMutex(mu *trace.ElementMutex, alt bool) {
 		if mu.IsSuc() {
 			a_base.CurrentlyHoldLock[id] = mu
 			a_scenarios.IncFuzzingCounter(mu)
-
-			if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-				a_scenarios.CheckForUnlockBeforeLockLock(mu)
-			}
 		}
 
 	// --------- UNLOCK (write) ---------
@@ -89,10 +72,6 @@ func UpdateMutex(mu *trace.ElementMutex, alt bool) {
 
 		a_base.CurrentlyHoldLock[id] = nil
 
-		if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-			a_scenarios.CheckForUnlockBeforeLockUnlock(mu)
-		}
-
 	// --------- RUNLOCK (read) ---------
 	case trace.MutexRUnlock:
 		a_base.RelR[id] = &a_base.ElemWithVc{
@@ -102,10 +81,6 @@ func UpdateMutex(mu *trace.ElementMutex, alt bool) {
 
 		a_base.CurrentlyHoldLock[id] = nil
 
-		if a_base.AnalysisCasesMap[flags.UnlockBeforeLock] {
-			a_scenarios.CheckForUnlockBeforeLockUnlock(mu)
-		}
-
 	default:
 		log.Error("Unknown mutex operation: " + mu.String())
 	}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: summary.go
+// Brief: Update functions for summaries of operations that were removed by
+//    a package filter and for recorded operations on the summarized objects
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_elements
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/analysis/hb/a_hbcalc"
+	"advocate/trace"
+)
+
+// UpdateSummary updates the hb info for a summary
+//
+// Parameter:
+//   - su *trace.ElementSummary: the summary
+func UpdateSummary(su *trace.ElementSummary) {
+	a_hbcalc.UpdateHBSummary(su)
+}
+
+// CollectSummarizedObjects stores the ids of all objects that are used in
+// a summary in the main trace. Only recorded operations on those objects
+// can synchronize with summarized operations.
+func CollectSummarizedObjects() {
+	a_base.SummarizedObjects = make(map[int]struct{})
+
+	for _, rout := range a_base.MainTrace.GetTraces() {
+		for _, elem := range rout.Elems() {
+			su, ok := elem.(*trace.ElementSummary)
+			if !ok {
+				continue
+			}
+
+			for _, id := range su.ObjIDs() {
+				a_base.SummarizedObjects[id] = struct{}{}
+			}
+		}
+	}
+}
+
+// AcquireFromSummaries syncs a recorded element with the summarized releases
+// on its object, e.g. a receive with a send in a filtered package.
+// Must be called before the element is analyzed.
+//
+// Parameter:
+//   - elem trace.Element: the element
+func AcquireFromSummaries(elem trace.Element) {
+	id, acquire, _ := summarySync(elem)
+	if acquire {
+		a_hbcalc.AcquireSummary(elem, id)
+	}
+}
+
+// ReleaseToSummaries makes a recorded element visible to the summarized
+// acquires on its object, e.g. a send with a receive in a filtered package.
+// Must be called after the element is analyzed.
+//
+// Parameter:
+//   - elem trace.Element: the element
+func ReleaseToSummaries(elem trace.Element) {
+	id, _, release := summarySync(elem)
+	if release {
+		a_hbcalc.ReleaseSummary(elem, id)
+	}
+}
+
+// summarySync returns how a recorded element synchronizes with summarized
+// operations on the same object
+//
+// Parameter:
+//   - elem trace.Element: the element
+//
+// Returns:
+//   - int: the id of the object
+//   - bool: true if the element acquires the object
+//   - bool: true if the element releases the object
+func summarySync(elem trace.Element) (int, bool, bool) {
+	if len(a_base.SummarizedObjects) == 0 {
+		return -1, false, false
+	}
+
+	if se, ok := elem.(*trace.ElementSelect); ok {
+		elem = se.GetChosenCase()
+		if elem == nil {
+			return -1, false, false
+		}
+	}
+
+	id := elem.ObjID()
+	if _, ok := a_base.SummarizedObjects[id]; !ok || !elem.Committed() {
+		return -1, false, false
+	}
+
+	switch elem.Type(true) {
+	case trace.ChannelRecv, trace.CondWait, trace.WaitWait, trace.AtomicLoad:
+		return id, true, false
+	case trace.MutexLock, trace.MutexRLock, trace.MutexTryLock, trace.MutexTryRLock:
+		mu := elem.(*trace.ElementMutex)
+		return id, mu.IsSuc(), false
+	case trace.ChannelSend, trace.ChannelClose, trace.MutexUnlock, trace.MutexRUnlock,
+		trace.CondSignal, trace.CondBroadcast, trace.WaitDone,
+		trace.AtomicStore, trace.AtomicAdd, trace.AtomicAnd, trace.AtomicOr:
+		return id, false, true
+	case trace.OnceSuc, trace.OnceFail, trace.AtomicSwap, trace.AtomicCompAndSwap:
+		return id, true, true
+	}
+
+	return -1, false, false
+}
@@ -30,7 +30,8 @@ func AnalyzeWait(wa *trace.ElementWait) {
 	case trace.WaitAdd, trace.WaitDone:
 		a_base.LastChangeWG[wa.ObjID()] = wa
 
-		if a_base.AnalysisCasesMap[flags.DoneBeforeAdd] || f_base.FuzzingModeGoCRHBPlus {
+		// with the scenario enabled, the change is collected by the scenario
+		if f_base.FuzzingModeGoCRHBPlus && !a_base.AnalysisCasesMap[flags.DoneBeforeAdd] {
 			a_scenarios.CheckForDoneBeforeAddChange(wa)
 		}
 	case trace.WaitWait:
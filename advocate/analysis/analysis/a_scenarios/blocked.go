@@ -68,7 +68,12 @@ func Blocked() error {
 
 	reportBlocking(cyclic, helper.ADeadlock)
 	reportBlocking(b, helper.ABlocking)
-	reportLeak(l)
+
+	// if the trace was dumped by the flight recorder while the program was
+	// still running, the not blocked routines are still running and not leaking
+	if !a_base.TraceDumpedAlive {
+		reportLeak(l)
+	}
 
 	return nil
 }
@@ -14,7 +14,6 @@ import (
 	"advocate/analysis/a_hb"
 	"advocate/analysis/hb/a_clock"
 	"advocate/trace"
-	"advocate/utils/flags"
 	"advocate/utils/helper"
 	"advocate/utils/results/results"
 	"advocate/utils/timer"
@@ -33,7 +32,7 @@ func CheckForCommunicationOnClosedChannel(ch *trace.ElementChannel) {
 	id := ch.ObjID()
 
 	// check if there is an earlier send, that could happen concurrently to close
-	if a_base.AnalysisCasesMap[flags.SendOnClosed] && a_base.HasSend[id] {
+	if a_base.HasSend[id] {
 		for routine, mrs := range a_base.MostRecentSend {
 			happensBefore := a_clock.GetHappensBefore(mrs[id].Vc, a_base.CloseData[id].GetVC(a_clock.Strong))
 
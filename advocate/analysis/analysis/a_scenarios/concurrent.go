@@ -17,7 +17,6 @@ import (
 	"advocate/analysis/hb/a_clock"
 	"advocate/analysis/hb/a_vc"
 	"advocate/trace"
-	"advocate/utils/flags"
 	"advocate/utils/helper"
 	"advocate/utils/log"
 	"advocate/utils/results/results"
@@ -68,26 +67,64 @@ func GetConcurrentSendForFuzzing(sender *trace.ElementChannel) {
 	}
 }
 
-// CheckForConcurrentRecv checks if for the given recv, if there is a
+// GetConcurrentRecvForFuzzing checks if for the given recv, if there is a
 // concurrent recv on the same channel. If there is, the information is stored
-// in baseA.FuzzingFlowRecv.
+// in baseA.FuzzingFlowRecv. This is used for fuzzing.
 //
 // Parameter:
 //   - ch *TraceElementChannel: recv trace element
-func CheckForConcurrentRecv(ch *trace.ElementChannel, vc map[int]*a_clock.VectorClock) {
-	if a_base.AnalysisFuzzingFlow {
-		timer.Start(timer.FuzzingAna)
-		defer timer.Stop(timer.FuzzingAna)
+//   - vc map[int]*VectorClock: the vector clocks the recv is handled with
+func GetConcurrentRecvForFuzzing(ch *trace.ElementChannel, vc map[int]*a_clock.VectorClock) {
+	timer.Start(timer.FuzzingAna)
+	defer timer.Stop(timer.FuzzingAna)
+
+	id := ch.ObjID()
+	routine := ch.Routine()
+
+	IncFuzzingCounter(ch)
+
+	if !ch.Committed() {
+		for r, elem := range a_base.LastRecvRoutine {
+			if r == routine {
+				continue
+			}
+
+			if elem[id].Vc == nil || elem[id].Vc.GetClock() == nil {
+				continue
+			}
+
+			if a_clock.GetHappensBefore(elem[id].Vc, vc[routine]) == a_hb.Concurrent {
+				elem2 := elem[id].Elem
+				a_base.FuzzingFlowRecv = append(a_base.FuzzingFlowRecv, a_base.ConcurrentEntry{Elem: elem2, Counter: getFuzzingCounter(elem2), Type: a_base.CERecv})
+			}
+		}
+	}
+
+	if ch.Committed() {
+		if _, ok := a_base.LastRecvRoutine[routine]; !ok {
+			a_base.LastRecvRoutine[routine] = make(map[int]a_base.ElemWithVc)
+		}
+
+		a_base.LastRecvRoutine[routine][id] = a_base.ElemWithVc{Vc: vc[routine].Copy(), Elem: ch}
 	}
+}
+
+// CheckForConcurrentRecv checks if for the given recv, if there is a
+// concurrent recv on the same channel. If there is, an actual concurrent
+// receive is reported.
+//
+// Parameter:
+//   - ch *TraceElementChannel: recv trace element
+//   - vc map[int]*VectorClock: the vector clocks the recv is handled with
+//   - lastRecv map[int]map[int]a_base.ElemWithVc: routine -> channel id -> last committed recv, updated with ch
+func CheckForConcurrentRecv(ch *trace.ElementChannel, vc map[int]*a_clock.VectorClock, lastRecv map[int]map[int]a_base.ElemWithVc) {
 	timer.Start(timer.AnaConcurrent)
 	defer timer.Stop(timer.AnaConcurrent)
 
 	id := ch.ObjID()
 	routine := ch.Routine()
 
-	IncFuzzingCounter(ch)
-
-	for r, elem := range a_base.LastRecvRoutine {
+	for r, elem := range lastRecv {
 		if r == routine {
 			continue
 		}
@@ -98,46 +135,37 @@ func CheckForConcurrentRecv(ch *trace.ElementChannel, vc map[int]*a_clock.Vector
 
 		happensBefore := a_clock.GetHappensBefore(elem[id].Vc, vc[routine])
 		if happensBefore == a_hb.Concurrent {
-
 			elem2 := elem[id].Elem
 
-			if a_base.AnalysisFuzzingFlow {
-				if !ch.Committed() {
-					a_base.FuzzingFlowRecv = append(a_base.FuzzingFlowRecv, a_base.ConcurrentEntry{Elem: elem2, Counter: getFuzzingCounter(elem2), Type: a_base.CERecv})
-				}
+			arg1 := results.TraceElementResult{
+				RoutineID: routine,
+				ObjID:     id,
+				TRequest:  ch.T(trace.Request),
+				ObjType:   "CR",
+				File:      ch.File(),
+				Line:      ch.Line(),
 			}
 
-			if a_base.AnalysisCasesMap[flags.ConcurrentRecv] {
-				arg1 := results.TraceElementResult{
-					RoutineID: routine,
-					ObjID:     id,
-					TRequest:  ch.T(trace.Request),
-					ObjType:   "CR",
-					File:      ch.File(),
-					Line:      ch.Line(),
-				}
-
-				arg2 := results.TraceElementResult{
-					RoutineID: r,
-					ObjID:     id,
-					TRequest:  elem2.T(trace.Request),
-					ObjType:   "CR",
-					File:      elem2.File(),
-					Line:      elem2.Line(),
-				}
-
-				results.Result(results.WARNING, helper.AConcurrentRecv,
-					"recv", []results.ResultElem{arg1}, "recv", []results.ResultElem{arg2})
+			arg2 := results.TraceElementResult{
+				RoutineID: r,
+				ObjID:     id,
+				TRequest:  elem2.T(trace.Request),
+				ObjType:   "CR",
+				File:      elem2.File(),
+				Line:      elem2.Line(),
 			}
+
+			results.Result(results.WARNING, helper.AConcurrentRecv,
+				"recv", []results.ResultElem{arg1}, "recv", []results.ResultElem{arg2})
 		}
 	}
 
 	if ch.Committed() {
-		if _, ok := a_base.LastRecvRoutine[routine]; !ok {
-			a_base.LastRecvRoutine[routine] = make(map[int]a_base.ElemWithVc)
+		if _, ok := lastRecv[routine]; !ok {
+			lastRecv[routine] = make(map[int]a_base.ElemWithVc)
 		}
 
-		a_base.LastRecvRoutine[routine][id] = a_base.ElemWithVc{Vc: vc[routine].Copy(), Elem: ch}
+		lastRecv[routine][id] = a_base.ElemWithVc{Vc: vc[routine].Copy(), Elem: ch}
 	}
 }
 
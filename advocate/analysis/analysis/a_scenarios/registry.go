@@ -0,0 +1,459 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: registry.go
+// Brief: Register the built-in scenarios of the happens before analysis
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_scenarios
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/analysis/a_registry"
+	"advocate/trace"
+	"advocate/utils/consts"
+	"advocate/utils/flags"
+	"advocate/utils/helper"
+	"advocate/utils/log"
+)
+
+// register the built-in scenarios, the order is the order in which they are finished
+func init() {
+	builtIn := []struct {
+		letter   rune
+		scenario a_registry.Scenario
+	}{
+		{'s', &sendOnClosed{}},
+		{'n', &closeOnClosed{}},
+		{'b', &concurrentRecv{}},
+		{'w', &doneBeforeAdd{}},
+		{'c', &resourceDeadlock{}},
+		{'m', &mixedDeadlock{}},
+		{'u', &unlockBeforeLock{}},
+		{'l', &leak{}},
+	}
+
+	for _, b := range builtIn {
+		if err := a_registry.Register(b.letter, b.scenario); err != nil {
+			log.Error("Could not register scenario: ", err.Error())
+		}
+	}
+}
+
+// ========================================================
+// MARK: SendOnClosed
+// ========================================================
+
+// sendOnClosed is the scenario for actual and possible sends on closed channels
+type sendOnClosed struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *sendOnClosed) Name() string {
+	return string(flags.SendOnClosed)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *sendOnClosed) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.ASendOnClosed, Name: "Actual Send on Closed Channel", Crit: consts.Bug, Actual: true},
+		{Type: helper.PSendOnClosed, Name: "Possible send on closed channel", Crit: consts.Bug},
+	}
+}
+
+// Reset does nothing, the closes and sends are cleared with the analysis data
+func (this *sendOnClosed) Reset() {}
+
+// OnElement does nothing, the sends and closes are checked in OnChannelUpdate
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *sendOnClosed) OnElement(elem trace.Element, hb *a_registry.HBState) {}
+
+// OnChannelUpdate checks sends and not chosen select cases on closed channels
+// and closes that are concurrent to an earlier send
+//
+// Parameter:
+//   - update a_registry.ChannelUpdate: the channel update
+func (this *sendOnClosed) OnChannelUpdate(update a_registry.ChannelUpdate) {
+	switch update.Op {
+	case a_registry.ChannelSend, a_registry.ChannelSendOnClosed:
+		FoundSendOnClosedChannel(update.Elem, true)
+	case a_registry.ChannelSelectCase:
+		if update.Elem.Type(true) == trace.ChannelSend {
+			FoundSendOnClosedChannel(update.Elem, false)
+		}
+	case a_registry.ChannelClosed:
+		CheckForCommunicationOnClosedChannel(update.Elem)
+	}
+}
+
+// Finish does nothing, the bugs are reported while the channels are updated
+func (this *sendOnClosed) Finish() {}
+
+// ========================================================
+// MARK: CloseOnClosed
+// ========================================================
+
+// closeOnClosed is the scenario for actual closes of closed channels
+type closeOnClosed struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *closeOnClosed) Name() string {
+	return string(flags.CloseOnClosed)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *closeOnClosed) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.ACloseOnClosed, Name: "Actual Close on Closed Channel", Crit: consts.Bug, Actual: true},
+	}
+}
+
+// Reset does nothing, the closes are cleared with the analysis data
+func (this *closeOnClosed) Reset() {}
+
+// OnElement does nothing, the closes are checked in OnChannelUpdate
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *closeOnClosed) OnElement(elem trace.Element, hb *a_registry.HBState) {}
+
+// OnChannelUpdate checks a close before it is stored as the close of the channel
+//
+// Parameter:
+//   - update a_registry.ChannelUpdate: the channel update
+func (this *closeOnClosed) OnChannelUpdate(update a_registry.ChannelUpdate) {
+	if update.Op == a_registry.ChannelClose {
+		CheckForClosedOnClosed(update.Elem)
+	}
+}
+
+// Finish does nothing, the bugs are reported while the channels are updated
+func (this *closeOnClosed) Finish() {}
+
+// ========================================================
+// MARK: ConcurrentRecv
+// ========================================================
+
+// concurrentRecv is the scenario for concurrent receives on the same channel
+//
+// Fields:
+//   - lastRecv map[int]map[int]a_base.ElemWithVc: routine -> channel id -> last committed recv
+type concurrentRecv struct {
+	lastRecv map[int]map[int]a_base.ElemWithVc
+}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *concurrentRecv) Name() string {
+	return string(flags.ConcurrentRecv)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *concurrentRecv) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.AConcurrentRecv, Name: "Concurrent Receive", Crit: consts.Diagnostic, Actual: true},
+	}
+}
+
+// Reset clears the last receives
+func (this *concurrentRecv) Reset() {
+	this.lastRecv = make(map[int]map[int]a_base.ElemWithVc)
+}
+
+// OnElement does nothing, the receives are checked in OnChannelUpdate
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *concurrentRecv) OnElement(elem trace.Element, hb *a_registry.HBState) {}
+
+// OnChannelUpdate checks the receives for concurrent receives on the same channel
+//
+// Parameter:
+//   - update a_registry.ChannelUpdate: the channel update
+func (this *concurrentRecv) OnChannelUpdate(update a_registry.ChannelUpdate) {
+	if update.Op == a_registry.ChannelRecv {
+		CheckForConcurrentRecv(update.Elem, update.VC, this.lastRecv)
+	}
+}
+
+// Finish does nothing, the bugs are reported while the channels are updated
+func (this *concurrentRecv) Finish() {}
+
+// ========================================================
+// MARK: DoneBeforeAdd
+// ========================================================
+
+// doneBeforeAdd is the scenario for possible negative wait group counters
+type doneBeforeAdd struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *doneBeforeAdd) Name() string {
+	return string(flags.DoneBeforeAdd)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *doneBeforeAdd) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.PNegWG, Name: "Possible negative waitgroup counter", Crit: consts.Bug},
+	}
+}
+
+// Reset does nothing, the state is cleared with the analysis data
+func (this *doneBeforeAdd) Reset() {}
+
+// OnElement collects the adds and dones of the wait groups
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *doneBeforeAdd) OnElement(elem trace.Element, hb *a_registry.HBState) {
+	wa, ok := elem.(*trace.ElementWait)
+	if !ok {
+		return
+	}
+
+	switch wa.Type(true) {
+	case trace.WaitAdd, trace.WaitDone:
+		CheckForDoneBeforeAddChange(wa)
+	}
+}
+
+// Finish checks for possible negative wait group counters
+func (this *doneBeforeAdd) Finish() {
+	CheckForDoneBeforeAdd()
+}
+
+// ========================================================
+// MARK: ResourceDeadlock
+// ========================================================
+
+// resourceDeadlock is the scenario for possible cyclic deadlocks
+type resourceDeadlock struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *resourceDeadlock) Name() string {
+	return string(flags.ResourceDeadlock)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *resourceDeadlock) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.PCyclicDeadlock, Name: "Possible cyclic deadlock", Crit: consts.Bug},
+	}
+}
+
+// Reset clears the lock graph
+func (this *resourceDeadlock) Reset() {
+	ResetState()
+}
+
+// OnElement adds the mutex operations to the lock graph
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *resourceDeadlock) OnElement(elem trace.Element, hb *a_registry.HBState) {
+	if mu, ok := elem.(*trace.ElementMutex); ok {
+		HandleMutexEventForRessourceDeadlock(*mu)
+	}
+}
+
+// Finish checks the lock graph for cycles
+func (this *resourceDeadlock) Finish() {
+	CheckForResourceDeadlock()
+}
+
+// ========================================================
+// MARK: MixedDeadlock
+// ========================================================
+
+// mixedDeadlock is the scenario for possible mixed deadlocks
+type mixedDeadlock struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *mixedDeadlock) Name() string {
+	return string(flags.MixedDeadlock)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *mixedDeadlock) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.PMixedDeadlock, Name: "Possible Mixed Deadlock", Crit: consts.Bug},
+	}
+}
+
+// Reset clears the collected mutex and channel operations
+func (this *mixedDeadlock) Reset() {
+	ResetMixedDeadlockState()
+}
+
+// OnElement collects the mutex and channel operations
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *mixedDeadlock) OnElement(elem trace.Element, hb *a_registry.HBState) {
+	switch e := elem.(type) {
+	case *trace.ElementMutex:
+		HandleMutexEventForMixedDeadlock(e)
+	case *trace.ElementChannel:
+		HandleChannelEventForMixedDeadlock(e)
+	}
+}
+
+// Finish checks for possible mixed deadlocks
+func (this *mixedDeadlock) Finish() {
+	CheckForMixedDeadlock()
+}
+
+// ========================================================
+// MARK: UnlockBeforeLock
+// ========================================================
+
+// unlockBeforeLock is the scenario for possible unlocks of not locked mutexes
+type unlockBeforeLock struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *unlockBeforeLock) Name() string {
+	return string(flags.UnlockBeforeLock)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *unlockBeforeLock) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.PUnlockBeforeLock, Name: "Possible unlock of a not locked mutex", Crit: consts.Bug},
+	}
+}
+
+// Reset does nothing, the state is cleared with the analysis data
+func (this *unlockBeforeLock) Reset() {}
+
+// OnElement collects the successful locks and the unlocks
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *unlockBeforeLock) OnElement(elem trace.Element, hb *a_registry.HBState) {
+	mu, ok := elem.(*trace.ElementMutex)
+	if !ok {
+		return
+	}
+
+	switch mu.Type(true) {
+	case trace.MutexLock, trace.MutexRLock:
+		CheckForUnlockBeforeLockLock(mu)
+	case trace.MutexTryLock, trace.MutexTryRLock:
+		if mu.IsSuc() {
+			CheckForUnlockBeforeLockLock(mu)
+		}
+	case trace.MutexUnlock, trace.MutexRUnlock:
+		CheckForUnlockBeforeLockUnlock(mu)
+	}
+}
+
+// Finish checks for possible unlocks of not locked mutexes
+func (this *unlockBeforeLock) Finish() {
+	CheckForUnlockBeforeLock()
+}
+
+// ========================================================
+// MARK: Leak
+// ========================================================
+
+// leak is the scenario for leaks and actual blocking bugs, found from the
+// operations that were still blocked at the end of the run
+type leak struct{}
+
+// Name returns the name of the scenario
+//
+// Returns:
+//   - string: the name
+func (this *leak) Name() string {
+	return string(flags.Leak)
+}
+
+// Results returns the result types of the scenario
+//
+// Returns:
+//   - []helper.ResultTypeInfo: the result types
+func (this *leak) Results() []helper.ResultTypeInfo {
+	return []helper.ResultTypeInfo{
+		{Type: helper.ABlocking, Name: "Actual Non-Cyclic Blocking Bug", Crit: consts.Bug, Actual: true},
+		{Type: helper.ADeadlock, Name: "Actual Cyclic Deadlock", Crit: consts.Bug, Actual: true},
+		{Type: helper.LUnknown, Name: "Leak on routine or unknown element", Crit: consts.Leak},
+		{Type: helper.LChan, Name: "Leak on channel", Crit: consts.Leak},
+		{Type: helper.LNilChan, Name: "Leak on nil channel", Crit: consts.Leak},
+		{Type: helper.LSelect, Name: "Leak on select", Crit: consts.Leak},
+		{Type: helper.LMutex, Name: "Leak on mutex", Crit: consts.Leak},
+		{Type: helper.LWaitGroup, Name: "Leak on wait group", Crit: consts.Leak},
+		{Type: helper.LCond, Name: "Leak on conditional variable", Crit: consts.Leak},
+	}
+}
+
+// Reset does nothing, the leaks are found after the happens before analysis
+func (this *leak) Reset() {}
+
+// OnElement does nothing, the leaks are found after the happens before analysis
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - hb *a_registry.HBState: the happens before state
+func (this *leak) OnElement(elem trace.Element, hb *a_registry.HBState) {}
+
+// Finish does nothing, the leaks are found after the happens before analysis
+func (this *leak) Finish() {}
+
+// AnalyzeBlocked finds the leaks and blocking bugs from the blocked operations
+//
+// Returns:
+//   - error
+func (this *leak) AnalyzeBlocked() error {
+	return Blocked()
+}
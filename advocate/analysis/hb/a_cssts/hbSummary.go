@@ -0,0 +1,30 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: hbSummary.go
+// Brief: Update the cssts for summaries of operations removed by a package filter
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_cssts
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/trace"
+)
+
+// AcquireSummary adds an edge from the last release on a summarized object
+// to an acquiring element
+//
+// Parameter:
+//   - elem trace.Element: the acquiring element
+//   - id int: the id of the object
+func AcquireSummary(elem trace.Element, id int) {
+	rel, ok := a_base.SummaryRelease[id]
+	if !ok || rel.Elem == nil || rel.Elem == elem {
+		return
+	}
+
+	AddEdge(elem, rel.Elem, false)
+}
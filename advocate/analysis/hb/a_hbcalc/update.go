@@ -10,6 +10,8 @@
 package a_hbcalc
 
 import (
+	"advocate/analysis/a_base"
+	"advocate/analysis/hb/a_clock"
 	"advocate/analysis/hb/a_cssts"
 	"advocate/analysis/hb/a_pog"
 	"advocate/analysis/hb/a_vc"
@@ -215,3 +217,121 @@ func UpdateHBWait(wa *trace.ElementWait) {
 		a_cssts.UpdateHBWait(wa)
 	}
 }
+
+// UpdateHBSummary updates the hb info of the trace for a summary of
+// operations that were removed by a package filter. The summary first
+// acquires and then releases its objects.
+//
+// Parameter
+//   - su *trace.ElementSummary: the summary
+func UpdateHBSummary(su *trace.ElementSummary) {
+	timer.Start(timer.AnaHb)
+	defer timer.Stop(timer.AnaHb)
+
+	if su.IsAcquire() {
+		for _, id := range su.ObjIDs() {
+			acquireSummary(su, id)
+		}
+	}
+
+	if CalcVC {
+		a_vc.UpdateHBSummary(su)
+	}
+
+	if su.IsRelease() {
+		for _, id := range su.ObjIDs() {
+			releaseSummary(su, id)
+		}
+	}
+}
+
+// UpdateHBCustomSync updates the hb info of the trace for a user declared
+// release or acquire. The keys are handled like summarized objects, so that
+// custom syncs in ignored regions are connected with the recorded ones.
+//
+// Parameter:
+//   - cs *trace.ElementCustomSync: the release or acquire
+func UpdateHBCustomSync(cs *trace.ElementCustomSync) {
+	timer.Start(timer.AnaHb)
+	defer timer.Stop(timer.AnaHb)
+
+	if !cs.IsRelease() {
+		acquireSummary(cs, cs.ObjID())
+	}
+
+	if CalcVC {
+		a_vc.UpdateHBCustomSync(cs)
+	}
+
+	if cs.IsRelease() {
+		releaseSummary(cs, cs.ObjID())
+	}
+}
+
+// AcquireSummary updates the hb info for a recorded element that acquires
+// an object on which operations have been summarized. Must be called before
+// the hb info of the element itself is updated.
+//
+// Parameter
+//   - elem trace.Element: the acquiring element
+//   - id int: the id of the object
+func AcquireSummary(elem trace.Element, id int) {
+	timer.Start(timer.AnaHb)
+	defer timer.Stop(timer.AnaHb)
+
+	acquireSummary(elem, id)
+}
+
+// ReleaseSummary updates the hb info for a recorded element that releases
+// an object on which operations have been summarized. Must be called after
+// the hb info of the element itself is updated.
+//
+// Parameter
+//   - elem trace.Element: the releasing element
+//   - id int: the id of the object
+func ReleaseSummary(elem trace.Element, id int) {
+	timer.Start(timer.AnaHb)
+	defer timer.Stop(timer.AnaHb)
+
+	releaseSummary(elem, id)
+}
+
+// acquireSummary syncs an element with the releases on a summarized object
+//
+// Parameter
+//   - elem trace.Element: the acquiring element
+//   - id int: the id of the object
+func acquireSummary(elem trace.Element, id int) {
+	if CalcVC {
+		a_vc.AcquireSummary(elem, id)
+	}
+
+	if CalcPog {
+		a_pog.AcquireSummary(nil, elem, id)
+	}
+
+	if CalcCssts {
+		a_cssts.AcquireSummary(elem, id)
+	}
+}
+
+// releaseSummary stores an element as the last release on a summarized
+// object. The vector clock of the release is joined with the vector clocks
+// of all previous releases, since the summaries do not contain which
+// release was observed by an acquire.
+//
+// Parameter
+//   - elem trace.Element: the releasing element
+//   - id int: the id of the object
+func releaseSummary(elem trace.Element, id int) {
+	rel, ok := a_base.SummaryRelease[id]
+	if !ok {
+		rel = &a_base.ElemWithVc{}
+		a_base.SummaryRelease[id] = rel
+	}
+
+	rel.Elem = elem
+	if CalcVC {
+		rel.Vc = rel.Vc.Sync(elem.GetVC(a_clock.Strong))
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: hbSummary.go
+// Brief: Update the pog for summaries of operations removed by a package filter
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_pog
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/trace"
+)
+
+// AcquireSummary adds an edge from the last release on a summarized object
+// to an acquiring element
+//
+// Parameter:
+//   - graph *PoGraph: if nil, use the standard po/poivert, otherwise add to given
+//   - elem trace.Element: the acquiring element
+//   - id int: the id of the object
+func AcquireSummary(graph *PoGraph, elem trace.Element, id int) {
+	rel, ok := a_base.SummaryRelease[id]
+	if !ok || rel.Elem == nil || rel.Elem == elem {
+		return
+	}
+
+	if graph != nil {
+		graph.AddEdge(elem, rel.Elem)
+	} else {
+		AddEdge(elem, rel.Elem, false)
+	}
+}
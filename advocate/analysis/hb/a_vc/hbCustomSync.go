@@ -0,0 +1,31 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: hbCustomSync.go
+// Brief: Update the vc for user declared releases and acquires
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_vc
+
+import (
+	"advocate/analysis/hb/a_clock"
+	"advocate/trace"
+)
+
+// UpdateHBCustomSync sets the vector clock of a user declared release or
+// acquire and increments the vector clock of its routine. The sync with
+// previous releases on the key is done by AcquireSummary.
+//
+// Parameter:
+//   - cs *trace.ElementCustomSync: the release or acquire
+func UpdateHBCustomSync(cs *trace.ElementCustomSync) {
+	routine := cs.Routine()
+
+	cs.Vc(a_clock.Strong, CurrentVC[routine].Copy())
+	cs.Vc(a_clock.Weak, CurrentWVC[routine].Copy())
+
+	CurrentVC[routine].Inc(routine)
+	CurrentWVC[routine].Inc(routine)
+}
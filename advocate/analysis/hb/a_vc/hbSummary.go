@@ -0,0 +1,49 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: hbSummary.go
+// Brief: Update the vc for summaries of operations removed by a package filter
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package a_vc
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/analysis/hb/a_clock"
+	"advocate/trace"
+)
+
+// UpdateHBSummary sets the vector clock of a summary and increments the
+// vector clock of its routine. The acquires and releases of the summary are
+// handled by AcquireSummary and the release state in a_base.
+//
+// Parameter:
+//   - su *trace.ElementSummary: the summary
+func UpdateHBSummary(su *trace.ElementSummary) {
+	routine := su.Routine()
+
+	su.Vc(a_clock.Strong, CurrentVC[routine].Copy())
+	su.Vc(a_clock.Weak, CurrentWVC[routine].Copy())
+
+	CurrentVC[routine].Inc(routine)
+	CurrentWVC[routine].Inc(routine)
+}
+
+// AcquireSummary syncs the routine of an element with all previous releases
+// on a summarized object
+//
+// Parameter:
+//   - elem trace.Element: the acquiring element
+//   - id int: the id of the object
+func AcquireSummary(elem trace.Element, id int) {
+	rel, ok := a_base.SummaryRelease[id]
+	if !ok || rel.Vc == nil {
+		return
+	}
+
+	routine := elem.Routine()
+	CurrentVC[routine].Sync(rel.Vc)
+	CurrentWVC[routine].Sync(rel.Vc)
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: channel.go
+// Brief: Find channel operations that cannot have a partner
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// chanClass contains all operations on one class of channels
+//
+// Fields:
+//   - root any: the representative of the class
+//   - ops []*chanOp: all operations on the class
+//   - numSend, numRecv, numClose, numRange int: number of operations of each kind
+type chanClass struct {
+	root     any
+	ops      []*chanOp
+	numSend  int
+	numRecv  int
+	numClose int
+	numRange int
+}
+
+// checkChannels searches for channel operations that cannot have a
+// partner. Channels that can be used by code that is not analyzed are
+// never reported.
+func (this *analyzer) checkChannels() {
+	escaped, param, origin, members := this.classFacts()
+
+	classes := make(map[any]*chanClass)
+	order := make([]*chanClass, 0)
+	for _, op := range this.chanOps {
+		root := this.classOf(op.key)
+		c, ok := classes[root]
+		if !ok {
+			c = &chanClass{root: root}
+			classes[root] = c
+			order = append(order, c)
+		}
+		c.ops = append(c.ops, op)
+		switch op.kind {
+		case opSend:
+			c.numSend++
+		case opRecv:
+			c.numRecv++
+		case opClose:
+			c.numClose++
+		case opRange:
+			c.numRange++
+		}
+	}
+
+	for _, c := range order {
+		if escaped[c.root] {
+			continue
+		}
+
+		name := this.className(members[c.root], c.root)
+
+		// channel is never created
+		if !origin[c.root] && !param[c.root] {
+			pos := make([]token.Pos, 0, len(c.ops))
+			for _, op := range c.ops {
+				if op.sel == nil {
+					pos = append(pos, op.pos)
+				}
+			}
+			this.addFinding(NilChannel, name, weightCertain,
+				fmt.Sprintf("Channel %s is never created, operations on it block forever", name), pos...)
+			continue
+		}
+
+		if c.numSend > 0 && c.numRecv == 0 && c.numRange == 0 {
+			this.checkSendWithoutRecv(c, name)
+		}
+
+		if c.numSend == 0 && c.numClose == 0 && (c.numRecv > 0 || c.numRange > 0) && origin[c.root] {
+			pos := make([]token.Pos, 0)
+			for _, op := range c.ops {
+				if (op.kind == opRecv || op.kind == opRange) && op.sel == nil {
+					pos = append(pos, op.pos)
+				}
+			}
+			this.addFinding(RecvWithoutSend, name, weightCertain,
+				fmt.Sprintf("Channel %s is never sent on or closed, receives on it block forever", name), pos...)
+		}
+
+		if c.numRange > 0 && c.numSend > 0 && c.numClose == 0 {
+			pos := make([]token.Pos, 0)
+			for _, op := range c.ops {
+				if op.kind == opRange {
+					pos = append(pos, op.pos)
+				}
+			}
+			this.addFinding(RangeWithoutClose, name, weightPossible,
+				fmt.Sprintf("Channel %s is never closed, the range over it blocks after the last send", name), pos...)
+		}
+	}
+
+	this.checkSelects(classes, escaped)
+}
+
+// checkSendWithoutRecv reports the sends on a class of channels without
+// any receive
+//
+// Parameter:
+//   - c *chanClass: the class
+//   - name string: readable name of the class
+func (this *analyzer) checkSendWithoutRecv(c *chanClass, name string) {
+	// capacity of the created channels, -1 if unknown or not unique
+	capacity := -2
+	for key, cp := range this.capacity {
+		if this.classOf(key) != c.root {
+			continue
+		}
+		if capacity != -2 && capacity != cp {
+			capacity = -1
+			break
+		}
+		capacity = cp
+	}
+
+	sends := make([]*chanOp, 0)
+	inLoop := false
+	for _, op := range c.ops {
+		if op.kind == opSend && op.sel == nil {
+			sends = append(sends, op)
+			inLoop = inLoop || op.ctx.inLoop || op.ctx.goInLoop
+		}
+	}
+	if len(sends) == 0 {
+		return
+	}
+
+	pos := make([]token.Pos, len(sends))
+	for i, op := range sends {
+		pos[i] = op.pos
+	}
+
+	switch {
+	case capacity == 0:
+		this.addFinding(SendWithoutRecv, name, weightCertain,
+			fmt.Sprintf("Unbuffered channel %s is never received from, sends on it block forever", name), pos...)
+	case capacity > 0 && (inLoop || len(sends) > capacity):
+		this.addFinding(SendOnFullBuffer, name, weightPossible,
+			fmt.Sprintf("Channel %s with buffer size %d is never received from, sends block when the buffer is full", name, capacity), pos...)
+	}
+}
+
+// checkSelects reports select statements without default case, where no
+// case can have a partner
+//
+// Parameter:
+//   - classes map[any]*chanClass: the classes of channels with operations
+//   - escaped map[any]bool: the escaped classes
+func (this *analyzer) checkSelects(classes map[any]*chanClass, escaped map[any]bool) {
+	for _, sel := range this.selects {
+		if sel.hasDefault || len(sel.cases) == 0 {
+			continue
+		}
+
+		blocked := true
+		for _, op := range sel.cases {
+			if op.key == nil || this.hasPartner(op, classes, escaped) {
+				blocked = false
+				break
+			}
+		}
+		if !blocked {
+			continue
+		}
+
+		pos := []token.Pos{sel.pos}
+		for _, op := range sel.cases {
+			pos = append(pos, op.pos)
+		}
+		this.addFinding(SelectNoPartner, "select", weightCertain,
+			"No case of the select can have a partner, the select blocks forever", pos...)
+	}
+}
+
+// hasPartner returns whether a channel operation can have a partner
+//
+// Parameter:
+//   - op *chanOp: the operation
+//   - classes map[any]*chanClass: the classes of channels with operations
+//   - escaped map[any]bool: the escaped classes
+//
+// Returns:
+//   - bool: false if the operation can never have a partner
+func (this *analyzer) hasPartner(op *chanOp, classes map[any]*chanClass, escaped map[any]bool) bool {
+	root := this.classOf(op.key)
+	if escaped[root] {
+		return true
+	}
+
+	c := classes[root]
+	if c == nil {
+		return true
+	}
+
+	switch op.kind {
+	case opSend:
+		return c.numRecv > 0 || c.numRange > 0
+	case opRecv:
+		return c.numSend > 0 || c.numClose > 0
+	}
+	return true
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: load.go
+// Brief: Parse and type check all packages of a program for the static
+//    blocking analysis
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"bufio"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pkgInfo is one type checked package of the program
+//
+// Fields:
+//   - path string: import path of the package, with suffix _test for external test packages
+//   - dir string: folder of the package
+//   - files []*ast.File: the parsed files of the package
+//   - isTest map[*ast.File]bool: true for _test.go files
+//   - info *types.Info: the type information of the package
+type pkgInfo struct {
+	path   string
+	dir    string
+	files  []*ast.File
+	isTest map[*ast.File]bool
+	info   *types.Info
+}
+
+// program contains all packages of the analyzed program
+//
+// Fields:
+//   - fset *token.FileSet: the file set of all parsed files
+//   - pkgs []*pkgInfo: the packages sorted by their path
+type program struct {
+	fset *token.FileSet
+	pkgs []*pkgInfo
+}
+
+// loadProgram parses all go files in a program folder and type checks them
+// package by package. As in the concurrency coverage, imports are resolved
+// with the default importer, so that the types of the standard library
+// (e.g. sync and channels of imported types) are known. Errors of the type
+// checker, e.g. from packages of the program that cannot be imported, are
+// ignored. Expressions with unknown types are handled conservatively.
+//
+// Parameter:
+//   - progPath string: path to the program folder
+//
+// Returns:
+//   - *program: the loaded program
+//   - error
+func loadProgram(progPath string) (*program, error) {
+	root, err := filepath.Abs(progPath)
+	if err != nil {
+		return nil, err
+	}
+
+	modRoot, modPath := findModule(root)
+
+	prog := &program{fset: token.NewFileSet()}
+
+	// dir -> package name -> files
+	groups := make(map[string]map[string][]*ast.File)
+	testFiles := make(map[*ast.File]bool)
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (name == "advocateResult" || name == "vendor" ||
+				name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(prog.fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		if _, ok := groups[dir]; !ok {
+			groups[dir] = make(map[string][]*ast.File)
+		}
+		groups[dir][file.Name.Name] = append(groups[dir][file.Name.Name], file)
+		testFiles[file] = strings.HasSuffix(path, "_test.go")
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imp := importer.Default()
+
+	for dir, pkgs := range groups {
+		importPath := importPathOfDir(modRoot, modPath, dir)
+
+		for name, files := range pkgs {
+			path := importPath
+			if strings.HasSuffix(name, "_test") {
+				path += "_test"
+			}
+
+			info := &types.Info{
+				Types:      make(map[ast.Expr]types.TypeAndValue),
+				Defs:       make(map[*ast.Ident]types.Object),
+				Uses:       make(map[*ast.Ident]types.Object),
+				Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			}
+
+			conf := types.Config{Importer: imp, Error: func(error) {}}
+			_, _ = conf.Check(path, prog.fset, files, info)
+
+			isTest := make(map[*ast.File]bool)
+			for _, file := range files {
+				isTest[file] = testFiles[file]
+			}
+
+			prog.pkgs = append(prog.pkgs, &pkgInfo{
+				path:   path,
+				dir:    dir,
+				files:  files,
+				isTest: isTest,
+				info:   info,
+			})
+		}
+	}
+
+	sort.Slice(prog.pkgs, func(i, j int) bool {
+		return prog.pkgs[i].path < prog.pkgs[j].path
+	})
+
+	return prog, nil
+}
+
+// findModule searches the go.mod of the program in the folder and its parents
+//
+// Parameter:
+//   - dir string: the folder of the program
+//
+// Returns:
+//   - string: the folder containing the go.mod, dir if no go.mod was found
+//   - string: the module path, empty if no go.mod was found
+func findModule(dir string) (string, string) {
+	for d := dir; ; d = filepath.Dir(d) {
+		file, err := os.Open(filepath.Join(d, "go.mod"))
+		if err == nil {
+			defer file.Close()
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if strings.HasPrefix(line, "module ") {
+					return d, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), "\"")
+				}
+			}
+			return d, ""
+		}
+
+		if filepath.Dir(d) == d {
+			return dir, ""
+		}
+	}
+}
+
+// importPathOfDir returns the import path of a package folder
+//
+// Parameter:
+//   - modRoot string: folder containing the go.mod
+//   - modPath string: the module path
+//   - dir string: the folder of the package
+//
+// Returns:
+//   - string: the import path
+func importPathOfDir(modRoot, modPath, dir string) string {
+	rel, err := filepath.Rel(modRoot, dir)
+	if err != nil || rel == "." {
+		if modPath == "" {
+			return filepath.Base(dir)
+		}
+		return modPath
+	}
+
+	rel = filepath.ToSlash(rel)
+	if modPath == "" {
+		return rel
+	}
+	return modPath + "/" + rel
+}
@@ -0,0 +1,669 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: lock.go
+// Brief: Find locks that are acquired in inconsistent orders or twice
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// maximum length of a reported lock order cycle
+const maxLockCycle = 4
+
+// heldLock is a lock that is held at some point of a function
+//
+// Fields:
+//   - root any: the class of the lock
+//   - expr string: the expression of the lock, empty if the lock is acquired in a called function
+//   - pos token.Pos: position of the acquisition
+//   - read bool: true if acquired with RLock
+type heldLock struct {
+	root any
+	expr string
+	pos  token.Pos
+	read bool
+}
+
+// lockEdge is an edge of the lock order graph, the lock to is acquired
+// while the lock from is held
+//
+// Fields:
+//   - from token.Pos: position of the acquisition of the held lock
+//   - to token.Pos: position of the acquisition of the new lock
+//   - read bool: true if both locks are acquired with RLock
+type lockEdge struct {
+	from token.Pos
+	to   token.Pos
+	read bool
+}
+
+// lockChecker contains the state of the lock order analysis
+//
+// Fields:
+//   - a *analyzer: the analyzer
+//   - acquires map[*types.Func][]heldLock: function -> locks acquired by the function or its callees
+//   - edges map[any]map[any]lockEdge: the lock order graph
+//   - nodes []any: the locks of the graph in the order they were added
+//   - index map[any]int: lock -> index in nodes
+type lockChecker struct {
+	a        *analyzer
+	acquires map[*types.Func][]heldLock
+	edges    map[any]map[any]lockEdge
+	nodes    []any
+	index    map[any]int
+}
+
+// checkLocks builds the lock order graph of the program by following the
+// held locks through all functions. Cycles in the graph are reported as
+// inconsistent lock orders, the acquisition of a held lock as double lock.
+func (this *analyzer) checkLocks() {
+	lc := &lockChecker{
+		a:        this,
+		acquires: make(map[*types.Func][]heldLock),
+		edges:    make(map[any]map[any]lockEdge),
+		index:    make(map[any]int),
+	}
+
+	lc.summarize()
+
+	this.inspect(func(pkg *pkgInfo, n ast.Node, stack []ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				lc.walkStmts(pkg, n.Body.List, nil)
+			}
+		case *ast.FuncLit:
+			// function literals called directly are handled together with the enclosing function
+			if isDirectCall(n, stack) && (len(stack) < 2 || !isGoStmt(stack[len(stack)-2])) {
+				return true
+			}
+			lc.walkStmts(pkg, n.Body.List, nil)
+		}
+		return true
+	})
+
+	lc.reportCycles()
+}
+
+// isGoStmt returns whether a node is a go statement
+//
+// Parameter:
+//   - n ast.Node: the node
+//
+// Returns:
+//   - bool: true for go statements
+func isGoStmt(n ast.Node) bool {
+	_, ok := n.(*ast.GoStmt)
+	return ok
+}
+
+// summarize computes for each function the locks it or the functions it
+// calls acquire. Goroutines started by the function are not included.
+func (this *lockChecker) summarize() {
+	calls := make(map[*types.Func][]*types.Func)
+	order := make([]*types.Func, 0, len(this.a.funcs))
+
+	for _, pkg := range this.a.prog.pkgs {
+		for _, file := range pkg.files {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				if fn, ok := pkg.info.Defs[fd.Name].(*types.Func); ok {
+					order = append(order, fn)
+				}
+			}
+		}
+	}
+
+	for _, fn := range order {
+		fd := this.a.funcs[fn]
+		pkg := this.a.funcPkg[fd]
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GoStmt:
+				return false
+			case *ast.FuncLit:
+				return this.a.directLits[n]
+			case *ast.CallExpr:
+				kind, key, method := this.a.syncMethod(pkg, n)
+				if kind == primMutex {
+					if key != nil && (method == "Lock" || method == "RLock") {
+						this.acquires[fn] = addAcquired(this.acquires[fn],
+							heldLock{root: this.a.classOf(key), pos: n.Pos(), read: method == "RLock"})
+					}
+					return true
+				}
+				if callee := calleeFunc(pkg, n); callee != nil && this.a.funcs[callee] != nil {
+					calls[fn] = append(calls[fn], callee)
+				}
+			}
+			return true
+		})
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range order {
+			for _, callee := range calls[fn] {
+				for _, l := range this.acquires[callee] {
+					before := len(this.acquires[fn])
+					this.acquires[fn] = addAcquired(this.acquires[fn], l)
+					changed = changed || len(this.acquires[fn]) != before
+				}
+			}
+		}
+	}
+}
+
+// addAcquired adds a lock to a list of acquired locks, if its class is not
+// already in the list
+//
+// Parameter:
+//   - list []heldLock: the list
+//   - l heldLock: the lock
+//
+// Returns:
+//   - []heldLock: the new list
+func addAcquired(list []heldLock, l heldLock) []heldLock {
+	for _, h := range list {
+		if h.root == l.root {
+			return list
+		}
+	}
+	return append(list, l)
+}
+
+// walkStmts follows the held locks through a list of statements
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the statements
+//   - stmts []ast.Stmt: the statements
+//   - held []heldLock: the locks held before the statements
+//
+// Returns:
+//   - []heldLock: the locks held after the statements
+//   - bool: true if the statements always leave the block, e.g. with return
+func (this *lockChecker) walkStmts(pkg *pkgInfo, stmts []ast.Stmt, held []heldLock) ([]heldLock, bool) {
+	for _, s := range stmts {
+		var term bool
+		held, term = this.walkStmt(pkg, s, held)
+		if term {
+			return held, true
+		}
+	}
+	return held, false
+}
+
+// walkStmt follows the held locks through a statement. For branches, only
+// the locks held in all branches that do not leave the block are kept.
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the statement
+//   - s ast.Stmt: the statement
+//   - held []heldLock: the locks held before the statement
+//
+// Returns:
+//   - []heldLock: the locks held after the statement
+//   - bool: true if the statement always leaves the block
+func (this *lockChecker) walkStmt(pkg *pkgInfo, s ast.Stmt, held []heldLock) ([]heldLock, bool) {
+	switch s := s.(type) {
+	case nil:
+		return held, false
+
+	case *ast.BlockStmt:
+		return this.walkStmts(pkg, s.List, held)
+
+	case *ast.LabeledStmt:
+		return this.walkStmt(pkg, s.Stmt, held)
+
+	case *ast.ReturnStmt:
+		return this.walkNode(pkg, s, held), true
+
+	case *ast.BranchStmt:
+		return held, s.Tok != token.FALLTHROUGH
+
+	case *ast.ExprStmt:
+		held = this.walkNode(pkg, s.X, held)
+		if call, ok := ast.Unparen(s.X).(*ast.CallExpr); ok && builtinName(pkg, call) == "panic" {
+			return held, true
+		}
+		return held, false
+
+	case *ast.GoStmt:
+		// the started function is analyzed separately
+		for _, arg := range s.Call.Args {
+			held = this.walkNode(pkg, arg, held)
+		}
+		return held, false
+
+	case *ast.DeferStmt:
+		// deferred unlocks are executed at the end of the function, the lock stays held
+		return held, false
+
+	case *ast.IfStmt:
+		held, _ = this.walkStmt(pkg, s.Init, held)
+		held = this.walkNode(pkg, s.Cond, held)
+		thenHeld, thenTerm := this.walkStmt(pkg, s.Body, copyHeld(held))
+		elseHeld, elseTerm := copyHeld(held), false
+		if s.Else != nil {
+			elseHeld, elseTerm = this.walkStmt(pkg, s.Else, elseHeld)
+		}
+		return mergeHeld(held, []heldBranch{{thenHeld, thenTerm}, {elseHeld, elseTerm}})
+
+	case *ast.ForStmt:
+		held, _ = this.walkStmt(pkg, s.Init, held)
+		held = this.walkNode(pkg, s.Cond, held)
+		bodyHeld, bodyTerm := this.walkStmt(pkg, s.Body, copyHeld(held))
+		if !bodyTerm {
+			bodyHeld, _ = this.walkStmt(pkg, s.Post, bodyHeld)
+			held = intersectHeld(held, bodyHeld)
+		}
+		return held, false
+
+	case *ast.RangeStmt:
+		held = this.walkNode(pkg, s.X, held)
+		bodyHeld, bodyTerm := this.walkStmt(pkg, s.Body, copyHeld(held))
+		if !bodyTerm {
+			held = intersectHeld(held, bodyHeld)
+		}
+		return held, false
+
+	case *ast.SwitchStmt:
+		held, _ = this.walkStmt(pkg, s.Init, held)
+		held = this.walkNode(pkg, s.Tag, held)
+		return this.walkClauses(pkg, s.Body, held)
+
+	case *ast.TypeSwitchStmt:
+		held, _ = this.walkStmt(pkg, s.Init, held)
+		held, _ = this.walkStmt(pkg, s.Assign, held)
+		return this.walkClauses(pkg, s.Body, held)
+
+	case *ast.SelectStmt:
+		if len(s.Body.List) == 0 {
+			return held, true
+		}
+		return this.walkClauses(pkg, s.Body, held)
+	}
+
+	return this.walkNode(pkg, s, held), false
+}
+
+// heldBranch is the result of one branch of a statement
+//
+// Fields:
+//   - held []heldLock: the locks held at the end of the branch
+//   - term bool: true if the branch leaves the block
+type heldBranch struct {
+	held []heldLock
+	term bool
+}
+
+// walkClauses follows the held locks through the clauses of a switch or select
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the statement
+//   - body *ast.BlockStmt: the body containing the clauses
+//   - held []heldLock: the locks held before the clauses
+//
+// Returns:
+//   - []heldLock: the locks held after the statement
+//   - bool: true if the statement always leaves the block
+func (this *lockChecker) walkClauses(pkg *pkgInfo, body *ast.BlockStmt, held []heldLock) ([]heldLock, bool) {
+	branches := make([]heldBranch, 0, len(body.List)+1)
+	hasDefault := false
+
+	for _, stmt := range body.List {
+		switch c := stmt.(type) {
+		case *ast.CaseClause:
+			hasDefault = hasDefault || c.List == nil
+			h, term := this.walkStmts(pkg, c.Body, copyHeld(held))
+			branches = append(branches, heldBranch{h, term})
+		case *ast.CommClause:
+			hasDefault = true // a select always executes one of its clauses
+			h, _ := this.walkStmt(pkg, c.Comm, copyHeld(held))
+			h, term := this.walkStmts(pkg, c.Body, h)
+			branches = append(branches, heldBranch{h, term})
+		}
+	}
+
+	if !hasDefault {
+		branches = append(branches, heldBranch{copyHeld(held), false})
+	}
+
+	return mergeHeld(held, branches)
+}
+
+// walkNode follows the held locks through the calls in a node
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the node
+//   - n ast.Node: the node
+//   - held []heldLock: the locks held before the node
+//
+// Returns:
+//   - []heldLock: the locks held after the node
+func (this *lockChecker) walkNode(pkg *pkgInfo, n ast.Node, held []heldLock) []heldLock {
+	if n == nil {
+		return held
+	}
+
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// analyzed separately
+			return false
+		case *ast.CallExpr:
+			for _, arg := range n.Args {
+				held = this.walkNode(pkg, arg, held)
+			}
+			if lit, ok := ast.Unparen(n.Fun).(*ast.FuncLit); ok {
+				held, _ = this.walkStmts(pkg, lit.Body.List, held)
+				return false
+			}
+			if se, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok {
+				held = this.walkNode(pkg, se.X, held)
+			}
+			held = this.call(pkg, n, held)
+			return false
+		}
+		return true
+	})
+
+	return held
+}
+
+// call handles a call of a lock method or of a function of the program
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+//   - held []heldLock: the locks held before the call
+//
+// Returns:
+//   - []heldLock: the locks held after the call
+func (this *lockChecker) call(pkg *pkgInfo, call *ast.CallExpr, held []heldLock) []heldLock {
+	kind, key, method := this.a.syncMethod(pkg, call)
+	if kind == primMutex {
+		if key == nil {
+			return held
+		}
+
+		root := this.a.classOf(key)
+		expr := types.ExprString(ast.Unparen(call.Fun).(*ast.SelectorExpr).X)
+
+		switch method {
+		case "Lock", "RLock":
+			l := heldLock{root: root, expr: expr, pos: call.Pos(), read: method == "RLock"}
+			this.acquire(held, l)
+			return append(held, l)
+		case "Unlock", "RUnlock":
+			return releaseHeld(held, root, expr)
+		}
+		return held
+	}
+
+	fn := calleeFunc(pkg, call)
+	if fn == nil || this.a.funcs[fn] == nil {
+		return held
+	}
+
+	for _, l := range this.acquires[fn] {
+		this.acquire(held, l)
+	}
+	return held
+}
+
+// acquire adds the edges from all held locks to a newly acquired lock and
+// reports the acquisition of a held lock
+//
+// Parameter:
+//   - held []heldLock: the held locks
+//   - l heldLock: the acquired lock
+func (this *lockChecker) acquire(held []heldLock, l heldLock) {
+	for _, h := range held {
+		if h.root != l.root {
+			this.addEdge(h, l)
+			continue
+		}
+
+		// same class, but possibly a different instance, e.g. a field of another struct
+		if h.expr == "" || h.expr != l.expr || (h.read && l.read) {
+			continue
+		}
+
+		name := this.a.className(this.members(l.root), l.root)
+		this.a.addFinding(DoubleLock, name, weightCertain,
+			fmt.Sprintf("Lock %s is acquired while it is already held, the second acquisition blocks forever", name),
+			h.pos, l.pos)
+	}
+}
+
+// addEdge adds an edge to the lock order graph, if it does not exist
+//
+// Parameter:
+//   - from heldLock: the held lock
+//   - to heldLock: the acquired lock
+func (this *lockChecker) addEdge(from, to heldLock) {
+	for _, root := range []any{from.root, to.root} {
+		if _, ok := this.index[root]; !ok {
+			this.index[root] = len(this.nodes)
+			this.nodes = append(this.nodes, root)
+		}
+	}
+
+	if _, ok := this.edges[from.root]; !ok {
+		this.edges[from.root] = make(map[any]lockEdge)
+	}
+	if _, ok := this.edges[from.root][to.root]; ok {
+		return
+	}
+	this.edges[from.root][to.root] = lockEdge{from: from.pos, to: to.pos, read: from.read && to.read}
+}
+
+// reportCycles reports all cycles of the lock order graph up to length
+// maxLockCycle. Each cycle is reported once, starting at its lock with
+// the smallest index.
+func (this *lockChecker) reportCycles() {
+	for start := range this.nodes {
+		path := []int{start}
+		var dfs func(cur int)
+		dfs = func(cur int) {
+			for _, next := range this.successors(cur) {
+				if next == start && len(path) > 1 {
+					this.reportCycle(path)
+					continue
+				}
+				if next <= start || len(path) >= maxLockCycle || containsInt(path, next) {
+					continue
+				}
+				path = append(path, next)
+				dfs(next)
+				path = path[:len(path)-1]
+			}
+		}
+		dfs(start)
+	}
+}
+
+// successors returns the indices of the locks acquired while a lock is held
+//
+// Parameter:
+//   - i int: index of the held lock
+//
+// Returns:
+//   - []int: the indices, sorted
+func (this *lockChecker) successors(i int) []int {
+	res := make([]int, 0)
+	for j, node := range this.nodes {
+		if _, ok := this.edges[this.nodes[i]][node]; ok {
+			res = append(res, j)
+		}
+	}
+	return res
+}
+
+// reportCycle adds the finding for a cycle in the lock order graph
+//
+// Parameter:
+//   - path []int: indices of the locks in the cycle
+func (this *lockChecker) reportCycle(path []int) {
+	names := make([]string, len(path))
+	pos := make([]token.Pos, 0, 2*len(path))
+	read := true
+
+	for i, index := range path {
+		from := this.nodes[index]
+		to := this.nodes[path[(i+1)%len(path)]]
+		edge := this.edges[from][to]
+
+		names[i] = this.a.className(this.members(from), from)
+		pos = append(pos, edge.from, edge.to)
+		read = read && edge.read
+	}
+
+	weight := weightCertain
+	if read {
+		weight = weightPossible
+	}
+
+	object := strings.Join(names, ", ")
+	this.a.addFinding(LockOrder, object, weight,
+		fmt.Sprintf("Locks %s are acquired in inconsistent orders, this can lead to a cyclic deadlock", object), pos...)
+}
+
+// members returns all keys of the class of a lock
+//
+// Parameter:
+//   - root any: the representative of the class
+//
+// Returns:
+//   - []any: the keys of the class
+func (this *lockChecker) members(root any) []any {
+	res := []any{root}
+	for key := range this.a.names {
+		if key != root && this.a.classOf(key) == root {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// copyHeld returns a copy of a list of held locks
+//
+// Parameter:
+//   - held []heldLock: the list
+//
+// Returns:
+//   - []heldLock: the copy
+func copyHeld(held []heldLock) []heldLock {
+	return append([]heldLock{}, held...)
+}
+
+// releaseHeld removes the last acquisition of a lock from the held locks
+//
+// Parameter:
+//   - held []heldLock: the held locks
+//   - root any: the class of the released lock
+//   - expr string: the expression of the released lock
+//
+// Returns:
+//   - []heldLock: the held locks after the release
+func releaseHeld(held []heldLock, root any, expr string) []heldLock {
+	index := -1
+	for i := len(held) - 1; i >= 0; i-- {
+		if held[i].root != root {
+			continue
+		}
+		if held[i].expr == expr {
+			index = i
+			break
+		}
+		if index == -1 {
+			index = i
+		}
+	}
+
+	if index == -1 {
+		return held
+	}
+	return append(copyHeld(held[:index]), held[index+1:]...)
+}
+
+// intersectHeld returns the locks held in both lists
+//
+// Parameter:
+//   - a []heldLock: the first list
+//   - b []heldLock: the second list
+//
+// Returns:
+//   - []heldLock: the locks of a that are also in b
+func intersectHeld(a, b []heldLock) []heldLock {
+	res := make([]heldLock, 0, len(a))
+	for _, l := range a {
+		for _, m := range b {
+			if l.root == m.root && l.expr == m.expr {
+				res = append(res, l)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// mergeHeld merges the results of the branches of a statement
+//
+// Parameter:
+//   - held []heldLock: the locks held before the statement
+//   - branches []heldBranch: the results of the branches
+//
+// Returns:
+//   - []heldLock: the locks held in all branches that do not leave the block
+//   - bool: true if all branches leave the block
+func mergeHeld(held []heldLock, branches []heldBranch) ([]heldLock, bool) {
+	var res []heldLock
+	found := false
+	for _, b := range branches {
+		if b.term {
+			continue
+		}
+		if !found {
+			res, found = b.held, true
+			continue
+		}
+		res = intersectHeld(res, b.held)
+	}
+
+	if !found {
+		return held, true
+	}
+	return res, false
+}
+
+// containsInt returns whether a list contains a value
+//
+// Parameter:
+//   - list []int: the list
+//   - v int: the value
+//
+// Returns:
+//   - bool: true if v is in list
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1084 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: objects.go
+// Brief: Resolve which expressions of a program can refer to the same
+//    channel, mutex or wait group
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// primKind is the kind of primitive an expression refers to
+type primKind int
+
+const (
+	primNone primKind = iota
+	primChan
+	primMutex
+	primWaitGroup
+)
+
+// resultKey identifies a result of a function of the program
+//
+// Fields:
+//   - fn *types.Func: the function
+//   - index int: index of the result
+type resultKey struct {
+	fn    *types.Func
+	index int
+}
+
+// unionFind is a union find structure over the objects of the program.
+// The keys are variables and fields (*types.Var), results of functions
+// (resultKey) and the expressions creating a new object (*ast.CallExpr for
+// make and new, *ast.CompositeLit for literals).
+//
+// Fields:
+//   - parent map[any]any: key -> parent key, roots are not in the map
+type unionFind struct {
+	parent map[any]any
+}
+
+// find returns the representative of the class of a key
+//
+// Parameter:
+//   - key any: the key
+//
+// Returns:
+//   - any: the representative
+func (this *unionFind) find(key any) any {
+	root := key
+	for {
+		p, ok := this.parent[root]
+		if !ok {
+			break
+		}
+		root = p
+	}
+
+	// path compression
+	for key != root {
+		next := this.parent[key]
+		this.parent[key] = root
+		key = next
+	}
+
+	return root
+}
+
+// union merges the classes of two keys
+//
+// Parameter:
+//   - a any: the first key
+//   - b any: the second key
+func (this *unionFind) union(a, b any) {
+	ra, rb := this.find(a), this.find(b)
+	if ra != rb {
+		this.parent[ra] = rb
+	}
+}
+
+// analyzer contains the state of the static analysis of one program
+//
+// Fields:
+//   - prog *program: the analyzed program
+//   - uf *unionFind: classes of keys that can refer to the same object
+//   - escaped map[any]bool: keys that can be used by code that is not analyzed
+//   - origin map[any]bool: keys that create a new channel, mutex or wait group
+//   - param map[any]bool: keys that are parameters or results of functions
+//   - capacity map[any]int: make of a channel -> capacity, -1 if not constant
+//   - names map[any]string: key -> readable name
+//   - funcs map[*types.Func]*ast.FuncDecl: declarations of the functions of the program
+//   - funcPkg map[*ast.FuncDecl]*pkgInfo: package of the declarations
+//   - directLits map[*ast.FuncLit]bool: function literals that are called directly, e.g. with go func(){}()
+//   - pkgPaths map[string]bool: the import paths of the packages of the program
+//   - chanOps []*chanOp: all channel operations
+//   - selects []*selectOp: all select statements
+//   - wgOps []*wgOp: all wait group operations
+//   - findings []Finding: the found possibly blocking operations
+type analyzer struct {
+	prog       *program
+	uf         *unionFind
+	escaped    map[any]bool
+	origin     map[any]bool
+	param      map[any]bool
+	capacity   map[any]int
+	names      map[any]string
+	funcs      map[*types.Func]*ast.FuncDecl
+	funcPkg    map[*ast.FuncDecl]*pkgInfo
+	directLits map[*ast.FuncLit]bool
+	pkgPaths   map[string]bool
+	chanOps    []*chanOp
+	selects    []*selectOp
+	wgOps      []*wgOp
+	findings   []Finding
+}
+
+// newAnalyzer creates the analyzer for a program and collects the
+// declarations of all functions
+//
+// Parameter:
+//   - prog *program: the program
+//
+// Returns:
+//   - *analyzer: the analyzer
+func newAnalyzer(prog *program) *analyzer {
+	a := &analyzer{
+		prog:       prog,
+		uf:         &unionFind{parent: make(map[any]any)},
+		escaped:    make(map[any]bool),
+		origin:     make(map[any]bool),
+		param:      make(map[any]bool),
+		capacity:   make(map[any]int),
+		names:      make(map[any]string),
+		funcs:      make(map[*types.Func]*ast.FuncDecl),
+		funcPkg:    make(map[*ast.FuncDecl]*pkgInfo),
+		directLits: make(map[*ast.FuncLit]bool),
+		pkgPaths:   make(map[string]bool),
+	}
+
+	for _, pkg := range prog.pkgs {
+		a.pkgPaths[pkg.path] = true
+		for _, file := range pkg.files {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				if fn, ok := pkg.info.Defs[fd.Name].(*types.Func); ok {
+					a.funcs[fn] = fd
+					a.funcPkg[fd] = pkg
+				}
+			}
+		}
+	}
+
+	return a
+}
+
+// inspect walks all files of the program. f is called for each node
+// together with the stack of its ancestors. If f returns false, the
+// children of the node are skipped.
+//
+// Parameter:
+//   - f func(pkg *pkgInfo, n ast.Node, stack []ast.Node) bool: the function called for each node
+func (this *analyzer) inspect(f func(pkg *pkgInfo, n ast.Node, stack []ast.Node) bool) {
+	for _, pkg := range this.prog.pkgs {
+		for _, file := range pkg.files {
+			stack := make([]ast.Node, 0, 32)
+			ast.Inspect(file, func(n ast.Node) bool {
+				if n == nil {
+					stack = stack[:len(stack)-1]
+					return false
+				}
+
+				if !f(pkg, n, stack) {
+					return false
+				}
+
+				stack = append(stack, n)
+				return true
+			})
+		}
+	}
+}
+
+// kindOf returns the kind of primitive a type refers to. Pointers,
+// slices, arrays and maps of a primitive refer to the same kind, so that
+// the containers are in the same class as their elements.
+//
+// Parameter:
+//   - t types.Type: the type
+//
+// Returns:
+//   - primKind: the kind
+func kindOf(t types.Type) primKind {
+	for i := 0; i < 8 && t != nil; i++ {
+		if named, ok := types.Unalias(t).(*types.Named); ok {
+			obj := named.Obj()
+			if obj.Pkg() != nil && obj.Pkg().Path() == "sync" {
+				switch obj.Name() {
+				case "Mutex", "RWMutex":
+					return primMutex
+				case "WaitGroup":
+					return primWaitGroup
+				}
+			}
+		}
+
+		switch u := t.Underlying().(type) {
+		case *types.Chan:
+			return primChan
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		default:
+			return primNone
+		}
+	}
+	return primNone
+}
+
+// isChanType returns whether a type is a channel type
+//
+// Parameter:
+//   - t types.Type: the type
+//
+// Returns:
+//   - bool: true for channels
+func isChanType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Chan)
+	return ok
+}
+
+// kindOfExpr returns the kind of primitive of an expression
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - e ast.Expr: the expression
+//
+// Returns:
+//   - primKind: the kind, primNone if the type is not known
+func kindOfExpr(pkg *pkgInfo, e ast.Expr) primKind {
+	return kindOf(pkg.info.TypeOf(e))
+}
+
+// objOf returns the variable or field an expression refers to
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - e ast.Expr: the expression
+//
+// Returns:
+//   - any: the variable or field, nil if the expression does not refer to
+//     one or to a variable or field of a package that is not analyzed
+func (this *analyzer) objOf(pkg *pkgInfo, e ast.Expr) any {
+	v := varOf(pkg, e)
+	if v == nil {
+		return nil
+	}
+	if v.Pkg() != nil && !this.isProgramPkg(v.Pkg().Path()) {
+		return nil
+	}
+	this.nameVar(v)
+	return v
+}
+
+// varOf returns the variable or field an expression refers to
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - e ast.Expr: the expression
+//
+// Returns:
+//   - *types.Var: the variable or field, nil if the expression does not refer to one
+func varOf(pkg *pkgInfo, e ast.Expr) *types.Var {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return varOf(pkg, e.X)
+	case *ast.StarExpr:
+		return varOf(pkg, e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return varOf(pkg, e.X)
+		}
+	case *ast.IndexExpr:
+		return varOf(pkg, e.X)
+	case *ast.Ident:
+		if e.Name == "_" {
+			return nil
+		}
+		obj := pkg.info.Uses[e]
+		if obj == nil {
+			obj = pkg.info.Defs[e]
+		}
+		v, _ := obj.(*types.Var)
+		return v
+	case *ast.SelectorExpr:
+		if sel := pkg.info.Selections[e]; sel != nil {
+			if v, ok := sel.Obj().(*types.Var); ok && sel.Kind() == types.FieldVal {
+				return v
+			}
+			return nil
+		}
+		v, _ := pkg.info.Uses[e.Sel].(*types.Var)
+		return v
+	}
+	return nil
+}
+
+// nameVar stores a readable name of a variable or field
+//
+// Parameter:
+//   - v *types.Var: the variable
+func (this *analyzer) nameVar(v *types.Var) {
+	if _, ok := this.names[v]; ok {
+		return
+	}
+	pos := this.prog.fset.Position(v.Pos())
+	this.names[v] = fmt.Sprintf("%s (%s)", v.Name(), posKey(pos.Filename, pos.Line))
+}
+
+// srcKey returns the key of the object an expression evaluates to
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - e ast.Expr: the expression
+//
+// Returns:
+//   - any: the key, nil if the expression is nil or unknown
+//   - bool: false if the object is created or returned by code that is not analyzed
+func (this *analyzer) srcKey(pkg *pkgInfo, e ast.Expr) (any, bool) {
+	e = ast.Unparen(e)
+
+	switch e := e.(type) {
+	case *ast.Ident:
+		if e.Name == "nil" {
+			return nil, true
+		}
+	case *ast.CallExpr:
+		switch builtinName(pkg, e) {
+		case "make":
+			this.origin[e] = true
+			if isChanType(pkg.info.TypeOf(e)) {
+				this.capacity[e] = 0
+				if len(e.Args) > 1 {
+					this.capacity[e] = -1
+					if tv, ok := pkg.info.Types[e.Args[1]]; ok && tv.Value != nil {
+						var c int
+						if _, err := fmt.Sscan(tv.Value.ExactString(), &c); err == nil {
+							this.capacity[e] = c
+						}
+					}
+				}
+				pos := this.prog.fset.Position(e.Pos())
+				this.names[e] = fmt.Sprintf("channel created at %s", posKey(pos.Filename, pos.Line))
+			}
+			return e, true
+		case "new":
+			this.origin[e] = true
+			return e, true
+		case "append":
+			if len(e.Args) > 0 {
+				return this.srcKey(pkg, e.Args[0])
+			}
+			return nil, true
+		}
+
+		if fn := calleeFunc(pkg, e); fn != nil && this.funcs[fn] != nil {
+			return resultKey{fn, 0}, true
+		}
+		return nil, false
+	case *ast.CompositeLit:
+		this.origin[e] = true
+		return e, true
+	case *ast.UnaryExpr:
+		if lit, ok := ast.Unparen(e.X).(*ast.CompositeLit); ok && e.Op == token.AND {
+			return this.srcKey(pkg, lit)
+		}
+	}
+
+	if key := this.objOf(pkg, e); key != nil {
+		return key, true
+	}
+	return nil, false
+}
+
+// flowTo records, that the object of the expression src is stored in the
+// variable, field or result dst
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - dst any: the key of the destination, nil if unknown
+//   - dstType types.Type: type of the destination
+//   - src ast.Expr: the stored expression
+func (this *analyzer) flowTo(pkg *pkgInfo, dst any, dstType types.Type, src ast.Expr) {
+	srcKind := kindOfExpr(pkg, src)
+	dstKind := kindOf(dstType)
+	if srcKind == primNone && dstKind == primNone {
+		return
+	}
+
+	key, known := this.srcKey(pkg, src)
+
+	// stored in an interface, an unknown destination or a value that is not a primitive
+	if dst == nil || dstKind == primNone {
+		if key != nil {
+			this.escaped[key] = true
+		}
+		return
+	}
+
+	if !known {
+		this.escaped[dst] = true
+		return
+	}
+
+	if key != nil {
+		this.uf.union(dst, key)
+	}
+}
+
+// flow records the assignment of src to the expression dst
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expressions
+//   - dst ast.Expr: the assigned expression
+//   - src ast.Expr: the value
+func (this *analyzer) flow(pkg *pkgInfo, dst ast.Expr, src ast.Expr) {
+	if id, ok := dst.(*ast.Ident); ok && id.Name == "_" {
+		return
+	}
+	this.flowTo(pkg, this.objOf(pkg, dst), pkg.info.TypeOf(dst), src)
+}
+
+// flowUnknown records, that a value that is not analyzed is stored in dst
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - dst ast.Expr: the assigned expression
+func (this *analyzer) flowUnknown(pkg *pkgInfo, dst ast.Expr) {
+	if kindOfExpr(pkg, dst) == primNone {
+		return
+	}
+	if key := this.objOf(pkg, dst); key != nil {
+		this.escaped[key] = true
+	}
+}
+
+// escapeExpr marks the object of an expression as escaped
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - e ast.Expr: the expression
+func (this *analyzer) escapeExpr(pkg *pkgInfo, e ast.Expr) {
+	if kindOfExpr(pkg, e) != primNone {
+		if key, _ := this.srcKey(pkg, e); key != nil {
+			this.escaped[key] = true
+		}
+		return
+	}
+
+	// primitives in the fields of a struct given to code that is not analyzed
+	t := pkg.info.TypeOf(e)
+	if t == nil {
+		return
+	}
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		for i := 0; i < st.NumFields(); i++ {
+			if kindOf(st.Field(i).Type()) != primNone {
+				this.escaped[st.Field(i)] = true
+			}
+		}
+	}
+}
+
+// builtinName returns the name of the called builtin function
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+//
+// Returns:
+//   - string: the name of the builtin, empty if no builtin is called
+func builtinName(pkg *pkgInfo, call *ast.CallExpr) string {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if b, ok := pkg.info.Uses[id].(*types.Builtin); ok {
+		return b.Name()
+	}
+	return ""
+}
+
+// calleeFunc returns the function or method called in a call
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+//
+// Returns:
+//   - *types.Func: the called function, nil if unknown
+func calleeFunc(pkg *pkgInfo, call *ast.CallExpr) *types.Func {
+	fun := ast.Unparen(call.Fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	var fn *types.Func
+	switch f := fun.(type) {
+	case *ast.Ident:
+		fn, _ = pkg.info.Uses[f].(*types.Func)
+	case *ast.SelectorExpr:
+		if sel := pkg.info.Selections[f]; sel != nil {
+			fn, _ = sel.Obj().(*types.Func)
+		} else {
+			fn, _ = pkg.info.Uses[f.Sel].(*types.Func)
+		}
+	}
+
+	if fn == nil {
+		return nil
+	}
+	return fn.Origin()
+}
+
+// syncMethod returns the primitive key and name of a called method of
+// sync.Mutex, sync.RWMutex or sync.WaitGroup. For mutexes embedded in a
+// struct, the key is the embedded field.
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+//
+// Returns:
+//   - primKind: the kind of the primitive, primNone if no such method is called
+//   - any: the key of the primitive, nil if unknown
+//   - string: the name of the method
+func (this *analyzer) syncMethod(pkg *pkgInfo, call *ast.CallExpr) (primKind, any, string) {
+	se, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return primNone, nil, ""
+	}
+
+	sel := pkg.info.Selections[se]
+	if sel == nil || sel.Kind() != types.MethodVal {
+		return primNone, nil, ""
+	}
+
+	fn, ok := sel.Obj().(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "sync" {
+		return primNone, nil, ""
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return primNone, nil, ""
+	}
+	kind := kindOf(recv.Type())
+	if kind != primMutex && kind != primWaitGroup {
+		return primNone, nil, ""
+	}
+
+	index := sel.Index()
+	if len(index) == 1 {
+		return kind, this.objOf(pkg, se.X), fn.Name()
+	}
+
+	// method of an embedded primitive, use the embedded field as key
+	t := sel.Recv()
+	var field *types.Var
+	for _, i := range index[:len(index)-1] {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return kind, nil, fn.Name()
+		}
+		field = st.Field(i)
+		t = field.Type()
+	}
+
+	if field == nil {
+		return kind, nil, fn.Name()
+	}
+	this.nameVar(field)
+	return kind, field, fn.Name()
+}
+
+// collectFlows passes over the program and merges the classes of all
+// expressions that can refer to the same channel, mutex or wait group.
+// Objects that are given to code that is not analyzed, e.g. functions of
+// other modules, function values or interfaces, are marked as escaped.
+func (this *analyzer) collectFlows() {
+	// function literals that are called directly
+	this.inspect(func(pkg *pkgInfo, n ast.Node, stack []ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if lit, ok := ast.Unparen(call.Fun).(*ast.FuncLit); ok {
+				this.directLits[lit] = true
+			}
+		}
+		return true
+	})
+
+	this.inspect(func(pkg *pkgInfo, n ast.Node, stack []ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			this.declFlows(pkg, n)
+		case *ast.FuncLit:
+			this.paramFlows(pkg, n.Type.Params, !this.directLits[n])
+		case *ast.AssignStmt:
+			this.assignFlows(pkg, n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			this.assignFlows(pkg, lhs, n.Values)
+			for _, name := range n.Names {
+				if v, ok := pkg.info.Defs[name].(*types.Var); ok && name.IsExported() && kindOf(v.Type()) != primNone {
+					this.escaped[v] = true
+				}
+			}
+		case *ast.RangeStmt:
+			this.rangeFlows(pkg, n)
+		case *ast.ReturnStmt:
+			this.returnFlows(pkg, n, stack)
+		case *ast.CallExpr:
+			this.callFlows(pkg, n)
+		case *ast.CompositeLit:
+			this.literalFlows(pkg, n)
+		case *ast.SendStmt:
+			this.flowTo(pkg, nil, nil, n.Value)
+		case *ast.SelectorExpr:
+			this.methodValueFlows(pkg, n, stack)
+		case *ast.Field:
+			// exported fields of structs can be used by other packages
+			for _, name := range n.Names {
+				if v, ok := pkg.info.Defs[name].(*types.Var); ok && v.IsField() && name.IsExported() && kindOf(v.Type()) != primNone {
+					this.nameVar(v)
+					this.escaped[v] = true
+				}
+			}
+		}
+		return true
+	})
+}
+
+// declFlows handles the parameters and results of a function declaration.
+// Exported functions can be called by other packages, their parameters and
+// results are therefore escaped.
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the function
+//   - fd *ast.FuncDecl: the function
+func (this *analyzer) declFlows(pkg *pkgInfo, fd *ast.FuncDecl) {
+	fn, ok := pkg.info.Defs[fd.Name].(*types.Func)
+	if !ok {
+		return
+	}
+
+	exported := fd.Name.IsExported()
+	this.paramFlows(pkg, fd.Type.Params, exported)
+
+	// methods can be called with interfaces, e.g. for named channel types
+	this.paramFlows(pkg, fd.Recv, true)
+
+	sig := fn.Type().(*types.Signature)
+	for i := 0; i < sig.Results().Len(); i++ {
+		if kindOf(sig.Results().At(i).Type()) == primNone {
+			continue
+		}
+		key := resultKey{fn, i}
+		this.param[key] = true
+		if exported {
+			this.escaped[key] = true
+		}
+
+		// named results
+		this.uf.union(key, sig.Results().At(i))
+		this.param[sig.Results().At(i)] = true
+	}
+}
+
+// paramFlows marks the parameters of a function as parameters and, if the
+// callers of the function are not known, as escaped
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the function
+//   - params *ast.FieldList: the parameters or the receiver of the function
+//   - escaped bool: true if the parameters can be set by code that is not analyzed
+func (this *analyzer) paramFlows(pkg *pkgInfo, params *ast.FieldList, escaped bool) {
+	if params == nil {
+		return
+	}
+	for _, field := range params.List {
+		for _, name := range field.Names {
+			v, ok := pkg.info.Defs[name].(*types.Var)
+			if !ok || kindOf(v.Type()) == primNone {
+				continue
+			}
+			this.nameVar(v)
+			this.param[v] = true
+			if escaped {
+				this.escaped[v] = true
+			}
+		}
+	}
+}
+
+// methodValueFlows marks the receiver of a method value, e.g. wg.Done given
+// as a function to another function, as escaped, because the calls of the
+// method value are not analyzed
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the expression
+//   - se *ast.SelectorExpr: the selector expression
+//   - stack []ast.Node: the ancestors of the expression
+func (this *analyzer) methodValueFlows(pkg *pkgInfo, se *ast.SelectorExpr, stack []ast.Node) {
+	sel := pkg.info.Selections[se]
+	if sel == nil || sel.Kind() != types.MethodVal {
+		return
+	}
+
+	if len(stack) > 0 {
+		if call, ok := stack[len(stack)-1].(*ast.CallExpr); ok && ast.Unparen(call.Fun) == ast.Expr(se) {
+			return
+		}
+	}
+
+	this.escapeExpr(pkg, se.X)
+}
+
+// assignFlows handles assignments and variable declarations
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the assignment
+//   - lhs []ast.Expr: the assigned expressions
+//   - rhs []ast.Expr: the values
+func (this *analyzer) assignFlows(pkg *pkgInfo, lhs, rhs []ast.Expr) {
+	if len(lhs) == len(rhs) {
+		for i := range lhs {
+			this.flow(pkg, lhs[i], rhs[i])
+		}
+		return
+	}
+
+	if len(rhs) != 1 {
+		return
+	}
+
+	// a, b := f(), v, ok := <-c, v, ok := m[k], v, ok := x.(T)
+	call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+	if ok {
+		if fn := calleeFunc(pkg, call); fn != nil && this.funcs[fn] != nil {
+			for i, l := range lhs {
+				if id, ok := l.(*ast.Ident); ok && id.Name == "_" {
+					continue
+				}
+				if kindOfExpr(pkg, l) != primNone {
+					if key := this.objOf(pkg, l); key != nil {
+						this.uf.union(key, resultKey{fn, i})
+					}
+				}
+			}
+			return
+		}
+	}
+
+	if idx, ok := ast.Unparen(rhs[0]).(*ast.IndexExpr); ok {
+		this.flow(pkg, lhs[0], idx)
+		return
+	}
+
+	for _, l := range lhs {
+		this.flowUnknown(pkg, l)
+	}
+}
+
+// rangeFlows handles the variables of range statements over containers
+// of primitives
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the statement
+//   - rs *ast.RangeStmt: the statement
+func (this *analyzer) rangeFlows(pkg *pkgInfo, rs *ast.RangeStmt) {
+	if isChanType(pkg.info.TypeOf(rs.X)) {
+		// values received from a channel are not analyzed
+		if rs.Key != nil {
+			this.flowUnknown(pkg, rs.Key)
+		}
+		return
+	}
+
+	if rs.Value != nil {
+		this.flow(pkg, rs.Value, rs.X)
+	}
+}
+
+// returnFlows handles the results of a return statement
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the statement
+//   - rs *ast.ReturnStmt: the statement
+//   - stack []ast.Node: the ancestors of the statement
+func (this *analyzer) returnFlows(pkg *pkgInfo, rs *ast.ReturnStmt, stack []ast.Node) {
+	var fn *types.Func
+	for i := len(stack) - 1; i >= 0; i-- {
+		if _, ok := stack[i].(*ast.FuncLit); ok {
+			// results of function literals are not analyzed
+			for _, res := range rs.Results {
+				this.escapeExpr(pkg, res)
+			}
+			return
+		}
+		if fd, ok := stack[i].(*ast.FuncDecl); ok {
+			fn, _ = pkg.info.Defs[fd.Name].(*types.Func)
+			break
+		}
+	}
+
+	if fn == nil {
+		return
+	}
+
+	sig := fn.Type().(*types.Signature)
+	if len(rs.Results) != sig.Results().Len() {
+		return
+	}
+
+	for i, res := range rs.Results {
+		this.flowTo(pkg, resultKey{fn, i}, sig.Results().At(i).Type(), res)
+	}
+}
+
+// callFlows binds the arguments of a call to the parameters of the called
+// function. Arguments of functions that are not analyzed are escaped.
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+func (this *analyzer) callFlows(pkg *pkgInfo, call *ast.CallExpr) {
+	switch builtinName(pkg, call) {
+	case "append":
+		if len(call.Args) > 1 {
+			dst := this.objOf(pkg, call.Args[0])
+			for _, arg := range call.Args[1:] {
+				this.flowTo(pkg, dst, pkg.info.TypeOf(call.Args[0]), arg)
+			}
+		}
+		return
+	case "":
+	default:
+		return
+	}
+
+	if lit, ok := ast.Unparen(call.Fun).(*ast.FuncLit); ok {
+		this.bindParams(pkg, call, pkg.info.TypeOf(lit))
+		return
+	}
+
+	if kind, _, _ := this.syncMethod(pkg, call); kind != primNone {
+		return
+	}
+
+	fn := calleeFunc(pkg, call)
+	if fn == nil || this.funcs[fn] == nil {
+		// functions of the standard library do not use the fields of the program
+		std := fn != nil && fn.Pkg() != nil && !this.isProgramPkg(fn.Pkg().Path())
+		for _, arg := range call.Args {
+			if std && kindOfExpr(pkg, arg) == primNone {
+				continue
+			}
+			this.escapeExpr(pkg, arg)
+		}
+		return
+	}
+
+	this.bindParams(pkg, call, fn.Type())
+}
+
+// bindParams binds the arguments of a call to the parameters of the called function
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+//   - t types.Type: the type of the called function
+func (this *analyzer) bindParams(pkg *pkgInfo, call *ast.CallExpr, t types.Type) {
+	sig, ok := t.(*types.Signature)
+	if !ok {
+		for _, arg := range call.Args {
+			this.escapeExpr(pkg, arg)
+		}
+		return
+	}
+
+	params := sig.Params()
+	for i, arg := range call.Args {
+		if params.Len() == 0 {
+			break
+		}
+
+		p := params.At(min(i, params.Len()-1))
+		pt := p.Type()
+		if sig.Variadic() && i >= params.Len()-1 && !call.Ellipsis.IsValid() {
+			pt = pt.(*types.Slice).Elem()
+		}
+		this.flowTo(pkg, p, pt, arg)
+	}
+}
+
+// literalFlows handles the elements of composite literals
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the literal
+//   - lit *ast.CompositeLit: the literal
+func (this *analyzer) literalFlows(pkg *pkgInfo, lit *ast.CompositeLit) {
+	t := pkg.info.TypeOf(lit)
+	if t == nil {
+		return
+	}
+
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		for i, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if id, ok := kv.Key.(*ast.Ident); ok {
+					if v, ok := pkg.info.Uses[id].(*types.Var); ok {
+						this.nameVar(v)
+						this.flowTo(pkg, v, v.Type(), kv.Value)
+					}
+				}
+				continue
+			}
+			if i < st.NumFields() {
+				this.nameVar(st.Field(i))
+				this.flowTo(pkg, st.Field(i), st.Field(i).Type(), elt)
+			}
+		}
+		return
+	}
+
+	if kindOf(t) == primNone {
+		return
+	}
+
+	// slices, arrays and maps of primitives
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		this.flowTo(pkg, lit, t, elt)
+	}
+}
+
+// isProgramPkg returns whether an import path belongs to the program
+//
+// Parameter:
+//   - path string: the import path
+//
+// Returns:
+//   - bool: true if the package is part of the analyzed program
+func (this *analyzer) isProgramPkg(path string) bool {
+	return this.pkgPaths[path]
+}
+
+// classOf returns the representative of the class of a key
+//
+// Parameter:
+//   - key any: the key
+//
+// Returns:
+//   - any: the representative
+func (this *analyzer) classOf(key any) any {
+	return this.uf.find(key)
+}
+
+// classFacts returns whether a class is escaped, contains a parameter or
+// result and contains an expression creating a new object
+//
+// Returns:
+//   - map[any]bool: escaped classes
+//   - map[any]bool: classes containing parameters or results
+//   - map[any]bool: classes containing the creation of an object
+//   - map[any][]any: representative -> all keys of the class
+func (this *analyzer) classFacts() (map[any]bool, map[any]bool, map[any]bool, map[any][]any) {
+	escaped := make(map[any]bool)
+	param := make(map[any]bool)
+	origin := make(map[any]bool)
+	members := make(map[any][]any)
+
+	seen := make(map[any]bool)
+	add := func(key any) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		root := this.uf.find(key)
+		members[root] = append(members[root], key)
+		if !seen[root] {
+			seen[root] = true
+			members[root] = append(members[root], root)
+		}
+	}
+
+	for key := range this.uf.parent {
+		add(key)
+	}
+	for key := range this.escaped {
+		add(key)
+		escaped[this.uf.find(key)] = true
+	}
+	for key := range this.param {
+		add(key)
+		param[this.uf.find(key)] = true
+	}
+	for key := range this.origin {
+		add(key)
+		origin[this.uf.find(key)] = true
+	}
+
+	return escaped, param, origin, members
+}
+
+// className returns a readable name of a class
+//
+// Parameter:
+//   - members []any: the keys of the class
+//   - root any: the representative of the class
+//
+// Returns:
+//   - string: the name
+func (this *analyzer) className(members []any, root any) string {
+	// prefer variables and fields, then the creation of the object
+	best := ""
+	for _, m := range members {
+		if _, ok := m.(*types.Var); !ok {
+			continue
+		}
+		if name, ok := this.names[m]; ok && (best == "" || name < best) {
+			best = name
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	for _, m := range members {
+		if name, ok := this.names[m]; ok && (best == "" || name < best) {
+			best = name
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	if name, ok := this.names[root]; ok {
+		return name
+	}
+	return "unknown"
+}
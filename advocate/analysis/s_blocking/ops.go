@@ -0,0 +1,325 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: ops.go
+// Brief: Collect the channel and wait group operations of a program
+//    together with the control flow context they are executed in
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+)
+
+// kinds of channel operations
+const (
+	opSend = iota
+	opRecv
+	opClose
+	opRange
+)
+
+// opCtx is the control flow context of an operation
+//
+// Fields:
+//   - decl *ast.FuncDecl: the top level function containing the operation
+//   - fn ast.Node: the function (*ast.FuncDecl or *ast.FuncLit) executing the operation
+//   - inLoop bool: the operation is in a loop of fn
+//   - conditional bool: the operation is in a branch of fn
+//   - deferred bool: the operation is deferred
+//   - inGo bool: fn is a function literal started with go
+//   - goInLoop bool: the go statement starting fn is in a loop
+type opCtx struct {
+	decl        *ast.FuncDecl
+	fn          ast.Node
+	inLoop      bool
+	conditional bool
+	deferred    bool
+	inGo        bool
+	goInLoop    bool
+}
+
+// chanOp is a channel operation
+//
+// Fields:
+//   - kind int: opSend, opRecv, opClose or opRange
+//   - key any: the key of the channel
+//   - pos token.Pos: position of the operation
+//   - sel *selectOp: the select containing the operation as case, nil if not in a select
+//   - ctx opCtx: the context of the operation
+type chanOp struct {
+	kind int
+	key  any
+	pos  token.Pos
+	sel  *selectOp
+	ctx  opCtx
+}
+
+// selectOp is a select statement
+//
+// Fields:
+//   - pos token.Pos: position of the select
+//   - hasDefault bool: the select has a default case
+//   - cases []*chanOp: the channel operations of the cases
+type selectOp struct {
+	pos        token.Pos
+	hasDefault bool
+	cases      []*chanOp
+}
+
+// wgOp is a call of a method of a wait group
+//
+// Fields:
+//   - method string: Add, Done, Wait or Go
+//   - key any: the key of the wait group
+//   - pos token.Pos: position of the call
+//   - delta int: the value added to the counter, if known
+//   - constDelta bool: delta is a constant
+//   - ctx opCtx: the context of the call
+type wgOp struct {
+	method     string
+	key        any
+	pos        token.Pos
+	delta      int
+	constDelta bool
+	ctx        opCtx
+}
+
+// contextOf returns the control flow context of a node
+//
+// Parameter:
+//   - n ast.Node: the node
+//   - stack []ast.Node: the ancestors of the node
+//
+// Returns:
+//   - opCtx: the context
+func contextOf(n ast.Node, stack []ast.Node) opCtx {
+	ctx := opCtx{}
+
+	// 0: in the function executing the node, 1: in the function containing
+	// the go statement that started it, 2: outside of both
+	level := 0
+
+	child := n
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch s := stack[i].(type) {
+		case *ast.ForStmt:
+			if child == s.Body {
+				ctx.inLoop = ctx.inLoop || level == 0
+				ctx.goInLoop = ctx.goInLoop || level == 1
+			}
+		case *ast.RangeStmt:
+			if child == s.Body {
+				ctx.inLoop = ctx.inLoop || level == 0
+				ctx.goInLoop = ctx.goInLoop || level == 1
+			}
+		case *ast.IfStmt:
+			if level == 0 && (child == s.Body || child == s.Else) {
+				ctx.conditional = true
+			}
+		case *ast.CaseClause, *ast.CommClause:
+			if level == 0 {
+				ctx.conditional = true
+			}
+		case *ast.DeferStmt:
+			if level == 0 {
+				ctx.deferred = true
+			}
+		case *ast.FuncLit:
+			if level > 0 {
+				level = 2
+				break
+			}
+
+			if !isDirectCall(s, stack[:i]) {
+				ctx.fn = s
+				level = 2
+				break
+			}
+
+			// function literals that are called directly are executed by the
+			// enclosing function, except if they are started with go
+			if i >= 2 {
+				switch stack[i-2].(type) {
+				case *ast.GoStmt:
+					ctx.fn = s
+					ctx.inGo = true
+					level = 1
+				case *ast.DeferStmt:
+					ctx.deferred = true
+				}
+			}
+		case *ast.FuncDecl:
+			if ctx.fn == nil {
+				ctx.fn = s
+			}
+			ctx.decl = s
+			return ctx
+		}
+		child = stack[i]
+	}
+
+	return ctx
+}
+
+// isDirectCall returns whether a function literal is called directly by
+// the enclosing function, e.g. func(){ ... }()
+//
+// Parameter:
+//   - lit *ast.FuncLit: the function literal
+//   - stack []ast.Node: the ancestors of the function literal
+//
+// Returns:
+//   - bool: true if the literal is called directly
+func isDirectCall(lit *ast.FuncLit, stack []ast.Node) bool {
+	if len(stack) == 0 {
+		return false
+	}
+	call, ok := stack[len(stack)-1].(*ast.CallExpr)
+	return ok && ast.Unparen(call.Fun) == lit
+}
+
+// collectOps collects all channel and wait group operations
+func (this *analyzer) collectOps() {
+	selectComm := make(map[ast.Node]*selectOp)
+
+	this.inspect(func(pkg *pkgInfo, n ast.Node, stack []ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectStmt:
+			sel := &selectOp{pos: n.Pos()}
+			this.selects = append(this.selects, sel)
+			for _, stmt := range n.Body.List {
+				cc, ok := stmt.(*ast.CommClause)
+				if !ok {
+					continue
+				}
+				switch comm := cc.Comm.(type) {
+				case nil:
+					sel.hasDefault = true
+				case *ast.SendStmt:
+					selectComm[comm] = sel
+				case *ast.ExprStmt:
+					selectComm[ast.Unparen(comm.X)] = sel
+				case *ast.AssignStmt:
+					if len(comm.Rhs) == 1 {
+						selectComm[ast.Unparen(comm.Rhs[0])] = sel
+					}
+				}
+			}
+
+		case *ast.SendStmt:
+			this.addChanOp(pkg, opSend, n.Chan, n.Pos(), selectComm[n], n, stack)
+
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				this.addChanOp(pkg, opRecv, n.X, n.Pos(), selectComm[n], n, stack)
+			}
+
+		case *ast.RangeStmt:
+			if isChanType(pkg.info.TypeOf(n.X)) {
+				this.addChanOp(pkg, opRange, n.X, n.Pos(), nil, n, stack)
+			}
+
+		case *ast.CallExpr:
+			if builtinName(pkg, n) == "close" && len(n.Args) == 1 {
+				this.addChanOp(pkg, opClose, n.Args[0], n.Pos(), nil, n, stack)
+				return true
+			}
+
+			kind, key, method := this.syncMethod(pkg, n)
+			if kind != primWaitGroup || key == nil {
+				return true
+			}
+
+			op := &wgOp{method: method, key: key, pos: n.Pos(), ctx: contextOf(n, stack)}
+			switch method {
+			case "Add":
+				if len(n.Args) == 1 {
+					if tv, ok := pkg.info.Types[n.Args[0]]; ok && tv.Value != nil {
+						if v, exact := constant.Int64Val(tv.Value); exact {
+							op.delta, op.constDelta = int(v), true
+						}
+					}
+				}
+			case "Done":
+				op.delta, op.constDelta = -1, true
+			}
+			this.wgOps = append(this.wgOps, op)
+		}
+		return true
+	})
+}
+
+// addChanOp adds a channel operation
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the operation
+//   - kind int: the kind of the operation
+//   - ch ast.Expr: the channel expression
+//   - pos token.Pos: the position of the operation
+//   - sel *selectOp: the select, if the operation is a select case
+//   - n ast.Node: the node of the operation
+//   - stack []ast.Node: the ancestors of the node
+func (this *analyzer) addChanOp(pkg *pkgInfo, kind int, ch ast.Expr, pos token.Pos, sel *selectOp, n ast.Node, stack []ast.Node) {
+	if !isChanType(pkg.info.TypeOf(ch)) {
+		return
+	}
+
+	key, _ := this.srcKey(pkg, ch)
+	op := &chanOp{kind: kind, key: key, pos: pos, sel: sel, ctx: contextOf(n, stack)}
+
+	if sel != nil {
+		sel.cases = append(sel.cases, op)
+	}
+
+	// operations on channels returned by code that is not analyzed, e.g. time.After
+	if key == nil {
+		return
+	}
+
+	this.chanOps = append(this.chanOps, op)
+}
+
+// hasReturnBefore returns whether the function fn can return before the position pos
+//
+// Parameter:
+//   - fn ast.Node: the function
+//   - pos token.Pos: the position
+//
+// Returns:
+//   - bool: true if a return statement of fn is before pos
+func hasReturnBefore(fn ast.Node, pos token.Pos) bool {
+	var body *ast.BlockStmt
+	switch f := fn.(type) {
+	case *ast.FuncDecl:
+		body = f.Body
+	case *ast.FuncLit:
+		body = f.Body
+	}
+	if body == nil {
+		return false
+	}
+
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if found || n == nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if n.Pos() < pos {
+				found = true
+			}
+		}
+		return true
+	})
+
+	return found
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: rank.go
+// Brief: Rank the tests of a program by the findings reachable from them
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rankTests computes for each test the findings in the functions
+// reachable from the test. Calls that cannot be resolved with the type
+// information, e.g. interface methods or functions of packages the type
+// checker could not import, are resolved by the name of the called function.
+//
+// Parameter:
+//   - findings []Finding: the findings
+//
+// Returns:
+//   - []TestScore: the tests with at least one reachable finding, ordered by score
+func (this *analyzer) rankTests(findings []Finding) []TestScore {
+	decls := make([]*ast.FuncDecl, 0)
+	declPkg := make(map[*ast.FuncDecl]*pkgInfo)
+	declFile := make(map[*ast.FuncDecl]*ast.File)
+	byName := make(map[string][]*ast.FuncDecl)
+
+	for _, pkg := range this.prog.pkgs {
+		for _, file := range pkg.files {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				decls = append(decls, fd)
+				declPkg[fd] = pkg
+				declFile[fd] = file
+				byName[fd.Name.Name] = append(byName[fd.Name.Name], fd)
+			}
+		}
+	}
+
+	// call graph
+	callees := make(map[*ast.FuncDecl][]*ast.FuncDecl)
+	for _, fd := range decls {
+		pkg := declPkg[fd]
+		seen := make(map[*ast.FuncDecl]bool)
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			for _, callee := range this.resolveCall(pkg, call, byName) {
+				if !seen[callee] {
+					seen[callee] = true
+					callees[fd] = append(callees[fd], callee)
+				}
+			}
+			return true
+		})
+	}
+
+	// findings in each function
+	declFindings := make(map[*ast.FuncDecl][]int)
+	for i, f := range findings {
+		for _, fd := range decls {
+			start := this.prog.fset.Position(fd.Pos())
+			end := this.prog.fset.Position(fd.End())
+			for _, pos := range f.positions {
+				if pos.Filename == start.Filename && pos.Line >= start.Line && pos.Line <= end.Line {
+					declFindings[fd] = append(declFindings[fd], i)
+					break
+				}
+			}
+		}
+	}
+
+	res := make([]TestScore, 0)
+	for _, fd := range decls {
+		pkg := declPkg[fd]
+		if fd.Recv != nil || !pkg.isTest[declFile[fd]] || !isTestName(fd.Name.Name) {
+			continue
+		}
+
+		reached := make(map[int]bool)
+		visited := map[*ast.FuncDecl]bool{fd: true}
+		queue := []*ast.FuncDecl{fd}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, i := range declFindings[cur] {
+				reached[i] = true
+			}
+			for _, callee := range callees[cur] {
+				if !visited[callee] {
+					visited[callee] = true
+					queue = append(queue, callee)
+				}
+			}
+		}
+
+		if len(reached) == 0 {
+			continue
+		}
+
+		ts := TestScore{
+			File:     this.prog.fset.Position(fd.Pos()).Filename,
+			Name:     fd.Name.Name,
+			Findings: make([]int, 0, len(reached)),
+		}
+		for i := range reached {
+			ts.Findings = append(ts.Findings, i)
+			ts.Score += findings[i].Weight
+		}
+		sort.Ints(ts.Findings)
+		res = append(res, ts)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+
+	return res
+}
+
+// resolveCall returns the declarations a call can execute
+//
+// Parameter:
+//   - pkg *pkgInfo: the package of the call
+//   - call *ast.CallExpr: the call
+//   - byName map[string][]*ast.FuncDecl: function name -> declarations with this name
+//
+// Returns:
+//   - []*ast.FuncDecl: the possibly called declarations
+func (this *analyzer) resolveCall(pkg *pkgInfo, call *ast.CallExpr, byName map[string][]*ast.FuncDecl) []*ast.FuncDecl {
+	if fn := calleeFunc(pkg, call); fn != nil {
+		if fd, ok := this.funcs[fn]; ok {
+			return []*ast.FuncDecl{fd}
+		}
+
+		// methods of interfaces can be implemented by each method with the
+		// same name whose receiver implements the interface
+		sig, ok := fn.Type().(*types.Signature)
+		if !ok || sig.Recv() == nil {
+			return nil
+		}
+		iface, ok := sig.Recv().Type().Underlying().(*types.Interface)
+		if !ok {
+			return nil
+		}
+
+		res := make([]*ast.FuncDecl, 0)
+		for _, fd := range byName[fn.Name()] {
+			method, ok := this.funcPkg[fd].info.Defs[fd.Name].(*types.Func)
+			if !ok || fd.Recv == nil {
+				continue
+			}
+			recv := method.Type().(*types.Signature).Recv().Type()
+			if types.Implements(recv, iface) {
+				res = append(res, fd)
+			}
+		}
+		return res
+	}
+
+	// the type of the called function is unknown, e.g. because the package
+	// of the program could not be imported by the type checker
+	fun := ast.Unparen(call.Fun)
+	if idx, ok := fun.(*ast.IndexExpr); ok {
+		fun = idx.X
+	}
+
+	// functions of other packages are called with the package name,
+	// methods with a value
+	name, method := "", false
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if pkg.info.Uses[f] != nil {
+			return nil
+		}
+		name = f.Name
+	case *ast.SelectorExpr:
+		if pkg.info.Uses[f.Sel] != nil {
+			return nil
+		}
+		name = f.Sel.Name
+		if x, ok := f.X.(*ast.Ident); ok {
+			_, isPkg := pkg.info.Uses[x].(*types.PkgName)
+			method = !isPkg
+		} else {
+			method = true
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	res := make([]*ast.FuncDecl, 0)
+	for _, fd := range byName[name] {
+		if (fd.Recv != nil) == method {
+			res = append(res, fd)
+		}
+	}
+	return res
+}
+
+// isTestName returns whether a function name is the name of a test,
+// benchmark, fuzz target or example
+//
+// Parameter:
+//   - name string: the name of the function
+//
+// Returns:
+//   - bool: true if the function can be run by go test
+func isTestName(name string) bool {
+	if name == "TestMain" {
+		return false
+	}
+
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if len(name) == len(prefix) {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+		return !unicode.IsLower(r)
+	}
+
+	return false
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: static.go
+// Brief: Static blocking analysis of a program, used to prioritize the
+//    recording of tests and the mutations of the fuzzing
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"advocate/utils/log"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of findings of the static blocking analysis
+const (
+	SendWithoutRecv   = "sendWithoutReceiver"
+	SendOnFullBuffer  = "sendOnFullBuffer"
+	RecvWithoutSend   = "recvWithoutSender"
+	RangeWithoutClose = "rangeWithoutClose"
+	NilChannel        = "nilChannel"
+	SelectNoPartner   = "selectWithoutPartner"
+	LockOrder         = "inconsistentLockOrder"
+	DoubleLock        = "doubleLock"
+	AddInGoroutine    = "addInGoroutine"
+	SkippableDone     = "skippableDone"
+	WaitWithoutDone   = "waitWithoutDone"
+	DoneWithoutAdd    = "doneWithoutAdd"
+	AddDoneMismatch   = "addDoneMismatch"
+)
+
+// weights of the findings, findings that block in every execution that
+// reaches them have a higher weight than findings that depend on the schedule
+const (
+	weightCertain  = 3.0
+	weightPossible = 2.0
+)
+
+// Finding is one possibly blocking operation or pattern found by the
+// static analysis
+//
+// Fields:
+//   - Kind string: kind of the finding
+//   - Object string: the channel, mutex or wait group of the finding
+//   - Message string: readable description
+//   - Weight float64: weight of the finding, used for the prioritization
+//   - Pos []string: the code positions of the involved operations as file:line
+//   - positions []token.Position: the positions of the involved operations
+type Finding struct {
+	Kind      string           `json:"kind"`
+	Object    string           `json:"object"`
+	Message   string           `json:"message"`
+	Weight    float64          `json:"weight"`
+	Pos       []string         `json:"pos"`
+	positions []token.Position `json:"-"`
+}
+
+// TestScore is the priority of a test, based on the findings in the
+// functions reachable from the test
+//
+// Fields:
+//   - File string: the file containing the test
+//   - Name string: the name of the test
+//   - Score float64: sum of the weights of the reachable findings
+//   - Findings []int: indices of the reachable findings
+type TestScore struct {
+	File     string  `json:"file"`
+	Name     string  `json:"name"`
+	Score    float64 `json:"score"`
+	Findings []int   `json:"findings"`
+}
+
+// Result is the result of the static blocking analysis
+//
+// Fields:
+//   - Path string: the analyzed program
+//   - Findings []Finding: the findings, ordered by weight
+//   - Tests []TestScore: all tests with at least one reachable finding, ordered by score
+//   - weights map[string]float64: file:line -> highest weight of a finding at this position
+//   - testScores map[string]float64: file:test -> score of the test
+//   - fileScores map[string]float64: file -> highest score of a test in the file
+type Result struct {
+	Path     string      `json:"path"`
+	Findings []Finding   `json:"findings"`
+	Tests    []TestScore `json:"tests"`
+
+	weights    map[string]float64
+	testScores map[string]float64
+	fileScores map[string]float64
+}
+
+// result of the last run, used to prioritize the tests and mutations
+var current *Result
+
+// Analyze runs the static blocking analysis on a program
+//
+// Parameter:
+//   - progPath string: path to the program folder
+//
+// Returns:
+//   - *Result: the result of the analysis
+//   - error
+func Analyze(progPath string) (*Result, error) {
+	prog, err := loadProgram(progPath)
+	if err != nil {
+		return nil, err
+	}
+
+	a := newAnalyzer(prog)
+	a.collectFlows()
+	a.collectOps()
+	a.checkChannels()
+	a.checkWaitGroups()
+	a.checkLocks()
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		if a.findings[i].Weight != a.findings[j].Weight {
+			return a.findings[i].Weight > a.findings[j].Weight
+		}
+		return a.findings[i].Pos[0] < a.findings[j].Pos[0]
+	})
+
+	res := &Result{
+		Path:       progPath,
+		Findings:   a.findings,
+		weights:    make(map[string]float64),
+		testScores: make(map[string]float64),
+		fileScores: make(map[string]float64),
+	}
+
+	for _, f := range res.Findings {
+		for _, pos := range f.positions {
+			key := posKey(pos.Filename, pos.Line)
+			res.weights[key] = max(res.weights[key], f.Weight)
+		}
+	}
+
+	res.Tests = a.rankTests(res.Findings)
+	for _, t := range res.Tests {
+		res.testScores[t.File+":"+t.Name] = t.Score
+		res.fileScores[t.File] = max(res.fileScores[t.File], t.Score)
+	}
+
+	return res, nil
+}
+
+// Run runs the static blocking analysis and stores the result, so that it
+// is used to order the tests and to seed the mutations of the fuzzing
+//
+// Parameter:
+//   - progPath string: path to the program folder
+//
+// Returns:
+//   - error
+func Run(progPath string) error {
+	res, err := Analyze(progPath)
+	if err != nil {
+		return err
+	}
+
+	current = res
+	log.Infof("Static blocking analysis: %d findings in %d tests", len(res.Findings), len(res.Tests))
+
+	return nil
+}
+
+// HasResult returns whether a result of the static analysis is stored
+//
+// Returns:
+//   - bool: true if Run has been called successfully
+func HasResult() bool {
+	return current != nil
+}
+
+// Weight returns the highest weight of a finding at a code position
+//
+// Parameter:
+//   - file string: the file
+//   - line int: the line
+//
+// Returns:
+//   - float64: the weight, 0 if no finding is at the position or no result is stored
+func Weight(file string, line int) float64 {
+	if current == nil {
+		return 0
+	}
+	return current.weights[posKey(file, line)]
+}
+
+// SortTestFiles orders test files by the highest score of their tests.
+// Files with the same score keep their order.
+//
+// Parameter:
+//   - files []string: the test files
+//
+// Returns:
+//   - []string: the ordered test files
+func SortTestFiles(files []string) []string {
+	if current == nil {
+		return files
+	}
+
+	res := append([]string{}, files...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return current.fileScores[absPath(res[i])] > current.fileScores[absPath(res[j])]
+	})
+	return res
+}
+
+// SortTests orders the tests in a file by their score.
+// Tests with the same score keep their order.
+//
+// Parameter:
+//   - file string: the file containing the tests
+//   - tests []string: the names of the tests
+//
+// Returns:
+//   - []string: the ordered tests
+func SortTests(file string, tests []string) []string {
+	if current == nil {
+		return tests
+	}
+
+	file = absPath(file)
+	res := append([]string{}, tests...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return current.testScores[file+":"+res[i]] > current.testScores[file+":"+res[j]]
+	})
+	return res
+}
+
+// String returns a readable representation of the result
+//
+// Returns:
+//   - string: the readable representation
+func (this *Result) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Static blocking analysis of %s\n\n", this.Path))
+
+	if len(this.Findings) == 0 {
+		sb.WriteString("No possibly blocking operations found\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Found %d possibly blocking operations\n\n", len(this.Findings)))
+	for i, f := range this.Findings {
+		sb.WriteString(fmt.Sprintf("%d %s (weight %.0f): %s\n", i+1, f.Kind, f.Weight, f.Message))
+		for _, pos := range f.Pos {
+			sb.WriteString(fmt.Sprintf("\t%s\n", pos))
+		}
+	}
+
+	if len(this.Tests) > 0 {
+		sb.WriteString("\nTests ordered by priority\n")
+		for _, t := range this.Tests {
+			sb.WriteString(fmt.Sprintf("\t%s in %s: score %.0f, findings %v\n", t.Name, t.File, t.Score, indicesFromOne(t.Findings)))
+		}
+	}
+
+	return sb.String()
+}
+
+// JSON returns the json representation of the result
+//
+// Returns:
+//   - string: the json representation
+//   - error
+func (this *Result) JSON() (string, error) {
+	var sb strings.Builder
+
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(this); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// addFinding adds a finding with the positions of the given operations
+//
+// Parameter:
+//   - kind string: kind of the finding
+//   - object string: the object of the finding
+//   - weight float64: weight of the finding
+//   - msg string: readable description
+//   - pos ...token.Pos: the positions of the involved operations
+func (this *analyzer) addFinding(kind, object string, weight float64, msg string, pos ...token.Pos) {
+	f := Finding{Kind: kind, Object: object, Message: msg, Weight: weight}
+
+	seen := make(map[string]struct{})
+	for _, p := range pos {
+		position := this.prog.fset.Position(p)
+		key := posKey(position.Filename, position.Line)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		f.positions = append(f.positions, position)
+		f.Pos = append(f.Pos, key)
+	}
+
+	if len(f.Pos) == 0 {
+		return
+	}
+
+	this.findings = append(this.findings, f)
+}
+
+// posKey returns the key of a code position
+//
+// Parameter:
+//   - file string: the file
+//   - line int: the line
+//
+// Returns:
+//   - string: file:line
+func posKey(file string, line int) string {
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// absPath returns the cleaned absolute path of a file
+//
+// Parameter:
+//   - path string: the path
+//
+// Returns:
+//   - string: the absolute path, or the cleaned path if it cannot be made absolute
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// indicesFromOne returns the indices as shown in the readable result
+//
+// Parameter:
+//   - indices []int: indices starting at 0
+//
+// Returns:
+//   - []int: indices starting at 1
+func indicesFromOne(indices []int) []int {
+	res := make([]int, len(indices))
+	for i, index := range indices {
+		res[i] = index + 1
+	}
+	return res
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: waitgroup.go
+// Brief: Find wait groups whose Add and Done calls can diverge
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package s_blocking
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// checkWaitGroups searches for wait groups where the Wait can block
+// forever or where the counter can become negative
+func (this *analyzer) checkWaitGroups() {
+	escaped, _, _, members := this.classFacts()
+
+	classes := make(map[any][]*wgOp)
+	order := make([]any, 0)
+	for _, op := range this.wgOps {
+		root := this.classOf(op.key)
+		if _, ok := classes[root]; !ok {
+			order = append(order, root)
+		}
+		classes[root] = append(classes[root], op)
+	}
+
+	for _, root := range order {
+		ops := classes[root]
+		name := this.className(members[root], root)
+
+		adds, dones, waits, gos := make([]*wgOp, 0), make([]*wgOp, 0), make([]*wgOp, 0), make([]*wgOp, 0)
+		for _, op := range ops {
+			switch op.method {
+			case "Add":
+				adds = append(adds, op)
+			case "Done":
+				dones = append(dones, op)
+			case "Wait":
+				waits = append(waits, op)
+			case "Go":
+				gos = append(gos, op)
+			}
+		}
+
+		// Add in the started goroutine can be executed after the Wait
+		if len(waits) > 0 {
+			for _, add := range adds {
+				if add.ctx.inGo {
+					this.addFinding(AddInGoroutine, name, weightPossible,
+						fmt.Sprintf("Add on wait group %s is called in the started goroutine and can be executed after Wait", name),
+						add.pos, waits[0].pos)
+				}
+			}
+		}
+
+		// Done in a started goroutine that is not deferred and can be skipped
+		// by a branch or return, if it is the only Done in the goroutine
+		if len(waits) > 0 {
+			perFn := make(map[any]int)
+			for _, done := range dones {
+				perFn[done.ctx.fn]++
+			}
+			for _, done := range dones {
+				if !done.ctx.inGo || perFn[done.ctx.fn] > 1 || done.ctx.deferred {
+					continue
+				}
+				if done.ctx.conditional || hasReturnBefore(done.ctx.fn, done.pos) {
+					this.addFinding(SkippableDone, name, weightPossible,
+						fmt.Sprintf("Done on wait group %s is not deferred and can be skipped, Wait can block forever", name),
+						done.pos, waits[0].pos)
+				}
+			}
+		}
+
+		if escaped[root] {
+			continue
+		}
+
+		switch {
+		case len(dones) > 0 && len(adds) == 0 && len(gos) == 0:
+			this.addFinding(DoneWithoutAdd, name, weightCertain,
+				fmt.Sprintf("Done is called on wait group %s without any Add, the counter becomes negative", name),
+				wgPositions(dones)...)
+		case len(waits) > 0 && len(adds) > 0 && len(dones) == 0 && len(gos) == 0:
+			this.addFinding(WaitWithoutDone, name, weightCertain,
+				fmt.Sprintf("Done is never called on wait group %s, Wait blocks forever", name),
+				append(wgPositions(waits), wgPositions(adds)...)...)
+		case len(waits) > 0 && len(gos) == 0:
+			this.checkAddDoneCount(name, adds, dones, waits)
+		}
+	}
+}
+
+// checkAddDoneCount compares the sum of the Adds with the number of Dones,
+// if both are known statically, i.e. no Add or Done is in a loop or a branch
+//
+// Parameter:
+//   - name string: readable name of the wait group
+//   - adds []*wgOp: the calls of Add
+//   - dones []*wgOp: the calls of Done
+//   - waits []*wgOp: the calls of Wait
+func (this *analyzer) checkAddDoneCount(name string, adds, dones, waits []*wgOp) {
+	if len(adds) == 0 || len(dones) == 0 {
+		return
+	}
+
+	decl := waits[0].ctx.decl
+	for _, w := range waits {
+		if w.ctx.decl != decl {
+			return
+		}
+	}
+
+	sum := 0
+	for _, add := range adds {
+		if !add.constDelta || add.ctx.inLoop || add.ctx.inGo || add.ctx.conditional || add.ctx.fn != ast.Node(decl) {
+			return
+		}
+		sum += add.delta
+	}
+
+	for _, done := range dones {
+		if done.ctx.inLoop || done.ctx.goInLoop || done.ctx.conditional || done.ctx.decl != decl {
+			return
+		}
+		// function literals that are not started with go, e.g. callbacks, can be executed any number of times
+		if done.ctx.fn != ast.Node(decl) && !done.ctx.inGo {
+			return
+		}
+	}
+
+	if sum == len(dones) {
+		return
+	}
+
+	msg := fmt.Sprintf("Add on wait group %s adds %d, but Done is called %d times", name, sum, len(dones))
+	if sum > len(dones) {
+		msg += ", Wait blocks forever"
+	} else {
+		msg += ", the counter becomes negative"
+	}
+
+	this.addFinding(AddDoneMismatch, name, weightCertain, msg,
+		append(append(wgPositions(waits), wgPositions(adds)...), wgPositions(dones)...)...)
+}
+
+// wgPositions returns the positions of wait group operations
+//
+// Parameter:
+//   - ops []*wgOp: the operations
+//
+// Returns:
+//   - []token.Pos: the positions
+func wgPositions(ops []*wgOp) []token.Pos {
+	res := make([]token.Pos, len(ops))
+	for i, op := range ops {
+		res[i] = op.pos
+	}
+	return res
+}
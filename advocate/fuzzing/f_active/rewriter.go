@@ -11,6 +11,7 @@
 package f_active
 
 import (
+	"advocate/analysis/a_registry"
 	"advocate/trace"
 	"advocate/utils/helper"
 	"advocate/utils/results/bugs"
@@ -84,7 +85,13 @@ func RewriteTrace(tr *trace.Trace, bug bugs.Bug, rewrittenBugs map[helper.Result
 	case helper.RTimeout:
 		err = errors.New("Timeout. No rewrite possible")
 	default:
-		err = errors.New("For the given bug type no trace rewriting is implemented")
+		rewriter, ok := a_registry.GetRewriter(bug.Type)
+		if !ok {
+			err = errors.New("For the given bug type no trace rewriting is implemented")
+			break
+		}
+		rewriteNeeded = true
+		code, err = rewriter.Rewrite(tr, bug)
 	}
 	return rewriteNeeded, code, err
 }
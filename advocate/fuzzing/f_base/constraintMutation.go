@@ -12,12 +12,14 @@ package f_base
 import (
 	"advocate/analysis/a_base"
 	"advocate/analysis/hb/a_concurrent"
+	"advocate/analysis/s_blocking"
 	"advocate/trace"
 	"advocate/utils/flags"
 	"advocate/utils/settings"
 	"maps"
 	"math"
 	"math/rand/v2"
+	"sort"
 )
 
 // Create the mutations for a GoPie chain
@@ -348,3 +350,71 @@ func Quality(elem trace.Element) float64 {
 
 	return q * ((rand.Float64() * 0.2) - 0.1)
 }
+
+// StaticSeeds returns elements of the trace at operations found by the
+// static blocking analysis (-static), used as starting points of the
+// mutations. For each code position, at most one element is returned. The
+// quality of a seed is the weight of the finding at its position, which is
+// higher than the quality of all other elements.
+//
+// Parameter:
+//   - num int: maximum number of seeds
+//   - sameElem bool: if true, only elements with concurrent elements on the same object are used
+//
+// Returns:
+//   - []ElemWithQual: the seeds, ordered by quality
+func StaticSeeds(num int, sameElem bool) []ElemWithQual {
+	res := make([]ElemWithQual, 0)
+
+	if num <= 0 || !s_blocking.HasResult() {
+		return res
+	}
+
+	seen := make(map[string]struct{})
+	for _, routine := range a_base.MainTrace.GetTraces() {
+		for _, elem := range routine.Elems() {
+			weight := StaticWeight(elem)
+			if weight == 0 {
+				continue
+			}
+
+			pos := elem.Pos().String()
+			if _, ok := seen[pos]; ok {
+				continue
+			}
+
+			if !CanBeAddedToConstraint(elem) {
+				continue
+			}
+
+			if a_concurrent.GetNumberConcurrent(elem, sameElem, settings.SameElementTypeInSC, false) == 0 {
+				continue
+			}
+
+			seen[pos] = struct{}{}
+			res = append(res, ElemWithQual{Elem: elem, Quality: weight})
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Quality > res[j].Quality
+	})
+
+	if len(res) > num {
+		res = res[:num]
+	}
+
+	return res
+}
+
+// StaticWeight returns the weight of the finding of the static blocking
+// analysis at the position of an element
+//
+// Parameter:
+//   - elem trace.Element: the element
+//
+// Returns:
+//   - float64: the weight, 0 if there is no finding or the static analysis was not run
+func StaticWeight(elem trace.Element) float64 {
+	return s_blocking.Weight(elem.File(), elem.Line())
+}
@@ -20,6 +20,7 @@ const (
 	GoCR        = "GoCR"        // improved goPie without predictive analysis
 	GoCRHB      = "GoCRHB"      // improved goPie with predictive analysis
 	Guided      = "Guided"      // hb guided fuzzing
+	Adaptive    = "Adaptive"    // bandit over the single strategies
 )
 
 // Possible mut types
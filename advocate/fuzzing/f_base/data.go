@@ -26,6 +26,7 @@ var (
 	MaxTimeSet        = false
 	NumberFuzzingRuns = 0
 	MutationQueue     = types.NewQueue[Mutation]()
+	LastMutation      Mutation // mutation of the last run, empty for the first run
 
 	// count how often a specific mutation has been in the queue
 	AllMutations          = make(map[string]int)
@@ -34,8 +35,12 @@ var (
 	FuzzingModeGoCRHBPlus = false
 	FuzzingModeFlow       = false
 	FuzzingModeGuided     = false
+	FuzzingModeAdaptive   = false
 	FuzzingHbAnalysis     = true
 
+	// number of mutations in the queues of the strategies in adaptive mode
+	NumberQueuedStrategies = 0
+
 	NumberOfPreviousRuns = 0
 
 	UseHBInfoFuzzing = true
@@ -43,6 +48,8 @@ var (
 	FinishIfBugFound = false
 
 	NumberWrittenMutations = 0
+	// the folder for the fuzzing traces of the current test/prog has been created
+	fuzzingTraceFolderCreated = false
 	// for each mutation file, store the file number and the chain
 	ChainFiles = make(map[int]Constraint)
 
@@ -63,4 +70,6 @@ func ClearDataFull() {
 	results.Reset()
 
 	NumberOfPreviousRuns = 0
+	NumberQueuedStrategies = 0
+	fuzzingTraceFolderCreated = false
 }
@@ -23,7 +23,7 @@ import (
 // Returns:
 //   - bool: true, if the mutation was added, false otherwise
 func AddMutToQueue(mut Mutation, force bool) bool {
-	if force || MaxNumberRuns == -1 || NumberFuzzingRuns+MutationQueue.Size() <= MaxNumberRuns {
+	if force || MaxNumberRuns == -1 || NumberFuzzingRuns+MutationQueue.Size()+NumberQueuedStrategies <= MaxNumberRuns {
 		MutationQueue.Push(mut)
 		return true
 	}
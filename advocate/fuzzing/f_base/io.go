@@ -62,8 +62,10 @@ func WriteMutConstraint(mut Constraint, first bool) (bool, error) {
 		traceCopy.AddElement(c)
 	}
 
-	if first {
+	// in adaptive mode, multiple strategies can write the first mutation
+	if first && !fuzzingTraceFolderCreated {
 		AddFuzzingTraceFolder(paths.FuzzingTraces)
+		fuzzingTraceFolderCreated = true
 	}
 
 	fuzzingTracePath := filepath.Join(paths.FuzzingTraces, fmt.Sprintf("fuzzingTrace_%d", NumberWrittenMutations))
@@ -0,0 +1,252 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: strategy.go
+// Brief: Interface for fuzzing strategies and the registry of all strategies,
+//    that can be selected with -mode
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_base
+
+import (
+	"advocate/trace"
+	"advocate/utils/types"
+	"fmt"
+	"sort"
+)
+
+// Strategy is a fuzzing strategy. After each run, it parses the recorded
+// trace, decides whether the run was interesting and creates the mutations
+// for the next runs.
+type Strategy interface {
+	// Name returns the name of the strategy, used as fuzzing mode
+	Name() string
+
+	// ParseTrace collects the data of the strategy from the recorded trace
+	// of the last run. The data of previous runs that is only required for
+	// one run must be cleared here. It is called after the analysis of the
+	// run, so the happens before information is available if the
+	// mode flags require it.
+	ParseTrace(tr *trace.Trace)
+
+	// CreateMutations creates the mutations for the next runs. It is only
+	// called if the run was interesting. The mutation of the last run is
+	// stored in LastMutation.
+	CreateMutations() []Mutation
+
+	// Interesting returns whether mutations should be created for the last run
+	Interesting() bool
+
+	// Reset clears all data of the strategy before the fuzzing of a new test or program
+	Reset()
+}
+
+// Configurer can optionally be implemented by a strategy to set the mode
+// flags (FuzzingModeGFuzz, FuzzingModeGoPie, UseHBInfoFuzzing, ...), that
+// define which data is collected during the recording and analysis of a run.
+// Before Configure is called, all mode flags are set to false.
+type Configurer interface {
+	Configure()
+}
+
+// registered strategies
+var strategies = make(map[string]Strategy)
+
+// RegisterStrategy adds a strategy to the registry. It can then be selected
+// with -mode and its name.
+//
+// Parameter:
+//   - s Strategy: the strategy
+//
+// Returns:
+//   - error: if the name is empty or already used
+func RegisterStrategy(s Strategy) error {
+	name := s.Name()
+	if name == Default || name == Adaptive {
+		return fmt.Errorf("Invalid name for fuzzing strategy: '%s'", name)
+	}
+
+	if _, ok := strategies[name]; ok {
+		return fmt.Errorf("Fuzzing strategy %s is already registered", name)
+	}
+
+	strategies[name] = s
+	return nil
+}
+
+// GetStrategy returns a registered strategy
+//
+// Parameter:
+//   - name string: the name of the strategy
+//
+// Returns:
+//   - Strategy: the strategy
+//   - bool: false if no strategy with this name is registered
+func GetStrategy(name string) (Strategy, bool) {
+	s, ok := strategies[name]
+	return s, ok
+}
+
+// Strategies returns all registered strategies
+//
+// Returns:
+//   - []Strategy: the strategies, sorted by name
+func Strategies() []Strategy {
+	res := make([]Strategy, 0, len(strategies))
+	for _, name := range StrategyNames() {
+		res = append(res, strategies[name])
+	}
+	return res
+}
+
+// StrategyNames returns the names of all registered strategies
+//
+// Returns:
+//   - []string: the names, sorted
+func StrategyNames() []string {
+	res := make([]string, 0, len(strategies))
+	for name := range strategies {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ResetModeFlags sets all mode flags to false
+func ResetModeFlags() {
+	FuzzingModeGFuzz = false
+	FuzzingModeGoPie = false
+	FuzzingModeGoCRHBPlus = false
+	FuzzingModeFlow = false
+	FuzzingModeGuided = false
+	FuzzingModeAdaptive = false
+	UseHBInfoFuzzing = false
+}
+
+// ConfigureStrategy sets the mode flags for a strategy. If the strategy
+// does not implement Configurer, all flags stay false.
+//
+// Parameter:
+//   - s Strategy: the strategy
+func ConfigureStrategy(s Strategy) {
+	if c, ok := s.(Configurer); ok {
+		c.Configure()
+	}
+}
+
+// CollectMutations runs a function that adds mutations with AddMutToQueue
+// and returns the added mutations instead of leaving them in the mutation
+// queue. This allows the existing mutation functions to be used in
+// Strategy.CreateMutations. The mutations that are already queued still count
+// for the maximum number of runs.
+//
+// Parameter:
+//   - create func(): the function creating the mutations
+//
+// Returns:
+//   - []Mutation: the created mutations
+func CollectMutations(create func()) []Mutation {
+	queue := MutationQueue
+	MutationQueue = types.NewQueue[Mutation]()
+	NumberQueuedStrategies += queue.Size()
+
+	create()
+
+	res := make([]Mutation, 0, MutationQueue.Size())
+	for !MutationQueue.IsEmpty() {
+		res = append(res, MutationQueue.Pop())
+	}
+
+	NumberQueuedStrategies -= queue.Size()
+	MutationQueue = queue
+
+	return res
+}
+
+// combinedStrategy runs multiple strategies as one
+//
+// Fields:
+//   - name string: name of the combined strategy
+//   - parts []Strategy: the combined strategies
+type combinedStrategy struct {
+	name  string
+	parts []Strategy
+}
+
+// CombineStrategies creates a strategy, that runs multiple strategies after
+// each other. The mode flags are the union of the flags of all parts. The
+// mutations of a part are only created if the run was interesting for this part.
+//
+// Parameter:
+//   - name string: name of the combined strategy
+//   - parts ...Strategy: the strategies to combine
+//
+// Returns:
+//   - Strategy: the combined strategy
+func CombineStrategies(name string, parts ...Strategy) Strategy {
+	return &combinedStrategy{name: name, parts: parts}
+}
+
+// Name returns the name of the combined strategy
+//
+// Returns:
+//   - string: the name
+func (this *combinedStrategy) Name() string {
+	return this.name
+}
+
+// Configure sets the mode flags of all parts
+func (this *combinedStrategy) Configure() {
+	for _, p := range this.parts {
+		ConfigureStrategy(p)
+	}
+}
+
+// ParseTrace parses the trace for all parts
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace
+func (this *combinedStrategy) ParseTrace(tr *trace.Trace) {
+	for _, p := range this.parts {
+		p.ParseTrace(tr)
+	}
+}
+
+// CreateMutations creates the mutations of all parts for which the run was interesting
+//
+// Returns:
+//   - []Mutation: the mutations
+func (this *combinedStrategy) CreateMutations() []Mutation {
+	return CollectMutations(func() {
+		for _, p := range this.parts {
+			if !p.Interesting() {
+				continue
+			}
+			for _, mut := range p.CreateMutations() {
+				MutationQueue.Push(mut)
+			}
+		}
+	})
+}
+
+// Interesting returns whether the run was interesting for at least one part
+//
+// Returns:
+//   - bool: true if the run was interesting for one of the parts
+func (this *combinedStrategy) Interesting() bool {
+	for _, p := range this.parts {
+		if p.Interesting() {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset resets all parts
+func (this *combinedStrategy) Reset() {
+	for _, p := range this.parts {
+		p.Reset()
+	}
+}
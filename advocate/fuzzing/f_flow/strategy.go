@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: strategy.go
+// Brief: Flow mutation as fuzzing strategy
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_flow
+
+import (
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/log"
+)
+
+func init() {
+	if err := f_base.RegisterStrategy(&flow{}); err != nil {
+		log.Error("Could not register fuzzing strategy: ", err.Error())
+	}
+}
+
+// flow is the strategy, that delays operations with concurrent partners
+type flow struct{}
+
+// Name returns the name of the strategy
+//
+// Returns:
+//   - string: the name
+func (this *flow) Name() string {
+	return f_base.Flow
+}
+
+// Configure sets the mode flags for flow mutations
+func (this *flow) Configure() {
+	f_base.FuzzingModeFlow = true
+	f_base.UseHBInfoFuzzing = true
+}
+
+// ParseTrace does nothing, the concurrent operations are collected by the analysis
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace
+func (this *flow) ParseTrace(tr *trace.Trace) {}
+
+// CreateMutations creates the flow mutations
+//
+// Returns:
+//   - []f_base.Mutation: the mutations
+func (this *flow) CreateMutations() []f_base.Mutation {
+	return f_base.CollectMutations(CreateMutations)
+}
+
+// Interesting returns true, the already delayed operations are skipped
+// when the mutations are created
+//
+// Returns:
+//   - bool: true
+func (this *flow) Interesting() bool {
+	return true
+}
+
+// Reset deletes the data of flow mutation
+func (this *flow) Reset() {
+	ClearData()
+}
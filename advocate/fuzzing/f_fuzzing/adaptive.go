@@ -0,0 +1,321 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: adaptive.go
+// Brief: Adaptive fuzzing, that uses a multi-armed bandit to decide which
+//    fuzzing strategy creates the mutation for the next run
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_fuzzing
+
+import (
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/control"
+	"advocate/utils/flags"
+	"advocate/utils/log"
+	"advocate/utils/results/results"
+	"advocate/utils/results/stats"
+	"advocate/utils/types"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// weight of a new bug in the reward compared to a new select case or hb pair
+const adaptiveBugWeight = 5
+
+// adaptiveStrategies returns the strategies used in adaptive mode, each is
+// an arm of the bandit. These are all registered strategies, except for the
+// strategies that combine other strategies, since their parts are already arms.
+//
+// Returns:
+//   - []string: the names of the strategies
+func adaptiveStrategies() []string {
+	res := make([]string, 0)
+	for _, name := range f_base.StrategyNames() {
+		if name == f_base.GFuzzHBFlow {
+			continue
+		}
+		res = append(res, name)
+	}
+	return res
+}
+
+// strategyArm is a fuzzing strategy in adaptive mode
+//
+// Fields:
+//   - name string: name of the strategy (fuzzing mode)
+//   - strategy f_base.Strategy: the strategy
+//   - queue *types.Queue[f_base.Mutation]: mutations created by the strategy
+//   - yield stats.StrategyYield: runs and reward of the strategy
+type strategyArm struct {
+	name     string
+	strategy f_base.Strategy
+	queue    *types.Queue[f_base.Mutation]
+	yield    stats.StrategyYield
+}
+
+var (
+	arms      = make([]*strategyArm, 0)
+	activeArm *strategyArm // arm that created the mutation of the current run, nil for the first run
+
+	seenBugs        = make(map[string]struct{})
+	seenSelectCases = make(map[string]struct{})
+	seenHBPairs     = make(map[string]struct{})
+)
+
+// clearDataAdaptive resets the adaptive data for a new test/prog
+func clearDataAdaptive() {
+	names := adaptiveStrategies()
+	arms = make([]*strategyArm, 0, len(names))
+	for _, name := range names {
+		s, ok := f_base.GetStrategy(name)
+		if !ok {
+			log.Errorf("Adaptive: unknown strategy %s", name)
+			continue
+		}
+
+		arms = append(arms, &strategyArm{
+			name:     name,
+			strategy: s,
+			queue:    types.NewQueue[f_base.Mutation](),
+			yield:    stats.StrategyYield{Strategy: name},
+		})
+	}
+	activeArm = nil
+
+	seenBugs = make(map[string]struct{})
+	seenSelectCases = make(map[string]struct{})
+	seenHBPairs = make(map[string]struct{})
+}
+
+// createMutationsAdaptive lets each strategy parse the recorded run and
+// create its mutations, and adds them to the queue of the strategy. Some
+// strategies share their data with other strategies, e.g. GoPie and GoCR,
+// and parse the trace depending on the mode flags. Each strategy therefore
+// parses the trace with its own mode flags directly before creating its mutations.
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace
+func createMutationsAdaptive(tr *trace.Trace) {
+	defer func() {
+		flags.FuzzingMode = f_base.Adaptive
+		setModeFlags(f_base.Adaptive)
+	}()
+
+	for _, arm := range arms {
+		flags.FuzzingMode = arm.name
+		setModeFlags(arm.name)
+
+		arm.strategy.ParseTrace(tr)
+		if control.WasCanceled() {
+			return
+		}
+
+		added := 0
+		for _, mut := range runStrategy(arm.strategy) {
+			arm.queue.Push(mut)
+			f_base.NumberQueuedStrategies++
+			added++
+		}
+
+		log.Infof("Strategy %s: %d new mutations, %d queued", arm.name, added, arm.queue.Size())
+	}
+}
+
+// popMutationAdaptive chooses the strategy for the next run with UCB1 and
+// returns the next mutation of this strategy. Strategies without queued
+// mutations are not considered, strategies that have not been run yet are
+// chosen first.
+//
+// Returns:
+//   - f_base.Mutation: the mutation for the next run
+func popMutationAdaptive() f_base.Mutation {
+	totalRuns := 0
+	for _, arm := range arms {
+		totalRuns += arm.yield.Runs
+	}
+
+	var best *strategyArm
+	bestScore := math.Inf(-1)
+	for _, arm := range arms {
+		if arm.queue.IsEmpty() {
+			continue
+		}
+
+		if arm.yield.Runs == 0 {
+			best = arm
+			break
+		}
+
+		mean := arm.yield.Reward / float64(arm.yield.Runs)
+		score := mean + math.Sqrt(2*math.Log(float64(totalRuns))/float64(arm.yield.Runs))
+		if score > bestScore {
+			best = arm
+			bestScore = score
+		}
+	}
+
+	activeArm = best
+	if best == nil {
+		return f_base.MutationQueue.Pop()
+	}
+
+	log.Infof("Adaptive: run mutation of strategy %s", best.name)
+	f_base.NumberQueuedStrategies--
+	return best.queue.Pop()
+}
+
+// updateAdaptive computes the reward of the last run and adds it to the
+// strategy that created its mutation. The reward is based on the number of
+// new bugs, newly selected select cases and new hb pairs.
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace, nil if the run failed
+func updateAdaptive(tr *trace.Trace) {
+	newBugs, newCases, newPairs := 0, 0, 0
+
+	if tr != nil {
+		for _, key := range results.GetResultKeys() {
+			if addSeen(seenBugs, key) {
+				newBugs++
+			}
+		}
+
+		newCases, newPairs = coverageAdaptive(tr)
+	}
+
+	if activeArm == nil {
+		return
+	}
+
+	gain := float64(adaptiveBugWeight*newBugs + newCases + newPairs)
+	reward := gain / (1 + gain)
+
+	activeArm.yield.Runs++
+	activeArm.yield.NewBugs += newBugs
+	activeArm.yield.NewSelectCases += newCases
+	activeArm.yield.NewHBPairs += newPairs
+	activeArm.yield.Reward += reward
+
+	log.Infof("Adaptive: strategy %s got reward %.2f (new bugs: %d, new select cases: %d, new hb pairs: %d)",
+		activeArm.name, reward, newBugs, newCases, newPairs)
+}
+
+// coverageAdaptive counts the select cases and hb pairs in a trace that
+// have not been seen in previous runs of the test. HB pairs are the pairs of
+// positions of a send and the receive it communicated with and of an unlock and
+// the next lock on the same mutex.
+//
+// Parameter:
+//   - tr *trace.Trace: the trace
+//
+// Returns:
+//   - int: number of new select cases
+//   - int: number of new hb pairs
+func coverageAdaptive(tr *trace.Trace) (int, int) {
+	newCases, newPairs := 0, 0
+	lastUnlock := make(map[int]*trace.ElementMutex)
+	mutexOps := make([]*trace.ElementMutex, 0)
+
+	for _, routine := range tr.GetTraces() {
+		for _, elem := range routine.Elems() {
+			if !elem.Committed() {
+				continue
+			}
+
+			switch e := elem.(type) {
+			case *trace.ElementSelect:
+				key := fmt.Sprintf("%s:%d", e.Pos().String(), e.GetChosenIndex())
+				if addSeen(seenSelectCases, key) {
+					newCases++
+				}
+				if !e.GetChosenDefault() && addChannelPair(e.GetChosenCase()) {
+					newPairs++
+				}
+			case *trace.ElementChannel:
+				if addChannelPair(e) {
+					newPairs++
+				}
+			case *trace.ElementMutex:
+				mutexOps = append(mutexOps, e)
+			}
+		}
+	}
+
+	sort.Slice(mutexOps, func(i, j int) bool {
+		return mutexOps[i].T(trace.Sorting) < mutexOps[j].T(trace.Sorting)
+	})
+	for _, m := range mutexOps {
+		if !m.IsLock() {
+			lastUnlock[m.ObjID()] = m
+			continue
+		}
+
+		if !m.IsSuc() {
+			continue
+		}
+
+		if u, ok := lastUnlock[m.ObjID()]; ok && u.Routine() != m.Routine() {
+			if addSeen(seenHBPairs, u.Pos().String()+"->"+m.Pos().String()) {
+				newPairs++
+			}
+		}
+	}
+
+	return newCases, newPairs
+}
+
+// addChannelPair adds the pair of a send and the receive it communicated with
+//
+// Parameter:
+//   - send *trace.ElementChannel: the channel operation
+//
+// Returns:
+//   - bool: true if the operation is a send with partner, whose pair has not been seen before
+func addChannelPair(send *trace.ElementChannel) bool {
+	if send == nil || send.Type(true) != trace.ChannelSend {
+		return false
+	}
+
+	recv := send.GetPartner()
+	if recv == nil {
+		return false
+	}
+
+	return addSeen(seenHBPairs, send.Pos().String()+"->"+recv.Pos().String())
+}
+
+// addSeen adds a key to a set
+//
+// Parameter:
+//   - seen map[string]struct{}: the set
+//   - key string: the key
+//
+// Returns:
+//   - bool: true if the key was not in the set before
+func addSeen(seen map[string]struct{}, key string) bool {
+	if _, ok := seen[key]; ok {
+		return false
+	}
+	seen[key] = struct{}{}
+	return true
+}
+
+// finishAdaptive logs the yield of each strategy and writes it into the
+// fuzzing stats
+func finishAdaptive() {
+	yields := make([]stats.StrategyYield, 0, len(arms))
+	for _, arm := range arms {
+		log.Infof("Adaptive: strategy %s: %d runs, %d new bugs, %d new select cases, %d new hb pairs",
+			arm.name, arm.yield.Runs, arm.yield.NewBugs, arm.yield.NewSelectCases, arm.yield.NewHBPairs)
+		yields = append(yields, arm.yield)
+	}
+
+	if flags.CreateStatistics {
+		stats.CreateStatsStrategies(flags.ExecName, yields)
+	}
+}
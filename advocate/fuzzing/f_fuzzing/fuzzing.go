@@ -12,11 +12,9 @@ package f_fuzzing
 import (
 	"advocate/advoc/toolchain"
 	"advocate/analysis/a_base"
+	"advocate/analysis/s_blocking"
 	"advocate/fuzzing/f_base"
-	"advocate/fuzzing/f_flow"
-	"advocate/fuzzing/f_gfuzz"
 	"advocate/fuzzing/f_gopie"
-	"advocate/fuzzing/f_roc"
 	"advocate/utils/control"
 	"advocate/utils/flags"
 	"advocate/utils/log"
@@ -27,14 +25,20 @@ import (
 	"advocate/utils/types"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
+// AfterRun is called after each successful fuzzing run with the id of the
+// recorded trace. If it returns true, the fuzzing of the test is stopped.
+// If nil, it is ignored
+var AfterRun func(traceID int) bool
+
 // Fuzzing creates the fuzzing data and runs the fuzzing executions
 func Fuzzing() error {
-	modes := []string{f_base.GoPie, f_base.GoCR, f_base.GoCRHB, f_base.GFuzz, f_base.GFuzzHBFlow, f_base.GFuzzHB, f_base.Flow, f_base.Guided}
+	modes := append(f_base.StrategyNames(), f_base.Adaptive)
 	if !types.Contains(modes, flags.FuzzingMode) {
-		return fmt.Errorf("Invalid fuzzing mode '%s'. Possible values are Guided, GoPie, GoCR, GoCRHB, GFuzz, GFuzzFlow, GFuzzHB, Flow", flags.FuzzingMode)
+		return fmt.Errorf("Invalid fuzzing mode '%s'. Possible values are %s", flags.FuzzingMode, strings.Join(modes, ", "))
 	}
 
 	f_base.MaxNumberRuns = flags.MaxFuzzingRun
@@ -43,12 +47,7 @@ func Fuzzing() error {
 		f_base.MaxTimeSet = true
 	}
 
-	f_base.FuzzingModeGoPie = (flags.FuzzingMode == f_base.GoPie || flags.FuzzingMode == f_base.GoCR || flags.FuzzingMode == f_base.GoCRHB)
-	f_base.FuzzingModeGoCRHBPlus = (flags.FuzzingMode == f_base.GoCR || flags.FuzzingMode == f_base.GoCRHB)
-	f_base.FuzzingModeGFuzz = (flags.FuzzingMode == f_base.GFuzz || flags.FuzzingMode == f_base.GFuzzHBFlow || flags.FuzzingMode == f_base.GFuzzHB)
-	f_base.FuzzingModeFlow = (flags.FuzzingMode == f_base.Flow || flags.FuzzingMode == f_base.GFuzzHBFlow)
-	f_base.FuzzingModeGuided = (flags.FuzzingMode == f_base.Guided || flags.FuzzingMode == f_base.Default)
-	f_base.UseHBInfoFuzzing = (flags.FuzzingMode == f_base.Guided || flags.FuzzingMode == f_base.GFuzzHB || flags.FuzzingMode == f_base.GFuzzHBFlow || flags.FuzzingMode == f_base.Flow || flags.FuzzingMode == f_base.GoCR || flags.FuzzingMode == f_base.GoCRHB)
+	setModeFlags(flags.FuzzingMode)
 
 	if flags.Continue {
 		log.Info("Continue fuzzing")
@@ -58,13 +57,31 @@ func Fuzzing() error {
 
 	// run either fuzzing on main or fuzzing on one test
 	if flags.ModeMain || flags.ExecName != "" {
-		if flags.ModeMain {
-			log.Info("Run fuzzing on main function")
-		} else {
-			log.Info("Run fuzzing on test ", flags.ExecName)
+		tests := []string{flags.ExecName}
+		if !flags.ModeMain {
+			tests = fuzzTargetInputs("", flags.ExecName)
 		}
 
-		err := runFuzzing("", true, 0, 0)
+		var err error
+		for k, test := range tests {
+			flags.ExecName = test
+
+			if flags.ModeMain {
+				log.Info("Run fuzzing on main function")
+			} else {
+				log.Info("Run fuzzing on test ", flags.ExecName)
+			}
+
+			if k > 0 {
+				a_base.Clear()
+				ResetFuzzing()
+			}
+
+			err = runFuzzing("", k == 0, 0, 0)
+			if err != nil && len(tests) > 1 {
+				log.Error("Error in fuzzing: ", err.Error())
+			}
+		}
 
 		if flags.CreateStatistics {
 			err := stats.CreateStatsFuzzing(f_base.GetPath(flags.ProgPath))
@@ -97,6 +114,11 @@ func Fuzzing() error {
 
 	log.Infof("Found %d test files", totalFiles)
 
+	// fuzz the tests with possibly blocking operations first
+	if !flags.Continue {
+		testFiles = s_blocking.SortTestFiles(testFiles)
+	}
+
 	// Process each test file
 	fileCounter := 0
 	if flags.Continue {
@@ -113,34 +135,38 @@ func Fuzzing() error {
 			log.Info("Could not find test functions in ", testFile)
 			continue
 		}
+		if !flags.Continue {
+			testFunctions = s_blocking.SortTests(testFile, testFunctions)
+		}
 
 		for j, testFunc := range testFunctions {
-			flags.ExecName = testFunc
+			for k, test := range fuzzTargetInputs(testFile, testFunc) {
+				flags.ExecName = test
 
-			for control.WasCanceledRAM() {
-				log.Error("Wait RAM")
-				time.Sleep(6 * time.Second)
-			}
+				for control.WasCanceledRAM() {
+					log.Error("Wait RAM")
+					time.Sleep(6 * time.Second)
+				}
 
-			a_base.Clear()
-			ResetFuzzing()
-			timer.ResetTest()
+				a_base.Clear()
+				ResetFuzzing()
+				timer.ResetTest()
 
-			timer.Start(timer.TotalTest)
+				timer.Start(timer.TotalTest)
 
-			log.Progressf("Run fuzzing for %s (%d/%d) -> %s (%d/%d)", testFile, fileCounter, totalFiles, testFunc, j+1, len(testFunctions))
+				log.Progressf("Run fuzzing for %s (%d/%d) -> %s (%d/%d)", testFile, fileCounter, totalFiles, test, j+1, len(testFunctions))
 
-			firstRun := (i == 0 && j == 0)
+				firstRun := (i == 0 && j == 0 && k == 0)
 
-			err := runFuzzing(testFile, firstRun, fileCounter, j+1)
-			if err != nil {
-				log.Error("Error in fuzzing: ", err.Error())
-				clearDataRun()
-			}
+				err := runFuzzing(testFile, firstRun, fileCounter, j+1)
+				if err != nil {
+					log.Error("Error in fuzzing: ", err.Error())
+				}
 
-			timer.Stop(timer.TotalTest)
+				timer.Stop(timer.TotalTest)
 
-			timer.UpdateTimeFileOverview(testFunc)
+				timer.UpdateTimeFileOverview(test)
+			}
 		}
 	}
 
@@ -162,6 +188,66 @@ func Fuzzing() error {
 	return nil
 }
 
+// fuzzTargetInputs returns the tests that are fuzzed for a test. For a go
+// fuzz target, each input of its seed corpus is fuzzed as its own test,
+// so that each run and replay uses exactly this input. For all other tests
+// and fuzz targets without inputs, this is the test itself.
+//
+// Parameter:
+//   - testFile string: path to the file containing the test, if empty it is searched in the program
+//   - testName string: name of the test
+//
+// Returns:
+//   - []string: the names of the tests to fuzz
+func fuzzTargetInputs(testFile, testName string) []string {
+	var inputs []string
+	var err error
+	if testFile == "" {
+		inputs, err = toolchain.FindFuzzInputsOfTest(flags.ProgPath, testName)
+	} else {
+		inputs, err = toolchain.FindFuzzInputs(testFile, testName)
+	}
+
+	if err != nil {
+		log.Errorf("Could not find the inputs of %s: %s", testName, err.Error())
+	}
+
+	if len(inputs) == 0 {
+		return []string{testName}
+	}
+
+	log.Infof("Found %d inputs for fuzz target %s", len(inputs), testName)
+	return inputs
+}
+
+// setModeFlags sets the fuzzing mode booleans for a fuzzing mode by
+// configuring its strategy. In adaptive mode, the data for all strategies
+// is collected.
+//
+// Parameter:
+//   - mode string: the fuzzing mode
+func setModeFlags(mode string) {
+	f_base.ResetModeFlags()
+
+	if mode == f_base.Adaptive {
+		f_base.FuzzingModeAdaptive = true
+		for _, name := range adaptiveStrategies() {
+			if s, ok := f_base.GetStrategy(name); ok {
+				f_base.ConfigureStrategy(s)
+			}
+		}
+		return
+	}
+
+	if mode == f_base.Default {
+		mode = f_base.Guided
+	}
+
+	if s, ok := f_base.GetStrategy(mode); ok {
+		f_base.ConfigureStrategy(s)
+	}
+}
+
 // Run Fuzzing on one program/test
 //
 // Parameter:
@@ -171,12 +257,15 @@ func Fuzzing() error {
 func runFuzzing(testPath string, firstRun bool, fileNumber, testNumber int) error {
 	clearDataFull()
 
+	if f_base.FuzzingModeAdaptive {
+		defer finishAdaptive()
+	}
+
 	// while there are available mutations, run them
 	startTime := time.Now()
-	for f_base.NumberFuzzingRuns == 0 || f_base.MutationQueue.Size() != 0 {
+	for f_base.NumberFuzzingRuns == 0 || queueSize() != 0 {
 
 		// clean up
-		clearDataRun()
 		timer.ResetFuzzing()
 
 		if flags.CancelTestIfBugFound && results.GetBugWasFound() {
@@ -191,6 +280,7 @@ func runFuzzing(testPath string, firstRun bool, fileNumber, testNumber int) erro
 		var order f_base.Mutation
 		if f_base.NumberFuzzingRuns != 0 {
 			order = popMutation()
+			f_base.LastMutation = order
 			if order.MutType == f_base.MutPiType {
 				fuzzingPath = filepath.Join(progPathDir,
 					filepath.Join("fuzzingTraces",
@@ -227,6 +317,9 @@ func runFuzzing(testPath string, firstRun bool, fileNumber, testNumber int) erro
 
 		if err != nil {
 			log.Error("Fuzzing run failed: ", err.Error())
+			if f_base.FuzzingModeAdaptive {
+				updateAdaptive(nil)
+			}
 		} else {
 			log.Info("Parse recorded trace for fuzzing information")
 
@@ -244,34 +337,23 @@ func runFuzzing(testPath string, firstRun bool, fileNumber, testNumber int) erro
 
 			log.Infof("Create mutations")
 
-			// add mutation based on guided fuzzing
-			if f_base.FuzzingModeGuided {
-				f_roc.CreateMutations()
-			}
-			// Add mutation based on GFuzz
-			if f_base.FuzzingModeGFuzz {
-				f_gfuzz.CreateMutations(false)
-			}
-
-			// add new mutations based on flow path expansion
-			// if f_base.FuzzingModeFlow {
-			// 	f_flow.CreateMutations()
-			// }
-
-			// add mutations based on GoPie
-			if f_base.FuzzingModeGoPie {
-				f_gopie.CreateMutations(order.MutPie)
+			if f_base.FuzzingModeAdaptive {
+				updateAdaptive(&a_base.MainTrace)
+				createMutationsAdaptive(&a_base.MainTrace)
+			} else {
+				createMutations()
 			}
 
 			if flags.CreateStatistics {
 				stats.CreateStats(flags.ExecName, traceID, f_base.NumberFuzzingRuns-1)
 			}
 
-			log.Infof("Current fuzzing queue size: %d", f_base.MutationQueue.Size())
+			log.Infof("Current fuzzing queue size: %d", queueSize())
+		}
 
-			if f_base.FuzzingModeGFuzz {
-				f_gfuzz.MergeTraceInfoIntoFileInfo()
-			}
+		if err == nil && AfterRun != nil && AfterRun(traceID) {
+			log.Infof("Finish fuzzing after %d runs\n", f_base.NumberFuzzingRuns)
+			return nil
 		}
 
 		// cancel if max number of mutations have been reached
@@ -311,34 +393,54 @@ func runFuzzing(testPath string, firstRun bool, fileNumber, testNumber int) erro
 	return nil
 }
 
-// Remove and return the first mutation from the mutation queue
+// createMutations creates the mutations for the non adaptive fuzzing modes
+// with the strategies of the mode and adds them to the mutation queue
+func createMutations() {
+	for _, s := range activeStrategies() {
+		for _, mut := range runStrategy(s) {
+			f_base.MutationQueue.Push(mut)
+		}
+	}
+}
+
+// Remove and return the next mutation. In adaptive mode, the
+// mutation is taken from the queue of the strategy chosen by the bandit,
+// otherwise the first mutation from the mutation queue is returned
 //
 // Returns:
-//   - the first mutation from the mutation queue
+//   - the next mutation
 func popMutation() f_base.Mutation {
+	if f_base.FuzzingModeAdaptive {
+		return popMutationAdaptive()
+	}
 	return f_base.MutationQueue.Pop()
 }
 
+// queueSize returns the number of mutations that are waiting to be run
+//
+// Returns:
+//   - int: the number of queued mutations
+func queueSize() int {
+	return f_base.MutationQueue.Size() + f_base.NumberQueuedStrategies
+}
+
 // Reset fuzzing
 func ResetFuzzing() {
 	log.Debug("RESET1")
 	f_base.NumberFuzzingRuns = 0
 	f_base.MutationQueue = types.NewQueue[f_base.Mutation]()
+	f_base.LastMutation = f_base.Mutation{}
 	// count how often a specific mutation has been in the queue
 	f_base.AllMutations = make(map[string]int)
 	f_base.ChainFiles = make(map[int]f_base.Constraint)
 
 }
 
+// clearDataFull resets the data of all strategies before the fuzzing of a new test/prog
 func clearDataFull() {
 	f_base.ClearDataFull()
-	f_gopie.ClearData()
-	f_gfuzz.ClearDataFull()
-	f_flow.ClearData()
-}
-
-func clearDataRun() {
-	f_gopie.ClearDataRun()
-	f_gfuzz.ClearDataRun()
-	f_flow.ClearData()
+	for _, s := range f_base.Strategies() {
+		s.Reset()
+	}
+	clearDataAdaptive()
 }
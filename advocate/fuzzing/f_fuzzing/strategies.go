@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: strategies.go
+// Brief: Register the built-in fuzzing strategies and get the strategies
+//    of the current fuzzing mode
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_fuzzing
+
+import (
+	"advocate/fuzzing/f_base"
+	"advocate/utils/flags"
+	"advocate/utils/log"
+
+	// the strategies register themselves
+	_ "advocate/fuzzing/f_flow"
+	_ "advocate/fuzzing/f_gfuzz"
+	_ "advocate/fuzzing/f_gopie"
+	_ "advocate/fuzzing/f_roc"
+)
+
+// register the strategies that combine other strategies
+func init() {
+	gFuzzHB, okGFuzz := f_base.GetStrategy(f_base.GFuzzHB)
+	flow, okFlow := f_base.GetStrategy(f_base.Flow)
+	if !okGFuzz || !okFlow {
+		log.Error("Could not register fuzzing strategy ", f_base.GFuzzHBFlow)
+		return
+	}
+
+	err := f_base.RegisterStrategy(f_base.CombineStrategies(f_base.GFuzzHBFlow, gFuzzHB, flow))
+	if err != nil {
+		log.Error("Could not register fuzzing strategy: ", err.Error())
+	}
+}
+
+// activeStrategies returns the strategies of the current fuzzing mode.
+// In adaptive mode, these are the strategies of all arms.
+//
+// Returns:
+//   - []f_base.Strategy: the active strategies
+func activeStrategies() []f_base.Strategy {
+	if f_base.FuzzingModeAdaptive {
+		res := make([]f_base.Strategy, 0, len(arms))
+		for _, arm := range arms {
+			res = append(res, arm.strategy)
+		}
+		return res
+	}
+
+	if s, ok := f_base.GetStrategy(flags.FuzzingMode); ok {
+		return []f_base.Strategy{s}
+	}
+	return nil
+}
+
+// runStrategy creates the mutations of a strategy, if the last run was
+// interesting for the strategy
+//
+// Parameter:
+//   - s f_base.Strategy: the strategy
+//
+// Returns:
+//   - []f_base.Mutation: the created mutations
+func runStrategy(s f_base.Strategy) []f_base.Mutation {
+	if !s.Interesting() {
+		log.Infof("Run was not interesting for strategy %s", s.Name())
+		return nil
+	}
+
+	return s.CreateMutations()
+}
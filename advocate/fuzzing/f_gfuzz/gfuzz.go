@@ -20,18 +20,26 @@ const maxNumberMutsIfGuided = 4
 func CreateMutations(guided bool) {
 	// add new mutations based on GFuzz select
 	if isInterestingSelect() {
-		numberMut := numberMutations()
-		if guided {
-			numberMut = min(numberMut, maxNumberMutsIfGuided)
-		}
-		flipProb := getFlipProbability()
-		numMutAdd := createMutationsGFuzz(numberMut, flipProb)
-		log.Infof("Add %d select mutations to queue", numMutAdd)
+		addMutations(guided)
 	} else {
 		log.Info("Add 0 select mutations to queue")
 	}
 }
 
+// addMutations adds the select mutations for GFuzz to the queue
+//
+// Parameter:
+//   - guided bool: if true, the number of mutations is limited for guided fuzzing
+func addMutations(guided bool) {
+	numberMut := numberMutations()
+	if guided {
+		numberMut = min(numberMut, maxNumberMutsIfGuided)
+	}
+	flipProb := getFlipProbability()
+	numMutAdd := createMutationsGFuzz(numberMut, flipProb)
+	log.Infof("Add %d select mutations to queue", numMutAdd)
+}
+
 // Get the probability that a select changes its preferred case
 // It is selected in such a way, that at least one of the selects if flipped
 // with a probability of at least 99%.
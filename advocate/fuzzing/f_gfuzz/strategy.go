@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: strategy.go
+// Brief: GFuzz as fuzzing strategy
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_gfuzz
+
+import (
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/log"
+)
+
+// register GFuzz with and without the use of hb information
+func init() {
+	for _, s := range []*gFuzz{
+		{name: f_base.GFuzz, hb: false},
+		{name: f_base.GFuzzHB, hb: true},
+	} {
+		if err := f_base.RegisterStrategy(s); err != nil {
+			log.Error("Could not register fuzzing strategy: ", err.Error())
+		}
+	}
+}
+
+// gFuzz is the GFuzz strategy, that mutates the preferred cases of selects
+//
+// Fields:
+//   - name string: name of the strategy
+//   - hb bool: if true, the hb information is used to decide whether a run is interesting
+type gFuzz struct {
+	name string
+	hb   bool
+}
+
+// Name returns the name of the strategy
+//
+// Returns:
+//   - string: the name
+func (this *gFuzz) Name() string {
+	return this.name
+}
+
+// Configure sets the mode flags for GFuzz
+func (this *gFuzz) Configure() {
+	f_base.FuzzingModeGFuzz = true
+	if this.hb {
+		f_base.UseHBInfoFuzzing = true
+	}
+}
+
+// ParseTrace collects the channel, pair and select information of the run
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace
+func (this *gFuzz) ParseTrace(tr *trace.Trace) {
+	ParseTrace(tr)
+}
+
+// CreateMutations creates the select mutations and merges the information
+// of the run into the information of all runs
+//
+// Returns:
+//   - []f_base.Mutation: the mutations
+func (this *gFuzz) CreateMutations() []f_base.Mutation {
+	muts := f_base.CollectMutations(func() {
+		addMutations(false)
+	})
+	MergeTraceInfoIntoFileInfo()
+	return muts
+}
+
+// Interesting returns whether the run was interesting for GFuzz
+//
+// Returns:
+//   - bool: true if the run was interesting
+func (this *gFuzz) Interesting() bool {
+	return isInterestingSelect()
+}
+
+// Reset deletes all the data of GFuzz
+func (this *gFuzz) Reset() {
+	ClearDataFull()
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: trace.go
+// Brief: Function to parse the trace and get the information for GFuzz
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_gfuzz
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/control"
+)
+
+var currentTrace *trace.Trace
+
+// ParseTrace parses the trace and records the channel, pair and select
+// information of the run
+//
+// Parameter:
+//   - tr *trace.Trace: The trace to parse
+func ParseTrace(tr *trace.Trace) {
+	ClearDataRun()
+	currentTrace = tr
+
+	for _, routine := range tr.GetTraces() {
+		if control.WasCanceled() {
+			return
+		}
+
+		for _, elem := range routine.Elems() {
+			if control.WasCanceled() {
+				return
+			}
+
+			if f_base.IgnoreFuzzing(elem, false) || !elem.Committed() {
+				continue
+			}
+
+			switch e := elem.(type) {
+			case *trace.ElementAlloc:
+				parseNew(e)
+			case *trace.ElementChannel:
+				parseChannelOp(e, -2) // -2: not part of select
+			case *trace.ElementSelect:
+				parseSelectOp(e)
+			}
+		}
+	}
+
+	if control.WasCanceled() {
+		return
+	}
+
+	SortSelects()
+
+	NumberSelectCasesWithPartner = a_base.NumberSelectCasesWithPartner
+}
+
+// Parse a new elem element.
+// For now only channels are considered
+// Add the corresponding info into FuzzingChannel
+func parseNew(elem *trace.ElementAlloc) {
+	globalID := currentTrace.GetObjectIdentity(elem.ObjID())
+	if globalID == "" {
+		globalID = elem.Pos().String()
+	}
+
+	fuzzingElem := FuzzingChannel{
+		GlobalID:  globalID,
+		LocalID:   elem.ObjID(),
+		CloseInfo: Never,
+		QSize:     elem.GetNum(),
+		MaxQCount: 0,
+	}
+
+	ChannelInfoTrace[fuzzingElem.LocalID] = fuzzingElem
+}
+
+// Parse a channel operations.
+// If the operation is a close, update the data in channelInfoTrace
+// If it is an send, add it to pairInfoTrace
+// If it is an recv, it is either tPost = 0 (ignore) or will be handled by the send
+// selID is the case id if it is a select case, -2 otherwise
+func parseChannelOp(elem *trace.ElementChannel, selID int) {
+	op := elem.Type(true)
+
+	// close -> update channelInfoTrace
+	switch op {
+	case trace.ChannelClose:
+		e := ChannelInfoTrace[elem.ObjID()]
+		e.CloseInfo = Always // before is always unknown
+		ChannelInfoTrace[elem.ObjID()] = e
+		NumberClose++
+	case trace.ChannelSend:
+		if !elem.Committed() {
+			return
+		}
+
+		recv := elem.GetPartner()
+		chanID := elem.ObjID()
+
+		if recv != nil {
+			sendPos := elem.Pos().String()
+			recvPos := recv.Pos().String()
+			key := sendPos + "-" + recvPos
+
+			// if receive is a select case
+			selIDRecv := -2
+			selRecv := recv.GetSelect()
+			if selRecv != nil {
+				selIDRecv = selRecv.GetChosenIndex()
+			}
+
+			if e, ok := PairInfoTrace[key]; ok {
+				e.Com++
+				PairInfoTrace[key] = e
+			} else {
+				fp := FuzzingPair{
+					ChanID:  chanID,
+					Com:     1,
+					SendSel: selID,
+					RecvSel: selIDRecv,
+				}
+				PairInfoTrace[key] = fp
+			}
+		}
+
+		channelNew := ChannelInfoTrace[chanID]
+		channelNew.MaxQCount = max(channelNew.MaxQCount, elem.GetQCount())
+	}
+}
+
+// Parse a select operation in the trace for fuzzing
+//
+// Parameter:
+//   - elem *analysis.TraceElementSelect: the select element
+func parseSelectOp(elem *trace.ElementSelect) {
+	AddFuzzingSelect(elem)
+
+	if elem.GetChosenDefault() {
+		return
+	}
+	parseChannelOp(elem.GetChosenCase(), elem.GetChosenIndex())
+}
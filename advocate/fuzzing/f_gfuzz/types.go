@@ -33,7 +33,7 @@ type FuzzingPair struct {
 //
 //	has ever been created,
 //
-//	 - GlobalId: file:line of creation with new
+//	 - GlobalId: stable identity of the channel, see trace.ComputeObjectIdentities
 //	 - LocalId: id in this run
 //	 - CLoseInfo whether the channel has always/never/sometimes been closed
 //	 - qSize: buffer size of the channel
@@ -13,6 +13,7 @@ import (
 	"advocate/analysis/a_base"
 	"advocate/analysis/hb/a_concurrent"
 	"advocate/fuzzing/f_base"
+	"advocate/trace"
 	"advocate/utils/settings"
 	"math/rand"
 )
@@ -35,7 +36,12 @@ func startChains(num int) []f_base.Constraint {
 			return res
 		}
 
-		top := []f_base.ElemWithQual{}
+		// start with the operations found by the static analysis, if any
+		top := f_base.StaticSeeds((num+1)/2, sameElem)
+		seeded := make(map[trace.Element]struct{})
+		for _, e := range top {
+			seeded[e.Elem] = struct{}{}
+		}
 
 		for i := 0; i < 1000; i++ {
 			key := rand.Intn(len(routines)) + 1
@@ -48,6 +54,10 @@ func startChains(num int) []f_base.Constraint {
 			ind := rand.Intn(routine.Len())
 			elem := routine.At(ind)
 
+			if _, ok := seeded[elem]; ok {
+				continue
+			}
+
 			if !f_base.CanBeAddedToConstraint(elem) {
 				continue
 			}
@@ -118,8 +128,24 @@ func startChains(num int) []f_base.Constraint {
 			}
 		}
 	} else {
+		// start with the pairs at operations found by the static analysis, if any
+		for elem1, rel := range rel2 {
+			if len(res) >= (num+1)/2 {
+				break
+			}
+			if f_base.StaticWeight(elem1) == 0 {
+				continue
+			}
+			for elem2 := range rel {
+				c := f_base.NewConstraint()
+				c.Add(elem1, elem2)
+				res = append(res, c)
+				break
+			}
+		}
+
 		// start with two random elements in rel2
-		i := 0
+		i := len(res)
 		for elem1, rel := range rel2 {
 			for elem2 := range rel {
 				c := f_base.NewConstraint()
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: strategy.go
+// Brief: GoPie and GoCR as fuzzing strategies
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_gopie
+
+import (
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/log"
+)
+
+// register the original GoPie and the improved versions GoCR and GoCRHB
+func init() {
+	for _, s := range []*goPie{
+		{name: f_base.GoPie, improved: false},
+		{name: f_base.GoCR, improved: true},
+		{name: f_base.GoCRHB, improved: true},
+	} {
+		if err := f_base.RegisterStrategy(s); err != nil {
+			log.Error("Could not register fuzzing strategy: ", err.Error())
+		}
+	}
+}
+
+// goPie is the GoPie strategy, that mutates scheduling chains
+//
+// Fields:
+//   - name string: name of the strategy
+//   - improved bool: if true, the improved version with hb information is used
+type goPie struct {
+	name     string
+	improved bool
+}
+
+// Name returns the name of the strategy
+//
+// Returns:
+//   - string: the name
+func (this *goPie) Name() string {
+	return this.name
+}
+
+// Configure sets the mode flags for GoPie
+func (this *goPie) Configure() {
+	f_base.FuzzingModeGoPie = true
+	if this.improved {
+		f_base.FuzzingModeGoCRHBPlus = true
+		f_base.UseHBInfoFuzzing = true
+	}
+}
+
+// ParseTrace computes the scheduling chains and relations of the run
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace
+func (this *goPie) ParseTrace(tr *trace.Trace) {
+	ParseTrace(tr)
+}
+
+// CreateMutations mutates the scheduling chains of the run
+//
+// Returns:
+//   - []f_base.Mutation: the mutations
+func (this *goPie) CreateMutations() []f_base.Mutation {
+	return f_base.CollectMutations(func() {
+		if err := CreateMutations(f_base.LastMutation.MutPie); err != nil {
+			log.Error("Failed to create GoPie mutations: ", err.Error())
+		}
+	})
+}
+
+// Interesting returns true, GoPie mutates every run. The number of
+// mutations is given by the energy of the run.
+//
+// Returns:
+//   - bool: true
+func (this *goPie) Interesting() bool {
+	return true
+}
+
+// Reset deletes all the GoPie data
+func (this *goPie) Reset() {
+	ClearData()
+}
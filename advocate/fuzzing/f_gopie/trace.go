@@ -0,0 +1,64 @@
+// Copyright (c) 2024 Erik Kassubek
+//
+// File: trace.go
+// Brief: Function to parse the trace and get the information for GoPie
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_gopie
+
+import (
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/control"
+)
+
+// ParseTrace parses the trace and computes the scheduling chains and, if no
+// hb information is used, the relations of GoPie
+//
+// Parameter:
+//   - tr *trace.Trace: The trace to parse
+func ParseTrace(tr *trace.Trace) {
+	ClearDataRun()
+
+	SchedulingChains = make([]f_base.Constraint, 0)
+	CurrentChain = f_base.NewConstraint()
+	LastRoutine = -1
+
+	for _, routine := range tr.GetTraces() {
+		if control.WasCanceled() {
+			return
+		}
+
+		CalculateRelRule1(routine)
+
+		for _, elem := range routine.Elems() {
+			if control.WasCanceled() {
+				return
+			}
+
+			if f_base.IgnoreFuzzing(elem, false) {
+				continue
+			}
+
+			if !f_base.UseHBInfoFuzzing && f_base.CanBeAddedToConstraint(elem) {
+				CalculateRelRule2AddElem(elem)
+			}
+		}
+	}
+
+	if CurrentChain.Len() != 0 {
+		SchedulingChains = append(SchedulingChains, CurrentChain)
+		CurrentChain = f_base.NewConstraint()
+	}
+
+	if !f_base.UseHBInfoFuzzing {
+		CalculateRelRule2And4()
+		if control.WasCanceled() {
+			return
+		}
+		CalculateRelRule3()
+	}
+}
@@ -36,9 +36,13 @@ func startConstraint(num, length int) []f_base.Constraint {
 		return res
 	}
 
-	top := []f_base.ElemWithQual{}
+	// start with the operations found by the static analysis, if any
+	top := f_base.StaticSeeds((num+1)/2, true)
 
 	alreadyAdded := make(map[int]struct{})
+	for _, e := range top {
+		alreadyAdded[e.Elem.T(trace.Commit)] = struct{}{}
+	}
 
 	for i := 0; i < 1000; i++ {
 		key := rand.Intn(len(routines)) + 1
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: strategy.go
+// Brief: Guided fuzzing as fuzzing strategy
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package f_roc
+
+import (
+	"advocate/analysis/a_base"
+	"advocate/fuzzing/f_base"
+	"advocate/trace"
+	"advocate/utils/log"
+)
+
+func init() {
+	if err := f_base.RegisterStrategy(&guided{}); err != nil {
+		log.Error("Could not register fuzzing strategy: ", err.Error())
+	}
+}
+
+// guided is the hb guided fuzzing strategy
+type guided struct{}
+
+// Name returns the name of the strategy
+//
+// Returns:
+//   - string: the name
+func (this *guided) Name() string {
+	return f_base.Guided
+}
+
+// Configure sets the mode flags for guided fuzzing
+func (this *guided) Configure() {
+	f_base.FuzzingModeGuided = true
+	f_base.UseHBInfoFuzzing = true
+}
+
+// ParseTrace does nothing, guided fuzzing uses the results of the analysis
+//
+// Parameter:
+//   - tr *trace.Trace: the recorded trace
+func (this *guided) ParseTrace(tr *trace.Trace) {}
+
+// CreateMutations creates the guided mutations
+//
+// Returns:
+//   - []f_base.Mutation: the mutations
+func (this *guided) CreateMutations() []f_base.Mutation {
+	return f_base.CollectMutations(CreateMutations)
+}
+
+// Interesting returns whether the run was interesting. Runs where the
+// mutation could not be fully satisfied are dropped.
+//
+// Returns:
+//   - bool: false if a replay timeout happened
+func (this *guided) Interesting() bool {
+	return !a_base.GetTimeoutHappened(false)
+}
+
+// Reset does nothing, the data of guided fuzzing is cleared for each run
+func (this *guided) Reset() {}
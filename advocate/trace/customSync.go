@@ -0,0 +1,371 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: customSync.go
+// Brief: Trace element for user declared releases and acquires recorded with
+//    advocatego.Release and advocatego.Acquire
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+import (
+	"advocate/analysis/hb/a_clock"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ========================================================
+// MARK: Data
+// ========================================================
+
+// ElementCustomSync is a trace element for a user declared release or
+// acquire on a key. All operations before a release happen before all
+// operations after a later acquire on the same key.
+// Fields:
+//   - t int: The timestamp of the release or acquire
+//   - op OperationType: CustomRelease or CustomAcquire
+//   - objId int: id of the key
+//   - pos Position: code position of the operation
+//   - ci *concInfo: concurrency info
+//   - function *ElementFunc: the function the operation was executed in
+type ElementCustomSync struct {
+	ElementBase
+
+	t        int
+	op       OperationType
+	objId    int
+	pos      Position
+	ci       *concInfo
+	function *ElementFunc
+}
+
+// ========================================================
+// MARK: Constructor
+// ========================================================
+
+// AddTraceElementCustomSync adds a user declared release or acquire to the main trace
+//
+// Parameter:
+//   - routine int: The routine id
+//   - t string: The timestamp of the event
+//   - op string: R for release, A for acquire
+//   - id string: id of the key
+//   - pos string: The position of the operation in the code
+func (this *Trace) AddTraceElementCustomSync(routine int, t, op, id, pos string) error {
+	tInt, err := strconv.Atoi(t)
+	if err != nil {
+		return errors.New("t is not an integer")
+	}
+
+	var o OperationType
+	switch op {
+	case "R":
+		o = CustomRelease
+	case "A":
+		o = CustomAcquire
+	default:
+		return errors.New("op is not a valid custom sync operation")
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.New("id is not an integer")
+	}
+
+	file, line, err := PosFromPosString(pos)
+	if err != nil {
+		return err
+	}
+
+	elem := ElementCustomSync{
+		ElementBase: this.newElementBase(routine),
+		t:           tInt,
+		op:          o,
+		objId:       idInt,
+		pos:         newPosition(file, line),
+		ci:          newConcInfo(),
+		function:    getLastCall(routine),
+	}
+
+	this.AddElement(&elem)
+
+	return nil
+}
+
+// ========================================================
+// MARK: ID
+// ========================================================
+
+// ObjID returns the id of the key
+//
+// Returns:
+//   - int: The id of the key
+func (this *ElementCustomSync) ObjID() int {
+	return this.objId
+}
+
+// ========================================================
+// MARK: Index
+// ========================================================
+
+// Routine returns the routine ID of the element.
+//
+// Returns:
+//   - int: The routine of the element
+func (this *ElementCustomSync) Routine() int {
+	return this.routine
+}
+
+// TraceIndex returns trace local index of the element in the trace
+//
+// Returns:
+//   - int: the routine id of the element
+//   - int: The trace local index of the element in the trace
+func (this *ElementCustomSync) TraceIndex() (int, int) {
+	return this.routine, this.index
+}
+
+// ========================================================
+// MARK: Operation
+// ========================================================
+
+// Type returns the object type
+//
+// Parameter:
+//   - operation bool: if true get the operation code, otherwise only the primitive code
+//
+// Returns:
+//   - OperationType: the object type
+func (this *ElementCustomSync) Type(operation bool) OperationType {
+	if !operation {
+		return CustomSync
+	}
+
+	return this.op
+}
+
+// IsRelease returns if the operation is a release
+//
+// Returns:
+//   - bool: true for CustomRelease, false for CustomAcquire
+func (this *ElementCustomSync) IsRelease() bool {
+	return this.op == CustomRelease
+}
+
+// ========================================================
+// MARK: Timestamps
+// ========================================================
+
+// T returns the timestamp of the element
+//
+// Returns:
+//   - int: The timestamp of the element
+func (this *ElementCustomSync) T(_ timeType) int {
+	return this.t
+}
+
+// SetT sets the timestamp of the element
+//
+// Parameter:
+//   - time int: The timestamp of the element
+func (this *ElementCustomSync) SetT(_ timeType, tSort int) {
+	this.t = tSort
+}
+
+// SetTWithoutNotExecuted set the timer, that is used for the sorting of the trace, only if the original
+// value was not 0
+//
+// Parameter:
+//   - tSort int: The timer of the element
+func (this *ElementCustomSync) SetTWithoutNotExecuted(tSort int) {
+	if this.t == 0 {
+		return
+	}
+	this.t = tSort
+}
+
+// Committed returns if the operation was committed
+//
+// Returns:
+//   - bool: always true, the operations do not block
+func (this *ElementCustomSync) Committed() bool {
+	return true
+}
+
+// ========================================================
+// MARK: Position
+// ========================================================
+
+// Pos returns the position of the operation
+//
+// Returns:
+//   - position: the position
+func (this *ElementCustomSync) Pos() Position {
+	return this.pos
+}
+
+// File returns the file of the element
+//
+// Returns:
+//   - The file of the element
+func (this *ElementCustomSync) File() string {
+	return this.pos.file
+}
+
+// Line returns the line of the element
+//
+// Returns:
+//   - The line of the element
+func (this *ElementCustomSync) Line() int {
+	return this.pos.line
+}
+
+// ========================================================
+// MARK: Equal
+// ========================================================
+
+// IsEqual checks if an trace element is equal to this element
+//
+// Parameter:
+//   - elem TraceElement: The element to check against
+//
+// Returns:
+//   - bool: true if it is the same operation, false otherwise
+func (this *ElementCustomSync) IsEqual(elem Element) bool {
+	return this.id == elem.ID()
+}
+
+// IsSameElement returns checks if the element on which the at and elem
+// where performed are the same
+//
+// Parameter:
+//   - elem Element: the element to compare against
+//
+// Returns:
+//   - bool: true if both are custom syncs on the same key
+func (this *ElementCustomSync) IsSameElement(elem Element) bool {
+	_, ok := elem.(*ElementCustomSync)
+	return ok && this.objId == elem.ObjID()
+}
+
+// ========================================================
+// MARK: String
+// ========================================================
+
+// String returns the simple string representation of the element
+//
+// Returns:
+//   - string: The simple string representation of the element
+func (this *ElementCustomSync) String() string {
+	opStr := "A"
+	if this.op == CustomRelease {
+		opStr = "R"
+	}
+
+	return fmt.Sprintf("K,%d,%s,%d,%s", this.t, opStr, this.objId, this.Pos().String())
+}
+
+// String returns the simple string representation of the element with leading routine
+//
+// Returns:
+//   - string: The simple string representation of the element with leading routine
+func (this *ElementCustomSync) StringDebug() string {
+	routine := fmt.Sprintf("%4d", this.Routine())
+	if this.ElementBase.init {
+		routine = "   *"
+	}
+	return fmt.Sprintf("%s -> %s", routine, this.String())
+}
+
+// ========================================================
+// MARK: Function
+// ========================================================
+
+func (this *ElementCustomSync) Function() *ElementFunc {
+	return this.function
+}
+
+// ========================================================
+// MARK: Concurrent
+// ========================================================
+
+// Vc sets the vector clock
+//
+// Parameter:
+//   - weak bool: set the weak wv
+//   - cl *clock.VectorClock: the vector clock
+func (this *ElementCustomSync) Vc(weak a_clock.VcType, cl *a_clock.VectorClock) {
+	this.ci.setVC(weak, cl)
+}
+
+// GetVC returns the vector clock of the element
+//
+// Parameter:
+//   - weak bool: get the weak
+//
+// Returns:
+//   - VectorClock: The vector clock of the element
+func (this *ElementCustomSync) GetVC(weak a_clock.VcType) *a_clock.VectorClock {
+	return this.ci.getVC(weak)
+}
+
+// NumberConcurrent returns the number of elements concurrent to the element
+// If not set, it returns -1
+func (this *ElementCustomSync) NumberConcurrent(_, _ bool) int {
+	return -1
+}
+
+// SetNumberConcurrent sets the number of concurrent elements
+func (this *ElementCustomSync) SetNumberConcurrent(_ int, _, _ bool) {}
+
+// ========================================================
+// MARK: Replay
+// ========================================================
+
+// ReplayID is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - string: empty string
+func (this *ElementCustomSync) ReplayID() string {
+	return ""
+}
+
+// ========================================================
+// MARK: Copy
+// ========================================================
+
+// Copy the element
+//
+// Parameter:
+//   - mapping map[string]Element: map containing all already copied elements.
+//   - keep bool: if true, keep vc and order information
+//
+// Returns:
+//   - TraceElement: The copy of the element
+func (this *ElementCustomSync) Copy(mapping map[int]Element, keep bool) Element {
+	ci := newConcInfo()
+	if keep {
+		ci = this.ci.copy()
+	}
+
+	return &ElementCustomSync{
+		ElementBase: this.ElementBase.Copy(),
+		t:           this.t,
+		op:          this.op,
+		objId:       this.objId,
+		pos:         this.pos.copy(),
+		ci:          ci,
+		function:    this.function.CopyFunc(mapping, keep),
+	}
+}
+
+// ========================================================
+// MARK: Valid
+// ========================================================
+
+func (this *ElementCustomSync) IsValid() bool {
+	return this != nil
+}
@@ -64,7 +64,7 @@ type Element interface {
 
 func IsOp(elem Element) bool {
 	switch elem.(type) {
-	case *ElementAlloc, *ElementReplay, *ElementRoutineEnd:
+	case *ElementAlloc, *ElementReplay, *ElementRoutineEnd, *ElementSubtest, *ElementSummary, *ElementEvent, *ElementCustomSync:
 		return false
 	}
 
@@ -190,6 +190,22 @@ const (
 	ControllIf     OperationType = "II"
 	ControllSwitch OperationType = "IS"
 
+	Subtest      OperationType = "T"
+	SubtestStart OperationType = "TS"
+	SubtestEnd   OperationType = "TE"
+
+	Summary        OperationType = "H"
+	SummaryRelease OperationType = "HR"
+	SummaryAcquire OperationType = "HA"
+	SummaryBoth    OperationType = "HB"
+
+	Event       OperationType = "V"
+	EventMarker OperationType = "VM"
+
+	CustomSync    OperationType = "K"
+	CustomRelease OperationType = "KR"
+	CustomAcquire OperationType = "KA"
+
 	UnknownOperation OperationType = "XX"
 )
 
@@ -224,6 +240,14 @@ func GetElemTypeFromObjectType(ob OperationType) OperationType {
 		return Wait
 	case Func, FuncCall, FuncReturn:
 		return Func
+	case Subtest, SubtestStart, SubtestEnd:
+		return Subtest
+	case Summary, SummaryRelease, SummaryAcquire, SummaryBoth:
+		return Summary
+	case Event, EventMarker:
+		return Event
+	case CustomSync, CustomRelease, CustomAcquire:
+		return CustomSync
 	default:
 		return None
 	}
@@ -0,0 +1,397 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: event.go
+// Brief: Trace element for user event markers recorded with advocatego.Event
+//    and the attribution of elements to the phase given by the last event
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+import (
+	"advocate/analysis/hb/a_clock"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ========================================================
+// MARK: Data
+// ========================================================
+
+// ElementEvent is a trace element for a user event marker
+// Fields:
+//   - t int: The timestamp of the event
+//   - name string: name of the event
+//   - kv string: key value pairs of the event in the form k=v;k=v
+//   - pos Position: code position of the event
+//   - function *ElementFunc: the function the event was recorded in
+type ElementEvent struct {
+	ElementBase
+
+	t        int
+	name     string
+	kv       string
+	pos      Position
+	function *ElementFunc
+}
+
+// ========================================================
+// MARK: Constructor
+// ========================================================
+
+// AddTraceElementEvent adds a user event marker to the main trace
+//
+// Parameter:
+//   - routine int: The routine id
+//   - t string: The timestamp of the event
+//   - name string: name of the event
+//   - pos string: The position of the event in the code
+//   - kv string: key value pairs of the event in the form k=v;k=v
+func (this *Trace) AddTraceElementEvent(routine int, t, name, pos, kv string) error {
+	tInt, err := strconv.Atoi(t)
+	if err != nil {
+		return errors.New("t is not an integer")
+	}
+
+	file, line, err := PosFromPosString(pos)
+	if err != nil {
+		return err
+	}
+
+	elem := ElementEvent{
+		ElementBase: this.newElementBase(routine),
+		t:           tInt,
+		name:        name,
+		kv:          kv,
+		pos:         newPosition(file, line),
+		function:    getLastCall(routine),
+	}
+
+	this.AddElement(&elem)
+
+	return nil
+}
+
+// ========================================================
+// MARK: ID
+// ========================================================
+
+// ObjID is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - int: -1
+func (this *ElementEvent) ObjID() int {
+	return -1
+}
+
+// ========================================================
+// MARK: Index
+// ========================================================
+
+// Routine returns the routine ID of the element.
+//
+// Returns:
+//   - int: The routine of the element
+func (this *ElementEvent) Routine() int {
+	return this.routine
+}
+
+// TraceIndex returns trace local index of the element in the trace
+//
+// Returns:
+//   - int: the routine id of the element
+//   - int: The trace local index of the element in the trace
+func (this *ElementEvent) TraceIndex() (int, int) {
+	return this.routine, this.index
+}
+
+// ========================================================
+// MARK: Operation
+// ========================================================
+
+// Type returns the object type
+//
+// Parameter:
+//   - operation bool: if true get the operation code, otherwise only the primitive code
+//
+// Returns:
+//   - OperationType: the object type
+func (this *ElementEvent) Type(operation bool) OperationType {
+	if !operation {
+		return Event
+	}
+
+	return EventMarker
+}
+
+// ========================================================
+// MARK: Timestamps
+// ========================================================
+
+// T returns the timestamp of the element
+//
+// Returns:
+//   - int: The timestamp of the element
+func (this *ElementEvent) T(_ timeType) int {
+	return this.t
+}
+
+// SetT sets the timestamp of the element
+//
+// Parameter:
+//   - time int: The timestamp of the element
+func (this *ElementEvent) SetT(_ timeType, tSort int) {
+	this.t = tSort
+}
+
+// SetTWithoutNotExecuted set the timer, that is used for the sorting of the trace, only if the original
+// value was not 0
+//
+// Parameter:
+//   - tSort int: The timer of the element
+func (this *ElementEvent) SetTWithoutNotExecuted(tSort int) {
+	if this.t == 0 {
+		return
+	}
+	this.t = tSort
+}
+
+// Committed returns if the operation was committed (tPost != 0)
+//
+// Returns:
+//   - bool: true if committed, false if not
+func (this *ElementEvent) Committed() bool {
+	return true
+}
+
+// ========================================================
+// MARK: Position
+// ========================================================
+
+// Pos returns the position of the event
+//
+// Returns:
+//   - position: the position
+func (this *ElementEvent) Pos() Position {
+	return this.pos
+}
+
+// File returns the file of the element
+//
+// Returns:
+//   - The file of the element
+func (this *ElementEvent) File() string {
+	return this.pos.file
+}
+
+// Line returns the line of the element
+//
+// Returns:
+//   - The line of the element
+func (this *ElementEvent) Line() int {
+	return this.pos.line
+}
+
+// ========================================================
+// MARK: Equal
+// ========================================================
+
+// IsEqual checks if an trace element is equal to this element
+//
+// Parameter:
+//   - elem TraceElement: The element to check against
+//
+// Returns:
+//   - bool: true if it is the same operation, false otherwise
+func (this *ElementEvent) IsEqual(elem Element) bool {
+	return this.id == elem.ID()
+}
+
+// IsSameElement returns checks if the element on which the at and elem
+// where performed are the same
+//
+// Parameter:
+//   - elem Element: the element to compare against
+//
+// Returns:
+//   - bool: always false
+func (this *ElementEvent) IsSameElement(elem Element) bool {
+	return false
+}
+
+// ========================================================
+// MARK: String
+// ========================================================
+
+// String returns the simple string representation of the element
+//
+// Returns:
+//   - string: The simple string representation of the element
+func (this *ElementEvent) String() string {
+	return fmt.Sprintf("V,%d,%s,%s,%s", this.t, this.name, this.Pos().String(), this.kv)
+}
+
+// String returns the simple string representation of the element with leading routine
+//
+// Returns:
+//   - string: The simple string representation of the element with leading routine
+func (this *ElementEvent) StringDebug() string {
+	routine := fmt.Sprintf("%4d", this.Routine())
+	if this.ElementBase.init {
+		routine = "   *"
+	}
+	return fmt.Sprintf("%s -> %s", routine, this.String())
+}
+
+// ========================================================
+// MARK: Function
+// ========================================================
+
+func (this *ElementEvent) Function() *ElementFunc {
+	return this.function
+}
+
+// ========================================================
+// MARK: Concurrent
+// ========================================================
+
+// Vc is a dummy function to implement the traceElement interface
+func (this *ElementEvent) Vc(_ a_clock.VcType, _ *a_clock.VectorClock) {
+}
+
+// GetVC is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - VectorClock: empty vector clock
+func (this *ElementEvent) GetVC(_ a_clock.VcType) *a_clock.VectorClock {
+	return &a_clock.VectorClock{}
+}
+
+// NumberConcurrent returns the number of elements concurrent to the element
+// If not set, it returns -1
+func (this *ElementEvent) NumberConcurrent(_, _ bool) int {
+	return -1
+}
+
+// SetNumberConcurrent sets the number of concurrent elements
+func (this *ElementEvent) SetNumberConcurrent(_ int, _, _ bool) {}
+
+// ========================================================
+// MARK: Replay
+// ========================================================
+
+// ReplayID is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - string: empty string
+func (this *ElementEvent) ReplayID() string {
+	return ""
+}
+
+// ========================================================
+// MARK: Copy
+// ========================================================
+
+// Copy the element
+//
+// Parameter:
+//   - mapping map[string]Element: map containing all already copied elements.
+//   - keep bool: if true, keep vc and order information
+//
+// Returns:
+//   - TraceElement: The copy of the element
+func (this *ElementEvent) Copy(mapping map[int]Element, keep bool) Element {
+	return &ElementEvent{
+		ElementBase: this.ElementBase.Copy(),
+		t:           this.t,
+		name:        this.name,
+		kv:          this.kv,
+		pos:         this.pos.copy(),
+		function:    this.function.CopyFunc(mapping, keep),
+	}
+}
+
+// ========================================================
+// MARK: Valid
+// ========================================================
+
+func (this *ElementEvent) IsValid() bool {
+	return this != nil
+}
+
+// ========================================================
+// MARK: Others
+// ========================================================
+
+// Name returns the name of the event
+//
+// Returns:
+//   - string: the name
+func (this *ElementEvent) Name() string {
+	return this.name
+}
+
+// KeyValues returns the key value pairs of the event
+//
+// Returns:
+//   - map[string]string: key -> value
+func (this *ElementEvent) KeyValues() map[string]string {
+	res := make(map[string]string)
+	if this.kv == "" {
+		return res
+	}
+
+	for pair := range strings.SplitSeq(this.kv, ";") {
+		k, v, _ := strings.Cut(pair, "=")
+		res[k] = v
+	}
+	return res
+}
+
+// ========================================================
+// MARK: Attribution
+// ========================================================
+
+// PhaseAt returns the name of the last event recorded in a routine before
+// the given time. A routine without an event before t inherits the phase of
+// the routine that created it at the time of the fork.
+//
+// Parameter:
+//   - routine int: the routine
+//   - t int: the time, math.MaxInt for the end of the routine
+//
+// Returns:
+//   - string: the name of the last event, empty if there is none
+func (this *Trace) PhaseAt(routine, t int) string {
+	rout, ok := this.routines[routine]
+	if !ok {
+		return ""
+	}
+
+	phase := ""
+	for _, elem := range rout.Elems() {
+		if t != math.MaxInt && elem.T(Sorting) > t {
+			break
+		}
+
+		if ev, ok := elem.(*ElementEvent); ok {
+			phase = ev.name
+		}
+	}
+
+	if phase != "" {
+		return phase
+	}
+
+	fork, ok := this.forks[routine]
+	if !ok || fork.Routine() == routine {
+		return ""
+	}
+
+	return this.PhaseAt(fork.Routine(), fork.T(Sorting))
+}
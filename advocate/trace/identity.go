@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: identity.go
+// Brief: Identities of routines and objects that are independent of the
+//    concrete execution, based on fork and allocation positions
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ShortPos returns the position of an element in the form file:line, where
+// file is only the name of the file without its folder. In contrast to the
+// full path, it is the same on all machines.
+//
+// Parameter:
+//   - elem Element: the element
+//
+// Returns:
+//   - string: the position
+func ShortPos(elem Element) string {
+	return fmt.Sprintf("%s:%d", filepath.Base(elem.File()), elem.Line())
+}
+
+// ForkPaths computes for each routine the path of forks that created it.
+// A routine that was not created by a recorded fork is identified by its id.
+// Every other routine is identified by the path of its parent and the
+// position and ordinal of the fork in its parent, e.g.
+// 1>main.go:12@0>main.go:20@1 for the second routine created at main.go:20
+// by the first routine created at main.go:12 by the main routine.
+//
+// Returns:
+//   - map[int]string: routine id -> fork path
+func (this *Trace) ForkPaths() map[int]string {
+	// child routine -> (parent routine, fork position and ordinal)
+	parent := make(map[int]int)
+	forkKey := make(map[int]string)
+	for id, routine := range this.routines {
+		ordinal := make(map[string]int)
+		for _, elem := range routine.Elems() {
+			fork, ok := elem.(*ElementFork)
+			if !ok {
+				continue
+			}
+			pos := ShortPos(fork)
+			forkKey[fork.ObjID()] = pos + "@" + strconv.Itoa(ordinal[pos])
+			parent[fork.ObjID()] = id
+			ordinal[pos]++
+		}
+	}
+
+	res := make(map[int]string)
+	for id := range this.routines {
+		res[id] = forkPath(id, parent, forkKey, make(map[int]struct{}))
+	}
+
+	return res
+}
+
+// forkPath computes the fork path of a routine
+//
+// Parameter:
+//   - id int: the routine id
+//   - parent map[int]int: child -> parent routine
+//   - forkKey map[int]string: child -> fork position and ordinal
+//   - visited map[int]struct{}: visited routines, to prevent cycles in corrupted traces
+//
+// Returns:
+//   - string: the fork path
+func forkPath(id int, parent map[int]int, forkKey map[int]string, visited map[int]struct{}) string {
+	p, ok := parent[id]
+	if _, seen := visited[id]; !ok || seen {
+		return strconv.Itoa(id)
+	}
+	visited[id] = struct{}{}
+	return forkPath(p, parent, forkKey, visited) + ">" + forkKey[id]
+}
+
+// ComputeObjectIdentities computes the stable identity of each object in the
+// trace, e.g. channels, mutexes, wait groups, conds and onces. The identities
+// are recorded by the runtime in the same way. They are only computed here
+// for traces that were recorded without the identity table. The object ids
+// depend on the order in which the routines first use the objects. The
+// identity only depends on the allocation site of the object, the fork path
+// of the routine that created it and the number of objects created before at
+// the same site by the same routine. It has the form site/ordinal/hash, e.g.
+// main.go:17/0/3fa9c1d2, where hash is the 32 bit FNV-1a hash of the fork path.
+// For objects without a recorded allocation, e.g. onces or mutexes in
+// structs, the first operation on the object is used as allocation.
+// Must be called after the trace has been read completely.
+func (this *Trace) ComputeObjectIdentities() {
+	this.identities = make(map[int]string)
+
+	forkPaths := this.ForkPaths()
+
+	// for objects without recorded allocation, the first operation on the object
+	first := make(map[int]Element)
+	for _, routine := range this.routines {
+		for _, elem := range routine.Elems() {
+			alloc, ok := this.allocs[elem.ObjID()]
+			if !ok || alloc.num != -1 || elem == alloc {
+				continue
+			}
+			if f, ok := first[elem.ObjID()]; !ok || elem.T(Sorting) < f.T(Sorting) {
+				first[elem.ObjID()] = elem
+			}
+		}
+	}
+
+	// fork path and site -> created objects
+	sites := make(map[string][]Element)
+	for id, alloc := range this.allocs {
+		var elem Element = alloc
+		if f, ok := first[id]; ok {
+			elem = f
+		}
+		key := forkPaths[elem.Routine()] + "|" + ShortPos(elem)
+		sites[key] = append(sites[key], elem)
+	}
+
+	for _, elems := range sites {
+		sort.Slice(elems, func(i, j int) bool {
+			if elems[i].T(Sorting) != elems[j].T(Sorting) {
+				return elems[i].T(Sorting) < elems[j].T(Sorting)
+			}
+			return elems[i].ObjID() < elems[j].ObjID()
+		})
+
+		for ordinal, elem := range elems {
+			hash := fnv.New32a()
+			hash.Write([]byte(forkPaths[elem.Routine()]))
+			this.identities[elem.ObjID()] = fmt.Sprintf("%s/%d/%08x",
+				ShortPos(elem), ordinal, hash.Sum32())
+		}
+	}
+}
+
+// GetObjectIdentities returns the stable identities of all objects
+//
+// Returns:
+//   - map[int]string: obj id -> identity, nil if the identities have not been computed
+func (this *Trace) GetObjectIdentities() map[int]string {
+	return this.identities
+}
+
+// SetObjectIdentities sets the stable identities of objects, e.g. the
+// identities recorded with the trace. They replace the identities of those
+// objects that have been set or computed before.
+//
+// Parameter:
+//   - identities map[int]string: obj id -> identity
+func (this *Trace) SetObjectIdentities(identities map[int]string) {
+	if this.identities == nil {
+		this.identities = make(map[int]string)
+	}
+	for id, identity := range identities {
+		this.identities[id] = identity
+	}
+}
+
+// GetObjectIdentity returns the stable identity of an object
+//
+// Parameter:
+//   - objID int: the id of the object in the trace
+//
+// Returns:
+//   - string: the identity, empty if the object has no identity, e.g. for forks
+func (this *Trace) GetObjectIdentity(objID int) string {
+	if this.identities == nil {
+		return ""
+	}
+	return this.identities[objID]
+}
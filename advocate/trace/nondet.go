@@ -0,0 +1,29 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: nondet.go
+// Brief: Recorded results of nondeterministic calls that are not
+//    concurrency operations, e.g. time.Now or math/rand
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+// SetNondetLog sets the recorded results of nondeterministic calls. The
+// lines are not interpreted by the analysis, they are only written into
+// rewritten traces, so that the replay can return the recorded results.
+//
+// Parameter:
+//   - lines []string: the lines of the trace_nondet.log file
+func (this *Trace) SetNondetLog(lines []string) {
+	this.nondet = lines
+}
+
+// GetNondetLog returns the recorded results of nondeterministic calls
+//
+// Returns:
+//   - []string: the lines of the trace_nondet.log file, nil if nothing was recorded
+func (this *Trace) GetNondetLog() []string {
+	return this.nondet
+}
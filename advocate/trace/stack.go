@@ -0,0 +1,163 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: stack.go
+// Brief: Side table with the call stacks recorded for the operations
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StackFrame is a frame of a recorded call stack
+//
+// Fields:
+//   - Function string: name of the function
+//   - File string: file of the frame
+//   - Line int: line of the frame
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// StackTable stores the recorded call stacks. Frames and stacks are
+// interned, the operations refer to their stack by its id.
+//
+// Fields:
+//   - frames map[int]StackFrame: id -> frame
+//   - stacks map[int][]int: id -> ids of the frames, starting with the frame of the operation
+//   - ops map[int]int: tPre of an operation -> id of its stack
+type StackTable struct {
+	frames map[int]StackFrame
+	stacks map[int][]int
+	ops    map[int]int
+}
+
+// NewStackTable creates a new empty stack table
+//
+// Returns:
+//   - *StackTable: the new table
+func NewStackTable() *StackTable {
+	return &StackTable{
+		frames: make(map[int]StackFrame),
+		stacks: make(map[int][]int),
+		ops:    make(map[int]int),
+	}
+}
+
+// String returns the frame in the form [function] ([file]:[line])
+//
+// Returns:
+//   - string: the string representation of the frame
+func (this StackFrame) String() string {
+	return fmt.Sprintf("%s (%s)", this.Function, newPosition(this.File, this.Line).String())
+}
+
+// AddLine adds a line of the trace_stacks.log file to the table. The lines
+// have the form
+//
+//	F,[id],[function],[file:line]
+//	S,[id],[frame id].[frame id]...
+//	E,[tPre],[stack id]
+//
+// Parameter:
+//   - line string: the line
+//
+// Returns:
+//   - error
+func (this *StackTable) AddLine(line string) error {
+	fields := strings.Split(line, ",")
+	if len(fields) < 3 {
+		return fmt.Errorf("invalid stack table line: %s", line)
+	}
+
+	id, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return errors.New("id is not an integer")
+	}
+
+	switch fields[0] {
+	case "F":
+		if len(fields) < 4 {
+			return fmt.Errorf("invalid stack frame: %s", line)
+		}
+		// the function name may contain the separator
+		function := strings.Join(fields[2:len(fields)-1], ",")
+		file, l, err := PosFromPosString(fields[len(fields)-1])
+		if err != nil {
+			return err
+		}
+		this.frames[id] = StackFrame{Function: function, File: file, Line: l}
+	case "S":
+		frames := make([]int, 0)
+		for f := range strings.SplitSeq(fields[2], ".") {
+			frameID, err := strconv.Atoi(f)
+			if err != nil {
+				return errors.New("frame id is not an integer")
+			}
+			frames = append(frames, frameID)
+		}
+		this.stacks[id] = frames
+	case "E":
+		stackID, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return errors.New("stack id is not an integer")
+		}
+		this.ops[id] = stackID
+	default:
+		return fmt.Errorf("unknown stack table line: %s", line)
+	}
+
+	return nil
+}
+
+// SetStackTable sets the recorded call stacks of the trace
+//
+// Parameter:
+//   - stacks *StackTable: the stack table
+func (this *Trace) SetStackTable(stacks *StackTable) {
+	this.stacks = stacks
+}
+
+// HasStacks returns if call stacks have been recorded for the trace
+//
+// Returns:
+//   - bool: true if the trace has a stack table
+func (this *Trace) HasStacks() bool {
+	return this.stacks != nil && len(this.stacks.ops) > 0
+}
+
+// StackAt returns the recorded call stack of an operation
+//
+// Parameter:
+//   - tPre int: tPre of the operation
+//
+// Returns:
+//   - []StackFrame: the frames, starting with the frame of the operation, nil if no stack was recorded
+func (this *Trace) StackAt(tPre int) []StackFrame {
+	if this.stacks == nil {
+		return nil
+	}
+
+	stackID, ok := this.stacks.ops[tPre]
+	if !ok {
+		return nil
+	}
+
+	res := make([]StackFrame, 0, len(this.stacks.stacks[stackID]))
+	for _, frameID := range this.stacks.stacks[stackID] {
+		if frame, ok := this.stacks.frames[frameID]; ok {
+			res = append(res, frame)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,474 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: subtest.go
+// Brief: Trace element for the start and end of subtests (t.Run) and the
+//    attribution of routines and elements to subtests
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+import (
+	"advocate/analysis/hb/a_clock"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ========================================================
+// MARK: Data
+// ========================================================
+
+// ElementSubtest is a trace element for the start or end of a subtest.
+// It is recorded in the routine that runs the subtest.
+// Fields:
+//   - t int: The timestamp of the event
+//   - op OperationType: SubtestStart or SubtestEnd
+//   - name string: full name of the subtest, e.g. TestFoo/case_3
+type ElementSubtest struct {
+	ElementBase
+
+	t    int
+	op   OperationType
+	name string
+}
+
+// ========================================================
+// MARK: Constructor
+// ========================================================
+
+// AddTraceElementSubtest adds a subtest marker to the main trace
+//
+// Parameter:
+//   - routine int: The routine id
+//   - t string: The timestamp of the event
+//   - op string: S for start, E for end
+//   - name string: full name of the subtest
+func (this *Trace) AddTraceElementSubtest(routine int, t, op, name string) error {
+	tInt, err := strconv.Atoi(t)
+	if err != nil {
+		return errors.New("t is not an integer")
+	}
+
+	var o OperationType
+	switch op {
+	case "S":
+		o = SubtestStart
+	case "E":
+		o = SubtestEnd
+	default:
+		return errors.New("op is not a valid subtest operation")
+	}
+
+	elem := ElementSubtest{
+		ElementBase: this.newElementBase(routine),
+		t:           tInt,
+		op:          o,
+		name:        name,
+	}
+
+	this.AddElement(&elem)
+
+	return nil
+}
+
+// ========================================================
+// MARK: ID
+// ========================================================
+
+// ObjID is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - int: -1
+func (this *ElementSubtest) ObjID() int {
+	return -1
+}
+
+// ========================================================
+// MARK: Index
+// ========================================================
+
+// Routine returns the routine ID of the element.
+//
+// Returns:
+//   - int: The routine of the element
+func (this *ElementSubtest) Routine() int {
+	return this.routine
+}
+
+// TraceIndex returns trace local index of the element in the trace
+//
+// Returns:
+//   - int: the routine id of the element
+//   - int: The trace local index of the element in the trace
+func (this *ElementSubtest) TraceIndex() (int, int) {
+	return this.routine, this.index
+}
+
+// ========================================================
+// MARK: Operation
+// ========================================================
+
+// Type returns the object type
+//
+// Parameter:
+//   - operation bool: if true get the operation code, otherwise only the primitive code
+//
+// Returns:
+//   - OperationType: the object type
+func (this *ElementSubtest) Type(operation bool) OperationType {
+	if !operation {
+		return Subtest
+	}
+
+	return this.op
+}
+
+// ========================================================
+// MARK: Timestamps
+// ========================================================
+
+// T returns the timestamp of the element
+//
+// Returns:
+//   - int: The timestamp of the element
+func (this *ElementSubtest) T(_ timeType) int {
+	return this.t
+}
+
+// SetT sets the timestamp of the element
+//
+// Parameter:
+//   - time int: The timestamp of the element
+func (this *ElementSubtest) SetT(_ timeType, tSort int) {
+	this.t = tSort
+}
+
+// SetTWithoutNotExecuted set the timer, that is used for the sorting of the trace, only if the original
+// value was not 0
+//
+// Parameter:
+//   - tSort int: The timer of the element
+func (this *ElementSubtest) SetTWithoutNotExecuted(tSort int) {
+	if this.t == 0 {
+		return
+	}
+	this.t = tSort
+}
+
+// Committed returns if the operation was committed (tPost != 0)
+//
+// Returns:
+//   - bool: true if committed, false if not
+func (this *ElementSubtest) Committed() bool {
+	return true
+}
+
+// ========================================================
+// MARK: Position
+// ========================================================
+
+// Pos is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - position: the position
+func (this *ElementSubtest) Pos() Position {
+	return newPosition("", 0)
+}
+
+// File is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - string: empty string
+func (this *ElementSubtest) File() string {
+	return ""
+}
+
+// Line is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - int: 0
+func (this *ElementSubtest) Line() int {
+	return 0
+}
+
+// ========================================================
+// MARK: Equal
+// ========================================================
+
+// IsEqual checks if an trace element is equal to this element
+//
+// Parameter:
+//   - elem TraceElement: The element to check against
+//
+// Returns:
+//   - bool: true if it is the same operation, false otherwise
+func (this *ElementSubtest) IsEqual(elem Element) bool {
+	return this.id == elem.ID()
+}
+
+// IsSameElement returns checks if the element on which the at and elem
+// where performed are the same
+//
+// Parameter:
+//   - elem Element: the element to compare against
+//
+// Returns:
+//   - bool: always false
+func (this *ElementSubtest) IsSameElement(elem Element) bool {
+	return false
+}
+
+// ========================================================
+// MARK: String
+// ========================================================
+
+// String returns the simple string representation of the element
+//
+// Returns:
+//   - string: The simple string representation of the element
+func (this *ElementSubtest) String() string {
+	opStr := "S"
+	if this.op == SubtestEnd {
+		opStr = "E"
+	}
+	return fmt.Sprintf("T,%d,%s,%s", this.t, opStr, this.name)
+}
+
+// String returns the simple string representation of the element with leading routine
+//
+// Returns:
+//   - string: The simple string representation of the element with leading routine
+func (this *ElementSubtest) StringDebug() string {
+	routine := fmt.Sprintf("%4d", this.Routine())
+	if this.ElementBase.init {
+		routine = "   *"
+	}
+	return fmt.Sprintf("%s -> %s", routine, this.String())
+}
+
+// ========================================================
+// MARK: Function
+// ========================================================
+
+func (this *ElementSubtest) Function() *ElementFunc {
+	return nil
+}
+
+// ========================================================
+// MARK: Concurrent
+// ========================================================
+
+// Vc is a dummy function to implement the traceElement interface
+func (this *ElementSubtest) Vc(_ a_clock.VcType, _ *a_clock.VectorClock) {
+}
+
+// GetVC is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - VectorClock: empty vector clock
+func (this *ElementSubtest) GetVC(_ a_clock.VcType) *a_clock.VectorClock {
+	return &a_clock.VectorClock{}
+}
+
+// NumberConcurrent returns the number of elements concurrent to the element
+// If not set, it returns -1
+func (this *ElementSubtest) NumberConcurrent(_, _ bool) int {
+	return -1
+}
+
+// SetNumberConcurrent sets the number of concurrent elements
+func (this *ElementSubtest) SetNumberConcurrent(_ int, _, _ bool) {}
+
+// ========================================================
+// MARK: Replay
+// ========================================================
+
+// ReplayID is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - string: empty string
+func (this *ElementSubtest) ReplayID() string {
+	return ""
+}
+
+// ========================================================
+// MARK: Copy
+// ========================================================
+
+// Copy the element
+//
+// Parameter:
+//   - mapping map[string]Element: map containing all already copied elements.
+//   - keep bool: if true, keep vc and order information
+//
+// Returns:
+//   - TraceElement: The copy of the element
+func (this *ElementSubtest) Copy(_ map[int]Element, _ bool) Element {
+	return &ElementSubtest{
+		ElementBase: this.ElementBase.Copy(),
+		t:           this.t,
+		op:          this.op,
+		name:        this.name,
+	}
+}
+
+// ========================================================
+// MARK: Valid
+// ========================================================
+
+func (this *ElementSubtest) IsValid() bool {
+	return this != nil
+}
+
+// ========================================================
+// MARK: Others
+// ========================================================
+
+// Name returns the full name of the subtest
+//
+// Returns:
+//   - string: the name
+func (this *ElementSubtest) Name() string {
+	return this.name
+}
+
+// ========================================================
+// MARK: Attribution
+// ========================================================
+
+// SubtestAt returns the subtest that was active in a routine at a given time.
+// A routine belongs to the subtests started in it. Otherwise it belongs to the
+// subtest that was active in the routine that created it, when it was created.
+//
+// Parameter:
+//   - routine int: the routine
+//   - t int: the time, math.MaxInt for the end of the routine
+//
+// Returns:
+//   - string: name of the subtest, empty if the routine is not in a subtest
+func (this *Trace) SubtestAt(routine, t int) string {
+	rout, ok := this.routines[routine]
+	if !ok {
+		return ""
+	}
+
+	active := make([]string, 0)
+	for _, elem := range rout.Elems() {
+		if t != math.MaxInt && elem.T(Sorting) > t {
+			break
+		}
+
+		st, ok := elem.(*ElementSubtest)
+		if !ok {
+			continue
+		}
+
+		if st.op == SubtestStart {
+			active = append(active, st.name)
+		} else if len(active) > 0 {
+			active = active[:len(active)-1]
+		}
+	}
+
+	if len(active) > 0 {
+		return active[len(active)-1]
+	}
+
+	fork, ok := this.forks[routine]
+	if !ok || fork.Routine() == routine {
+		return ""
+	}
+
+	return this.SubtestAt(fork.Routine(), fork.T(Sorting))
+}
+
+// SubtestOfRoutine returns the subtest a routine belongs to. For a routine
+// that runs a subtest this is the first subtest started in it.
+//
+// Parameter:
+//   - routine int: the routine
+//
+// Returns:
+//   - string: name of the subtest, empty if the routine is not in a subtest
+func (this *Trace) SubtestOfRoutine(routine int) string {
+	if name, ok := this.runsSubtest(routine); ok {
+		return name
+	}
+
+	fork, ok := this.forks[routine]
+	if !ok || fork.Routine() == routine {
+		return ""
+	}
+
+	return this.SubtestAt(fork.Routine(), fork.T(Sorting))
+}
+
+// runsSubtest checks if a subtest was started in the routine
+//
+// Parameter:
+//   - routine int: the routine
+//
+// Returns:
+//   - string: name of the first subtest started in the routine
+//   - bool: true if a subtest was started in the routine
+func (this *Trace) runsSubtest(routine int) (string, bool) {
+	rout, ok := this.routines[routine]
+	if !ok {
+		return "", false
+	}
+
+	for _, elem := range rout.Elems() {
+		if st, ok := elem.(*ElementSubtest); ok && st.op == SubtestStart {
+			return st.name, true
+		}
+	}
+
+	return "", false
+}
+
+// SubtestFinished checks if the end of a subtest was recorded
+//
+// Parameter:
+//   - name string: name of the subtest
+//
+// Returns:
+//   - bool: true if the subtest finished
+func (this *Trace) SubtestFinished(name string) bool {
+	for _, rout := range this.routines {
+		for _, elem := range rout.Elems() {
+			if st, ok := elem.(*ElementSubtest); ok && st.op == SubtestEnd && st.name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// OutlivesSubtest checks if a routine that did not terminate outlived the
+// subtest it belongs to. This is the case if the routine is not in a subtest
+// or if the subtest finished. If the subtest did not finish, only the routine
+// running the subtest itself is seen as outliving it, since the other routines
+// can still be released by the subtest.
+//
+// Parameter:
+//   - routine int: the routine
+//
+// Returns:
+//   - bool: true if a leak in the routine should be counted
+func (this *Trace) OutlivesSubtest(routine int) bool {
+	name := this.SubtestOfRoutine(routine)
+	if name == "" {
+		return true
+	}
+
+	if this.SubtestFinished(name) {
+		return true
+	}
+
+	_, runs := this.runsSubtest(routine)
+	return runs
+}
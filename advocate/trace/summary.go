@@ -0,0 +1,392 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: summary.go
+// Brief: Trace element for the summary of operations that have been removed
+//    from the trace by a package filter
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package trace
+
+import (
+	"advocate/analysis/hb/a_clock"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ========================================================
+// MARK: Data
+// ========================================================
+
+// ElementSummary is a trace element that summarizes operations in packages
+// that are not recorded because of a package filter. It only contains the
+// objects on which the operations synchronized, so that happens before
+// relations through the filtered code are kept.
+// Fields:
+//   - t int: The timestamp of the release or acquire
+//   - op OperationType: SummaryRelease, SummaryAcquire or SummaryBoth
+//   - objIDs []int: ids of the objects
+//   - ci *concInfo: concurrency info
+type ElementSummary struct {
+	ElementBase
+
+	t      int
+	op     OperationType
+	objIDs []int
+	ci     *concInfo
+}
+
+// ========================================================
+// MARK: Constructor
+// ========================================================
+
+// AddTraceElementSummary adds a summary of filtered operations to the main trace
+//
+// Parameter:
+//   - routine int: The routine id
+//   - t string: The timestamp of the event
+//   - op string: R for release, A for acquire, B for both
+//   - ids string: ids of the objects, separated by .
+func (this *Trace) AddTraceElementSummary(routine int, t, op, ids string) error {
+	tInt, err := strconv.Atoi(t)
+	if err != nil {
+		return errors.New("t is not an integer")
+	}
+
+	var o OperationType
+	switch op {
+	case "R":
+		o = SummaryRelease
+	case "A":
+		o = SummaryAcquire
+	case "B":
+		o = SummaryBoth
+	default:
+		return errors.New("op is not a valid summary operation")
+	}
+
+	objIDs := make([]int, 0)
+	for id := range strings.SplitSeq(ids, ".") {
+		idInt, err := strconv.Atoi(id)
+		if err != nil {
+			return errors.New("id is not an integer")
+		}
+		objIDs = append(objIDs, idInt)
+	}
+
+	elem := ElementSummary{
+		ElementBase: this.newElementBase(routine),
+		t:           tInt,
+		op:          o,
+		objIDs:      objIDs,
+		ci:          newConcInfo(),
+	}
+
+	this.AddElement(&elem)
+
+	return nil
+}
+
+// ========================================================
+// MARK: ID
+// ========================================================
+
+// ObjID is a dummy function to implement the traceElement interface.
+// A summary can refer to multiple objects, use ObjIDs to get them.
+//
+// Returns:
+//   - int: -1
+func (this *ElementSummary) ObjID() int {
+	return -1
+}
+
+// ObjIDs returns the ids of the objects the summarized operations synchronized on
+//
+// Returns:
+//   - []int: the ids
+func (this *ElementSummary) ObjIDs() []int {
+	return this.objIDs
+}
+
+// ========================================================
+// MARK: Index
+// ========================================================
+
+// Routine returns the routine ID of the element.
+//
+// Returns:
+//   - int: The routine of the element
+func (this *ElementSummary) Routine() int {
+	return this.routine
+}
+
+// TraceIndex returns trace local index of the element in the trace
+//
+// Returns:
+//   - int: the routine id of the element
+//   - int: The trace local index of the element in the trace
+func (this *ElementSummary) TraceIndex() (int, int) {
+	return this.routine, this.index
+}
+
+// ========================================================
+// MARK: Operation
+// ========================================================
+
+// Type returns the object type
+//
+// Parameter:
+//   - operation bool: if true get the operation code, otherwise only the primitive code
+//
+// Returns:
+//   - OperationType: the object type
+func (this *ElementSummary) Type(operation bool) OperationType {
+	if !operation {
+		return Summary
+	}
+
+	return this.op
+}
+
+// IsRelease returns if the summarized operations released the objects
+//
+// Returns:
+//   - bool: true for SummaryRelease and SummaryBoth
+func (this *ElementSummary) IsRelease() bool {
+	return this.op == SummaryRelease || this.op == SummaryBoth
+}
+
+// IsAcquire returns if the summarized operations acquired the objects
+//
+// Returns:
+//   - bool: true for SummaryAcquire and SummaryBoth
+func (this *ElementSummary) IsAcquire() bool {
+	return this.op == SummaryAcquire || this.op == SummaryBoth
+}
+
+// ========================================================
+// MARK: Timestamps
+// ========================================================
+
+// T returns the timestamp of the element
+//
+// Returns:
+//   - int: The timestamp of the element
+func (this *ElementSummary) T(_ timeType) int {
+	return this.t
+}
+
+// SetT sets the timestamp of the element
+//
+// Parameter:
+//   - time int: The timestamp of the element
+func (this *ElementSummary) SetT(_ timeType, tSort int) {
+	this.t = tSort
+}
+
+// SetTWithoutNotExecuted set the timer, that is used for the sorting of the trace, only if the original
+// value was not 0
+//
+// Parameter:
+//   - tSort int: The timer of the element
+func (this *ElementSummary) SetTWithoutNotExecuted(tSort int) {
+	if this.t == 0 {
+		return
+	}
+	this.t = tSort
+}
+
+// Committed returns if the operation was committed
+//
+// Returns:
+//   - bool: always true, incomplete summaries are not recorded
+func (this *ElementSummary) Committed() bool {
+	return true
+}
+
+// ========================================================
+// MARK: Position
+// ========================================================
+
+// Pos is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - position: the position
+func (this *ElementSummary) Pos() Position {
+	return newPosition("", 0)
+}
+
+// File is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - string: empty string
+func (this *ElementSummary) File() string {
+	return ""
+}
+
+// Line is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - int: 0
+func (this *ElementSummary) Line() int {
+	return 0
+}
+
+// ========================================================
+// MARK: Equal
+// ========================================================
+
+// IsEqual checks if an trace element is equal to this element
+//
+// Parameter:
+//   - elem TraceElement: The element to check against
+//
+// Returns:
+//   - bool: true if it is the same operation, false otherwise
+func (this *ElementSummary) IsEqual(elem Element) bool {
+	return this.id == elem.ID()
+}
+
+// IsSameElement returns checks if the element on which the at and elem
+// where performed are the same
+//
+// Parameter:
+//   - elem Element: the element to compare against
+//
+// Returns:
+//   - bool: always false
+func (this *ElementSummary) IsSameElement(elem Element) bool {
+	return false
+}
+
+// ========================================================
+// MARK: String
+// ========================================================
+
+// String returns the simple string representation of the element
+//
+// Returns:
+//   - string: The simple string representation of the element
+func (this *ElementSummary) String() string {
+	opStr := "R"
+	switch this.op {
+	case SummaryAcquire:
+		opStr = "A"
+	case SummaryBoth:
+		opStr = "B"
+	}
+
+	ids := make([]string, 0, len(this.objIDs))
+	for _, id := range this.objIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+
+	return fmt.Sprintf("H,%d,%s,%s", this.t, opStr, strings.Join(ids, "."))
+}
+
+// String returns the simple string representation of the element with leading routine
+//
+// Returns:
+//   - string: The simple string representation of the element with leading routine
+func (this *ElementSummary) StringDebug() string {
+	routine := fmt.Sprintf("%4d", this.Routine())
+	if this.ElementBase.init {
+		routine = "   *"
+	}
+	return fmt.Sprintf("%s -> %s", routine, this.String())
+}
+
+// ========================================================
+// MARK: Function
+// ========================================================
+
+func (this *ElementSummary) Function() *ElementFunc {
+	return nil
+}
+
+// ========================================================
+// MARK: Concurrent
+// ========================================================
+
+// Vc sets the vector clock
+//
+// Parameter:
+//   - weak bool: set the weak wv
+//   - cl *clock.VectorClock: the vector clock
+func (this *ElementSummary) Vc(weak a_clock.VcType, cl *a_clock.VectorClock) {
+	this.ci.setVC(weak, cl)
+}
+
+// GetVC returns the vector clock of the element
+//
+// Parameter:
+//   - weak bool: get the weak
+//
+// Returns:
+//   - VectorClock: The vector clock of the element
+func (this *ElementSummary) GetVC(weak a_clock.VcType) *a_clock.VectorClock {
+	return this.ci.getVC(weak)
+}
+
+// NumberConcurrent returns the number of elements concurrent to the element
+// If not set, it returns -1
+func (this *ElementSummary) NumberConcurrent(_, _ bool) int {
+	return -1
+}
+
+// SetNumberConcurrent sets the number of concurrent elements
+func (this *ElementSummary) SetNumberConcurrent(_ int, _, _ bool) {}
+
+// ========================================================
+// MARK: Replay
+// ========================================================
+
+// ReplayID is a dummy function to implement the traceElement interface
+//
+// Returns:
+//   - string: empty string
+func (this *ElementSummary) ReplayID() string {
+	return ""
+}
+
+// ========================================================
+// MARK: Copy
+// ========================================================
+
+// Copy the element
+//
+// Parameter:
+//   - mapping map[string]Element: map containing all already copied elements.
+//   - keep bool: if true, keep vc and order information
+//
+// Returns:
+//   - TraceElement: The copy of the element
+func (this *ElementSummary) Copy(_ map[int]Element, keep bool) Element {
+	objIDs := make([]int, len(this.objIDs))
+	copy(objIDs, this.objIDs)
+
+	ci := newConcInfo()
+	if keep {
+		ci = this.ci.copy()
+	}
+
+	return &ElementSummary{
+		ElementBase: this.ElementBase.Copy(),
+		t:           this.t,
+		op:          this.op,
+		objIDs:      objIDs,
+		ci:          ci,
+	}
+}
+
+// ========================================================
+// MARK: Valid
+// ========================================================
+
+func (this *ElementSummary) IsValid() bool {
+	return this != nil
+}
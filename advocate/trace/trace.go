@@ -40,6 +40,9 @@ import (
 //   - allocs: allocs
 //   - resources: obj id to resource
 //   - callGraph: call graph
+//   - stacks: recorded call stacks, nil if no stacks have been recorded
+//   - nondet: lines of the recorded results of nondeterministic calls, e.g. time.Now
+//   - identities: obj id to stable identity of the object, see ComputeObjectIdentities
 type Trace struct {
 	routines              map[int]*Routine
 	hbWasCalc             bool
@@ -52,6 +55,9 @@ type Trace struct {
 	allocs                map[int]*ElementAlloc
 	resources             map[int]*Resource
 	callTree              CallTree
+	stacks                *StackTable
+	nondet                []string
+	identities            map[int]string
 }
 
 // NewTrace creates a new empty trace structure
@@ -83,6 +89,9 @@ func (this *Trace) Clear() {
 	this.allocs = make(map[int]*ElementAlloc)
 	this.resources = make(map[int]*Resource)
 	this.callTree = *newCallGraph()
+	this.stacks = nil
+	this.nondet = nil
+	this.identities = nil
 }
 
 // AddElement adds an element to the trace
@@ -111,7 +120,7 @@ func (this *Trace) AddElement(elem Element) {
 // AddElement adds a resouce if elem has a resource and if it is not created yet
 func (this *Trace) AddResource(elem Element) {
 	switch elem.(type) {
-	case *ElementFork, *ElementFunc, *ElementReturn, *ElementRoutineEnd, *ElementReplay, *ElementControllFlow:
+	case *ElementFork, *ElementFunc, *ElementReturn, *ElementRoutineEnd, *ElementReplay, *ElementControllFlow, *ElementSubtest, *ElementSummary, *ElementEvent, *ElementCustomSync:
 		return
 	}
 
@@ -502,6 +511,12 @@ func (this *Trace) Copy(keep bool) (Trace, error) {
 		}
 	}
 
+	// the stack table, nondeterministic calls and identities are not changed
+	// after reading and can therefore be shared
+	newTrace.stacks = this.stacks
+	newTrace.nondet = this.nondet
+	newTrace.identities = this.identities
+
 	return newTrace, nil
 }
 
@@ -1008,7 +1023,7 @@ func (this *Trace) GetResources(elem Element) []*Resource {
 	res := make([]*Resource, 0)
 
 	switch elem := elem.(type) {
-	case *ElementFork, *ElementFunc, *ElementReturn, *ElementRoutineEnd, *ElementReplay:
+	case *ElementFork, *ElementFunc, *ElementReturn, *ElementRoutineEnd, *ElementReplay, *ElementSubtest, *ElementSummary, *ElementEvent, *ElementCustomSync:
 	case *ElementSelect:
 		for _, c := range elem.GetCases() {
 			r, ok := this.resources[c.ObjID()]
@@ -0,0 +1,368 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: anchor.go
+// Brief: Stable anchors for source positions, consisting of the enclosing
+//    function, the statement kind and a normalized fingerprint of the AST
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// Anchor identifies a source position independent of its line number
+//
+// Fields:
+//   - File string: path to the file
+//   - Line int: line of the position
+//   - Func string: enclosing function, e.g. TestA, (*T).M or TestA.func1
+//   - Kind string: kind of the innermost statement or declaration containing the line, e.g. SendStmt
+//   - Fingerprint string: hash of the normalized AST of the statement
+//   - Ordinal int: number of previous statements in the file with the same function, kind and fingerprint
+//   - Offset int: line of the position relative to the first line of the statement,
+//     -1 if the position is the last line of a statement with multiple lines, e.g. the
+//     closing brace of a function literal, where deferred calls are executed
+type Anchor struct {
+	File        string
+	Line        int
+	Func        string
+	Kind        string
+	Fingerprint string
+	Ordinal     int
+	Offset      int
+}
+
+// node is a statement or declaration of a source file an anchor can refer to
+//
+// Fields:
+//   - start int: first line
+//   - end int: last line
+//   - fn string: enclosing function
+//   - kind string: kind of the node
+//   - fingerprint string: hash of the normalized AST
+//   - ordinal int: number of previous nodes with the same fn, kind and fingerprint
+type node struct {
+	start, end  int
+	fn          string
+	kind        string
+	fingerprint string
+	ordinal     int
+}
+
+// key returns the identifier of a node without its ordinal
+//
+// Returns:
+//   - string: the identifier
+func (this *node) key() string {
+	return this.fn + "!" + this.kind + "!" + this.fingerprint
+}
+
+// line returns the line in a node for the offset of an anchor
+//
+// Parameter:
+//   - offset int: the offset of the anchor
+//
+// Returns:
+//   - int: the line
+func (this *node) line(offset int) int {
+	if offset == -1 {
+		return this.end
+	}
+	return this.start + offset
+}
+
+// String returns a readable representation of the anchor
+//
+// Returns:
+//   - string: the representation
+func (this *Anchor) String() string {
+	return fmt.Sprintf("%s#%d (%s in %s)", this.File, this.Line, this.Kind, this.Func)
+}
+
+// parseNodes parses a source file and returns all statements and declarations
+// in the order of their position
+//
+// Parameter:
+//   - path string: path to the file
+//
+// Returns:
+//   - []node: the nodes
+//   - error
+func parseNodes(path string) ([]node, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]node, 0)
+	add := func(n ast.Node, fn string) {
+		kind := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+		res = append(res, node{
+			start:       fset.Position(n.Pos()).Line,
+			end:         fset.Position(n.End()).Line,
+			fn:          fn,
+			kind:        kind,
+			fingerprint: fingerprint(n),
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := funcDeclName(d)
+			add(d, name)
+			if d.Body != nil {
+				collectNodes(d.Body, name, add)
+			}
+		case *ast.GenDecl:
+			add(d, "")
+			collectNodes(d, "", add)
+		}
+	}
+
+	ordinals := make(map[string]int)
+	for i := range res {
+		res[i].ordinal = ordinals[res[i].key()]
+		ordinals[res[i].key()]++
+	}
+
+	return res, nil
+}
+
+// collectNodes calls add for all statements in a node. Function literals
+// are named like the go compiler names them, e.g. TestA.func1 and TestA.func1.1
+//
+// Parameter:
+//   - root ast.Node: the node
+//   - fn string: the function the node is in
+//   - add func(ast.Node, string): function called for every statement with its enclosing function
+func collectNodes(root ast.Node, fn string, add func(ast.Node, string)) {
+	numberLit := 0
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			numberLit++
+			name := fmt.Sprintf("%s.%d", fn, numberLit)
+			if !strings.Contains(fn, ".func") {
+				name = fmt.Sprintf("%s.func%d", fn, numberLit)
+			}
+			collectNodes(n.Body, name, add)
+			return false
+		case *ast.BlockStmt:
+			return true
+		case ast.Stmt:
+			add(n, fn)
+		}
+		return true
+	})
+}
+
+// funcDeclName returns the name of a function declaration, e.g. F, T.M or (*T).M
+//
+// Parameter:
+//   - decl *ast.FuncDecl: the declaration
+//
+// Returns:
+//   - string: the name
+func funcDeclName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+
+	recv := decl.Recv.List[0].Type
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	} else if index, ok := recv.(*ast.IndexListExpr); ok {
+		recv = index.X
+	}
+
+	if star, ok := recv.(*ast.StarExpr); ok {
+		return "(*" + typeName(star.X) + ")." + decl.Name.Name
+	}
+	return typeName(recv) + "." + decl.Name.Name
+}
+
+// typeName returns the name of a receiver type
+//
+// Parameter:
+//   - expr ast.Expr: the type
+//
+// Returns:
+//   - string: the name
+func typeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return typeName(e.X)
+	case *ast.IndexListExpr:
+		return typeName(e.X)
+	}
+	return "?"
+}
+
+// fingerprint returns a hash of the normalized AST of a statement or declaration.
+// The hash contains the node types, identifiers, literals and operators, but
+// not the positions, comments or formatting. Nested blocks, e.g. the body
+// of a for loop, a case or a function literal, are not part of the fingerprint,
+// so that changes in them do not change the fingerprint.
+//
+// Parameter:
+//   - root ast.Node: the node
+//
+// Returns:
+//   - string: the hex encoded hash
+func fingerprint(root ast.Node) string {
+	var sb strings.Builder
+
+	visit := func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+
+		switch n.(type) {
+		case *ast.BlockStmt, *ast.CommentGroup:
+			return false
+		}
+
+		sb.WriteString(strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast."))
+		switch n := n.(type) {
+		case *ast.Ident:
+			sb.WriteString(":" + n.Name)
+		case *ast.BasicLit:
+			sb.WriteString(":" + n.Value)
+		case *ast.BinaryExpr:
+			sb.WriteString(":" + n.Op.String())
+		case *ast.UnaryExpr:
+			sb.WriteString(":" + n.Op.String())
+		case *ast.AssignStmt:
+			sb.WriteString(":" + n.Tok.String())
+		case *ast.IncDecStmt:
+			sb.WriteString(":" + n.Tok.String())
+		case *ast.BranchStmt:
+			sb.WriteString(":" + n.Tok.String())
+		case *ast.RangeStmt:
+			sb.WriteString(":" + n.Tok.String())
+		case *ast.GenDecl:
+			sb.WriteString(":" + n.Tok.String())
+		}
+		sb.WriteString(";")
+		return true
+	}
+
+	switch n := root.(type) {
+	case *ast.CaseClause:
+		// only the case expressions, not the statements of the case
+		sb.WriteString("CaseClause;")
+		for _, expr := range n.List {
+			ast.Inspect(expr, visit)
+		}
+	case *ast.CommClause:
+		sb.WriteString("CommClause;")
+		if n.Comm != nil {
+			ast.Inspect(n.Comm, visit)
+		}
+	default:
+		ast.Inspect(root, visit)
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:6])
+}
+
+// anchorForLine returns the anchor of a line in a file. The anchor refers to
+// the innermost statement or declaration containing the line.
+//
+// Parameter:
+//   - nodes []node: the nodes of the file
+//   - file string: path to the file
+//   - line int: the line
+//
+// Returns:
+//   - Anchor: the anchor
+//   - bool: false if no statement or declaration contains the line
+func anchorForLine(nodes []node, file string, line int) (Anchor, bool) {
+	best := -1
+	for i, n := range nodes {
+		if n.start > line || n.end < line {
+			continue
+		}
+
+		// the innermost node has the smallest span, for equal spans the later node is nested
+		if best == -1 || n.end-n.start <= nodes[best].end-nodes[best].start {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return Anchor{}, false
+	}
+
+	n := nodes[best]
+	offset := line - n.start
+	if line == n.end && n.end > n.start {
+		offset = -1
+	}
+
+	return Anchor{
+		File:        file,
+		Line:        line,
+		Func:        n.fn,
+		Kind:        n.kind,
+		Fingerprint: n.fingerprint,
+		Ordinal:     n.ordinal,
+		Offset:      offset,
+	}, true
+}
+
+// findLine returns the line an anchor refers to in the current version of the file
+//
+// Parameter:
+//   - nodes []node: the nodes of the current version of the file
+//   - a Anchor: the anchor
+//
+// Returns:
+//   - int: the line
+//   - bool: false if the anchor could not be mapped to a unique line
+func findLine(nodes []node, a Anchor) (int, bool) {
+	key := a.Func + "!" + a.Kind + "!" + a.Fingerprint
+
+	candidates := make([]node, 0)
+	for _, n := range nodes {
+		if n.key() == key {
+			candidates = append(candidates, n)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0].line(a.Offset), true
+	}
+	if a.Ordinal < len(candidates) {
+		return candidates[a.Ordinal].line(a.Offset), true
+	}
+
+	// the enclosing function may have been renamed or moved
+	if len(candidates) == 0 {
+		for _, n := range nodes {
+			if n.kind == a.Kind && n.fingerprint == a.Fingerprint {
+				candidates = append(candidates, n)
+			}
+		}
+		if len(candidates) == 1 {
+			return candidates[0].line(a.Offset), true
+		}
+	}
+
+	return 0, false
+}
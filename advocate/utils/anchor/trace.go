@@ -0,0 +1,378 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: trace.go
+// Brief: Store the anchors of all positions in a trace and remap the
+//    positions of a trace to the current version of the source files
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package anchor
+
+import (
+	"advocate/utils/paths"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// matches the positions file.go#line in the trace files
+var posRegex = regexp.MustCompile(`([^,;\s]+\.go)#([0-9]+)`)
+
+// WriteAnchors computes the anchors of all positions in a trace and writes them
+// into the position_anchors.log file in the trace folder. Must be called while
+// the source files are in the same state as during the recording, including
+// the advocate header. Files of the runtime are not anchored. Each line has the form
+//
+//	File![file]![sha256]
+//	Anchor![file]#[line]![func]![kind]![fingerprint]![ordinal]![offset]
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - error
+func WriteAnchors(tracePath string) error {
+	positions, err := readPositions(tracePath)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(positions))
+	for file := range positions {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+
+	var sb strings.Builder
+	for _, file := range files {
+		hash, err := hashFile(file)
+		if err != nil {
+			continue
+		}
+
+		nodes, err := parseNodes(file)
+		if err != nil {
+			continue
+		}
+
+		sb.WriteString("File!" + file + "!" + hash + "\n")
+		for _, line := range positions[file] {
+			a, ok := anchorForLine(nodes, file, line)
+			if !ok {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("Anchor!%s#%d!%s!%s!%s!%d!%d\n",
+				a.File, a.Line, a.Func, a.Kind, a.Fingerprint, a.Ordinal, a.Offset))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return nil
+	}
+
+	return os.WriteFile(filepath.Join(tracePath, paths.NameAnchors), []byte(sb.String()), 0644)
+}
+
+// Remap maps the positions of a trace to the current version of the source
+// files. For each file that has been changed since the recording, the
+// position of each anchor is searched in the current file and all positions
+// in the trace files are replaced. If a position cannot be mapped, the trace
+// is not changed. Must be called while the advocate header is added.
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - int: number of remapped positions
+//   - []Anchor: the anchors that could not be mapped
+//   - error
+func Remap(tracePath string) (int, []Anchor, error) {
+	hashes, anchors, err := readAnchors(tracePath)
+	if err != nil || len(anchors) == 0 {
+		return 0, nil, err
+	}
+
+	mapping := make(map[string]string)
+	unmapped := make([]Anchor, 0)
+	nodes := make(map[string][]node)
+
+	for _, a := range anchors {
+		if hash, err := hashFile(a.File); err == nil && hash == hashes[a.File] {
+			continue
+		}
+
+		if _, ok := nodes[a.File]; !ok {
+			n, err := parseNodes(a.File)
+			if err != nil {
+				n = []node{}
+			}
+			nodes[a.File] = n
+		}
+
+		line, ok := findLine(nodes[a.File], a)
+		if !ok {
+			unmapped = append(unmapped, a)
+			continue
+		}
+
+		if line != a.Line {
+			mapping[posString(a.File, a.Line)] = posString(a.File, line)
+		}
+	}
+
+	if len(unmapped) > 0 || len(nodes) == 0 {
+		return 0, unmapped, nil
+	}
+
+	if len(mapping) > 0 {
+		err = replacePositions(tracePath, mapping)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// store the anchors for the new positions and hashes
+	return len(mapping), nil, WriteAnchors(tracePath)
+}
+
+// CopyAnchors copies the anchors from a trace into a rewritten trace
+//
+// Parameter:
+//   - pathTrace string: path to the recorded trace
+//   - newTrace string: path to the rewritten trace
+//
+// Returns:
+//   - error
+func CopyAnchors(pathTrace, newTrace string) error {
+	content, err := os.ReadFile(filepath.Join(pathTrace, paths.NameAnchors))
+	if err != nil {
+		return nil
+	}
+
+	return os.WriteFile(filepath.Join(newTrace, paths.NameAnchors), content, 0644)
+}
+
+// readPositions reads all positions in the trace files of a trace, except
+// the positions in the runtime
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - map[string][]int: file -> sorted lines
+//   - error
+func readPositions(tracePath string) (map[string][]int, error) {
+	files, err := traceFiles(tracePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]map[int]struct{})
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range posRegex.FindAllStringSubmatch(string(content), -1) {
+			file := match[1]
+			if !filepath.IsAbs(file) || isRuntimeFile(file) {
+				continue
+			}
+
+			line, err := strconv.Atoi(match[2])
+			if err != nil {
+				continue
+			}
+
+			if _, ok := lines[file]; !ok {
+				lines[file] = make(map[int]struct{})
+			}
+			lines[file][line] = struct{}{}
+		}
+	}
+
+	res := make(map[string][]int)
+	for file, l := range lines {
+		for line := range l {
+			res[file] = append(res[file], line)
+		}
+		slices.Sort(res[file])
+	}
+
+	return res, nil
+}
+
+// readAnchors reads the position_anchors.log file of a trace
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - map[string]string: file -> hash of the file at the time the anchors were written
+//   - []Anchor: the anchors, empty if the trace has no anchors
+//   - error
+func readAnchors(tracePath string) (map[string]string, []Anchor, error) {
+	hashes := make(map[string]string)
+	anchors := make([]Anchor, 0)
+
+	file, err := os.Open(filepath.Join(tracePath, paths.NameAnchors))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return hashes, anchors, nil
+		}
+		return hashes, anchors, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "!")
+		switch {
+		case len(fields) == 3 && fields[0] == "File":
+			hashes[fields[1]] = fields[2]
+		case len(fields) == 7 && fields[0] == "Anchor":
+			posFile, posLine, found := strings.Cut(fields[1], "#")
+			line, err := strconv.Atoi(posLine)
+			if !found || err != nil {
+				return hashes, anchors, fmt.Errorf("invalid anchor position %s", fields[1])
+			}
+			ordinal, err1 := strconv.Atoi(fields[5])
+			offset, err2 := strconv.Atoi(fields[6])
+			if err1 != nil || err2 != nil {
+				return hashes, anchors, fmt.Errorf("invalid anchor %s", scanner.Text())
+			}
+			anchors = append(anchors, Anchor{
+				File:        posFile,
+				Line:        line,
+				Func:        fields[2],
+				Kind:        fields[3],
+				Fingerprint: fields[4],
+				Ordinal:     ordinal,
+				Offset:      offset,
+			})
+		case scanner.Text() != "":
+			return hashes, anchors, fmt.Errorf("invalid anchor line %s", scanner.Text())
+		}
+	}
+
+	return hashes, anchors, scanner.Err()
+}
+
+// replacePositions replaces positions in all trace files of a trace
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//   - mapping map[string]string: old position -> new position
+//
+// Returns:
+//   - error
+func replacePositions(tracePath string, mapping map[string]string) error {
+	files, err := traceFiles(tracePath)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		res := posRegex.ReplaceAllStringFunc(string(content), func(pos string) string {
+			if newPos, ok := mapping[pos]; ok {
+				changed = true
+				return newPos
+			}
+			return pos
+		})
+
+		if changed {
+			if err := os.WriteFile(f, []byte(res), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// traceFiles returns the trace files of a trace, including the stack and
+// nondeterminism table
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - []string: paths to the files
+//   - error
+func traceFiles(tracePath string) ([]string, error) {
+	entries, err := os.ReadDir(tracePath)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "trace_") || !strings.HasSuffix(e.Name(), ".log") {
+			continue
+		}
+		res = append(res, filepath.Join(tracePath, e.Name()))
+	}
+
+	return res, nil
+}
+
+// isRuntimeFile returns whether a file is part of the patched go runtime
+//
+// Parameter:
+//   - file string: path to the file
+//
+// Returns:
+//   - bool: true if the file is in the runtime
+func isRuntimeFile(file string) bool {
+	if paths.GoPatch == "" {
+		return false
+	}
+	rel, err := filepath.Rel(paths.GoPatch, file)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// posString returns the position in the format of the trace
+//
+// Parameter:
+//   - file string: the file
+//   - line int: the line
+//
+// Returns:
+//   - string: file#line
+func posString(file string, line int) string {
+	return file + "#" + strconv.Itoa(line)
+}
+
+// hashFile returns the sha256 hash of a file
+//
+// Parameter:
+//   - path string: path to the file
+//
+// Returns:
+//   - string: the hex encoded hash
+//   - error
+func hashFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
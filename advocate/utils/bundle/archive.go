@@ -0,0 +1,294 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: archive.go
+// Brief: Write and read the archive of a bug bundle and verify the source
+//    files of a bundle before it is replayed
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of changes of a source file
+const (
+	Changed = "changed"
+	Missing = "missing"
+)
+
+// Change describes a source file that differs from the file the bundle was created with
+//
+// Fields:
+//   - Source Source: the source file in the bundle
+//   - Kind string: Changed or Missing
+//   - Local string: path to the file on this machine
+type Change struct {
+	Source Source
+	Kind   string
+	Local  string
+}
+
+// writeArchive writes the bundle as tar.gz. It contains the metadata, the
+// bug report and the trace folder.
+//
+// Parameter:
+//   - out string: path to the archive
+//   - meta Meta: the metadata
+//   - bug []byte: content of the bug report
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - error
+func writeArchive(out string, meta Meta, bug []byte, tracePath string) error {
+	metaJSON, err := meta.JSON()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	err = writeEntry(tw, NameMeta, metaJSON)
+	if err != nil {
+		return err
+	}
+
+	err = writeEntry(tw, NameBug, bug)
+	if err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(tracePath)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(tracePath, f.Name()))
+		if err != nil {
+			return err
+		}
+
+		err = writeEntry(tw, path.Join(meta.TraceName, f.Name()), content)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeEntry writes a file into a tar archive
+//
+// Parameter:
+//   - tw *tar.Writer: the archive
+//   - name string: name of the file in the archive
+//   - content []byte: content of the file
+//
+// Returns:
+//   - error
+func writeEntry(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
+
+// readArchive calls f for each file in a bundle
+//
+// Parameter:
+//   - bundlePath string: path to the bundle
+//   - f func(string, io.Reader) error: function called with the name and content of each file
+//
+// Returns:
+//   - error
+func readArchive(bundlePath string, f func(string, io.Reader) error) error {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("%s is not a bundle: %s", bundlePath, err.Error())
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := f(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadMeta reads the metadata of a bundle
+//
+// Parameter:
+//   - bundlePath string: path to the bundle
+//
+// Returns:
+//   - Meta: the metadata
+//   - error
+func ReadMeta(bundlePath string) (Meta, error) {
+	meta := Meta{}
+	found := false
+
+	err := readArchive(bundlePath, func(name string, r io.Reader) error {
+		if name != NameMeta {
+			return nil
+		}
+		found = true
+		return json.NewDecoder(r).Decode(&meta)
+	})
+	if err != nil {
+		return meta, err
+	}
+
+	if !found {
+		return meta, fmt.Errorf("%s does not contain %s", bundlePath, NameMeta)
+	}
+
+	return meta, nil
+}
+
+// ExtractTrace writes the trace of a bundle into a folder. An existing trace
+// with the same name is replaced.
+//
+// Parameter:
+//   - bundlePath string: path to the bundle
+//   - meta Meta: the metadata of the bundle
+//   - dir string: the folder to write the trace into
+//
+// Returns:
+//   - string: path to the extracted trace folder
+//   - error
+func ExtractTrace(bundlePath string, meta Meta, dir string) (string, error) {
+	tracePath := filepath.Join(dir, meta.TraceName)
+
+	if err := os.RemoveAll(tracePath); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(tracePath, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	err := readArchive(bundlePath, func(name string, r io.Reader) error {
+		traceName, fileName, found := strings.Cut(name, "/")
+		if !found || traceName != meta.TraceName || fileName != path.Base(fileName) {
+			return nil
+		}
+
+		out, err := os.Create(filepath.Join(tracePath, fileName))
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, r)
+		return err
+	})
+
+	return tracePath, err
+}
+
+// Verify compares the source files of a bundle with the files on this machine
+//
+// Parameter:
+//   - meta Meta: the metadata of the bundle
+//   - root string: module root of the program on this machine
+//
+// Returns:
+//   - []Change: the changed and missing files
+func Verify(meta Meta, root string) []Change {
+	res := make([]Change, 0)
+
+	for _, source := range meta.Sources {
+		local := localPath(source.Path, root)
+		hash, err := hashFile(local)
+		if err != nil {
+			if source.Hash != "" {
+				res = append(res, Change{Source: source, Kind: Missing, Local: local})
+			}
+			continue
+		}
+
+		if hash != source.Hash {
+			res = append(res, Change{Source: source, Kind: Changed, Local: local})
+		}
+	}
+
+	return res
+}
+
+// ModuleRoot returns the folder containing the go.mod file of a path
+//
+// Parameter:
+//   - path string: path to a file or folder in the module
+//
+// Returns:
+//   - string: the module root, empty if no go.mod file was found
+func ModuleRoot(path string) string {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+	return moduleRoot(path)
+}
+
+// String returns a readable description of the change
+//
+// Returns:
+//   - string: the description
+func (this *Change) String() string {
+	lines := make([]string, 0, len(this.Source.Lines))
+	for _, line := range this.Source.Lines {
+		lines = append(lines, fmt.Sprint(line))
+	}
+
+	return fmt.Sprintf("%s %s (%s), the trace refers to line(s) %s",
+		this.Source.Path, this.Kind, this.Local, strings.Join(lines, ", "))
+}
@@ -0,0 +1,450 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: bundle.go
+// Brief: Create portable bug bundles, containing the rewritten trace of a
+//    bug, metadata about the run and hashes of all source files used in the trace
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package bundle
+
+import (
+	"advocate/utils/consts"
+	"advocate/utils/paths"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// names of the entries in a bundle
+const (
+	NameMeta = "bundle.json"
+	NameBug  = "bug.md"
+)
+
+// prefixes for source files that are not part of the module of the program
+const (
+	prefixGoRoot   = "$GOROOT"
+	prefixModCache = "$GOMODCACHE"
+)
+
+// matches positions file.go#line or file.go:line in the trace files
+var posRegex = regexp.MustCompile(`([^,;\s]+\.go)[#:]([0-9]+)`)
+
+// Source is a source file mentioned in the bundled trace
+//
+// Fields:
+//   - Path string: path relative to the module root, or starting with $GOROOT or $GOMODCACHE
+//     for files outside the module, absolute if it is in none of them
+//   - Hash string: sha256 of the file, empty if the file did not exist
+//   - Lines []int: lines of the file mentioned in the trace
+type Source struct {
+	Path  string `json:"path"`
+	Hash  string `json:"sha256"`
+	Lines []int  `json:"lines"`
+}
+
+// Meta is the metadata of a bundle
+//
+// Fields:
+//   - Bug string: name of the bug report the bundle was created from
+//   - Test string: name of the test, Main for main programs
+//   - File string: file containing the test or main function, relative to the module root
+//   - Trace string: name of the recorded trace the bug was found in
+//   - Rewritten bool: true if the bundled trace is the rewritten trace of the bug,
+//     false if the bug was not rewritten and the recorded trace is bundled
+//   - TraceName string: name of the trace folder in the bundle
+//   - ExitCode string: exit code of the replay of the bug, empty if unknown
+//   - Command string: advocate command the bug was found with, empty if unknown
+//   - GoVersion string: version of the patched go runtime
+//   - AdvocateVersion string: version of advocate
+//   - Created string: time the bundle was created
+//   - Root string: module root at the time the bundle was created
+//   - Sources []Source: source files mentioned in the trace
+type Meta struct {
+	Bug             string   `json:"bug"`
+	Test            string   `json:"test"`
+	File            string   `json:"file"`
+	Trace           string   `json:"trace"`
+	Rewritten       bool     `json:"rewritten"`
+	TraceName       string   `json:"traceName"`
+	ExitCode        string   `json:"exitCode,omitempty"`
+	Command         string   `json:"command,omitempty"`
+	GoVersion       string   `json:"goVersion"`
+	AdvocateVersion string   `json:"advocateVersion"`
+	Created         string   `json:"created"`
+	Root            string   `json:"root"`
+	Sources         []Source `json:"sources"`
+}
+
+// CreateBundle creates a bundle for a bug report in the result folder
+//
+// Parameter:
+//   - bugPath string: path to the bug report, e.g. advocateResult/.../bugs/bug_1.md
+//   - out string: path of the created bundle, if empty [test]_[bug].tar.gz in the current folder
+//
+// Returns:
+//   - string: path to the created bundle
+//   - Meta: metadata of the bundle
+//   - error
+func CreateBundle(bugPath, out string) (string, Meta, error) {
+	meta := Meta{}
+
+	bugPath, err := filepath.Abs(bugPath)
+	if err != nil {
+		return "", meta, err
+	}
+
+	bugContent, err := os.ReadFile(bugPath)
+	if err != nil {
+		return "", meta, fmt.Errorf("could not read bug report: %s", err.Error())
+	}
+
+	resultDir := filepath.Dir(filepath.Dir(bugPath))
+	meta.Bug = strings.TrimSuffix(filepath.Base(bugPath), ".md")
+	readBugInfo(string(bugContent), &meta)
+
+	tracePath, err := findTrace(resultDir, meta.Bug, meta.Trace)
+	if err != nil {
+		return "", meta, err
+	}
+	meta.Rewritten = strings.HasPrefix(filepath.Base(tracePath), "rewrittenTrace_")
+
+	// the replay finds the trace by the number at the end of its name
+	_, index, _ := strings.Cut(meta.Bug, "_")
+	meta.TraceName = "rewrittenTrace_" + index[strings.LastIndex(index, "_")+1:]
+
+	meta.Command = readCommand(filepath.Join(resultDir, paths.NameOut, paths.NameOutput))
+
+	meta.Root = moduleRoot(filepath.Dir(meta.File))
+	if meta.Root != "" {
+		if rel, err := filepath.Rel(meta.Root, meta.File); err == nil {
+			meta.File = paths.ToUnix(rel)
+		}
+	}
+
+	meta.GoVersion = GoVersion()
+	meta.AdvocateVersion = advocateVersion()
+	meta.Created = time.Now().Format(time.RFC3339)
+
+	meta.Sources, err = collectSources(tracePath, meta.Root)
+	if err != nil {
+		return "", meta, err
+	}
+
+	if out == "" {
+		name := strings.NewReplacer("/", "_", " ", "_").Replace(meta.Test)
+		out = fmt.Sprintf("%s_%s.tar.gz", name, meta.Bug)
+	}
+
+	err = writeArchive(out, meta, bugContent, tracePath)
+	return out, meta, err
+}
+
+// readBugInfo reads the test, file, trace and exit code from a bug report
+//
+// Parameter:
+//   - content string: content of the bug report
+//   - meta *Meta: the metadata to fill
+func readBugInfo(content string, meta *Meta) {
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "- Test/Prog: "):
+			meta.Test = strings.TrimPrefix(line, "- Test/Prog: ")
+		case strings.HasPrefix(line, "- File: "):
+			meta.File = strings.TrimPrefix(line, "- File: ")
+		case strings.HasPrefix(line, "- Trace: "):
+			meta.Trace = strings.TrimPrefix(line, "- Trace: ")
+		case strings.HasPrefix(line, consts.ItExitedWithTheFollowingCode):
+			meta.ExitCode = strings.TrimPrefix(line, consts.ItExitedWithTheFollowingCode)
+		}
+	}
+}
+
+// findTrace returns the trace of a bug. This is the rewritten trace of the bug,
+// or the recorded trace if the bug has not been rewritten.
+//
+// Parameter:
+//   - resultDir string: the result folder of the test or program
+//   - bug string: name of the bug report without extension, e.g. bug_1
+//   - trace string: name of the recorded trace
+//
+// Returns:
+//   - string: path to the trace folder
+//   - error
+func findTrace(resultDir, bug, trace string) (string, error) {
+	_, index, found := strings.Cut(bug, "_")
+	if !found {
+		return "", fmt.Errorf("invalid bug report name %s", bug)
+	}
+
+	tracesDir := filepath.Join(resultDir, paths.NameTraces)
+	candidates := []string{filepath.Join(tracesDir, "rewrittenTrace_"+index)}
+	if i := strings.LastIndex(index, "_"); i != -1 {
+		candidates = append(candidates, filepath.Join(tracesDir, "rewrittenTrace_"+index[i+1:]))
+	}
+	if trace != "" && trace != "unknown" {
+		candidates = append(candidates, filepath.Join(tracesDir, trace))
+	}
+
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && info.IsDir() {
+			return c, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a trace for %s in %s. Traces are removed if -deleteTrace is set", bug, tracesDir)
+}
+
+// readCommand reads the advocate command from the output of the run
+//
+// Parameter:
+//   - outputPath string: path to the output.log file
+//
+// Returns:
+//   - string: the command, empty if not found
+func readCommand(outputPath string) string {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if cmd, found := strings.CutPrefix(scanner.Text(), "AdvocateCommand: "); found {
+			return strings.TrimSpace(cmd)
+		}
+	}
+
+	return ""
+}
+
+// collectSources collects and hashes all source files mentioned in a trace
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//   - root string: module root of the program
+//
+// Returns:
+//   - []Source: the source files, sorted by path
+//   - error
+func collectSources(tracePath, root string) ([]Source, error) {
+	files, err := os.ReadDir(tracePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]map[int]struct{})
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".log") {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(tracePath, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range posRegex.FindAllStringSubmatch(string(content), -1) {
+			// generated files like _testmain.go have no absolute path
+			if !filepath.IsAbs(match[1]) {
+				continue
+			}
+			line, err := strconv.Atoi(match[2])
+			if err != nil {
+				continue
+			}
+			if _, ok := lines[match[1]]; !ok {
+				lines[match[1]] = make(map[int]struct{})
+			}
+			lines[match[1]][line] = struct{}{}
+		}
+	}
+
+	res := make([]Source, 0, len(lines))
+	for file, l := range lines {
+		source := Source{Path: portablePath(file, root)}
+		source.Hash, _ = hashFile(file)
+		for line := range l {
+			source.Lines = append(source.Lines, line)
+		}
+		slices.Sort(source.Lines)
+		res = append(res, source)
+	}
+
+	slices.SortFunc(res, func(a, b Source) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return res, nil
+}
+
+// portablePath returns the path of a file, that is independent of the machine
+//
+// Parameter:
+//   - file string: absolute path to the file
+//   - root string: module root of the program
+//
+// Returns:
+//   - string: the path relative to root, $GOROOT or $GOMODCACHE, or file
+//     if it is in none of them
+func portablePath(file, root string) string {
+	bases := []struct{ prefix, dir string }{
+		{"", root},
+		{prefixGoRoot, paths.GoPatch},
+		{prefixModCache, modCache()},
+	}
+
+	for _, b := range bases {
+		if b.dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(b.dir, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if b.prefix == "" {
+			return paths.ToUnix(rel)
+		}
+		return b.prefix + "/" + paths.ToUnix(rel)
+	}
+
+	return file
+}
+
+// localPath is the reverse of portablePath
+//
+// Parameter:
+//   - path string: the path returned by portablePath
+//   - root string: module root of the program on this machine
+//
+// Returns:
+//   - string: the path to the file on this machine
+func localPath(path, root string) string {
+	if rel, found := strings.CutPrefix(path, prefixGoRoot+"/"); found {
+		return filepath.Join(paths.GoPatch, filepath.FromSlash(rel))
+	}
+	if rel, found := strings.CutPrefix(path, prefixModCache+"/"); found {
+		return filepath.Join(modCache(), filepath.FromSlash(rel))
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// moduleRoot returns the folder containing the go.mod file of a folder
+//
+// Parameter:
+//   - dir string: the folder
+//
+// Returns:
+//   - string: the module root, empty if no go.mod file was found
+func moduleRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// modCache returns the go module cache
+//
+// Returns:
+//   - string: path to the module cache
+func modCache() string {
+	if cache := os.Getenv("GOMODCACHE"); cache != "" {
+		return cache
+	}
+	return filepath.Join(build.Default.GOPATH, "pkg", "mod")
+}
+
+// hashFile returns the sha256 hash of a file
+//
+// Parameter:
+//   - path string: path to the file
+//
+// Returns:
+//   - string: the hex encoded hash
+//   - error
+func hashFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GoVersion returns the version of the patched go runtime
+//
+// Returns:
+//   - string: the version, unknown if it could not be read
+func GoVersion() string {
+	content, err := os.ReadFile(filepath.Join(paths.GoPatch, "VERSION"))
+	if err != nil {
+		return "unknown"
+	}
+	version, _, _ := strings.Cut(string(content), "\n")
+	return strings.TrimSpace(version)
+}
+
+// advocateVersion returns the version of advocate from the build info
+//
+// Returns:
+//   - string: the version and vcs revision, unknown if not available
+func advocateVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	res := info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			res += " " + setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				res += " (modified)"
+			}
+		}
+	}
+
+	return res
+}
+
+// JSON returns the metadata as indented json
+//
+// Returns:
+//   - []byte: the json
+//   - error
+func (this *Meta) JSON() ([]byte, error) {
+	return json.MarshalIndent(this, "", "  ")
+}
@@ -14,6 +14,7 @@ import (
 	"advocate/utils/flags"
 	"advocate/utils/log"
 	"advocate/utils/paths"
+	"bytes"
 	"context"
 	"io"
 	"os"
@@ -39,6 +40,24 @@ var count = 0
 // Returns:
 //   - error
 func RunCommand(osOut, osErr *os.File, timeout int, name string, args ...string) error {
+	return RunCommandInput(osOut, osErr, timeout, nil, nil, name, args...)
+}
+
+// RunCommandInput runs a command line (shell) command with a given stdin
+// and environment
+//
+// Parameter:
+//   - osOut *os.File: file/output to write to not being what os.Stdout points to
+//   - osErr *os.File: file/output to write to not being what os.Stdout points to
+//   - timeout int: timeout in seconds, -1 for no timeout
+//   - stdin []byte: bytes given to stdin, nil for no stdin
+//   - env []string: environment of the command in the form NAME=value, nil to use the environment of the current process
+//   - name string: main command
+//   - args ...string: command line parameters
+//
+// Returns:
+//   - error
+func RunCommandInput(osOut, osErr *os.File, timeout int, stdin []byte, env []string, name string, args ...string) error {
 	var ctx context.Context
 	var cancel context.CancelFunc
 	if timeout > 0 {
@@ -50,6 +69,10 @@ func RunCommand(osOut, osErr *os.File, timeout int, name string, args ...string)
 	defer control.RemoveRunningCom(id)
 
 	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = env
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
 
 	if flags.Output {
 		if osOut != nil {
@@ -70,6 +93,32 @@ func RunCommand(osOut, osErr *os.File, timeout int, name string, args ...string)
 	return cmd.Run()
 }
 
+// RunCommandOutput runs a command line (shell) command and returns its standard output
+//
+// Parameter:
+//   - timeout int: timeout in seconds, -1 for no timeout
+//   - name string: main command
+//   - args ...string: command line parameters
+//
+// Returns:
+//   - []byte: the standard output of the command
+//   - error
+func RunCommandOutput(timeout int, name string, args ...string) ([]byte, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	id := control.AddRunningCom(cancel)
+	defer control.RemoveRunningCom(id)
+
+	count++
+
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
 func RunGoModTidy() {
 	log.Info("Run go mod tidy")
 
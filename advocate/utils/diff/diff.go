@@ -0,0 +1,523 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: diff.go
+// Brief: Compare two recorded traces and find the first divergence per routine
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package diff
+
+import (
+	"advocate/trace"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Kinds of divergences between two traces
+const (
+	SelectCase     = "differentSelectCase"
+	ChannelPartner = "differentChannelPartner"
+	LockOrder      = "differentLockOrder"
+	Blocked        = "differentBlocking"
+	DifferentOp    = "differentOperation"
+	DifferentObj   = "differentObject"
+	ExtraOp        = "extraOperation"
+	MissingOp      = "missingOperation"
+	ExtraRoutine   = "extraRoutine"
+	MissingRoutine = "missingRoutine"
+)
+
+// Divergence describes the first point, where one routine differs between two traces
+//
+// Fields:
+//   - Routine string: identifier of the routine, based on the fork position
+//   - Index int: index of the diverging operation in the routine
+//   - Kind string: kind of the divergence
+//   - OpA string: diverging operation in trace A, empty if it does not exist
+//   - OpB string: diverging operation in trace B, empty if it does not exist
+//   - Detail string: readable description of the divergence
+type Divergence struct {
+	Routine string `json:"routine"`
+	Index   int    `json:"index"`
+	Kind    string `json:"kind"`
+	OpA     string `json:"opA,omitempty"`
+	OpB     string `json:"opB,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+// Result is the result of the comparison of two traces
+//
+// Fields:
+//   - TraceA string: path to the first trace
+//   - TraceB string: path to the second trace
+//   - RoutinesA int: number of routines in the first trace
+//   - RoutinesB int: number of routines in the second trace
+//   - Divergences []Divergence: first divergence of each diverging routine
+type Result struct {
+	TraceA      string       `json:"traceA"`
+	TraceB      string       `json:"traceB"`
+	RoutinesA   int          `json:"routinesA"`
+	RoutinesB   int          `json:"routinesB"`
+	Divergences []Divergence `json:"divergences"`
+}
+
+// Aligned is a trace, where the routines and operations have been assigned
+// identifiers that are independent of the concrete execution. Routines are
+// identified by the position and ordinal of the fork that created them,
+// operations by their routine, position and ordinal in the routine.
+// The operation identifier corresponds to the ReplayID of the element, but
+// the routine id is replaced by the routine identifier, since routine ids
+// depend on the order in which the routines were started and differ between
+// executions. The ordinal distinguishes the iterations of a loop, which have
+// the same ReplayID.
+//
+// Fields:
+//   - routineKey map[int]string: routine id -> routine identifier
+//   - routines map[string][]trace.Element: routine identifier -> operations
+//   - opID map[trace.Element]string: operation -> operation identifier
+//   - lockPred map[trace.Element]string: lock -> identifier of the previous acquisition of the same mutex
+//   - tr *trace.Trace: the trace, used for the stable identities of the objects
+type Aligned struct {
+	tr         *trace.Trace
+	routineKey map[int]string
+	routines   map[string][]trace.Element
+	opID       map[trace.Element]string
+	lockPred   map[trace.Element]string
+}
+
+// Align computes the execution independent identifiers for a trace
+//
+// Parameter:
+//   - tr *trace.Trace: the trace
+//
+// Returns:
+//   - *Aligned: the aligned trace
+func Align(tr *trace.Trace) *Aligned {
+	res := &Aligned{
+		tr:         tr,
+		routineKey: make(map[int]string),
+		routines:   make(map[string][]trace.Element),
+		opID:       make(map[trace.Element]string),
+		lockPred:   make(map[trace.Element]string),
+	}
+
+	for id, path := range tr.ForkPaths() {
+		res.routineKey[id] = path
+	}
+
+	locks := make(map[int][]trace.Element)
+
+	for id, routine := range tr.GetTraces() {
+		key := res.routineKey[id]
+		ordinal := make(map[string]int)
+		ops := make([]trace.Element, 0)
+		for _, elem := range routine.Elems() {
+			if !isRelevant(elem) {
+				continue
+			}
+
+			pos := trace.ShortPos(elem)
+			res.opID[elem] = key + "|" + pos + "@" + strconv.Itoa(ordinal[pos])
+			ordinal[pos]++
+			ops = append(ops, elem)
+
+			if mutex, ok := elem.(*trace.ElementMutex); ok && mutex.IsLock() && mutex.IsSuc() && mutex.Committed() {
+				locks[mutex.ObjID()] = append(locks[mutex.ObjID()], mutex)
+			}
+		}
+		res.routines[key] = ops
+	}
+
+	for _, acquisitions := range locks {
+		sort.SliceStable(acquisitions, func(i, j int) bool {
+			return acquisitions[i].T(trace.Sorting) < acquisitions[j].T(trace.Sorting)
+		})
+		for i, lock := range acquisitions {
+			if i == 0 {
+				res.lockPred[lock] = ""
+			} else {
+				res.lockPred[lock] = res.opID[acquisitions[i-1]]
+			}
+		}
+	}
+
+	return res
+}
+
+// Routines returns the sorted identifiers of all routines in the aligned trace
+//
+// Returns:
+//   - []string: the routine identifiers
+func (this *Aligned) Routines() []string {
+	res := make([]string, 0, len(this.routines))
+	for key := range this.routines {
+		res = append(res, key)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Ops returns the relevant operations of a routine
+//
+// Parameter:
+//   - routine string: the routine identifier
+//
+// Returns:
+//   - []trace.Element: the operations of the routine
+func (this *Aligned) Ops(routine string) []trace.Element {
+	return this.routines[routine]
+}
+
+// OpID returns the execution independent identifier of an operation
+//
+// Parameter:
+//   - elem trace.Element: the operation
+//
+// Returns:
+//   - string: the identifier, empty if elem is not part of the aligned trace
+func (this *Aligned) OpID(elem trace.Element) string {
+	if elem == nil {
+		return ""
+	}
+	return this.opID[elem]
+}
+
+// RoutineKey returns the execution independent identifier of a routine
+//
+// Parameter:
+//   - routine int: the routine id in the trace
+//
+// Returns:
+//   - string: the routine identifier
+func (this *Aligned) RoutineKey(routine int) string {
+	return this.routineKey[routine]
+}
+
+// ObjectIdentity returns the stable identity of the object an operation
+// was executed on, e.g. the channel of a send
+//
+// Parameter:
+//   - elem trace.Element: the operation
+//
+// Returns:
+//   - string: the identity, empty for operations without a relevant object, e.g. selects
+func (this *Aligned) ObjectIdentity(elem trace.Element) string {
+	switch elem.(type) {
+	case *trace.ElementChannel, *trace.ElementMutex, *trace.ElementWait, *trace.ElementCond, *trace.ElementOnce:
+		return this.tr.GetObjectIdentity(elem.ObjID())
+	}
+	return ""
+}
+
+// LockPred returns the identifier of the previous successful acquisition of
+// the mutex acquired by a lock operation
+//
+// Parameter:
+//   - elem trace.Element: the lock operation
+//
+// Returns:
+//   - string: the identifier of the previous acquisition, empty if it was the first
+//   - bool: false if elem is not a successful lock operation
+func (this *Aligned) LockPred(elem trace.Element) (string, bool) {
+	pred, ok := this.lockPred[elem]
+	return pred, ok
+}
+
+// Compare compares two traces and returns the first divergence of each routine
+//
+// Parameter:
+//   - a *trace.Trace: the first trace
+//   - b *trace.Trace: the second trace
+//
+// Returns:
+//   - Result: the result of the comparison (TraceA and TraceB are not set)
+func Compare(a, b *trace.Trace) Result {
+	alA, alB := Align(a), Align(b)
+
+	res := Result{
+		RoutinesA:   len(alA.routines),
+		RoutinesB:   len(alB.routines),
+		Divergences: make([]Divergence, 0),
+	}
+
+	routines := alA.Routines()
+	for _, key := range alB.Routines() {
+		if _, ok := alA.routines[key]; !ok {
+			routines = append(routines, key)
+		}
+	}
+	slices.Sort(routines)
+
+	for _, key := range routines {
+		opsA, okA := alA.routines[key]
+		opsB, okB := alB.routines[key]
+
+		if !okA {
+			res.Divergences = append(res.Divergences, Divergence{
+				Routine: key, Kind: ExtraRoutine, Detail: "routine only exists in trace B"})
+			continue
+		}
+		if !okB {
+			res.Divergences = append(res.Divergences, Divergence{
+				Routine: key, Kind: MissingRoutine, Detail: "routine only exists in trace A"})
+			continue
+		}
+
+		if div := compareRoutine(key, opsA, opsB, alA, alB); div != nil {
+			res.Divergences = append(res.Divergences, *div)
+		}
+	}
+
+	return res
+}
+
+// compareRoutine finds the first divergence of a routine
+//
+// Parameter:
+//   - key string: the routine identifier
+//   - opsA []trace.Element: operations of the routine in trace A
+//   - opsB []trace.Element: operations of the routine in trace B
+//   - alA *Aligned: aligned trace A
+//   - alB *Aligned: aligned trace B
+//
+// Returns:
+//   - *Divergence: the first divergence, nil if the routine is equal in both traces
+func compareRoutine(key string, opsA, opsB []trace.Element, alA, alB *Aligned) *Divergence {
+	for i := 0; i < min(len(opsA), len(opsB)); i++ {
+		elemA, elemB := opsA[i], opsB[i]
+		div := &Divergence{Routine: key, Index: i, OpA: opString(elemA), OpB: opString(elemB)}
+
+		if trace.ShortPos(elemA) != trace.ShortPos(elemB) || elemA.Type(true) != elemB.Type(true) {
+			div.Kind = DifferentOp
+			div.Detail = fmt.Sprintf("executed %s in A, but %s in B", div.OpA, div.OpB)
+			return div
+		}
+
+		if kind, detail := compareOp(elemA, elemB, alA, alB); kind != "" {
+			div.Kind = kind
+			div.Detail = detail
+			return div
+		}
+	}
+
+	if len(opsA) > len(opsB) {
+		return &Divergence{Routine: key, Index: len(opsB), Kind: MissingOp, OpA: opString(opsA[len(opsB)]),
+			Detail: fmt.Sprintf("%d operations starting with %s are missing in B", len(opsA)-len(opsB), opString(opsA[len(opsB)]))}
+	}
+
+	if len(opsB) > len(opsA) {
+		return &Divergence{Routine: key, Index: len(opsA), Kind: ExtraOp, OpB: opString(opsB[len(opsA)]),
+			Detail: fmt.Sprintf("%d additional operations starting with %s in B", len(opsB)-len(opsA), opString(opsB[len(opsA)]))}
+	}
+
+	return nil
+}
+
+// compareOp compares two operations with the same position and type
+//
+// Parameter:
+//   - elemA trace.Element: the operation in trace A
+//   - elemB trace.Element: the operation in trace B
+//   - alA *Aligned: aligned trace A
+//   - alB *Aligned: aligned trace B
+//
+// Returns:
+//   - string: kind of the divergence, empty if the operations are equal
+//   - string: readable description of the divergence
+func compareOp(elemA, elemB trace.Element, alA, alB *Aligned) (string, string) {
+	if kind, detail := compareObject(elemA, elemB, alA, alB); kind != "" {
+		return kind, detail
+	}
+
+	switch a := elemA.(type) {
+	case *trace.ElementSelect:
+		b := elemB.(*trace.ElementSelect)
+		caseA, caseB := selectCaseString(a), selectCaseString(b)
+		if caseA != caseB {
+			return SelectCase, fmt.Sprintf("select at %s chose %s in A, but %s in B", trace.ShortPos(a), caseA, caseB)
+		}
+		if a.GetChosenCase() != nil && b.GetChosenCase() != nil {
+			if kind, detail := compareObject(a.GetChosenCase(), b.GetChosenCase(), alA, alB); kind != "" {
+				return kind, detail
+			}
+			if kind, detail := comparePartner(a.GetChosenCase(), b.GetChosenCase(), alA, alB); kind != "" {
+				return kind, detail
+			}
+		}
+	case *trace.ElementChannel:
+		if kind, detail := comparePartner(a, elemB.(*trace.ElementChannel), alA, alB); kind != "" {
+			return kind, detail
+		}
+	case *trace.ElementMutex:
+		b := elemB.(*trace.ElementMutex)
+		if a.IsSuc() != b.IsSuc() {
+			return LockOrder, fmt.Sprintf("trylock at %s succeeded in %s", trace.ShortPos(a), sucString(a.IsSuc()))
+		}
+		predA, okA := alA.lockPred[a]
+		predB, okB := alB.lockPred[b]
+		if okA && okB && predA != predB {
+			return LockOrder, fmt.Sprintf("lock at %s acquired after %s in A, but after %s in B",
+				trace.ShortPos(a), predString(predA), predString(predB))
+		}
+	}
+
+	if elemA.Committed() != elemB.Committed() {
+		blocked := "B"
+		if elemB.Committed() {
+			blocked = "A"
+		}
+		return Blocked, fmt.Sprintf("%s only blocked in %s", opString(elemA), blocked)
+	}
+
+	return "", ""
+}
+
+// compareObject compares the objects two operations were executed on
+//
+// Parameter:
+//   - elemA trace.Element: the operation in trace A
+//   - elemB trace.Element: the operation in trace B
+//   - alA *Aligned: aligned trace A
+//   - alB *Aligned: aligned trace B
+//
+// Returns:
+//   - string: kind of the divergence, empty if the objects are equal or unknown
+//   - string: readable description of the divergence
+func compareObject(elemA, elemB trace.Element, alA, alB *Aligned) (string, string) {
+	objA, objB := alA.ObjectIdentity(elemA), alB.ObjectIdentity(elemB)
+	if objA == "" || objB == "" || objA == objB {
+		return "", ""
+	}
+
+	return DifferentObj, fmt.Sprintf("%s was executed on the object created at %s in A, but at %s in B",
+		opString(elemA), objA, objB)
+}
+
+// comparePartner compares the communication partner of two channel operations
+//
+// Parameter:
+//   - a *trace.ElementChannel: the operation in trace A
+//   - b *trace.ElementChannel: the operation in trace B
+//   - alA *Aligned: aligned trace A
+//   - alB *Aligned: aligned trace B
+//
+// Returns:
+//   - string: kind of the divergence, empty if the partners are equal
+//   - string: readable description of the divergence
+func comparePartner(a, b *trace.ElementChannel, alA, alB *Aligned) (string, string) {
+	if a.Type(true) == trace.ChannelClose {
+		return "", ""
+	}
+
+	partnerA, partnerB := partnerID(a, alA), partnerID(b, alB)
+	if partnerA == partnerB {
+		return "", ""
+	}
+
+	// no partner information (e.g. communication on nil channel), fall back to the oID
+	if partnerA == "" && partnerB == "" && a.GetOID() != b.GetOID() {
+		return ChannelPartner, fmt.Sprintf("%s communicated with message %d in A, but %d in B", opString(a), a.GetOID(), b.GetOID())
+	}
+
+	return ChannelPartner, fmt.Sprintf("%s communicated with %s in A, but with %s in B",
+		opString(a), predString(partnerA), predString(partnerB))
+}
+
+// partnerID returns the identifier of the communication partner of a channel operation
+//
+// Parameter:
+//   - ch *trace.ElementChannel: the channel operation
+//   - al *Aligned: the aligned trace containing ch
+//
+// Returns:
+//   - string: the identifier of the partner, empty if there is none
+func partnerID(ch *trace.ElementChannel, al *Aligned) string {
+	partner := ch.GetPartner()
+	if partner == nil {
+		return ""
+	}
+	if sel := partner.GetSelect(); sel != nil {
+		return al.OpID(sel)
+	}
+	return al.OpID(partner)
+}
+
+// isRelevant returns if an element should be compared
+//
+// Parameter:
+//   - elem trace.Element: the element
+//
+// Returns:
+//   - bool: true if the element is a concurrency operation
+func isRelevant(elem trace.Element) bool {
+	if !trace.IsOp(elem) {
+		return false
+	}
+
+	switch elem.Type(false) {
+	case trace.Func, trace.Controll, trace.End, trace.Replay:
+		return false
+	}
+
+	return true
+}
+
+// opString returns a short description of an operation
+//
+// Parameter:
+//   - elem trace.Element: the operation
+//
+// Returns:
+//   - string: the description
+func opString(elem trace.Element) string {
+	return fmt.Sprintf("%s at %s", elem.Type(true), trace.ShortPos(elem))
+}
+
+// selectCaseString returns a description of the chosen case of a select
+//
+// Parameter:
+//   - sel *trace.ElementSelect: the select
+//
+// Returns:
+//   - string: the description
+func selectCaseString(sel *trace.ElementSelect) string {
+	if sel.GetChosenDefault() {
+		return "default"
+	}
+	if sel.GetChosenCase() == nil {
+		return "no case"
+	}
+	return fmt.Sprintf("case %d", sel.GetChosenIndex())
+}
+
+// predString returns the description of an operation identifier
+//
+// Parameter:
+//   - id string: the identifier
+//
+// Returns:
+//   - string: the description
+func predString(id string) string {
+	if id == "" {
+		return "nothing"
+	}
+	return id
+}
+
+// sucString returns the trace in which a trylock was successful
+//
+// Parameter:
+//   - sucA bool: true if the trylock was successful in trace A
+//
+// Returns:
+//   - string: the trace
+func sucString(sucA bool) string {
+	if sucA {
+		return "A but not in B"
+	}
+	return "B but not in A"
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: output.go
+// Brief: Readable and json output of a trace diff
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package diff
+
+import (
+	"advocate/utils/io"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DiffTraces reads two trace folders and compares them
+//
+// Parameter:
+//   - pathA string: path to the first trace folder
+//   - pathB string: path to the second trace folder
+//
+// Returns:
+//   - Result: the result of the comparison
+//   - error
+func DiffTraces(pathA, pathB string) (Result, error) {
+	trA, err := io.ReadTrace(pathA)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not read trace %s: %s", pathA, err.Error())
+	}
+
+	trB, err := io.ReadTrace(pathB)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not read trace %s: %s", pathB, err.Error())
+	}
+
+	res := Compare(trA, trB)
+	res.TraceA = pathA
+	res.TraceB = pathB
+
+	return res, nil
+}
+
+// String returns a readable representation of the diff result
+//
+// Returns:
+//   - string: the readable representation
+func (this *Result) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("A: %s (%d routines)\n", this.TraceA, this.RoutinesA))
+	sb.WriteString(fmt.Sprintf("B: %s (%d routines)\n\n", this.TraceB, this.RoutinesB))
+
+	if len(this.Divergences) == 0 {
+		sb.WriteString("No divergence found\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Found %d diverging routines\n\n", len(this.Divergences)))
+
+	for _, div := range this.Divergences {
+		sb.WriteString(fmt.Sprintf("Routine %s\n", div.Routine))
+		sb.WriteString(fmt.Sprintf("\t%s at operation %d: %s\n", div.Kind, div.Index, div.Detail))
+	}
+
+	return sb.String()
+}
+
+// JSON returns the json representation of the diff result
+//
+// Returns:
+//   - string: the json representation
+//   - error
+func (this *Result) JSON() (string, error) {
+	var sb strings.Builder
+
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(this)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
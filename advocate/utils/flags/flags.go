@@ -14,9 +14,20 @@ var (
 	ProgPath  string
 	RootPath  string
 	TracePath string
+	Bundle    string
 
 	ProgName string
 	ExecName string
+
+	// positional arguments after the mode, e.g. the traces for diff
+	ModeArgs []string
+)
+
+// Inputs of a main program
+var (
+	ProgArgs  string
+	ProgStdin string
+	ProgEnv   string
 )
 
 // Modes
@@ -28,11 +39,18 @@ var (
 
 // timeouts and limits
 var (
-	Timeout        int
-	TimeoutFuzzing int
-	MaxFuzzingRun  int
+	Timeout         int
+	TimeoutFuzzing  int
+	MaxFuzzingRun   int
+	MaxFlakyRuns    int
+	MaxMinimizeRuns int
 
 	MaxNumberElements int
+
+	FlightRecorder int
+	RecordFilter   string
+	SyncConfig     string
+	Stacks         int
 )
 
 // logging
@@ -51,6 +69,11 @@ var (
 	CheckBenign      bool
 )
 
+// output format
+var (
+	JSON bool
+)
+
 // memory and panic
 var (
 	NoMemorySupervisor bool
@@ -61,6 +84,7 @@ var (
 var (
 	IgnoreAtomics         bool
 	IgnoreCriticalSection bool
+	PartialOrder          bool
 
 	OnlyAPanicAndLeak bool
 
@@ -68,7 +92,10 @@ var (
 
 	Settings string
 
-	Scenarios string
+	Scenarios   string
+	QueryConfig string
+
+	Static bool
 )
 
 // execution control
@@ -78,4 +105,6 @@ var (
 	NoRewrite     bool
 	NoSkipRewrite bool
 	DeleteTraces  bool
+	FlakyFuzzing  bool
+	Minimize      bool
 )
@@ -30,6 +30,31 @@ const (
 	ResourceDeadlock AnalysisCases = "resourceDeadlock"
 )
 
+// letters of the analysis cases used with -scen, filled by the registration
+// of the scenarios
+var analysisCaseLetters = make(map[rune]AnalysisCases)
+
+// RegisterAnalysisCase adds an analysis case that can be selected with -scen.
+// Registering an existing case with the same letter again has no effect.
+//
+// Parameter:
+//   - letter rune: the letter used with -scen
+//   - c AnalysisCases: the analysis case
+//
+// Returns:
+//   - error: if the letter is already used for another case
+func RegisterAnalysisCase(letter rune, c AnalysisCases) error {
+	if existing, ok := analysisCaseLetters[letter]; ok && existing != c {
+		return fmt.Errorf("Analysis case letter %c is already used for %s", letter, existing)
+	}
+	if letter == 'r' {
+		return fmt.Errorf("Analysis case letter r is reserved")
+	}
+
+	analysisCaseLetters[letter] = c
+	return nil
+}
+
 // ParseAnalysisCases parses the given analysis cases
 //
 // Returns:
@@ -37,16 +62,11 @@ const (
 //   - error: An error if the cases could not be parsed
 func ParseAnalysisCases() (map[AnalysisCases]bool, error) {
 	analysisCases := map[AnalysisCases]bool{
-		All:          false, // all cases enabled
-		SendOnClosed: false,
+		All: false, // all cases enabled
 		// ReceiveOnClosed:  false,
-		DoneBeforeAdd:    false,
-		CloseOnClosed:    false,
-		ConcurrentRecv:   false,
-		Leak:             false,
-		UnlockBeforeLock: false,
-		MixedDeadlock:    false,
-		ResourceDeadlock: false,
+	}
+	for _, c := range analysisCaseLetters {
+		analysisCases[c] = false
 	}
 
 	if Scenarios == "-" {
@@ -66,29 +86,16 @@ func ParseAnalysisCases() (map[AnalysisCases]bool, error) {
 	}
 
 	for _, c := range Scenarios {
-		switch c {
-		case 's':
-			analysisCases[SendOnClosed] = true
-		case 'r':
+		if c == 'r' {
 			// analysisCases[ReceiveOnClosed] = true
-		case 'w':
-			analysisCases[DoneBeforeAdd] = true
-		case 'n':
-			analysisCases[CloseOnClosed] = true
-		case 'b':
-			analysisCases[ConcurrentRecv] = true
-		case 'l':
-			analysisCases[Leak] = true
-		case 'u':
-			analysisCases[UnlockBeforeLock] = true
-		case 'c':
-			analysisCases[ResourceDeadlock] = true
-		// MIXED DEADLOCK [REMOVE]
-		case 'm':
-			analysisCases[MixedDeadlock] = true
-		default:
+			continue
+		}
+
+		analysisCase, ok := analysisCaseLetters[c]
+		if !ok {
 			return nil, fmt.Errorf("Invalid analysis case: %c", c)
 		}
+		analysisCases[analysisCase] = true
 	}
 
 	all := true
@@ -20,16 +20,19 @@ var (
 
 	// submodes
 	runMain      = newFlagVal("main", "false", "", "Set to run on main function. If not set, the unit tests are run")
-	fuzzingModes = newFlagVal("mode", "", "", "Mode for fuzzing. Possible values are:", "\tGFuzz", "\tGFuzzHB", "\tGFuzzHBFlow", "\tFlow", "\tGoPie", "\tGoCR", "\tGoCRHB")
+	fuzzingModes = newFlagVal("mode", "", "", "Mode for fuzzing. Possible values are:", "\tGFuzz", "\tGFuzzHB", "\tGFuzzHBFlow", "\tFlow", "\tGoPie", "\tGoCR", "\tGoCRHB", "\tAdaptive")
 
 	// paths
-	path  = newFlagVal("path", "", "", "Path to the program folder, for main: path to main file, for test: path to test folder")
-	root  = newFlagVal("root", "", "if different from path", "Path to the root of the program folder. Must only be set if different from path")
-	prog  = newFlagVal("prog", "", "-stat/-time/-notExec", "Name of the program")
-	prog2 = newFlagVal("prog", "", "", "Name of the program")
-	exec1 = newFlagVal("exec", "", "-main", "Name of the executable or test. If set for test, only this test will be executed, otherwise all tests will be run")
-	exec2 = newFlagVal("exec", "", "", "Name of the executable or test")
-	trace = newFlagVal("trace", "", "", "Path to the trace folder to replay")
+	path   = newFlagVal("path", "", "", "Path to the program folder, for main: path to main file, for test: path to test folder")
+	root   = newFlagVal("root", "", "if different from path", "Path to the root of the program folder. Must only be set if different from path")
+	prog   = newFlagVal("prog", "", "-stat/-time/-notExec", "Name of the program")
+	prog2  = newFlagVal("prog", "", "", "Name of the program")
+	exec1  = newFlagVal("exec", "", "-main", "Name of the executable or test. If set for test, only this test will be executed, otherwise all tests will be run. Subtests can be set as Test/sub")
+	exec2  = newFlagVal("exec", "", "", "Name of the executable or test")
+	trace  = newFlagVal("trace", "", "if -bundle is not set", "Path to the trace folder to replay")
+	trace2 = newFlagVal("trace", "", "", "Path to the trace folder to query")
+	path2  = newFlagVal("path", "", "", "Path to the program folder to analyze")
+	bundle = newFlagVal("bundle", "", "", "Path to a bundle created with the bundle mode. The source files are verified and the trace of the bundle is replayed. If -exec is not set, the test of the bundle is used")
 
 	// scenarios
 	scenarios = newFlagVal("scen", "", "", "Select which analysis scenario to run, e.g. -scen srd for the option s, r and d",
@@ -42,7 +45,8 @@ var (
 		"\tb: Concurrent receive on channel",
 		"\tl: Leaking routine",
 		"\tu: Unlock of unlocked mutex",
-		"\tc: Cyclic deadlock")
+		"\tc: Cyclic deadlock",
+		"\tq: Matches of the queries set with -queries")
 	noWarning = newFlagVal("noWarning", "false", "", "Only show critical bugs")
 	onlyA     = newFlagVal("onlyActual", "false", "", "only test for actual bugs leading to panic and actual leaks. This will overwrite `scen`")
 
@@ -52,9 +56,20 @@ var (
 	timeoutFuz    = newFlagVal("timeoutFuz", "420", "", "Timeout of fuzzing per test/program in seconds. To Disable, set to -1")
 	maxFuzzingRun = newFlagVal("maxFuzzingRuns", "-1", "", "Maximum number of fuzzing runs per test/prog. To Disable, set to -1")
 
+	// flight recorder and package filter
+	flightRecorder = newFlagVal("flightRecorder", "0", "", "Only keep the last n elements of each routine while recording and dump the trace on a deadlock, panic, SIGUSR1 or advocatego.Dump(). To disable set to 0")
+	filter         = newFlagVal("filter", "", "", "Comma separated package patterns to record, e.g. example.com/app/...,-example.com/app/gen/... Patterns starting with - are excluded. Synchronization through filtered packages is kept as summaries")
+	queries        = newFlagVal("queries", "", "", "Path to a file with trace queries, one per line, e.g. \"noSendAfterClose: a.type == close && b.type == send && sameObj && concurrent(a, b)\". Matches of the queries are reported as results of the analysis")
+	static         = newFlagVal("static", "false", "", "Run the static blocking analysis before the tests. Tests with possibly blocking operations are run first and the GoPie and Guided mutations start at those operations")
+	syncConfig     = newFlagVal("syncConfig", "", "", "Path to a file that maps functions of user defined synchronization primitives to the roles lock, unlock, send, recv, signal and wait, e.g. \"mypkg.(*Sem).Acquire lock\"")
+	stacks         = newFlagVal("stacks", "0", "", "Record call stacks with at most n frames for channel, mutex, wait group and cond operations and show them in the bug reports. To disable set to 0")
+	progArgs       = newFlagVal("args", "", "", "Only for main: command line arguments of the program, e.g. \"-v 'file name'\". The arguments are stored in the trace and used again in replay and fuzzing")
+	progStdin      = newFlagVal("stdin", "", "", "Only for main: path to a file whose content is given to stdin of the program. The content is stored in the trace and used again in replay and fuzzing")
+	progEnv        = newFlagVal("env", "", "", "Only for main: comma separated environment variables of the program, either NAME to record the current value or NAME=value. The values are stored in the trace and used again in replay and fuzzing")
+
 	// statistics
 	measureTime = newFlagVal("time", "false", "", "Measure the execution times of programs/tests and analysis")
-	notExec     = newFlagVal("notExec", "false", "", "Find never executed operations")
+	notExec     = newFlagVal("notExec", "false", "", "Find never executed operations and create a concurrency coverage report")
 	stats       = newFlagVal("stats", "false", "", "Create statistics")
 
 	// logging and output
@@ -78,10 +93,22 @@ var (
 	ignoreCriticalSection = newFlagVal("ignoreCritSec", "false", "", "Ignore happens before relations of critical sections")
 	ignoreAtomics         = newFlagVal("ignoreAtomics", "false", "", "Ignore atomic operations. Use to reduce memory required for large traces")
 	replayAll             = newFlagVal("replayAll", "false", "", "Replay a bug even if it has already been confirmed")
+	partialOrder          = newFlagVal("partialOrder", "false", "", "Replay by only enforcing the order of operations on the same channel, mutex, wait group, cond or once. Independent operations run freely")
+	minimize              = newFlagVal("minimize", "false", "", "Minimize confirmed rewritten traces to the orderings required to reproduce the bug")
+	minimizeRuns          = newFlagVal("minimizeRuns", "50", "", "Maximum number of replays used to minimize one rewritten trace")
 	noRewrite             = newFlagVal("noRewrite", "true", "", "Do not rewrite/replay the trace file")
 	deleteTrace           = newFlagVal("deleteTrace", "false", "", "If set, the traces are deleted after analysis. Can avoid the need to store all trace files")
 	settings              = newFlagVal("settings", "", "", "Set some internal settings. For more info, see ../doc/usage.md")
 	cancelTestIfFound     = newFlagVal("cancelTestIfBugFound", "", "false", "Skip further fuzzing runs of a test if one bug has been found. Mostly used for benchmarks")
+
+	// output format
+	jsonOut = newFlagVal("json", "false", "", "Print the result as json")
+
+	// flaky
+	exec3      = newFlagVal("exec", "", "test", "Name of the test to analyze")
+	flakyRuns  = newFlagVal("flakyRuns", "100", "", "Maximum number of runs of the test")
+	flakyFuzz  = newFlagVal("flakyFuzz", "false", "", "Run the test under fuzzing mutations instead of plain recordings")
+	flakyModes = newFlagVal("mode", "Guided", "", "Fuzzing mode used with -flakyFuzz")
 )
 
 // flagValue is a struct to store one flag value and its description
@@ -166,6 +193,16 @@ func PrintHelpMode(mode string) {
 		printHelpRecord()
 	case "replay":
 		printHelpReplay()
+	case "diff":
+		printHelpDiff()
+	case "bundle":
+		printHelpBundle()
+	case "query":
+		printHelpQuery()
+	case "static":
+		printHelpStatic()
+	case "flaky":
+		printHelpFlaky()
 	default:
 		fmt.Printf("Unknown mode '%s'\n\n", mode)
 		printHeader()
@@ -177,16 +214,26 @@ func PrintHelpMode(mode string) {
 func printHeader() {
 	fmt.Println("Usage: ./advocate [mode] [args]")
 	fmt.Println("")
-	fmt.Println("Advocate contains four different mode. These are:")
+	fmt.Println("Advocate contains the following modes:")
 	fmt.Println("\trecord")
 	fmt.Println("\treplay")
 	fmt.Println("\tanalysis")
 	fmt.Println("\tfuzzing")
+	fmt.Println("\tflaky")
+	fmt.Println("\tdiff")
+	fmt.Println("\tbundle")
+	fmt.Println("\tquery")
+	fmt.Println("\tstatic")
 	fmt.Println("")
 	fmt.Println("With 'record', the execution of a program or test can be recorded into a trace.")
 	fmt.Println("With 'replay', a program or test can be forced to follow the execution schedule specified in a trace.")
 	fmt.Println("With 'analyzer', a program or test can be recorded and then analyzed to find potential bugs. For some bugs, a rewrite and replay mechanism has been implemented to confirm the potential bugs.")
 	fmt.Println("With 'fuzzing', different fuzzing approaches can be run on a program or test.")
+	fmt.Println("With 'flaky', a flaky test can be run repeatedly to find the ordering decisions that make it fail.")
+	fmt.Println("With 'diff', two recorded traces of the same program or test can be compared.")
+	fmt.Println("With 'bundle', a found bug can be packed into a single archive that can be replayed on another machine.")
+	fmt.Println("With 'query', a recorded trace can be searched for operations matching a pattern.")
+	fmt.Println("With 'static', the source of a program can be searched for possibly blocking operations without running it.")
 	fmt.Print("\n\n")
 	fmt.Println("For more information about the mode and there functionality, see the doc folder in the repository.")
 	fmt.Println("For information on how to prepare the required runtime, see the usage file linked in the README")
@@ -256,6 +303,13 @@ func printHelpRecord() {
 
 	// timeout
 	fmt.Println(timeoutRec.toString(false))
+	fmt.Println(flightRecorder.toString(false))
+	fmt.Println(filter.toString(false))
+	fmt.Println(syncConfig.toString(false))
+	fmt.Println(stacks.toString(false))
+	fmt.Println(progArgs.toString(false))
+	fmt.Println(progStdin.toString(false))
+	fmt.Println(progEnv.toString(false))
 
 	// statistics
 	fmt.Println(measureTime.toString(false))
@@ -301,6 +355,7 @@ func printHelpReplay() {
 	fmt.Println(path.toString(true))
 	fmt.Println(exec2.toString(true))
 	fmt.Println(trace.toString(true))
+	fmt.Println(bundle.toString(false))
 
 	// timeout
 	fmt.Println(timeoutRep.toString(false))
@@ -316,6 +371,149 @@ func printHelpReplay() {
 
 	// settings
 	fmt.Println(ignoreAtomics.toString(false))
+	fmt.Println(partialOrder.toString(false))
+}
+
+// print help for flaky mode
+func printHelpFlaky() {
+	fmt.Println("Mode: flaky")
+	fmt.Println("")
+	fmt.Println("Run a test repeatedly until it has both passing and failing executions. The traces of the")
+	fmt.Println("two groups are compared and the ordering decisions (select cases, channel partners,")
+	fmt.Println("lock order) that separate them are ranked.")
+	fmt.Println("")
+
+	printFlagHeader()
+
+	// help
+	fmt.Println(help1.toString(false))
+	fmt.Println(help2.toString(false))
+
+	// submodes
+	fmt.Println(runMain.toString(false))
+	fmt.Println(flakyFuzz.toString(false))
+	fmt.Println(flakyModes.toString(false))
+
+	// paths
+	fmt.Println(path.toString(true))
+	fmt.Println(exec3.toString(true))
+
+	// timeout
+	fmt.Println(timeoutRec.toString(false))
+	fmt.Println(flakyRuns.toString(false))
+
+	// logging and output
+	fmt.Println(noInfo.toString(false))
+	fmt.Println(output.toString(false))
+
+	// memory
+	fmt.Println(maxNumberElem.toString(false))
+	fmt.Println(noMemorySupervisor.toString(false))
+}
+
+// print help for diff mode
+func printHelpDiff() {
+	fmt.Println("Mode: diff")
+	fmt.Println("")
+	fmt.Println("Usage: ./advocate diff [traceA] [traceB]")
+	fmt.Println("")
+	fmt.Println("Compare two recorded traces of the same program or test. Routines are aligned by the position")
+	fmt.Println("and ordinal of the fork that created them, operations by their position in the routine.")
+	fmt.Println("For each routine, the first divergence is printed, e.g. a different select case, a different")
+	fmt.Println("channel partner, a different lock acquisition order or extra or missing operations.")
+	fmt.Println("")
+
+	printFlagHeader()
+
+	// help
+	fmt.Println(help1.toString(false))
+	fmt.Println(help2.toString(false))
+
+	// output
+	fmt.Println(jsonOut.toString(false))
+
+	// memory
+	fmt.Println(maxNumberElem.toString(false))
+}
+
+// print help for bundle mode
+func printHelpBundle() {
+	fmt.Println("Mode: bundle")
+	fmt.Println("")
+	fmt.Println("Usage: ./advocate bundle [bug.md] [out.tar.gz]")
+	fmt.Println("")
+	fmt.Println("Pack a bug report from the advocateResult folder into a single archive. The archive contains the")
+	fmt.Println("rewritten trace of the bug, metadata (test, exit code, command, Go and ADVOCATE version) and the")
+	fmt.Println("hashes of all source files mentioned in the trace. If no output path is given, the archive is")
+	fmt.Println("written into the current folder. The bundle can be replayed with ./advocate replay -bundle [bundle].")
+	fmt.Println("")
+
+	printFlagHeader()
+
+	// help
+	fmt.Println(help1.toString(false))
+	fmt.Println(help2.toString(false))
+}
+
+// print help for query mode
+func printHelpQuery() {
+	fmt.Println("Mode: query")
+	fmt.Println("")
+	fmt.Println("Usage: ./advocate query -trace [trace] '[query]'")
+	fmt.Println("")
+	fmt.Println("Evaluate a query over a recorded trace and print all matches. A query binds variables to trace")
+	fmt.Println("elements and combines comparisons of their fields (type, kind, obj, id, routine, pos, file, line,")
+	fmt.Println("name, tpre, tpost) with the predicates hb(a,b), concurrent(a,b), sameObj(a,b), sameRoutine(a,b)")
+	fmt.Println("and holds(a,b) using &&, || and !, e.g.")
+	fmt.Println("\t'a.type == close && b.type == send && sameObj && concurrent(a, b)'")
+	fmt.Println("\t'a.type == lock && b.type == lock && !sameObj && holds(a, b) && b.pos ~ \"pkg/*.go:*\"'")
+	fmt.Println("For the full language, see ../doc/analysis.md.")
+	fmt.Println("")
+
+	printFlagHeader()
+
+	// help
+	fmt.Println(help1.toString(false))
+	fmt.Println(help2.toString(false))
+
+	// paths
+	fmt.Println(trace2.toString(true))
+
+	// output
+	fmt.Println(jsonOut.toString(false))
+
+	// memory
+	fmt.Println(maxNumberElem.toString(false))
+
+	// settings
+	fmt.Println(noFifo.toString(false))
+	fmt.Println(ignoreCriticalSection.toString(false))
+	fmt.Println(ignoreAtomics.toString(false))
+}
+
+// print help for static mode
+func printHelpStatic() {
+	fmt.Println("Mode: static")
+	fmt.Println("")
+	fmt.Println("Usage: ./advocate static -path [folder]")
+	fmt.Println("")
+	fmt.Println("Run the static blocking analysis on the source of a program without executing it. The analysis")
+	fmt.Println("reports channel operations that can never have a partner, locks acquired in inconsistent orders")
+	fmt.Println("and wait groups whose Add and Done calls can diverge. The tests of the program are ordered by the")
+	fmt.Println("findings reachable from them. To use the result in the analysis or fuzzing, set -static.")
+	fmt.Println("")
+
+	printFlagHeader()
+
+	// help
+	fmt.Println(help1.toString(false))
+	fmt.Println(help2.toString(false))
+
+	// paths
+	fmt.Println(path2.toString(true))
+
+	// output
+	fmt.Println(jsonOut.toString(false))
 }
 
 // print help for analysis mode
@@ -340,11 +538,20 @@ func printHelpAnalysis() {
 
 	// scenarios
 	fmt.Println(scenarios.toString(false))
+	fmt.Println(queries.toString(false))
+	fmt.Println(static.toString(false))
 	fmt.Println(noWarning.toString(false))
 	fmt.Println(onlyA.toString(false))
 
 	// timeout
 	fmt.Println(timeoutRec.toString(false))
+	fmt.Println(flightRecorder.toString(false))
+	fmt.Println(filter.toString(false))
+	fmt.Println(syncConfig.toString(false))
+	fmt.Println(stacks.toString(false))
+	fmt.Println(progArgs.toString(false))
+	fmt.Println(progStdin.toString(false))
+	fmt.Println(progEnv.toString(false))
 	fmt.Println(timeoutRep.toString(false))
 
 	// statistics
@@ -373,6 +580,9 @@ func printHelpAnalysis() {
 	fmt.Println(ignoreCriticalSection.toString(false))
 	fmt.Println(ignoreAtomics.toString(false))
 	fmt.Println(replayAll.toString(false))
+	fmt.Println(partialOrder.toString(false))
+	fmt.Println(minimize.toString(false))
+	fmt.Println(minimizeRuns.toString(false))
 	fmt.Println(noRewrite.toString(false))
 	fmt.Println(deleteTrace.toString(false))
 }
@@ -399,6 +609,8 @@ func printHelpFuzzing() {
 
 	// scenarios
 	fmt.Println(scenarios.toString(false))
+	fmt.Println(queries.toString(false))
+	fmt.Println(static.toString(false))
 	fmt.Println(noWarning.toString(false))
 	fmt.Println(onlyA.toString(false))
 
@@ -407,6 +619,12 @@ func printHelpFuzzing() {
 	fmt.Println(timeoutRep.toString(false))
 	fmt.Println(timeoutFuz.toString(false))
 	fmt.Println(maxFuzzingRun.toString(false))
+	fmt.Println(filter.toString(false))
+	fmt.Println(syncConfig.toString(false))
+	fmt.Println(stacks.toString(false))
+	fmt.Println(progArgs.toString(false))
+	fmt.Println(progStdin.toString(false))
+	fmt.Println(progEnv.toString(false))
 
 	// statistics
 	fmt.Println(measureTime.toString(false))
@@ -430,6 +648,8 @@ func printHelpFuzzing() {
 	fmt.Println(ignoreCriticalSection.toString(false))
 	fmt.Println(ignoreAtomics.toString(false))
 	fmt.Println(replayAll.toString(false))
+	fmt.Println(minimize.toString(false))
+	fmt.Println(minimizeRuns.toString(false))
 	fmt.Println(noRewrite.toString(false))
 	fmt.Println(deleteTrace.toString(false))
 	fmt.Println(settings.toString(false))
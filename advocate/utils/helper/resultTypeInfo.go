@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: resultTypeInfo.go
+// Brief: Metadata of result types that are added by scenarios outside of
+//    the built-in analysis
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResultTypeInfo contains the metadata of a result type
+//
+// Fields:
+//   - Type ResultType: the code of the result, e.g. X01
+//   - Name string: readable name of the result
+//   - Crit string: Bug, Diagnostic or Leak, see consts
+//   - Actual bool: true if the result describes a bug that occurred in the recorded run
+//   - Explanation string: explanation of the result used in the bug reports
+type ResultTypeInfo struct {
+	Type        ResultType
+	Name        string
+	Crit        string
+	Actual      bool
+	Explanation string
+}
+
+// metadata of the result types that are not built in
+var customResultTypes = make(map[ResultType]ResultTypeInfo)
+
+// RegisterResultType adds a result type that is not built in. The code must
+// not be a code of a built-in result type and must not contain any of the
+// separators used in the result files. Codes of registered result types
+// should start with X, codes starting with L are handled as leaks.
+//
+// Parameter:
+//   - info ResultTypeInfo: the metadata of the result type
+//
+// Returns:
+//   - error: if the code is invalid or already registered
+func RegisterResultType(info ResultTypeInfo) error {
+	code := string(info.Type)
+	if code == "" || strings.ContainsAny(code, ",:;#!\n ") {
+		return fmt.Errorf("invalid result code %q", code)
+	}
+
+	if ResultTypeFromString(code) != Empty {
+		return fmt.Errorf("result code %s is already used", code)
+	}
+
+	customResultTypes[info.Type] = info
+	return nil
+}
+
+// GetResultTypeInfo returns the metadata of a result type that is not built in
+//
+// Parameter:
+//   - rt ResultType: the result type
+//
+// Returns:
+//   - ResultTypeInfo: the metadata
+//   - bool: false if the result type has not been registered
+func GetResultTypeInfo(rt ResultType) (ResultTypeInfo, bool) {
+	info, ok := customResultTypes[rt]
+	return info, ok
+}
+
+// GetResultTypeInfos returns the metadata of all result types that are not built in
+//
+// Returns:
+//   - []ResultTypeInfo: the metadata, sorted by code
+func GetResultTypeInfos() []ResultTypeInfo {
+	res := make([]ResultTypeInfo, 0, len(customResultTypes))
+	for _, info := range customResultTypes {
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Type < res[j].Type
+	})
+	return res
+}
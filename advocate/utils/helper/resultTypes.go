@@ -168,6 +168,9 @@ func ResultTypeFromString(code string) ResultType {
 	case "R02":
 		return RTimeout
 	default:
+		if info, ok := customResultTypes[ResultType(code)]; ok {
+			return info.Type
+		}
 		return Empty // Return Empty for codes not found
 	}
 }
@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: progInput.go
+// Brief: Read and write the inputs a main program was recorded with
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package io
+
+import (
+	"advocate/utils/paths"
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProgInput contains the inputs a main program is run with
+//
+// Fields:
+//   - Args []string: the command line arguments
+//   - Env []string: the recorded environment variables in the form NAME=value
+//   - Unset []string: names of recorded environment variables that were not set
+//   - Stdin []byte: the bytes given to stdin, nil if no stdin is given
+type ProgInput struct {
+	Args  []string
+	Env   []string
+	Unset []string
+	Stdin []byte
+}
+
+// IsEmpty returns if no input is set
+//
+// Returns:
+//   - bool: true if no arguments, environment variables or stdin are set
+func (this ProgInput) IsEmpty() bool {
+	return len(this.Args) == 0 && len(this.Env) == 0 && len(this.Unset) == 0 && this.Stdin == nil
+}
+
+// Environ returns the environment of the program. It is the environment
+// of the current process with the recorded variables set or removed.
+//
+// Returns:
+//   - []string: the environment in the form NAME=value
+func (this ProgInput) Environ() []string {
+	recorded := make(map[string]struct{})
+	for _, env := range this.Env {
+		name, _, _ := strings.Cut(env, "=")
+		recorded[name] = struct{}{}
+	}
+	for _, name := range this.Unset {
+		recorded[name] = struct{}{}
+	}
+
+	res := make([]string, 0)
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if _, ok := recorded[name]; !ok {
+			res = append(res, env)
+		}
+	}
+
+	return append(res, this.Env...)
+}
+
+// CommandLine returns a shell command that runs a program with the input,
+// e.g. NAME=value ./prog arg < stdin
+//
+// Parameter:
+//   - exec string: the executable
+//   - stdinFile string: file containing the stdin, only used if stdin is set
+//
+// Returns:
+//   - string: the command line
+func (this ProgInput) CommandLine(exec, stdinFile string) string {
+	parts := make([]string, 0)
+	for _, name := range this.Unset {
+		parts = append(parts, "-u", shellQuote(name))
+	}
+	if len(parts) > 0 {
+		parts = append([]string{"env"}, parts...)
+	}
+
+	for _, env := range this.Env {
+		name, value, _ := strings.Cut(env, "=")
+		parts = append(parts, name+"="+shellQuote(value))
+	}
+
+	parts = append(parts, exec)
+	for _, arg := range this.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+
+	if this.Stdin != nil {
+		parts = append(parts, "<", shellQuote(stdinFile))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// WriteProgInput writes the input into the program_input.log file in a
+// trace folder. Nothing is written if the input is empty. Each line has the form
+//
+//	Arg!"[arg]"
+//	Env![name]!"[value]"
+//	Unset![name]
+//	Stdin!"[stdin]"
+//
+// Parameter:
+//   - input ProgInput: the input
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - error
+func WriteProgInput(input ProgInput, tracePath string) error {
+	if input.IsEmpty() {
+		return nil
+	}
+
+	res := ""
+	for _, arg := range input.Args {
+		res += "Arg!" + strconv.Quote(arg) + "\n"
+	}
+	for _, env := range input.Env {
+		name, value, _ := strings.Cut(env, "=")
+		res += "Env!" + name + "!" + strconv.Quote(value) + "\n"
+	}
+	for _, name := range input.Unset {
+		res += "Unset!" + name + "\n"
+	}
+	if input.Stdin != nil {
+		res += "Stdin!" + strconv.Quote(string(input.Stdin)) + "\n"
+	}
+
+	return os.WriteFile(filepath.Join(tracePath, paths.NameProgInput), []byte(res), 0644)
+}
+
+// ReadProgInput reads the input from the program_input.log file in a trace folder
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//
+// Returns:
+//   - ProgInput: the input
+//   - bool: true if the trace folder contains an input file
+//   - error
+func ReadProgInput(tracePath string) (ProgInput, bool, error) {
+	res := ProgInput{}
+
+	file, err := os.Open(filepath.Join(tracePath, paths.NameProgInput))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, false, nil
+		}
+		return res, false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "!")
+		switch key {
+		case "Arg":
+			arg, err := strconv.Unquote(value)
+			if err != nil {
+				return res, true, fmt.Errorf("invalid argument %s", value)
+			}
+			res.Args = append(res.Args, arg)
+		case "Env":
+			name, quoted, _ := strings.Cut(value, "!")
+			env, err := strconv.Unquote(quoted)
+			if err != nil {
+				return res, true, fmt.Errorf("invalid value of environment variable %s", name)
+			}
+			res.Env = append(res.Env, name+"="+env)
+		case "Unset":
+			res.Unset = append(res.Unset, value)
+		case "Stdin":
+			stdin, err := strconv.Unquote(value)
+			if err != nil {
+				return res, true, fmt.Errorf("invalid stdin")
+			}
+			res.Stdin = []byte(stdin)
+		default:
+			return res, true, fmt.Errorf("unknown program input line %s", line)
+		}
+	}
+
+	return res, true, scanner.Err()
+}
+
+// shellQuote quotes a string for the use in a shell command if necessary
+//
+// Parameter:
+//   - s string: the string
+//
+// Returns:
+//   - string: the quoted string
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\$`&|;<>()*?[]#~!{}") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
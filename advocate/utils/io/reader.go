@@ -27,6 +27,7 @@ import (
 )
 
 // CreateTraceFromFiles creates the trace from all files in a folder.
+// The created trace is set as the main trace
 //
 // Parameter:
 //   - filePath string: The path to the folder
@@ -36,21 +37,60 @@ import (
 //   - int: The number of elements
 //   - error: An error if the trace could not be created
 func CreateTraceFromFiles(folderPath string) (int, int, error) {
+	tr, numberRoutines, elemCounter, err := readTraceFolder(folderPath, true)
+	if err != nil {
+		return numberRoutines, elemCounter, err
+	}
+
+	a_base.SetMainTrace(&tr)
+
+	return numberRoutines, elemCounter, nil
+}
+
+// ReadTrace reads the trace in a folder without setting it as the main trace
+// and without reading the trace info file. It is used to read additional traces,
+// e.g. the recorded and rewritten traces for the bug reports
+//
+// Parameter:
+//   - folderPath string: The path to the folder
+//
+// Returns:
+//   - *trace.Trace: the trace
+//   - error
+func ReadTrace(folderPath string) (*trace.Trace, error) {
+	tr, _, _, err := readTraceFolder(folderPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tr, nil
+}
+
+// readTraceFolder reads all trace files in a folder into a new trace
+//
+// Parameter:
+//   - folderPath string: The path to the folder
+//   - readInfo bool: if true, the trace info file is read and stored in the analysis data
+//
+// Returns:
+//   - trace.Trace: the read trace
+//   - int: The number of routines
+//   - int: The number of elements
+//   - error: An error if the trace could not be created
+func readTraceFolder(folderPath string, readInfo bool) (trace.Trace, int, int, error) {
 	timer.Start(timer.Io)
 	defer timer.Stop(timer.Io)
 
 	numberRoutines := 0
+	tr := trace.NewTrace()
+
 	// traverse all files in the folder
 	files, err := os.ReadDir(folderPath)
 	if err != nil {
-		return 0, 0, err
+		return tr, 0, 0, err
 	}
 
-	tr := trace.NewTrace()
-
-	if err != nil {
-		log.Error(err)
-	}
+	var identities map[int]string
 
 	elemCounter := 0
 	for _, file := range files {
@@ -65,7 +105,32 @@ func CreateTraceFromFiles(folderPath string) (int, int, error) {
 		filePath := filepath.Join(folderPath, file.Name())
 
 		if file.Name() == paths.NameTraceInfo {
-			getTraceInfoFromFile(filePath)
+			if readInfo {
+				getTraceInfoFromFile(filePath)
+			}
+			continue
+		}
+
+		if file.Name() == paths.NameStacks {
+			if err := readStacksFromFile(&tr, filePath); err != nil {
+				log.Errorf("Could not read stacks: %s", err.Error())
+			}
+			continue
+		}
+
+		if file.Name() == paths.NameNondet {
+			if err := readNondetFromFile(&tr, filePath); err != nil {
+				log.Errorf("Could not read nondeterministic calls: %s", err.Error())
+			}
+			continue
+		}
+
+		if file.Name() == paths.NameIdentities {
+			identities, err = readIdentitiesFromFile(filePath)
+			if err != nil {
+				log.Errorf("Could not read object identities: %s", err.Error())
+			}
+			continue
 		}
 
 		routine, err := getRoutineFromFileName(file.Name())
@@ -75,24 +140,31 @@ func CreateTraceFromFiles(folderPath string) (int, int, error) {
 
 		numberElems, err := createTraceFromFile(&tr, filePath, routine)
 		if err != nil {
-			return 0, elemCounter, err
+			return tr, 0, elemCounter, err
 		}
 		elemCounter += numberElems
 		numberRoutines++
 
 		if elemCounter > flags.MaxNumberElements {
-			return numberRoutines, elemCounter, fmt.Errorf("Too many elements")
+			return tr, numberRoutines, elemCounter, fmt.Errorf("Too many elements")
 		}
 
 		if control.WasCanceled() {
-			return numberRoutines, elemCounter, fmt.Errorf("Canceled by memory")
+			return tr, numberRoutines, elemCounter, fmt.Errorf("Canceled by memory")
 		}
 	}
 
 	tr.Sort()
-	a_base.SetMainTrace(&tr)
 
-	return numberRoutines, elemCounter, nil
+	// the identities are recorded by the runtime. They are only computed
+	// for traces without the identity table
+	if identities != nil {
+		tr.SetObjectIdentities(identities)
+	} else {
+		tr.ComputeObjectIdentities()
+	}
+
+	return tr, numberRoutines, elemCounter, nil
 }
 
 // getTraceInfoFromFile reads in the information from a the trace_info.log file
@@ -121,6 +193,11 @@ func getTraceInfoFromFile(filePath string) error {
 	activeReleased := 0
 	allActiveReleased := 0
 
+	flightRecorder := 0
+	dumpReason := ""
+	filter := ""
+	ignoreRegions := false
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineSplit := strings.Split(line, "!")
@@ -157,6 +234,14 @@ func getTraceInfoFromFile(filePath string) error {
 			}
 		case "AllActiveReleased":
 			allActiveReleased, _ = strconv.Atoi(lineSplit[1])
+		case "FlightRecorder":
+			flightRecorder, err = strconv.Atoi(lineSplit[1])
+		case "Dump":
+			dumpReason = lineSplit[1]
+		case "Filter":
+			filter = lineSplit[1]
+		case "Ignore":
+			ignoreRegions = lineSplit[1] == "1"
 		}
 
 		if err != nil {
@@ -166,10 +251,156 @@ func getTraceInfoFromFile(filePath string) error {
 
 	a_base.SetExitInfo(exitCode, exitPos)
 	a_base.SetReplayInfo(timeoutOldest, timeoutDisabled, timeoutAck, activeReleased, allActiveReleased)
+	a_base.SetFlightRecorderInfo(flightRecorder, dumpReason)
+	a_base.SetFilterInfo(filter, ignoreRegions)
 
 	return nil
 }
 
+// readStacksFromFile reads the side table with the recorded call stacks
+// from the trace_stacks.log file and sets it in the trace
+//
+// Parameter:
+//   - tr *trace.Trace: the trace
+//   - filePath string: the path to the trace_stacks.log file
+//
+// Returns:
+//   - error
+func readStacksFromFile(tr *trace.Trace, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stacks := trace.NewStackTable()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if err := stacks.AddLine(line); err != nil {
+			log.Errorf("Error in processing stack table line %s: %s", line, err.Error())
+		}
+	}
+
+	tr.SetStackTable(stacks)
+
+	return scanner.Err()
+}
+
+// readNondetFromFile reads the trace_nondet.log file with the recorded
+// results of nondeterministic calls and stores its lines in the trace
+//
+// Parameter:
+//   - tr *trace.Trace: the trace
+//   - filePath string: the path to the trace_nondet.log file
+//
+// Returns:
+//   - error
+func readNondetFromFile(tr *trace.Trace, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	tr.SetNondetLog(lines)
+
+	return scanner.Err()
+}
+
+// readIdentitiesFromFile reads the object_identities.log file with the
+// stable identities of the objects. Each line has the form objID!identity.
+//
+// Parameter:
+//   - filePath string: the path to the object_identities.log file
+//
+// Returns:
+//   - map[int]string: obj id -> identity
+//   - error
+func readIdentitiesFromFile(filePath string) (map[int]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	identities := make(map[int]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		id, identity, ok := strings.Cut(scanner.Text(), "!")
+		if !ok {
+			continue
+		}
+
+		objID, err := strconv.Atoi(id)
+		if err != nil {
+			log.Errorf("Invalid object id in identity line %s", scanner.Text())
+			continue
+		}
+		identities[objID] = identity
+	}
+
+	return identities, scanner.Err()
+}
+
+// ReadExitCode reads the exit code and exit position of a recorded run
+// from the trace_info.log file in a trace folder without changing the
+// stored exit info of the analysis
+//
+// Parameter:
+//   - folderPath string: path to the trace folder
+//
+// Returns:
+//   - int: the exit code, 0 if no exit code was recorded
+//   - string: the exit position
+//   - error
+func ReadExitCode(folderPath string) (int, string, error) {
+	file, err := os.Open(filepath.Join(folderPath, paths.NameTraceInfo))
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	exitCode := 0
+	exitPos := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSplit := strings.Split(scanner.Text(), "!")
+		if len(lineSplit) != 2 {
+			continue
+		}
+
+		switch lineSplit[0] {
+		case "ExitCode":
+			exitCode, err = strconv.Atoi(lineSplit[1])
+			if err != nil {
+				return 0, "", err
+			}
+		case "ExitPosition":
+			exitPos = lineSplit[1]
+		}
+	}
+
+	return exitCode, exitPos, nil
+}
+
 // Read and build the trace from a file
 //
 // Parameter:
@@ -197,7 +428,7 @@ func createTraceFromFile(tr *trace.Trace, filePath string, routine int) (int, er
 		line := scanner.Text()
 		err := processElement(tr, line, routine)
 		if err != nil {
-			log.Error("Error in processing trace element %s: ", line, err)
+			log.Errorf("Error in processing trace element %s: %s", line, err.Error())
 		}
 		counter++
 
@@ -297,6 +528,37 @@ func processElement(tr *trace.Trace, element string, routine int) error {
 			return fmt.Errorf("Invalid element: %s. Len: %d. Expected len: 6", element, len(fields))
 		}
 		err = tr.AddTraceElementControllFlow(routine, fields[1], fields[2], fields[3], fields[4], fields[5])
+	case "X":
+		if len(fields) != 3 {
+			return fmt.Errorf("Invalid element: %s. Len: %d. Expected len: 3", element, len(fields))
+		}
+		ts, errT := strconv.Atoi(fields[1])
+		exitCode, errE := strconv.Atoi(fields[2])
+		if errT != nil || errE != nil {
+			return fmt.Errorf("Invalid element: %s", element)
+		}
+		err = tr.AddTraceElementReplay(ts, exitCode)
+	case "T":
+		if len(fields) < 4 {
+			return fmt.Errorf("Invalid element: %s. Len: %d. Expected len: 4", element, len(fields))
+		}
+		// the name of the subtest may contain commas
+		err = tr.AddTraceElementSubtest(routine, fields[1], fields[2], strings.Join(fields[3:], ","))
+	case "H":
+		if len(fields) != 4 {
+			return fmt.Errorf("Invalid element: %s. Len: %d. Expected len: 4", element, len(fields))
+		}
+		err = tr.AddTraceElementSummary(routine, fields[1], fields[2], fields[3])
+	case "V":
+		if len(fields) < 5 {
+			return fmt.Errorf("Invalid element: %s. Len: %d. Expected len: 5", element, len(fields))
+		}
+		err = tr.AddTraceElementEvent(routine, fields[1], fields[2], fields[3], strings.Join(fields[4:], ","))
+	case "K":
+		if len(fields) != 5 {
+			return fmt.Errorf("Invalid element: %s. Len: %d. Expected len: 5", element, len(fields))
+		}
+		err = tr.AddTraceElementCustomSync(routine, fields[1], fields[2], fields[3], fields[4])
 	case "OAT":
 		err = tr.AddTraceObjectAware(routine, fields[1])
 	default:
@@ -15,10 +15,13 @@ import (
 	"advocate/utils/paths"
 	"advocate/utils/results/bugs"
 	"advocate/utils/timer"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -94,6 +97,12 @@ func WriteTrace(traceToWrite *trace.Trace, path string, replay, control bool) er
 	}
 	wg.Wait()
 
+	if nondet := traceToWrite.GetNondetLog(); len(nondet) > 0 {
+		writeNondetFile(path, nondet)
+	}
+
+	writeIdentitiesFile(path, traceToWrite.GetObjectIdentities())
+
 	if control {
 		writeControlFile(path)
 	}
@@ -118,12 +127,13 @@ func isReplay(element trace.Element) bool {
 // Parameter:
 //   - path string: The path to the file folder to write to
 //   - bug bugs.Bug: The rewritten bug
+//   - tr *trace.Trace: The trace the elements of the bug belong to
 //   - exitCode int: The exit code
 //   - resultIndex int: The index of the result
 //
 // Returns:
 //   - error: The error that occurred
-func WriteRewriteInfoFile(path string, bug bugs.Bug, exitCode int, resultIndex int) error {
+func WriteRewriteInfoFile(path string, bug bugs.Bug, tr *trace.Trace, exitCode int, resultIndex int) error {
 	timer.Start(timer.Io)
 	defer timer.Stop(timer.Io)
 
@@ -134,7 +144,7 @@ func WriteRewriteInfoFile(path string, bug bugs.Bug, exitCode int, resultIndex i
 	}
 	defer file.Close()
 
-	if _, err := file.WriteString(strconv.Itoa(resultIndex+1) + "#" + bug.GetBugString() + "#" + strconv.Itoa(exitCode)); err != nil {
+	if _, err := file.WriteString(strconv.Itoa(resultIndex+1) + "#" + bug.GetBugString(tr) + "#" + strconv.Itoa(exitCode)); err != nil {
 		return err
 	}
 
@@ -153,3 +163,44 @@ func writeControlFile(path string) {
 
 	f.WriteString("0\n")
 }
+
+// writeNondetFile writes the recorded results of nondeterministic calls
+// into the trace folder, so that they are returned again in replay
+//
+// Parameter:
+//   - path string: path to the trace folder
+//   - lines []string: the lines of the trace_nondet.log file
+func writeNondetFile(path string, lines []string) {
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(path, paths.NameNondet), []byte(content), 0644); err != nil {
+		log.Error("Error in writing nondeterministic calls: ", err.Error())
+	}
+}
+
+// writeIdentitiesFile writes the stable identities of the objects into the
+// trace folder. Each line has the form objID!identity. The file is not
+// read by the replay.
+//
+// Parameter:
+//   - path string: path to the trace folder
+//   - identities map[int]string: obj id -> identity
+func writeIdentitiesFile(path string, identities map[int]string) {
+	if len(identities) == 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(identities))
+	for id := range identities {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var content strings.Builder
+	for _, id := range ids {
+		content.WriteString(fmt.Sprintf("%d!%s\n", id, identities[id]))
+	}
+
+	if err := os.WriteFile(filepath.Join(path, paths.NameIdentities), []byte(content.String()), 0644); err != nil {
+		log.Error("Error in writing object identities: ", err.Error())
+	}
+}
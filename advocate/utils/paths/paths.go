@@ -24,21 +24,33 @@ import (
 
 // names
 const (
-	NameResult         = "advocateResult"
-	NameOutput         = "output.log"
-	NameFuzzingData    = "fuzzingData.log"
-	NameFuzzingTraces  = "fuzzingTraces"
-	NameReplayActive   = "replay_active.log"
-	NameTimes          = "times.log"
-	NameTraceInfo      = "trace_info.log"
-	NameResultMachine  = "results_machine.log"
-	NameResultReadable = "results_readable.log"
-	NameRewrittenInfo  = "rewrite_info.log"
-	NameStats          = "stats"
-	NameStatsTime      = "times"
-	NameBugs           = "bugs"
-	NameTraces         = "traces"
-	NameOut            = "output"
+	NameResult          = "advocateResult"
+	NameOutput          = "output.log"
+	NameFuzzingData     = "fuzzingData.log"
+	NameFuzzingTraces   = "fuzzingTraces"
+	NameReplayActive    = "replay_active.log"
+	NameMinimalSchedule = "minimal_schedule.log"
+	NameDivergence      = "replay_divergence.log"
+	NameSubtests        = "subtests.log"
+	NameFuzzInput       = "fuzz_input.log"
+	NameProgInput       = "program_input.log"
+	NameAnchors         = "position_anchors.log"
+	NameCoverage        = "AdvocateCoverage"
+	NameCoverProfile    = "coverage.out"
+	NameCoverHTML       = "coverage.html"
+	NameTimes           = "times.log"
+	NameTraceInfo       = "trace_info.log"
+	NameStacks          = "trace_stacks.log"
+	NameNondet          = "trace_nondet.log"
+	NameIdentities      = "object_identities.log"
+	NameResultMachine   = "results_machine.log"
+	NameResultReadable  = "results_readable.log"
+	NameRewrittenInfo   = "rewrite_info.log"
+	NameStats           = "stats"
+	NameStatsTime       = "times"
+	NameBugs            = "bugs"
+	NameTraces          = "traces"
+	NameOut             = "output"
 )
 
 // advocate
@@ -117,6 +129,8 @@ func pathsResult(main bool) {
 }
 
 func SetCurrentResult(fileNumber, testNumber int, fileName, testName string) string {
+	// subtests are named Test/sub, the separator cannot be part of the folder name
+	testName = strings.ReplaceAll(testName, "/", "_")
 	dirName := fmt.Sprintf("file(%d)-test(%d)-%s-%s", fileNumber, testNumber, fileName, testName)
 	CurrentResult = filepath.Join(Result, dirName)
 	ResultOut = filepath.Join(CurrentResult, NameOut)
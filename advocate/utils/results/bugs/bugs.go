@@ -72,18 +72,24 @@ type Bug struct {
 	TraceElement2 []trace.Element
 }
 
-// GetBugString Convert the bug to a unique string. Mostly used internally
+// GetBugString Convert the bug to a unique string. Mostly used internally.
+// Each element is given by its position and the stable identity of its
+// object, so that the same bug found in different runs has the same string.
+//
+// Parameter:
+//   - tr *trace.Trace: the trace the elements of the bug belong to, used for
+//     the identities of the objects. If nil, only the positions are used
 //
 // Returns:
 //   - string: The bug as a string
-func (this Bug) GetBugString() string {
+func (this Bug) GetBugString(tr *trace.Trace) string {
 	paths := make([]string, 0)
 
 	for _, t := range this.TraceElement1 {
-		paths = append(paths, t.Pos().String())
+		paths = append(paths, bugElemString(t, tr))
 	}
 	for _, t := range this.TraceElement2 {
-		paths = append(paths, t.Pos().String())
+		paths = append(paths, bugElemString(t, tr))
 	}
 
 	sort.Strings(paths)
@@ -95,6 +101,26 @@ func (this Bug) GetBugString() string {
 	return res
 }
 
+// bugElemString returns the position of an element of a bug and the stable
+// identity of its object
+//
+// Parameter:
+//   - elem trace.Element: the element
+//   - tr *trace.Trace: the trace the element belongs to, may be nil
+//
+// Returns:
+//   - string: the position and identity, only the position if the object has no identity
+func bugElemString(elem trace.Element, tr *trace.Trace) string {
+	identity := ""
+	if tr != nil {
+		identity = tr.GetObjectIdentity(elem.ObjID())
+	}
+	if identity == "" {
+		return elem.Pos().String()
+	}
+	return elem.Pos().String() + "@" + identity
+}
+
 // ToString convert the bug to a string. Mostly used for output
 //
 // Returns:
@@ -193,8 +219,14 @@ func (this Bug) ToString() string {
 	// 	arg2Str = "partner: "
 
 	default:
-		log.Error("Unknown bug type in toString: " + string(this.Type))
-		return ""
+		info, ok := helper.GetResultTypeInfo(this.Type)
+		if !ok {
+			log.Error("Unknown bug type in toString: " + string(this.Type))
+			return ""
+		}
+		typeStr = info.Name + ":"
+		arg1Str = "elem: "
+		arg2Str = "other: "
 	}
 
 	res := typeStr + "\n\t" + arg1Str
@@ -319,7 +351,12 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 	// 	bug.Type = SNotExecutedWithPartner
 	// 	containsArg2 = true
 	default:
-		return actual, bug, errors.New("Unknown bug type in process bug: " + bugStr)
+		info, ok := helper.GetResultTypeInfo(helper.ResultType(bugType))
+		if !ok {
+			return actual, bug, errors.New("Unknown bug type in process bug: " + bugStr)
+		}
+		bug.Type = info.Type
+		actual = info.Actual
 	}
 
 	if !containsArg1 {
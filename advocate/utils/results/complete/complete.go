@@ -19,7 +19,8 @@ import (
 	"strings"
 )
 
-// Check if all program elements are in trace
+// Check if all program elements are in trace and write the concurrency
+// coverage over all traces in the result folder
 //
 // Parameter:
 //   - resultFolderPath: path to the folder containing the trace files
@@ -44,8 +45,11 @@ func Check(resultFolderPath string, progPath string) error {
 	notSelectedSelectCase := getNotSelectedSelectCases()
 
 	err = printNotExecutedToFiles(notInTrace, notSelectedSelectCase, resultFolderPath)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return writeCoverage(resultFolderPath)
 }
 
 // areAllProgElemInTrace takes all relevant element positions in the program
@@ -0,0 +1,598 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: coverage.go
+// Brief: Concurrency coverage over all recorded and fuzzed runs, written as
+//    go cover profile and html report
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package complete
+
+import (
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// coverBlock is a code range in the format of go cover profiles
+type coverBlock struct {
+	startLine int
+	startCol  int
+	endLine   int
+	endCol    int
+}
+
+// caseBlock is the code range of a select case
+//
+// Fields:
+//   - block coverBlock: the code range of the case
+//   - selectLine int: the line of the select statement
+//   - index int: the runtime index of the case (sends, then receives, then default)
+//   - isDefault bool: true for the default case
+type caseBlock struct {
+	block      coverBlock
+	selectLine int
+	index      int
+	isDefault  bool
+}
+
+// coverPos is a position in the code
+type coverPos struct {
+	file string
+	line int
+}
+
+// coverPair is a pair of positions, e.g. a send and the receive it
+// communicated with or a lock held while another lock was acquired
+type coverPair struct {
+	first  coverPos
+	second coverPos
+}
+
+// chanKey identifies a communication on a channel in one trace
+type chanKey struct {
+	trace string
+	id    string
+	oID   string
+}
+
+var (
+	opBlocks   = make(map[string][]coverBlock) // file -> code ranges of operations
+	caseBlocks = make(map[string][]caseBlock)  // file -> code ranges of select cases
+
+	operationCount = make(map[string]map[int]int) // file -> line -> number of executions
+	sends          = make(map[chanKey][]coverPos) // communication -> positions of the send
+	recvs          = make(map[chanKey][]coverPos) // communication -> positions of the receive
+	lockOrders     = make(map[coverPair]int)      // (held lock, acquired lock) -> count
+)
+
+// addProgramBlocks stores the code ranges of all operations and select
+// cases found in a program file
+//
+// Parameter:
+//   - file string: the program file
+//   - blocks []coverBlock: the code ranges of the operations
+//   - cases []caseBlock: the code ranges of the select cases
+func addProgramBlocks(file string, blocks []coverBlock, cases []caseBlock) {
+	if len(blocks) > 0 {
+		opBlocks[file] = append(opBlocks[file], blocks...)
+	}
+	if len(cases) > 0 {
+		caseBlocks[file] = append(caseBlocks[file], cases...)
+	}
+}
+
+// countOperation counts the execution of an operation
+//
+// Parameter:
+//   - file string: file of the operation
+//   - line int: line of the operation
+func countOperation(file string, line int) {
+	if _, ok := operationCount[file]; !ok {
+		operationCount[file] = make(map[int]int)
+	}
+	operationCount[file][line]++
+}
+
+// foundChannel records a channel send or receive, that communicated
+//
+// Parameter:
+//   - trace string: path to the trace the element is in
+//   - file string: file of the operation
+//   - line int: line of the operation
+//   - field []string: the fields of the channel trace element
+func foundChannel(trace, file string, line int, field []string) {
+	if len(field) < 7 {
+		return
+	}
+
+	// not executed or released by close
+	if field[2] == "0" || field[5] == "t" {
+		return
+	}
+
+	addComm(chanKey{trace, field[3], field[6]}, field[4], coverPos{file, line})
+}
+
+// foundSelectComm records the communication of the selected case of a select
+//
+// Parameter:
+//   - trace string: path to the trace the element is in
+//   - file string: file of the select
+//   - line int: line of the select
+//   - field []string: the fields of the select trace element
+func foundSelectComm(trace, file string, line int, field []string) {
+	if len(field) < 6 || field[2] == "0" {
+		return
+	}
+
+	for i, c := range strings.Split(field[4], "~") {
+		if strconv.Itoa(i) != field[5] {
+			continue
+		}
+
+		caseField := strings.Split(c, ".")
+		if len(caseField) < 5 {
+			return
+		}
+
+		addComm(chanKey{trace, caseField[1], caseField[4]}, caseField[2], coverPos{file, line})
+		return
+	}
+}
+
+// addComm stores a send or receive
+//
+// Parameter:
+//   - key chanKey: the communication
+//   - op string: S for send, R for receive
+//   - pos coverPos: position of the operation
+func addComm(key chanKey, op string, pos coverPos) {
+	if key.id == "*" {
+		return
+	}
+
+	switch op {
+	case "S":
+		sends[key] = append(sends[key], pos)
+	case "R":
+		recvs[key] = append(recvs[key], pos)
+	}
+}
+
+// foundMutex records the order in which locks are acquired in a routine.
+// If a lock is acquired while another lock is held, the pair is recorded.
+//
+// Parameter:
+//   - held map[string]coverPos: the currently held locks of the routine (id -> position of acquire)
+//   - file string: file of the operation
+//   - line int: line of the operation
+//   - field []string: the fields of the mutex trace element
+func foundMutex(held map[string]coverPos, file string, line int, field []string) {
+	if len(field) < 7 || field[2] == "0" {
+		return
+	}
+
+	id := field[3]
+	pos := coverPos{file, line}
+
+	switch field[5] {
+	case "L", "R", "T", "Y":
+		if field[6] != "t" {
+			return
+		}
+		for heldID, heldPos := range held {
+			if heldID != id {
+				lockOrders[coverPair{heldPos, pos}]++
+			}
+		}
+		held[id] = pos
+	case "U", "N":
+		delete(held, id)
+	}
+}
+
+// getChannelPairs returns all pairs of send and receive positions that
+// were observed communicating
+//
+// Returns:
+//   - map[coverPair]int: (send, receive) -> number of communications
+func getChannelPairs() map[coverPair]int {
+	res := make(map[coverPair]int)
+	for key, sendPos := range sends {
+		recvPos, ok := recvs[key]
+		if !ok {
+			continue
+		}
+		for _, s := range sendPos {
+			for _, r := range recvPos {
+				res[coverPair{s, r}]++
+			}
+		}
+	}
+	return res
+}
+
+// ========================================================
+// MARK: Output
+// ========================================================
+
+// coverLine is one operation or select case in the html report
+type coverLine struct {
+	Line  int
+	Name  string
+	Code  string
+	Count int
+}
+
+// coverFile is the coverage of one file in the html report
+type coverFile struct {
+	File          string
+	Ops           []coverLine
+	Cases         []coverLine
+	OpsCovered    int
+	CasesCovered  int
+	OpsPercent    string
+	CasesPercent  string
+	HasOperations bool
+}
+
+// coverPairLine is a channel pair or lock order in the html report
+type coverPairLine struct {
+	First  string
+	Second string
+	Count  int
+}
+
+// coverReport is the content of the html report
+type coverReport struct {
+	Files        []coverFile
+	Ops          int
+	OpsCovered   int
+	Cases        int
+	CasesCovered int
+	OpsPercent   string
+	CasesPercent string
+	ChannelPairs []coverPairLine
+	LockOrders   []coverPairLine
+}
+
+// writeCoverage writes the concurrency coverage into the folder
+// AdvocateCoverage in the result folder. The operation and select case
+// coverage is written as a go cover profile that can be used with go tool cover.
+// Additionally, an html report with the operation and select case coverage,
+// the observed channel pairs and the observed lock orders is created.
+//
+// Parameter:
+//   - path string: path to the result folder
+//
+// Returns:
+//   - error
+func writeCoverage(path string) error {
+	path = filepath.Join(path, paths.NameCoverage)
+	err := os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	report := buildCoverReport()
+
+	err = writeCoverProfile(filepath.Join(path, paths.NameCoverProfile))
+	if err != nil {
+		return err
+	}
+
+	err = writeCoverHTML(filepath.Join(path, paths.NameCoverHTML), report)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Concurrency coverage: %d/%d operations (%s), %d/%d select cases (%s), %d channel pairs, %d lock orders",
+		report.OpsCovered, report.Ops, report.OpsPercent, report.CasesCovered, report.Cases, report.CasesPercent,
+		len(report.ChannelPairs), len(report.LockOrders))
+	log.Infof("Coverage report written to %s", path)
+
+	return nil
+}
+
+// caseCount returns how often a select case was selected
+//
+// Parameter:
+//   - file string: file of the select
+//   - c caseBlock: the case
+//
+// Returns:
+//   - int: number of times the case was selected
+func caseCount(file string, c caseBlock) int {
+	cases, ok := selects[file][c.selectLine]
+	if !ok {
+		return 0
+	}
+
+	index := c.index
+	// the default case is always the last case in the trace
+	if c.isDefault {
+		index = len(cases) - 1
+	}
+
+	if index < 0 || index >= len(cases) {
+		return 0
+	}
+	return cases[index]
+}
+
+// writeCoverProfile writes the operation and select case coverage as a
+// go cover profile in count mode. Operations in the same line share the count.
+//
+// Parameter:
+//   - path string: path to the profile
+//
+// Returns:
+//   - error
+func writeCoverProfile(path string) error {
+	var sb strings.Builder
+	sb.WriteString("mode: count\n")
+
+	for _, file := range coverFiles() {
+		type entry struct {
+			block coverBlock
+			count int
+		}
+
+		entries := make([]entry, 0)
+		for _, b := range opBlocks[file] {
+			entries = append(entries, entry{b, operationCount[file][b.startLine]})
+		}
+		for _, c := range caseBlocks[file] {
+			entries = append(entries, entry{c.block, caseCount(file, c)})
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].block.startLine != entries[j].block.startLine {
+				return entries[i].block.startLine < entries[j].block.startLine
+			}
+			return entries[i].block.startCol < entries[j].block.startCol
+		})
+
+		name := file
+		if abs, err := filepath.Abs(file); err == nil {
+			name = abs
+		}
+
+		for _, e := range entries {
+			sb.WriteString(fmt.Sprintf("%s:%d.%d,%d.%d 1 %d\n", name,
+				e.block.startLine, e.block.startCol, e.block.endLine, e.block.endCol, e.count))
+		}
+	}
+
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// coverFiles returns all program files with operations or select cases in sorted order
+//
+// Returns:
+//   - []string: the files
+func coverFiles() []string {
+	files := make([]string, 0, len(opBlocks))
+	for file := range opBlocks {
+		files = append(files, file)
+	}
+	for file := range caseBlocks {
+		if _, ok := opBlocks[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// buildCoverReport collects the content of the html report
+//
+// Returns:
+//   - coverReport: the report
+func buildCoverReport() coverReport {
+	report := coverReport{}
+
+	for _, file := range coverFiles() {
+		code := readCodeLines(file)
+		cf := coverFile{File: file}
+
+		for _, b := range opBlocks[file] {
+			count := operationCount[file][b.startLine]
+			cf.Ops = append(cf.Ops, coverLine{Line: b.startLine, Code: codeLine(code, b.startLine), Count: count})
+			if count > 0 {
+				cf.OpsCovered++
+			}
+		}
+		sort.SliceStable(cf.Ops, func(i, j int) bool { return cf.Ops[i].Line < cf.Ops[j].Line })
+
+		for _, c := range caseBlocks[file] {
+			count := caseCount(file, c)
+			name := fmt.Sprintf("select line %d, case %d", c.selectLine, c.index)
+			if c.isDefault {
+				name = fmt.Sprintf("select line %d, default", c.selectLine)
+			}
+			cf.Cases = append(cf.Cases, coverLine{Line: c.block.startLine, Name: name,
+				Code: codeLine(code, c.block.startLine), Count: count})
+			if count > 0 {
+				cf.CasesCovered++
+			}
+		}
+
+		cf.OpsPercent = percent(cf.OpsCovered, len(cf.Ops))
+		cf.CasesPercent = percent(cf.CasesCovered, len(cf.Cases))
+		cf.HasOperations = len(cf.Ops)+len(cf.Cases) > 0
+
+		report.Ops += len(cf.Ops)
+		report.OpsCovered += cf.OpsCovered
+		report.Cases += len(cf.Cases)
+		report.CasesCovered += cf.CasesCovered
+		report.Files = append(report.Files, cf)
+	}
+
+	report.OpsPercent = percent(report.OpsCovered, report.Ops)
+	report.CasesPercent = percent(report.CasesCovered, report.Cases)
+	report.ChannelPairs = pairLines(getChannelPairs())
+	report.LockOrders = pairLines(lockOrders)
+
+	return report
+}
+
+// pairLines converts pairs into sorted lines for the html report
+//
+// Parameter:
+//   - pairs map[coverPair]int: the pairs with their counts
+//
+// Returns:
+//   - []coverPairLine: the lines
+func pairLines(pairs map[coverPair]int) []coverPairLine {
+	res := make([]coverPairLine, 0, len(pairs))
+	for pair, count := range pairs {
+		res = append(res, coverPairLine{
+			First:  fmt.Sprintf("%s:%d", pair.first.file, pair.first.line),
+			Second: fmt.Sprintf("%s:%d", pair.second.file, pair.second.line),
+			Count:  count,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].First != res[j].First {
+			return res[i].First < res[j].First
+		}
+		return res[i].Second < res[j].Second
+	})
+	return res
+}
+
+// percent returns a percentage as string
+//
+// Parameter:
+//   - covered int: number of covered elements
+//   - total int: number of elements
+//
+// Returns:
+//   - string: the percentage, "-" if total is 0
+func percent(covered, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(covered)/float64(total))
+}
+
+// readCodeLines reads the lines of a program file
+//
+// Parameter:
+//   - file string: the file
+//
+// Returns:
+//   - []string: the lines, nil if the file cannot be read
+func readCodeLines(file string) []string {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(content), "\n")
+}
+
+// codeLine returns a line of code
+//
+// Parameter:
+//   - code []string: the lines of the file
+//   - line int: the line number, starting with 1
+//
+// Returns:
+//   - string: the trimmed line
+func codeLine(code []string, line int) string {
+	if line < 1 || line > len(code) {
+		return ""
+	}
+	return strings.TrimSpace(code[line-1])
+}
+
+// writeCoverHTML writes the html report
+//
+// Parameter:
+//   - path string: path to the html file
+//   - report coverReport: the content
+//
+// Returns:
+//   - error
+func writeCoverHTML(path string, report coverReport) error {
+	tmpl, err := template.New("coverage").Parse(coverTemplate)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, report)
+}
+
+const coverTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ADVOCATE Concurrency Coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+td.code { font-family: monospace; white-space: pre; }
+tr.cov td.count { background: #c8f0c8; }
+tr.uncov td.count { background: #f5c6c6; }
+</style>
+</head>
+<body>
+<h1>Concurrency Coverage</h1>
+<table>
+<tr><th></th><th>Covered</th><th>Total</th><th>Percent</th></tr>
+<tr><td>Operations</td><td>{{.OpsCovered}}</td><td>{{.Ops}}</td><td>{{.OpsPercent}}</td></tr>
+<tr><td>Select cases</td><td>{{.CasesCovered}}</td><td>{{.Cases}}</td><td>{{.CasesPercent}}</td></tr>
+<tr><td>Channel pairs</td><td>{{len .ChannelPairs}}</td><td></td><td></td></tr>
+<tr><td>Lock orders</td><td>{{len .LockOrders}}</td><td></td><td></td></tr>
+</table>
+
+<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Operations</th><th>Select cases</th></tr>
+{{range .Files}}<tr><td><a href="#{{.File}}">{{.File}}</a></td><td>{{.OpsCovered}}/{{len .Ops}} ({{.OpsPercent}})</td><td>{{.CasesCovered}}/{{len .Cases}} ({{.CasesPercent}})</td></tr>
+{{end}}</table>
+
+<h2>Channel pairs</h2>
+<table>
+<tr><th>Send</th><th>Receive</th><th>Count</th></tr>
+{{range .ChannelPairs}}<tr><td>{{.First}}</td><td>{{.Second}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+
+<h2>Lock orders</h2>
+<table>
+<tr><th>Held lock</th><th>Acquired lock</th><th>Count</th></tr>
+{{range .LockOrders}}<tr><td>{{.First}}</td><td>{{.Second}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+
+{{range .Files}}{{if .HasOperations}}<h2 id="{{.File}}">{{.File}}</h2>
+{{if .Ops}}<h3>Operations</h3>
+<table>
+<tr><th>Line</th><th>Code</th><th>Count</th></tr>
+{{range .Ops}}<tr class="{{if .Count}}cov{{else}}uncov{{end}}"><td>{{.Line}}</td><td class="code">{{.Code}}</td><td class="count">{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Cases}}<h3>Select cases</h3>
+<table>
+<tr><th>Line</th><th>Case</th><th>Code</th><th>Count</th></tr>
+{{range .Cases}}<tr class="{{if .Count}}cov{{else}}uncov{{end}}"><td>{{.Line}}</td><td>{{.Name}}</td><td class="code">{{.Code}}</td><td class="count">{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{end}}
+</body>
+</html>
+`
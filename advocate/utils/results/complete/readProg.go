@@ -170,7 +170,18 @@ func getElemsFromContent(path string, content string, pkg *types.Package) ([]int
 		Uses: make(map[*ast.Ident]types.Object),
 	}
 
-	imports := pkg.Imports()
+	// type check the file to get the types of the used identifiers,
+	// errors e.g. from declarations in other files of the package are ignored
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	filePkg, _ := conf.Check(node.Name.Name, fset, []*ast.File{node}, info)
+
+	imports := make([]*types.Package, 0)
+	if filePkg != nil {
+		imports = append(imports, filePkg.Imports()...)
+	}
+	if pkg != nil {
+		imports = append(imports, pkg.Imports()...)
+	}
 
 	var syncPkg *types.Package
 	for _, imp := range imports {
@@ -181,9 +192,12 @@ func getElemsFromContent(path string, content string, pkg *types.Package) ([]int
 	}
 
 	v := &visitor{fset: fset, pkg: pkg, info: info, syncPkg: syncPkg,
-		selectCases: make(map[string]struct{}), elements: make([]int, 0)}
+		selectCases: make(map[string]struct{}), elements: make([]int, 0),
+		blocks: make([]coverBlock, 0), caseBlocks: make([]caseBlock, 0)}
 	ast.Walk(v, node)
 
+	addProgramBlocks(path, v.blocks, v.caseBlocks)
+
 	return v.elements, nil
 }
 
@@ -195,7 +209,9 @@ type visitor struct {
 	info        *types.Info
 	syncPkg     *types.Package
 	selectCases map[string]struct{}
-	elements    []int // line numbers
+	elements    []int        // line numbers
+	blocks      []coverBlock // code ranges of the operations
+	caseBlocks  []caseBlock  // code ranges of the select cases
 }
 
 // Visit is called for each node when passing the ast. It determines if the
@@ -214,25 +230,26 @@ func (this *visitor) Visit(n ast.Node) ast.Visitor {
 
 	switch x := n.(type) {
 	case *ast.GoStmt:
-		this.recordElement(this.fset.Position(n.Pos()))
+		// only mark the go keyword, the spawned function may contain other operations
+		this.recordElement(n.Pos(), x.Go+token.Pos(len("go")))
 	case *ast.SendStmt: // send
 		if _, ok := this.selectCases[this.fset.Position(n.Pos()).String()]; ok {
 			delete(this.selectCases, this.fset.Position(n.Pos()).String())
 		} else {
-			this.recordElement(this.fset.Position(n.Pos()))
+			this.recordElement(n.Pos(), n.End())
 		}
 	case *ast.UnaryExpr: // recv
 		if x.Op == token.ARROW {
 			if _, ok := this.selectCases[this.fset.Position(n.Pos()).String()]; ok {
 				delete(this.selectCases, this.fset.Position(n.Pos()).String())
 			} else {
-				this.recordElement(this.fset.Position(n.Pos()))
+				this.recordElement(n.Pos(), n.End())
 			}
 		}
 	case *ast.CallExpr:
 		// close
 		if fun, ok := x.Fun.(*ast.Ident); ok && fun.Name == "close" {
-			this.recordElement(this.fset.Position(n.Pos()))
+			this.recordElement(n.Pos(), n.End())
 		}
 		if fun, ok := x.Fun.(*ast.SelectorExpr); ok {
 			if ident, ok := fun.X.(*ast.Ident); ok {
@@ -240,6 +257,9 @@ func (this *visitor) Visit(n ast.Node) ast.Visitor {
 
 				if obj != nil && this.syncPkg != nil {
 					typ := obj.Type()
+					if ptr, ok := typ.(*types.Pointer); ok {
+						typ = ptr.Elem()
+					}
 
 					// Überprüfen Sie, ob der Typ zu einem der spezifischen Typen gehört
 					mutexType := this.syncPkg.Scope().Lookup("Mutex").Type()
@@ -250,21 +270,22 @@ func (this *visitor) Visit(n ast.Node) ast.Visitor {
 
 					switch {
 					case types.AssignableTo(typ, mutexType):
-						this.recordElement(this.fset.Position(n.Pos()))
+						this.recordElement(n.Pos(), n.End())
 					case types.AssignableTo(typ, rwMutexType):
-						this.recordElement(this.fset.Position(n.Pos()))
+						this.recordElement(n.Pos(), n.End())
 					case types.AssignableTo(typ, wgType):
-						this.recordElement(this.fset.Position(n.Pos()))
+						this.recordElement(n.Pos(), n.End())
 					case types.AssignableTo(typ, condType):
-						this.recordElement(this.fset.Position(n.Pos()))
+						this.recordElement(n.Pos(), n.End())
 					case types.AssignableTo(typ, onceType):
-						this.recordElement(this.fset.Position(n.Pos()))
+						this.recordElement(n.Pos(), n.End())
 					}
 				}
 			}
 		}
 	case *ast.SelectStmt:
-		this.recordElement(this.fset.Position(n.Pos()))
+		this.recordElement(x.Select, x.Select+token.Pos(len("select")))
+		this.recordSelectCases(x)
 		for _, stmt := range x.Body.List {
 			caseClause, ok := stmt.(*ast.CommClause)
 			if !ok {
@@ -279,6 +300,13 @@ func (this *visitor) Visit(n ast.Node) ast.Visitor {
 				if unaryExpr, ok := comm.X.(*ast.UnaryExpr); ok && unaryExpr.Op == token.ARROW {
 					this.selectCases[this.fset.Position(unaryExpr.Pos()).String()] = struct{}{}
 				}
+			case *ast.AssignStmt:
+				// store to not record the recv statement with assignment
+				if len(comm.Rhs) == 1 {
+					if unaryExpr, ok := comm.Rhs[0].(*ast.UnaryExpr); ok && unaryExpr.Op == token.ARROW {
+						this.selectCases[this.fset.Position(unaryExpr.Pos()).String()] = struct{}{}
+					}
+				}
 			}
 		}
 	case *ast.RangeStmt:
@@ -293,11 +321,69 @@ func (this *visitor) Visit(n ast.Node) ast.Visitor {
 	return this
 }
 
-// recordElement stores the line of a node in the visitor
+// recordElement stores the line and the code range of a node in the visitor
 //
 // Parameter:
-//   - pos (token.Position): the code position of the node for which the
-//     function is called
-func (this *visitor) recordElement(pos token.Position) {
-	this.elements = append(this.elements, pos.Line)
+//   - start token.Pos: the start of the code range of the operation
+//   - end token.Pos: the end of the code range of the operation
+func (this *visitor) recordElement(start, end token.Pos) {
+	this.elements = append(this.elements, this.fset.Position(start).Line)
+	this.blocks = append(this.blocks, this.newCoverBlock(start, end))
+}
+
+// recordSelectCases stores the code ranges of the cases of a select.
+// The cases are numbered like in the runtime: first all send cases,
+// then all receive cases, and finally the default case.
+//
+// Parameter:
+//   - sel *ast.SelectStmt: the select statement
+func (this *visitor) recordSelectCases(sel *ast.SelectStmt) {
+	sends := make([]*ast.CommClause, 0)
+	recvs := make([]*ast.CommClause, 0)
+	var def *ast.CommClause
+
+	for _, stmt := range sel.Body.List {
+		caseClause, ok := stmt.(*ast.CommClause)
+		if !ok {
+			continue
+		}
+
+		switch caseClause.Comm.(type) {
+		case nil:
+			def = caseClause
+		case *ast.SendStmt:
+			sends = append(sends, caseClause)
+		default:
+			recvs = append(recvs, caseClause)
+		}
+	}
+
+	ordered := append(sends, recvs...)
+	if def != nil {
+		ordered = append(ordered, def)
+	}
+
+	line := this.fset.Position(sel.Select).Line
+	for i, c := range ordered {
+		this.caseBlocks = append(this.caseBlocks, caseBlock{
+			block:      this.newCoverBlock(c.Case, c.Colon+1),
+			selectLine: line,
+			index:      i,
+			isDefault:  c == def,
+		})
+	}
+}
+
+// newCoverBlock creates the cover block for a code range
+//
+// Parameter:
+//   - start token.Pos: start of the range
+//   - end token.Pos: end of the range
+//
+// Returns:
+//   - coverBlock: the block
+func (this *visitor) newCoverBlock(start, end token.Pos) coverBlock {
+	s := this.fset.Position(start)
+	e := this.fset.Position(end)
+	return coverBlock{startLine: s.Line, startCol: s.Column, endLine: e.Line, endCol: e.Column}
 }
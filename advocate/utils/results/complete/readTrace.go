@@ -54,7 +54,7 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 
 			fileName := filepath.Base(path)
 
-			if info.IsDir() && fileName == "rewrittenTrace" {
+			if info.IsDir() && strings.HasPrefix(fileName, "rewrittenTrace") {
 				return filepath.SkipDir
 			}
 
@@ -69,7 +69,8 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 			}
 
 			// read trace file
-			if !strings.HasPrefix(fileName, "trace_") || !strings.HasSuffix(fileName, ".log") {
+			if !strings.HasPrefix(fileName, "trace_") || !strings.HasSuffix(fileName, ".log") ||
+				fileName == paths.NameStacks || fileName == paths.NameNondet {
 				return nil
 			}
 
@@ -80,14 +81,18 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 			}
 
 			elems := strings.Split(string(content), "\n")
+			traceDir := filepath.Dir(path)
+			heldLocks := make(map[string]coverPos)
 
 			for _, elem := range elems {
-				field := strings.Split(elem, ",")
-				if len(field) == 0 {
+				if elem == "" {
 					continue
 				}
 
-				if field[0] == "A" || field[0] == "X" {
+				field := strings.Split(elem, ",")
+
+				// elements without position
+				if field[0] == "A" || field[0] == "X" || field[0] == "E" || field[0] == "R" || field[0] == "T" {
 					continue
 				}
 
@@ -109,8 +114,14 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 				}
 				resLocal[file] = append(resLocal[file], line)
 
-				if field[0] == "S" {
-					foundSelect(file, line, field[4])
+				switch field[0] {
+				case "S":
+					foundSelect(file, line, field[4], field[5])
+					foundSelectComm(traceDir, file, line, field)
+				case "C":
+					foundChannel(traceDir, file, line, field)
+				case "M":
+					foundMutex(heldLocks, file, line, field)
 				}
 			}
 
@@ -136,6 +147,7 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 			}
 
 			for _, line := range lines {
+				countOperation(file, line)
 				if !types.Contains(res[file], line) {
 					res[file] = append(res[file], line)
 				}
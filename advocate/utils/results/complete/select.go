@@ -10,19 +10,28 @@
 package complete
 
 import (
+	"strconv"
 	"strings"
 )
 
-var selects = make(map[string]map[int][]bool)       // file -> line -> []wasSelected
+var selects = make(map[string]map[int][]int)        // file -> line -> []numberSelected
 var containsDefault = make(map[string]map[int]bool) // file -> line -> containsDefault
 
 // FoundSelect is called when a select statement is found.
-// It records the select statement.
+// It records the select statement and the selected case.
 //
 // Parameter:
-//   - elem: the select statement
-func foundSelect(file string, line int, cases string) {
+//   - file string: the file in which the select statement is found
+//   - line int: the line number of the select statement
+//   - cases string: the cases field of the select trace element
+//   - selIndex string: the index of the selected case
+func foundSelect(file string, line int, cases string, selIndex string) {
 	casesSplit := strings.Split(cases, "~")
+	selected, err := strconv.Atoi(selIndex)
+	if err != nil {
+		selected = -1
+	}
+
 	for i, c := range casesSplit {
 		if c == "" { // empty case
 			continue
@@ -37,13 +46,11 @@ func foundSelect(file string, line int, cases string) {
 				containsDefault[file] = make(map[int]bool)
 			}
 			containsDefault[file][line] = true
-		} else { // case
-			fields := strings.Split(c, ".")
-			if fields[2] == "0" { // not selected case
-				continue
-			} else {
-				addSelect(file, line, len(casesSplit), i)
-			}
+			addSelect(file, line, len(casesSplit), -1)
+		} else if i == selected { // selected case
+			addSelect(file, line, len(casesSplit), i)
+		} else { // not selected case
+			addSelect(file, line, len(casesSplit), -1)
 		}
 	}
 }
@@ -55,7 +62,7 @@ func foundSelect(file string, line int, cases string) {
 //   - file: the file in which the select statement is found
 //   - line: the line number of the select statement
 //   - numberCases: the number of cases in the select statement, including the default case
-//   - selected: the index of the selected case
+//   - selected: the index of the selected case, -1 to only record the select
 func addSelect(file string, line int, numberCases int, selected int) {
 	// ignore definition of select in src/runtime/select.go
 	if strings.HasSuffix(file, "src/runtime/select.go") {
@@ -63,12 +70,14 @@ func addSelect(file string, line int, numberCases int, selected int) {
 	}
 
 	if _, ok := selects[file]; !ok {
-		selects[file] = make(map[int][]bool)
+		selects[file] = make(map[int][]int)
 	}
 	if _, ok := selects[file][line]; !ok {
-		selects[file][line] = make([]bool, numberCases)
+		selects[file][line] = make([]int, numberCases)
+	}
+	if selected >= 0 && selected < len(selects[file][line]) {
+		selects[file][line][selected]++
 	}
-	selects[file][line][selected] = true
 }
 
 // GetNotSelectedSelectCases returns the select cases that were not selected.
@@ -80,7 +89,7 @@ func getNotSelectedSelectCases() map[string]map[int][]int {
 	for file, lines := range selects {
 		for line, cases := range lines {
 			for i, c := range cases {
-				if !c {
+				if c == 0 {
 					if _, ok := res[file]; !ok {
 						res[file] = make(map[int][]int)
 					}
@@ -314,6 +314,20 @@ func GetCodeFromDescription(description string) helper.ResultType {
 // Returns:
 //   - map[bugKeys]string: bug type descriptions
 func getBugTypeDescription(bt helper.ResultType) map[bugKeys]string {
+	if info, ok := helper.GetResultTypeInfo(bt); ok {
+		infoClass := consts.Possible
+		if info.Actual {
+			infoClass = consts.Actual
+		}
+		return map[bugKeys]string{
+			crit:        info.Crit,
+			name:        info.Name,
+			bugType:     string(bt),
+			explanation: info.Explanation,
+			class:       infoClass,
+		}
+	}
+
 	return map[bugKeys]string{
 		crit:        bugCrit[bt],
 		name:        bugNames[bt],
@@ -346,4 +360,8 @@ func buildBugCodes() {
 	for key, desc := range bugNames {
 		bugCodes[desc] = key
 	}
+
+	for _, info := range helper.GetResultTypeInfos() {
+		bugCodes[info.Name] = info.Type
+	}
 }
@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: divergence.go
+// Brief: Describe where the replay diverged from the rewritten trace
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"advocate/utils/paths"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// getDivergence returns the section describing where the replay of a
+// rewritten trace diverged from the trace, if the replay recorded a divergence
+//
+// Parameter:
+//   - index string: id of the bug and the rewritten trace
+//
+// Returns:
+//   - string: the divergence section, empty if no divergence was recorded
+func getDivergence(index string) string {
+	path := filepath.Join(paths.ResultTraces, "rewrittenTrace_"+index, paths.NameDivergence)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	kind, expected, actual := "", "", ""
+	waiting := make([]string, 0)
+	routines := make([]string, 0)
+
+	for line := range strings.SplitSeq(string(content), "\n") {
+		fields := strings.Split(line, "!")
+		switch fields[0] {
+		case "Kind":
+			if len(fields) == 2 {
+				kind = fields[1]
+			}
+		case "Expected":
+			if len(fields) == 3 {
+				expected = divergenceElem(fields[1], fields[2])
+			}
+		case "Actual":
+			if len(fields) == 3 {
+				actual = divergenceElem(fields[1], fields[2])
+			}
+		case "Waiting":
+			if len(fields) == 3 {
+				waiting = append(waiting, fmt.Sprintf("| %s | %s |", divergenceElem(fields[1], ""), fields[2]))
+			}
+		case "RoutineState":
+			// RoutineState!routine!executed!total!key!op!state
+			if len(fields) == 7 {
+				routines = append(routines, fmt.Sprintf("| %s | %s/%s | %s | %s |",
+					fields[1], fields[2], fields[3], divergenceElem(fields[4], fields[5]), fields[6]))
+			}
+		}
+	}
+
+	if kind == "" {
+		return ""
+	}
+
+	res := "### Divergence\n\n"
+	res += fmt.Sprintf("The execution diverged from the rewritten trace (**%s**).\n\n", kind)
+	res += "- Expected: " + expected + "\n"
+	res += "- Executed: " + actual + "\n\n"
+
+	if len(waiting) > 0 {
+		res += "Operations waiting to be released by the replay:\n\n"
+		res += "| Operation | Waiting [ms] |\n|---|---|\n"
+		res += strings.Join(waiting, "\n") + "\n\n"
+	}
+
+	if len(routines) > 0 {
+		res += "State of the routines:\n\n"
+		res += "| Routine | Executed | Next expected | State |\n|---|---|---|---|\n"
+		res += strings.Join(routines, "\n") + "\n\n"
+	}
+
+	return res
+}
+
+// divergenceElem returns the readable representation of an element in the divergence file
+//
+// Parameter:
+//   - key string: the replay key of the element (routine:file#line)
+//   - op string: the operation of the element
+//
+// Returns:
+//   - string: the readable representation
+func divergenceElem(key, op string) string {
+	if key == "" {
+		return "-"
+	}
+
+	routine, pos, _ := strings.Cut(key, ":")
+	res := fmt.Sprintf("routine %s `%s`", routine, strings.Replace(pos, "#", ":", 1))
+	if op != "" {
+		res += " (" + op + ")"
+	}
+	return res
+}
@@ -89,6 +89,7 @@ func writeBug(bugType helper.ResultType, positions map[int][]string) bool {
 // - if possible, the command to replay the bug
 // - position of the bug elements
 // - code of the bug elements in the trace (+- 10 lines)
+// - sequence diagrams of the interleaving leading to the bug
 // - info about replay (was it possible or not)
 
 // CreateOverview creates an overview over a bug found by the analyzer.
@@ -178,8 +179,16 @@ func CreateOverview(ignoreDouble bool, traceID, fuzzing int) (int, error) {
 				continue
 			}
 
+			interleaving := getInterleaving(result, index, traceID, id,
+				bugTypeDescription[class] == consts.Possible)
+			stacks := getStacks(result, index, traceID)
+
+			subtest := getSubtest(result, index, traceID)
+			phase := getPhase(result, index, traceID)
+			input := getFuzzInput(traceID, progInfo[name], subtest) + getProgInput(traceID)
+
 			err = writeFile(paths.CurrentResult, id, bugTypeDescription, bugPos, bugElemType, code,
-				replay, progInfo, fuzzing, falsePositive)
+				stacks, interleaving, subtest, phase, input, replay, progInfo, fuzzing, falsePositive)
 		}
 	}
 
@@ -285,6 +294,11 @@ func readAnalysisResults(path string, index int, fileWithHeader string) (helper.
 //   - positions map[int][]string: positions of the bug elements
 //   - bugElemType map[int]string: types of the bug elements
 //   - code map[int][]string: program codes that contains the bug elements
+//   - stacks string: section with the call stacks of the bug elements, may be empty
+//   - interleaving string: section with the sequence diagrams of the bug, may be empty
+//   - subtest string: subtest in which the bug was found, empty if not in a subtest
+//   - phase string: last user event before the bug, empty if there is none
+//   - input string: sections with the fuzz or program input the bug was found with, may be empty
 //   - replay map[string]string: information about the replay
 //   - progInfo map[string]sting: Info about the prog, e.g. prog/test name
 //   - fuzzing int: Fuzzing run number
@@ -294,7 +308,7 @@ func readAnalysisResults(path string, index int, fileWithHeader string) (helper.
 //   - error
 func writeFile(path string, index string, description map[bugKeys]string,
 	positions map[int][]string, bugElemType map[int]string, code map[int][]string,
-	replay map[bugKeys]string, progInfo map[bugKeys]string, fuzzing int, falsePositive bool) error {
+	stacks, interleaving, subtest, phase, input string, replay map[bugKeys]string, progInfo map[bugKeys]string, fuzzing int, falsePositive bool) error {
 
 	if replay[replaySuc] == consts.ConfirmedTheBug {
 		description[name] = strings.ReplaceAll(description[name], consts.Possible, consts.Confirmed)
@@ -313,6 +327,14 @@ func writeFile(path string, index string, description map[bugKeys]string,
 		res += "- Test: unknown" + "\n"
 	}
 
+	if subtest != "" {
+		res += "- Subtest: " + subtest + "\n"
+	}
+
+	if phase != "" {
+		res += "- Phase: " + phase + "\n"
+	}
+
 	if progInfo[file] != "" {
 		res += "- File: " + progInfo[file] + "\n"
 	} else {
@@ -329,6 +351,8 @@ func writeFile(path string, index string, description map[bugKeys]string,
 		res += "The bug is likely a false positive\n\n"
 	}
 
+	res += input
+
 	// write the code of the bug elements
 	if len(positions) > 0 {
 		res += "## Bug Elements\n\n"
@@ -351,6 +375,9 @@ func writeFile(path string, index string, description map[bugKeys]string,
 		}
 	}
 
+	res += stacks
+	res += interleaving
+
 	confirmed := false
 
 	if description[class] == consts.Possible { // replay only for possible bugs
@@ -381,6 +408,12 @@ func writeFile(path string, index string, description map[bugKeys]string,
 					res += replay[exitCodeDesc] + "\n\n"
 				}
 			}
+
+			if replay[replaySuc] == "confirmed the bug" {
+				res += getMinimalSchedule(index)
+			} else if replayPossible {
+				res += getDivergence(index)
+			}
 		}
 
 		if description[crit] == "Bug" {
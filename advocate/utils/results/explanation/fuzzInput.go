@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: fuzzInput.go
+// Brief: Describe the input of a go fuzz target a bug was found with
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"advocate/utils/paths"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// getFuzzInput returns the section with the input of a go fuzz target a bug
+// was found with, if the recorded trace contains fuzz inputs. The input is
+// the one run by the analyzed test or by the test the bug was found in.
+//
+// Parameter:
+//   - traceID int: id of the recorded trace
+//   - testName string: name of the analyzed test
+//   - subtest string: subtest in which the bug was found, empty if not in a subtest
+//
+// Returns:
+//   - string: the fuzz input section, empty if the bug was not found with a fuzz input
+func getFuzzInput(traceID int, testName, subtest string) string {
+	path := filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID), paths.NameFuzzInput)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	inputs := make(map[string]string)
+	names := make([]string, 0)
+	for block := range strings.SplitSeq(strings.TrimSpace(string(content)), "\n\n") {
+		name, input, found := strings.Cut(block, "\n")
+		if !found {
+			continue
+		}
+		inputs[name] = input
+		names = append(names, name)
+	}
+
+	inputName := ""
+	for _, name := range names {
+		if name == testName || subtest == name || strings.HasPrefix(subtest, name+"/") {
+			inputName = name
+			break
+		}
+	}
+	if inputName == "" && len(names) == 1 {
+		inputName = names[0]
+	}
+	if inputName == "" {
+		return ""
+	}
+
+	target, inputFile, _ := strings.Cut(inputName, "/")
+	inputFile = strings.ReplaceAll(inputFile, "#", "_")
+
+	res := "## Fuzz Input\n"
+	res += "The bug was found with the following input of the fuzz target " + target + ":\n\n"
+	res += "```\n" + inputs[inputName] + "\n```\n\n"
+	res += "The input is run by the test `" + inputName + "`. "
+	res += "To run it independent of changes to the seeds and corpus, store it as `testdata/fuzz/" +
+		target + "/" + inputFile + "` and run the test `" + target + "/" + inputFile + "`.\n\n"
+
+	return res
+}
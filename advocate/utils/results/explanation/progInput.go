@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: progInput.go
+// Brief: Describe the input of a main program a bug was found with
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"advocate/utils/flags"
+	"advocate/utils/io"
+	"advocate/utils/paths"
+	"fmt"
+	"path/filepath"
+)
+
+// maximum number of bytes of stdin shown in the bug report
+const maxStdinShown = 1000
+
+// getProgInput returns the section with the arguments, environment variables
+// and stdin of a main program a bug was found with, if the recorded trace
+// contains a program input
+//
+// Parameter:
+//   - traceID int: id of the recorded trace
+//
+// Returns:
+//   - string: the program input section, empty if the program was run without input
+func getProgInput(traceID int) string {
+	input, found, err := io.ReadProgInput(filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID)))
+	if err != nil || !found || input.IsEmpty() {
+		return ""
+	}
+
+	exec := flags.ExecName
+	if exec == "" {
+		exec = "prog"
+	}
+
+	res := "## Program Input\n"
+	res += "The bug was found with the program run as\n\n"
+	res += "```\n" + input.CommandLine("./"+exec, "stdin.txt") + "\n```\n\n"
+
+	if input.Stdin != nil {
+		res += fmt.Sprintf("where `stdin.txt` contains the following %d bytes", len(input.Stdin))
+		if len(input.Stdin) > maxStdinShown {
+			res += fmt.Sprintf(" (only the first %d bytes are shown, all are stored in %s in the trace)", maxStdinShown, paths.NameProgInput)
+		}
+		res += ":\n\n```\n" + string(input.Stdin[:min(len(input.Stdin), maxStdinShown)]) + "\n```\n\n"
+	}
+
+	res += "The input is stored in the trace and automatically used when the trace is replayed.\n\n"
+
+	return res
+}
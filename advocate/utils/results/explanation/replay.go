@@ -16,6 +16,7 @@ import (
 	"advocate/utils/paths"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -92,6 +93,13 @@ func getRewriteType(bugCode helper.ResultType) string {
 		}
 		return res
 	}
+
+	if info, ok := helper.GetResultTypeInfo(bugCode); ok {
+		if info.Actual {
+			return consts.Actual
+		}
+		return consts.Possible
+	}
 	return ""
 }
 
@@ -201,3 +209,46 @@ func getReplayInfo(codes map[string]string, index string) (string, string, strin
 
 	return exitCode, exitCodeExplanation[exitCode], replaySuc, nil
 }
+
+// getMinimalSchedule returns the section describing the minimal schedule
+// of a rewritten trace, if the trace has been minimized
+//
+// Parameter:
+//   - index string: id of the bug and the rewritten trace
+//
+// Returns:
+//   - string: the minimal schedule section, empty if no minimal schedule exists
+func getMinimalSchedule(index string) string {
+	path := filepath.Join(paths.ResultTraces, "rewrittenTrace_"+index, paths.NameMinimalSchedule)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	elems := make([]string, 0)
+	for line := range strings.SplitSeq(string(content), "\n") {
+		// routine:file#line,tPre,counter
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			continue
+		}
+
+		routine, pos, _ := strings.Cut(fields[0], ":")
+		elems = append(elems, fmt.Sprintf("Routine %s: `%s` (execution %s)",
+			routine, strings.Replace(pos, "#", ":", 1), fields[2]))
+	}
+
+	if len(elems) == 0 {
+		return ""
+	}
+
+	res := "### Minimal schedule\n\n"
+	res += fmt.Sprintf("The bug is still reproduced if the replay only enforces the order of the following %d operations. ", len(elems))
+	res += "All other operations are executed freely.\n\n"
+	for i, elem := range elems {
+		res += fmt.Sprintf("%d. %s\n", i+1, elem)
+	}
+	res += "\n"
+
+	return res
+}
@@ -0,0 +1,538 @@
+// Copyright (c) 2025 Erik Kassubek
+//
+// File: sequenceDiagram.go
+// Brief: Create mermaid sequence diagrams of the interleaving of a bug
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	advtrace "advocate/trace"
+	"advocate/utils/consts"
+	"advocate/utils/io"
+	"advocate/utils/paths"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maximum number of operations shown in one sequence diagram
+const maxDiagramElems = 30
+
+// bugElemInfo stores the identifying information of a bug element
+// as it is given in the machine readable result file
+//
+// Fields:
+//   - routine int: routine of the element
+//   - tReq int: request time of the element in the recorded trace
+//   - file string: file of the element
+//   - line int: line of the element
+//   - ordinal int: number of elements with the same position before the
+//     element in its routine of the recorded trace, -1 if not known
+type bugElemInfo struct {
+	routine int
+	tReq    int
+	file    string
+	line    int
+	ordinal int
+}
+
+// getInterleaving creates the interleaving section of the bug report
+// containing a sequence diagram of the recorded trace and, for possible
+// bugs, of the rewritten trace
+//
+// Parameter:
+//   - resultPath string: path to the machine readable result file
+//   - index int: index of the bug in the result file
+//   - traceID int: id of the recorded trace
+//   - id string: id of the bug, equal to the id of the rewritten trace
+//   - possible bool: true if the bug is a possible bug
+//
+// Returns:
+//   - string: the interleaving section, empty if no diagram could be created
+func getInterleaving(resultPath string, index, traceID int, id string, possible bool) string {
+	bugElems := readBugElements(resultPath, index)
+	if len(bugElems) == 0 {
+		return ""
+	}
+
+	recordedPath := filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID))
+	recorded, err := createSequenceDiagram(recordedPath, bugElems, true)
+	if err != nil {
+		return ""
+	}
+
+	res := "## Interleaving\n\n"
+	res += "### Recorded order\n\n"
+	res += "The following diagram shows the order in which the operations leading up to the bug "
+	res += "were executed in the recorded run. The bug elements are highlighted.\n\n"
+	res += recorded + "\n"
+
+	if !possible {
+		return res
+	}
+
+	rewrittenPath := filepath.Join(paths.ResultTraces, "rewrittenTrace_"+id)
+	if _, err := os.Stat(rewrittenPath); err != nil {
+		return res
+	}
+
+	rewritten, err := createSequenceDiagram(rewrittenPath, bugElems, false)
+	if err != nil {
+		return res
+	}
+
+	res += "### Rewritten order\n\n"
+	res += "The following diagram shows the order enforced by the rewritten trace "
+	res += "that is used to replay the bug.\n\n"
+	res += rewritten + "\n"
+
+	return res
+}
+
+// readBugElements reads the bug elements of a bug from the machine readable result file
+//
+// Parameter:
+//   - path string: path to the result file
+//   - index int: index of the bug in the file (1-based)
+//
+// Returns:
+//   - []bugElemInfo: the bug elements
+func readBugElements(path string, index int) []bugElemInfo {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(string(file), "\n")
+	if index < 1 || index > len(lines) {
+		return nil
+	}
+
+	res := make([]bugElemInfo, 0)
+
+	bugFields := strings.Split(lines[index-1], ",")
+	for i := 2; i < len(bugFields); i++ {
+		for _, elem := range strings.Split(bugFields[i], ";") {
+			fields := strings.Split(elem, consts.PosSep)
+			if len(fields) != 7 || fields[0] != "T" {
+				continue
+			}
+
+			routine, errR := strconv.Atoi(fields[1])
+			tReq, errT := strconv.Atoi(fields[3])
+			line, errL := strconv.Atoi(fields[6])
+			if errR != nil || errT != nil || errL != nil {
+				continue
+			}
+
+			res = append(res, bugElemInfo{routine, tReq, fields[5], line, -1})
+		}
+	}
+
+	return res
+}
+
+// createSequenceDiagram creates a mermaid sequence diagram for the bug
+// elements in the trace at the given path
+//
+// Parameter:
+//   - tracePath string: path to the trace folder
+//   - bugElems []bugElemInfo: the bug elements
+//   - matchTime bool: if true, the bug elements are matched by their request
+//     time and their ordinal is stored. Must be false for rewritten traces,
+//     where the times have been changed and the ordinal is used instead
+//
+// Returns:
+//   - string: the diagram as a mermaid code block
+//   - error
+func createSequenceDiagram(tracePath string, bugElems []bugElemInfo, matchTime bool) (string, error) {
+	tr, err := io.ReadTrace(tracePath)
+	if err != nil {
+		return "", err
+	}
+
+	isBug := make(map[advtrace.Element]struct{})
+	participants := make(map[int]struct{})
+	maxTime := 0
+
+	for _, routine := range tr.GetTraces() {
+		ordinals := make(map[string]int)
+		for _, elem := range routine.Elems() {
+			pos := fmt.Sprintf("%s:%d", elem.File(), elem.Line())
+			ordinal := ordinals[pos]
+			ordinals[pos]++
+
+			if !isBugElem(elem, ordinal, bugElems, matchTime) {
+				continue
+			}
+			isBug[elem] = struct{}{}
+			participants[elem.Routine()] = struct{}{}
+			maxTime = max(maxTime, diagramTime(elem))
+		}
+	}
+
+	if len(isBug) == 0 {
+		return "", errors.New("bug elements not found in trace")
+	}
+
+	elems := make([]advtrace.Element, 0)
+	for routine := range participants {
+		for _, elem := range tr.GetRoutineTrace(routine).Elems() {
+			if _, ok := isBug[elem]; !ok && (!showInDiagram(elem) || diagramTime(elem) > maxTime) {
+				continue
+			}
+			elems = append(elems, elem)
+		}
+	}
+
+	sort.SliceStable(elems, func(i, j int) bool {
+		ti, tj := diagramTime(elems[i]), diagramTime(elems[j])
+		if ti != tj {
+			return ti < tj
+		}
+		return elems[i].Routine() < elems[j].Routine()
+	})
+
+	// only keep the last operations before the bug, but always keep the bug elements
+	shown := make([]advtrace.Element, 0, maxDiagramElems)
+	for i, elem := range elems {
+		if _, ok := isBug[elem]; ok || i >= len(elems)-maxDiagramElems {
+			shown = append(shown, elem)
+		}
+	}
+
+	// add the communication partners of the shown channel operations as participants
+	for _, elem := range shown {
+		if partner := getDiagramPartner(elem); partner != nil {
+			participants[partner.Routine()] = struct{}{}
+		}
+	}
+
+	routines := make([]int, 0, len(participants))
+	for routine := range participants {
+		routines = append(routines, routine)
+	}
+	slices.Sort(routines)
+
+	res := "```mermaid\nsequenceDiagram\n"
+	for _, routine := range routines {
+		res += fmt.Sprintf("    participant R%d as Routine %d\n", routine, routine)
+	}
+
+	drawn := make(map[advtrace.Element]struct{})
+	for _, elem := range shown {
+		if _, ok := drawn[elem]; ok {
+			continue
+		}
+		drawn[elem] = struct{}{}
+
+		line := diagramLine(elem, participants, drawn)
+		if line == "" {
+			continue
+		}
+
+		if _, ok := isBug[elem]; ok {
+			res += "    rect rgba(255, 0, 0, 0.2)\n"
+			res += "    " + line + "\n"
+			res += "    end\n"
+		} else {
+			res += line + "\n"
+		}
+	}
+
+	res += "```\n"
+
+	return res, nil
+}
+
+// isBugElem checks if a trace element is one of the bug elements. In the
+// recorded trace, the elements are matched by their request time and the
+// ordinal of the matched element is stored in the bug element. In a rewritten
+// trace, the elements are matched by this ordinal, so that only the bug
+// element and not every element at the same position, e.g. in a loop, is matched.
+//
+// Parameter:
+//   - elem advtrace.Element: the trace element
+//   - ordinal int: number of elements with the same position before elem in its routine
+//   - bugElems []bugElemInfo: the bug elements
+//   - matchTime bool: if true, the request time must match, otherwise the ordinal
+//
+// Returns:
+//   - bool: true if elem is a bug element
+func isBugElem(elem advtrace.Element, ordinal int, bugElems []bugElemInfo, matchTime bool) bool {
+	for i, b := range bugElems {
+		if b.routine != elem.Routine() || b.file != elem.File() || b.line != elem.Line() {
+			continue
+		}
+
+		if !matchTime {
+			if b.ordinal != ordinal {
+				continue
+			}
+			return true
+		}
+
+		if b.tReq != elem.T(advtrace.Request) {
+			continue
+		}
+		bugElems[i].ordinal = ordinal
+		return true
+	}
+	return false
+}
+
+// showInDiagram returns if an element should be shown in the sequence diagram
+//
+// Parameter:
+//   - elem advtrace.Element: the element
+//
+// Returns:
+//   - bool: true if the element is a concurrency operation that should be shown
+func showInDiagram(elem advtrace.Element) bool {
+	// user annotations are shown to make the diagram easier to follow
+	switch elem.(type) {
+	case *advtrace.ElementEvent, *advtrace.ElementCustomSync:
+		return true
+	}
+
+	if !advtrace.IsOp(elem) {
+		return false
+	}
+
+	switch advtrace.GetElemTypeFromObjectType(elem.Type(true)) {
+	case advtrace.Channel, advtrace.Select, advtrace.Mutex, advtrace.Wait, advtrace.Cond, advtrace.Once, advtrace.Fork:
+		return true
+	}
+
+	// mutex try r lock is not mapped by GetElemTypeFromObjectType
+	return elem.Type(true) == advtrace.MutexTryRLock
+}
+
+// diagramTime returns the time used to order the elements in the diagram.
+// For not executed elements, the request time is used
+//
+// Parameter:
+//   - elem advtrace.Element: the element
+//
+// Returns:
+//   - int: the time
+func diagramTime(elem advtrace.Element) int {
+	t := elem.T(advtrace.Sorting)
+	if t == math.MaxInt || t == 0 {
+		return elem.T(advtrace.Request)
+	}
+	return t
+}
+
+// getDiagramPartner returns the communication partner of a channel or select element
+//
+// Parameter:
+//   - elem advtrace.Element: the element
+//
+// Returns:
+//   - *advtrace.ElementChannel: the partner, nil if none exists
+func getDiagramPartner(elem advtrace.Element) *advtrace.ElementChannel {
+	switch e := elem.(type) {
+	case *advtrace.ElementChannel:
+		return e.GetPartner()
+	case *advtrace.ElementSelect:
+		if e.GetChosenDefault() {
+			return nil
+		}
+		if c := e.GetChosenCase(); c != nil {
+			return c.GetPartner()
+		}
+	}
+	return nil
+}
+
+// diagramLine creates the line of the sequence diagram for an element
+//
+// Parameter:
+//   - elem advtrace.Element: the element
+//   - participants map[int]struct{}: the routines shown in the diagram
+//   - drawn map[advtrace.Element]struct{}: elements already drawn. Communication
+//     partners that are included in an arrow are added
+//
+// Returns:
+//   - string: the line, empty if nothing should be drawn
+func diagramLine(elem advtrace.Element, participants map[int]struct{}, drawn map[advtrace.Element]struct{}) string {
+	routine := elem.Routine()
+	pos := fmt.Sprintf("%s:%d", filepath.Base(elem.File()), elem.Line())
+
+	blocked := ""
+	if !elem.Committed() {
+		blocked = " (blocked)"
+	}
+
+	var ch *advtrace.ElementChannel
+	prefix := ""
+
+	switch e := elem.(type) {
+	case *advtrace.ElementEvent:
+		return diagramNote(routine, fmt.Sprintf("event %s (%s)", e.Name(), pos))
+	case *advtrace.ElementFork:
+		child := e.ObjID()
+		if _, ok := participants[child]; ok && child != routine {
+			return diagramArrow(routine, "-)", child, fmt.Sprintf("go (%s)", pos))
+		}
+		return diagramNote(routine, fmt.Sprintf("go R%d (%s)", child, pos))
+	case *advtrace.ElementSelect:
+		if e.GetChosenDefault() {
+			return diagramNote(routine, fmt.Sprintf("select default (%s)", pos))
+		}
+		ch = e.GetChosenCase()
+		if ch == nil {
+			return diagramNote(routine, fmt.Sprintf("select%s (%s)", blocked, pos))
+		}
+		prefix = "select "
+	case *advtrace.ElementChannel:
+		ch = e
+	}
+
+	if ch == nil {
+		return diagramNote(routine, fmt.Sprintf("%s%s (%s)", diagramOpName(elem), blocked, pos))
+	}
+
+	op := ch.Type(true)
+	partner := ch.GetPartner()
+	if op == advtrace.ChannelClose || partner == nil {
+		return diagramNote(routine, fmt.Sprintf("%s%s ch %d%s (%s)", prefix, diagramOpName(ch), ch.ObjID(), blocked, pos))
+	}
+
+	drawn[partner] = struct{}{}
+	if sel := partner.GetSelect(); sel != nil {
+		drawn[sel] = struct{}{}
+	}
+
+	sender, receiver := routine, partner.Routine()
+	if op == advtrace.ChannelRecv {
+		sender, receiver = receiver, sender
+	}
+
+	arrow := "->>"
+	buffered := ""
+	if ch.IsBuffered() {
+		arrow = "-)"
+		buffered = " buffered"
+	}
+
+	return diagramArrow(sender, arrow, receiver, fmt.Sprintf("%s%s ch %d%s (%s)", prefix, diagramOpName(ch), ch.ObjID(), buffered, pos))
+}
+
+// diagramNote creates a note over a routine in the sequence diagram
+//
+// Parameter:
+//   - routine int: the routine
+//   - text string: the text of the note, it is escaped
+//
+// Returns:
+//   - string: the line of the note
+func diagramNote(routine int, text string) string {
+	return fmt.Sprintf("    Note over R%d: %s", routine, escapeMermaid(text))
+}
+
+// diagramArrow creates a message between two routines in the sequence diagram
+//
+// Parameter:
+//   - from int: the sending routine
+//   - arrow string: the mermaid arrow
+//   - to int: the receiving routine
+//   - text string: the text of the message, it is escaped
+//
+// Returns:
+//   - string: the line of the message
+func diagramArrow(from int, arrow string, to int, text string) string {
+	return fmt.Sprintf("    R%d%sR%d: %s", from, arrow, to, escapeMermaid(text))
+}
+
+// mermaidEscaper replaces the characters that end or change a line of a
+// mermaid sequence diagram with their entity codes. Line breaks are replaced
+// by spaces
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	";", "#59;",
+	":", "#58;",
+	"<", "#lt;",
+	">", "#gt;",
+	"%", "#37;",
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// escapeMermaid escapes a text, e.g. the name of a user event, so that it
+// can be used as text of a note or message in a mermaid sequence diagram
+//
+// Parameter:
+//   - text string: the text
+//
+// Returns:
+//   - string: the escaped text
+func escapeMermaid(text string) string {
+	return mermaidEscaper.Replace(text)
+}
+
+// diagramOpName returns a readable name of the operation of an element
+//
+// Parameter:
+//   - elem advtrace.Element: the element
+//
+// Returns:
+//   - string: the name of the operation
+func diagramOpName(elem advtrace.Element) string {
+	id := elem.ObjID()
+
+	switch elem.Type(true) {
+	case advtrace.ChannelSend:
+		return "send"
+	case advtrace.ChannelRecv:
+		return "recv"
+	case advtrace.ChannelClose:
+		return "close"
+	case advtrace.MutexLock:
+		return fmt.Sprintf("Lock mutex %d", id)
+	case advtrace.MutexRLock:
+		return fmt.Sprintf("RLock mutex %d", id)
+	case advtrace.MutexTryLock:
+		return fmt.Sprintf("TryLock mutex %d", id)
+	case advtrace.MutexTryRLock:
+		return fmt.Sprintf("TryRLock mutex %d", id)
+	case advtrace.MutexUnlock:
+		return fmt.Sprintf("Unlock mutex %d", id)
+	case advtrace.MutexRUnlock:
+		return fmt.Sprintf("RUnlock mutex %d", id)
+	case advtrace.WaitAdd:
+		return fmt.Sprintf("Add wg %d", id)
+	case advtrace.WaitDone:
+		return fmt.Sprintf("Done wg %d", id)
+	case advtrace.WaitWait:
+		return fmt.Sprintf("Wait wg %d", id)
+	case advtrace.CondWait:
+		return fmt.Sprintf("Wait cond %d", id)
+	case advtrace.CondSignal:
+		return fmt.Sprintf("Signal cond %d", id)
+	case advtrace.CondBroadcast:
+		return fmt.Sprintf("Broadcast cond %d", id)
+	case advtrace.OnceSuc:
+		return fmt.Sprintf("Do once %d (executed)", id)
+	case advtrace.OnceFail:
+		return fmt.Sprintf("Do once %d (not executed)", id)
+	case advtrace.CustomRelease:
+		return fmt.Sprintf("Release key %d", id)
+	case advtrace.CustomAcquire:
+		return fmt.Sprintf("Acquire key %d", id)
+	}
+
+	return string(elem.Type(true))
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: stack.go
+// Brief: Create the section with the recorded call stacks of a bug
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"advocate/utils/consts"
+	"advocate/utils/io"
+	"advocate/utils/paths"
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// getStacks returns the section with the recorded call stacks of the bug
+// elements. Elements without a stack or that were not called through another
+// function are not shown.
+//
+// Parameter:
+//   - resultPath string: path to the machine readable result file
+//   - index int: index of the bug in the result file
+//   - traceID int: id of the recorded trace
+//
+// Returns:
+//   - string: the section, empty if no stacks have been recorded
+func getStacks(resultPath string, index, traceID int) string {
+	bugElems := readBugElements(resultPath, index)
+	if len(bugElems) == 0 {
+		return ""
+	}
+
+	tr, err := io.ReadTrace(filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID)))
+	if err != nil || !tr.HasStacks() {
+		return ""
+	}
+
+	res := ""
+	for _, elem := range bugElems {
+		stack := tr.StackAt(elem.tReq)
+		if len(stack) < 2 {
+			continue
+		}
+
+		res += "-> " + elem.file + consts.PosSep + strconv.Itoa(elem.line) + "\n\n"
+		res += "```\n"
+		for _, frame := range stack {
+			res += frame.String() + "\n"
+		}
+		res += "```\n\n"
+	}
+
+	if res == "" {
+		return ""
+	}
+
+	return "## Call Stacks\n\nThe bug elements were called through the following functions, starting with the element:\n\n" + res
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: subtest.go
+// Brief: Determine the subtest and the phase a bug was found in
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package explanation
+
+import (
+	"advocate/utils/io"
+	"advocate/utils/paths"
+	"fmt"
+	"path/filepath"
+)
+
+// getSubtest returns the subtest in which the first element of a bug was
+// executed in the recorded trace
+//
+// Parameter:
+//   - resultPath string: path to the machine readable result file
+//   - index int: index of the bug in the result file
+//   - traceID int: id of the recorded trace
+//
+// Returns:
+//   - string: name of the subtest, empty if the bug was not found in a subtest
+func getSubtest(resultPath string, index, traceID int) string {
+	bugElems := readBugElements(resultPath, index)
+	if len(bugElems) == 0 {
+		return ""
+	}
+
+	tr, err := io.ReadTrace(filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID)))
+	if err != nil {
+		return ""
+	}
+
+	return tr.SubtestAt(bugElems[0].routine, bugElems[0].tReq)
+}
+
+// getPhase returns the phase, given by the last user event recorded with
+// advocatego.Event, in which the first element of a bug was executed in
+// the recorded trace
+//
+// Parameter:
+//   - resultPath string: path to the machine readable result file
+//   - index int: index of the bug in the result file
+//   - traceID int: id of the recorded trace
+//
+// Returns:
+//   - string: name of the last event before the bug, empty if there is none
+func getPhase(resultPath string, index, traceID int) string {
+	bugElems := readBugElements(resultPath, index)
+	if len(bugElems) == 0 {
+		return ""
+	}
+
+	tr, err := io.ReadTrace(filepath.Join(paths.ResultTraces, fmt.Sprintf("advocateTrace_%d", traceID)))
+	if err != nil {
+		return ""
+	}
+
+	return tr.PhaseAt(bugElems[0].routine, bugElems[0].tReq)
+}
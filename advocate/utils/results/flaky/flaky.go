@@ -0,0 +1,363 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: flaky.go
+// Brief: Find the ordering decisions that separate passing and failing runs of a flaky test
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package flaky
+
+import (
+	"advocate/trace"
+	"advocate/utils/diff"
+	"advocate/utils/io"
+	"advocate/utils/log"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Kinds of ordering decisions
+const (
+	SelectDecision = "select"
+	RecvDecision   = "recv"
+	LockDecision   = "lock"
+)
+
+// value of a decision that was not made in a run
+const notExecuted = "not executed"
+
+// Run is one recorded execution of a flaky test
+//
+// Fields:
+//   - TraceID int: id of the recorded trace
+//   - TracePath string: path to the recorded trace
+//   - Failed bool: true if the execution failed
+//   - Reason string: reason why the execution failed
+type Run struct {
+	TraceID   int    `json:"traceID"`
+	TracePath string `json:"tracePath"`
+	Failed    bool   `json:"failed"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Decision is an ordering decision that separates passing and failing runs
+//
+// Fields:
+//   - Kind string: kind of the decision (select, recv, lock)
+//   - Op string: execution independent identifier of the operation
+//   - FailValue string: choice that is most characteristic for failing runs
+//   - FailCount int: number of failing runs with FailValue
+//   - PassValue string: most common choice in passing runs
+//   - PassCount int: number of passing runs with PassValue
+//   - Separation float64: difference between the share of failing and passing
+//     runs with FailValue. 1 means the decision perfectly separates the groups
+//   - Earliness float64: average relative time of the decision in the runs (0 = start, 1 = end)
+type Decision struct {
+	Kind       string  `json:"kind"`
+	Op         string  `json:"op"`
+	FailValue  string  `json:"failValue"`
+	FailCount  int     `json:"failCount"`
+	PassValue  string  `json:"passValue"`
+	PassCount  int     `json:"passCount"`
+	Separation float64 `json:"separation"`
+	Earliness  float64 `json:"earliness"`
+}
+
+// Report is the result of the flaky test analysis
+//
+// Fields:
+//   - Test string: name of the analyzed test or program
+//   - NumberPass int: number of passing runs
+//   - NumberFail int: number of failing runs
+//   - Runs []Run: all runs
+//   - Decisions []Decision: the separating decisions, ranked
+type Report struct {
+	Test       string     `json:"test"`
+	NumberPass int        `json:"numberPass"`
+	NumberFail int        `json:"numberFail"`
+	Runs       []Run      `json:"runs"`
+	Decisions  []Decision `json:"decisions"`
+}
+
+// choice is the value of one decision in one run
+type choice struct {
+	value string
+	time  float64
+}
+
+// Classify decides whether a recorded run failed
+//
+// Parameter:
+//   - tracePath string: path to the recorded trace
+//   - outputPath string: path to the output of the run
+//
+// Returns:
+//   - bool: true if the run failed
+//   - string: reason for the failure
+func Classify(tracePath, outputPath string) (bool, string) {
+	exitCode, exitPos, err := io.ReadExitCode(tracePath)
+	if err == nil && exitCode > 0 {
+		if exitPos != "" {
+			return true, fmt.Sprintf("exit code %d at %s", exitCode, exitPos)
+		}
+		return true, fmt.Sprintf("exit code %d", exitCode)
+	}
+
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		return false, ""
+	}
+
+	for line := range strings.SplitSeq(string(output), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--- FAIL") || strings.HasPrefix(line, "panic: ") {
+			return true, strings.TrimSpace(line)
+		}
+	}
+
+	return false, ""
+}
+
+// Analyze compares the traces of the passing and failing runs and ranks
+// the ordering decisions that separate them. Decisions with a higher
+// separation are ranked first, decisions with the same separation are
+// ranked by how early they were made.
+//
+// Parameter:
+//   - runs []Run: the recorded runs
+//
+// Returns:
+//   - Report: the analysis result
+//   - error
+func Analyze(runs []Run) (Report, error) {
+	res := Report{Runs: runs, Decisions: make([]Decision, 0)}
+
+	decisionsPass := make([]map[string]choice, 0)
+	decisionsFail := make([]map[string]choice, 0)
+
+	for _, run := range runs {
+		tr, err := io.ReadTrace(run.TracePath)
+		if err != nil {
+			log.Errorf("Could not read trace %s: %s", run.TracePath, err.Error())
+			continue
+		}
+
+		if run.Failed {
+			decisionsFail = append(decisionsFail, getDecisions(tr))
+		} else {
+			decisionsPass = append(decisionsPass, getDecisions(tr))
+		}
+	}
+
+	res.NumberPass = len(decisionsPass)
+	res.NumberFail = len(decisionsFail)
+
+	if res.NumberPass == 0 || res.NumberFail == 0 {
+		return res, fmt.Errorf("Need passing and failing runs, got %d passing and %d failing", res.NumberPass, res.NumberFail)
+	}
+
+	keys := make(map[string]struct{})
+	for _, decisions := range append(decisionsPass, decisionsFail...) {
+		for key := range decisions {
+			keys[key] = struct{}{}
+		}
+	}
+
+	for key := range keys {
+		if decision, ok := rankDecision(key, decisionsPass, decisionsFail); ok {
+			res.Decisions = append(res.Decisions, decision)
+		}
+	}
+
+	sort.Slice(res.Decisions, func(i, j int) bool {
+		a, b := res.Decisions[i], res.Decisions[j]
+		if a.Separation != b.Separation {
+			return a.Separation > b.Separation
+		}
+		if a.Earliness != b.Earliness {
+			return a.Earliness < b.Earliness
+		}
+		return a.Op < b.Op
+	})
+
+	return res, nil
+}
+
+// rankDecision computes how well a decision separates the passing and failing runs
+//
+// Parameter:
+//   - key string: the decision key
+//   - pass []map[string]choice: decisions of the passing runs
+//   - fail []map[string]choice: decisions of the failing runs
+//
+// Returns:
+//   - Decision: the ranked decision
+//   - bool: false if the decision does not separate the runs
+func rankDecision(key string, pass, fail []map[string]choice) (Decision, bool) {
+	countPass, countFail := make(map[string]int), make(map[string]int)
+	timeSum, timeCount := 0., 0
+
+	for _, run := range pass {
+		c, ok := run[key]
+		if !ok {
+			countPass[notExecuted]++
+			continue
+		}
+		countPass[c.value]++
+		timeSum += c.time
+		timeCount++
+	}
+
+	for _, run := range fail {
+		c, ok := run[key]
+		if !ok {
+			countFail[notExecuted]++
+			continue
+		}
+		countFail[c.value]++
+		timeSum += c.time
+		timeCount++
+	}
+
+	kind, op, _ := strings.Cut(key, "|")
+	res := Decision{Kind: kind, Op: op, Separation: math.Inf(-1)}
+
+	for value, count := range countFail {
+		sep := float64(count)/float64(len(fail)) - float64(countPass[value])/float64(len(pass))
+		if sep > res.Separation || (sep == res.Separation && value < res.FailValue) {
+			res.Separation = sep
+			res.FailValue = value
+			res.FailCount = count
+		}
+	}
+
+	for value, count := range countPass {
+		if count > res.PassCount || (count == res.PassCount && value < res.PassValue) {
+			res.PassValue = value
+			res.PassCount = count
+		}
+	}
+
+	if timeCount > 0 {
+		res.Earliness = timeSum / float64(timeCount)
+	}
+
+	return res, res.Separation > 0
+}
+
+// getDecisions collects the ordering decisions made in a trace
+//
+// Parameter:
+//   - tr *trace.Trace: the trace
+//
+// Returns:
+//   - map[string]choice: decision key -> choice
+func getDecisions(tr *trace.Trace) map[string]choice {
+	al := diff.Align(tr)
+	res := make(map[string]choice)
+
+	maxTime := 1
+	for _, routine := range al.Routines() {
+		for _, elem := range al.Ops(routine) {
+			if t := elem.T(trace.Sorting); t != math.MaxInt {
+				maxTime = max(maxTime, t)
+			}
+			maxTime = max(maxTime, elem.T(trace.Request))
+		}
+	}
+
+	for _, routine := range al.Routines() {
+		for _, elem := range al.Ops(routine) {
+			kind, value := decisionOf(elem, al)
+			if kind == "" {
+				continue
+			}
+
+			t := elem.T(trace.Sorting)
+			if t == math.MaxInt {
+				t = elem.T(trace.Request)
+			}
+
+			res[kind+"|"+al.OpID(elem)] = choice{value, float64(t) / float64(maxTime)}
+		}
+	}
+
+	return res
+}
+
+// decisionOf returns the ordering decision made by an operation
+//
+// Parameter:
+//   - elem trace.Element: the operation
+//   - al *diff.Aligned: the aligned trace containing the operation
+//
+// Returns:
+//   - string: kind of the decision, empty if the operation does not make a decision
+//   - string: the chosen value
+func decisionOf(elem trace.Element, al *diff.Aligned) (string, string) {
+	switch e := elem.(type) {
+	case *trace.ElementSelect:
+		if !e.Committed() {
+			return SelectDecision, "blocked"
+		}
+		if e.GetChosenDefault() {
+			return SelectDecision, "default"
+		}
+		return SelectDecision, fmt.Sprintf("case %d with %s", e.GetChosenIndex(), partnerOf(e.GetChosenCase(), al))
+	case *trace.ElementChannel:
+		if e.Type(true) != trace.ChannelRecv {
+			return "", ""
+		}
+		if !e.Committed() {
+			return RecvDecision, "blocked"
+		}
+		return RecvDecision, "from " + partnerOf(e, al)
+	case *trace.ElementMutex:
+		if !e.IsLock() {
+			return "", ""
+		}
+		if !e.Committed() {
+			return LockDecision, "blocked"
+		}
+		if !e.IsSuc() {
+			return LockDecision, "trylock failed"
+		}
+		pred, ok := al.LockPred(e)
+		if !ok {
+			return "", ""
+		}
+		if pred == "" {
+			return LockDecision, "first acquisition"
+		}
+		return LockDecision, "after " + pred
+	}
+
+	return "", ""
+}
+
+// partnerOf returns the identifier of the communication partner of a channel operation
+//
+// Parameter:
+//   - ch *trace.ElementChannel: the channel operation
+//   - al *diff.Aligned: the aligned trace containing the operation
+//
+// Returns:
+//   - string: the identifier of the partner
+func partnerOf(ch *trace.ElementChannel, al *diff.Aligned) string {
+	if ch == nil || ch.GetPartner() == nil {
+		if ch != nil && ch.GetClosed() {
+			return "closed channel"
+		}
+		return "unknown"
+	}
+
+	partner := ch.GetPartner()
+	if sel := partner.GetSelect(); sel != nil {
+		return al.OpID(sel)
+	}
+	return al.OpID(partner)
+}
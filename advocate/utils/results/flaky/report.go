@@ -0,0 +1,93 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: report.go
+// Brief: Write the result of the flaky test analysis
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package flaky
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maximum number of decisions written into the readable report
+const maxReportedDecisions = 20
+
+// Write writes the report as markdown (flaky.md) and json (flaky.json)
+// into the given folder
+//
+// Parameter:
+//   - folder string: path to the folder
+//
+// Returns:
+//   - error
+func (this *Report) Write(folder string) error {
+	err := os.MkdirAll(folder, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	resJSON, err := json.MarshalIndent(this, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(folder, "flaky.json"), resJSON, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(folder, "flaky.md"), []byte(this.String()), 0644)
+}
+
+// String returns the readable markdown representation of the report
+//
+// Returns:
+//   - string: the report
+func (this *Report) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("# Flaky test analysis: " + this.Test + "\n\n")
+	sb.WriteString(fmt.Sprintf("The test was executed %d times. ", len(this.Runs)))
+	sb.WriteString(fmt.Sprintf("%d runs passed, %d runs failed.\n\n", this.NumberPass, this.NumberFail))
+
+	sb.WriteString("## Runs\n\n")
+	sb.WriteString("| Trace | Result | Reason |\n|---|---|---|\n")
+	for _, run := range this.Runs {
+		result := "pass"
+		if run.Failed {
+			result = "fail"
+		}
+		sb.WriteString(fmt.Sprintf("| advocateTrace_%d | %s | %s |\n", run.TraceID, result, strings.ReplaceAll(run.Reason, "|", "\\|")))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Separating decisions\n\n")
+	if len(this.Decisions) == 0 {
+		sb.WriteString("No ordering decision separates the passing and failing runs.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("The following ordering decisions separate the failing from the passing runs. ")
+	sb.WriteString("A separation of 1 means, that the decision was made in all failing runs and in none of the passing runs. ")
+	sb.WriteString("Decisions with the same separation are ordered by how early they were made in the runs.\n\n")
+
+	for i, d := range this.Decisions {
+		if i >= maxReportedDecisions {
+			sb.WriteString(fmt.Sprintf("... and %d more, see flaky.json\n", len(this.Decisions)-maxReportedDecisions))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("%d. **%s** `%s` (separation %.2f, earliness %.2f)\n", i+1, d.Kind, d.Op, d.Separation, d.Earliness))
+		sb.WriteString(fmt.Sprintf("    - failing runs (%d/%d): %s\n", d.FailCount, this.NumberFail, d.FailValue))
+		sb.WriteString(fmt.Sprintf("    - passing runs (%d/%d): %s\n", d.PassCount, this.NumberPass, d.PassValue))
+	}
+
+	return sb.String()
+}
@@ -78,10 +78,15 @@ var (
 	resultCriticalMachine    []string
 	resultInformationMachine []string
 	resultWithoutTime        []string
+	resultKeys               []string
 )
 
 var lockedGC = make(map[string]map[int]struct{})
 
+// trace used to attribute the results to subtests, phases and call stacks,
+// nil if not set
+var subtestTrace *trace.Trace
+
 // store all context channel that have been canceled
 // store all dones with its file, line and id
 var contextCancel = make(map[int]struct{})
@@ -180,6 +185,14 @@ func Result(level resultLevel, resType helper.ResultType, argType1 string, arg1
 	// 	return
 	// }
 
+	subtest, routine := getSubtest(arg1)
+
+	// a leak in a subtest is only a leak if the routine outlives the subtest
+	if resType.IsLeak() && routine != -1 && !subtestTrace.OutlivesSubtest(routine) {
+		log.Infof("Ignore leak in routine %d, the routine does not outlive subtest %s", routine, subtest)
+		return
+	}
+
 	foundBug = true
 
 	if resType == helper.ABlocking {
@@ -195,16 +208,18 @@ func Result(level resultLevel, resType helper.ResultType, argType1 string, arg1
 	falsePos := "tp"
 
 	if flags.CheckBenign && (resType.IsLeak() || resType.IsBlocking()) {
-		falsePositive, err := benign.IsBenign(resType, arg1[0].getFile(), arg1[0].getLine(), blockedGC, contextCancel, contextDone)
-		if err != nil {
-			log.Errorf("Could not determine if bug is benign: %s", err.Error())
-		}
-		if falsePositive {
+		if isBenign(resType, arg1[0]) {
 			falsePos = "fp"
 		}
 	}
 
-	resultReadable := resultTypeMap[resType] + ":" + falsePos + ":\n\t" + argType1 + ": "
+	name, ok := resultTypeMap[resType]
+	if !ok {
+		info, _ := helper.GetResultTypeInfo(resType)
+		name = info.Name
+	}
+
+	resultReadable := name + ":" + falsePos + ":\n\t" + argType1 + ": "
 	resultMachine := string(resType) + "," + falsePos + ","
 	resultMachineShort := string(resType)
 
@@ -239,9 +254,36 @@ func Result(level resultLevel, resType helper.ResultType, argType1 string, arg1
 		}
 	}
 
+	if subtest != "" {
+		if !strings.HasSuffix(resultReadable, "\n") {
+			resultReadable += "\n"
+		}
+		resultReadable += "\tSubtest: " + subtest
+		resultMachineShort += consts.PosSep + subtest
+	}
+
+	if phase := getPhase(arg1); phase != "" {
+		if !strings.HasSuffix(resultReadable, "\n") {
+			resultReadable += "\n"
+		}
+		resultReadable += "\tPhase: " + phase
+	}
+
+	if stacks := getStacks(arg1, arg2); stacks != "" {
+		if !strings.HasSuffix(resultReadable, "\n") {
+			resultReadable += "\n"
+		}
+		resultReadable += stacks
+	}
+
 	resultReadable += "\n"
 	resultMachine += "\n"
 
+	isNew := !types.Contains(resultWithoutTime, resultMachineShort)
+	if isNew {
+		resultKeys = append(resultKeys, resultKey(resType, arg1, arg2, subtest))
+	}
+
 	switch level {
 	case WARNING:
 		if !types.Contains(resultWithoutTime, resultMachineShort) {
@@ -263,6 +305,145 @@ func Result(level resultLevel, resType helper.ResultType, argType1 string, arg1
 	}
 }
 
+// SetSubtestTrace sets the trace used to attribute results to subtests,
+// phases and call stacks
+//
+// Parameter:
+//   - tr *trace.Trace: the analyzed trace
+func SetSubtestTrace(tr *trace.Trace) {
+	subtestTrace = tr
+}
+
+// getSubtest returns the subtest the first element of a result belongs to
+//
+// Parameter:
+//   - arg []ResultElem: elements directly involved in the bug
+//
+// Returns:
+//   - string: name of the subtest, empty if not in a subtest
+//   - int: routine of the element, -1 if the subtest could not be determined
+func getSubtest(arg []ResultElem) (string, int) {
+	if subtestTrace == nil {
+		return "", -1
+	}
+
+	for _, a := range arg {
+		elem, ok := a.(TraceElementResult)
+		if !ok || elem.isInvalid() {
+			continue
+		}
+		return subtestTrace.SubtestAt(elem.RoutineID, elem.TRequest), elem.RoutineID
+	}
+
+	return "", -1
+}
+
+// getPhase returns the phase, given by the last user event, the first
+// element of a result was executed in
+//
+// Parameter:
+//   - arg []ResultElem: elements directly involved in the bug
+//
+// Returns:
+//   - string: name of the last event before the element, empty if there is none
+func getPhase(arg []ResultElem) string {
+	if subtestTrace == nil {
+		return ""
+	}
+
+	for _, a := range arg {
+		elem, ok := a.(TraceElementResult)
+		if !ok || elem.isInvalid() {
+			continue
+		}
+		return subtestTrace.PhaseAt(elem.RoutineID, elem.TRequest)
+	}
+
+	return ""
+}
+
+// GetStack returns the recorded call stack of a result element
+//
+// Parameter:
+//   - arg ResultElem: the element
+//
+// Returns:
+//   - []trace.StackFrame: the frames, starting with the frame of the element, nil if no stack was recorded
+func GetStack(arg ResultElem) []trace.StackFrame {
+	if subtestTrace == nil {
+		return nil
+	}
+
+	elem, ok := arg.(TraceElementResult)
+	if !ok || elem.isInvalid() || elem.TRequest <= 0 {
+		return nil
+	}
+
+	return subtestTrace.StackAt(elem.TRequest)
+}
+
+// getStacks returns the call stacks of the elements of a result that were
+// called through at least one other function
+//
+// Parameter:
+//   - arg1 []ResultElem: elements directly involved in the bug
+//   - arg2 []ResultElem: elements indirectly involved in the bug
+//
+// Returns:
+//   - string: the stacks with one frame per line, empty if there are none
+func getStacks(arg1, arg2 []ResultElem) string {
+	res := ""
+	for _, arg := range append(append([]ResultElem{}, arg1...), arg2...) {
+		stack := GetStack(arg)
+		if len(stack) < 2 {
+			continue
+		}
+
+		res += "\tStack of " + arg.stringReadable() + ":\n"
+		for _, frame := range stack {
+			res += "\t\t" + frame.String() + "\n"
+		}
+	}
+
+	return res
+}
+
+// isBenign checks if a blocking bug or leak is likely a false positive.
+// If a call stack was recorded for the element, the functions that called
+// the operation are checked as well, e.g. a helper that is called in an
+// endless loop.
+//
+// Parameter:
+//   - resType helper.ResultType: type of the bug
+//   - arg ResultElem: the blocking element
+//
+// Returns:
+//   - bool: true if the bug is likely benign
+func isBenign(resType helper.ResultType, arg ResultElem) bool {
+	falsePositive, err := benign.IsBenign(resType, arg.getFile(), arg.getLine(), blockedGC, contextCancel, contextDone)
+	if err != nil {
+		log.Errorf("Could not determine if bug is benign: %s", err.Error())
+	}
+	if falsePositive {
+		return true
+	}
+
+	stack := GetStack(arg)
+	for i := 1; i < len(stack); i++ {
+		// the remaining callers are in the standard library, e.g. the testing package
+		if strings.Contains(stack[i].File, paths.Join(true, true, "goPatch", "src")) {
+			break
+		}
+
+		falsePositive, err := benign.IsBenign(resType, stack[i].File, stack[i].Line, blockedGC, contextCancel, contextDone)
+		if err == nil && falsePositive {
+			return true
+		}
+	}
+
+	return false
+}
+
 // AddContext stores all context channel, that have been canceled and stores the
 // corresponding data for the done
 //
@@ -458,6 +639,7 @@ func Reset() {
 	resultInformationMachine = make([]string, 0)
 
 	resultWithoutTime = make([]string, 0)
+	resultKeys = make([]string, 0)
 
 	outputMachineFile = ""
 	outputReadableFile = ""
@@ -465,6 +647,42 @@ func Reset() {
 	foundBug = false
 }
 
+// resultKey returns a key for a result that only consists of the type
+// and the code positions of the result. It does not contain times or ids
+// and can therefore be used to compare results between runs.
+//
+// Parameter:
+//   - resType helper.ResultType: type of the result
+//   - arg1 []ResultElem: first elements of the result
+//   - arg2 []ResultElem: second elements of the result
+//   - subtest string: the subtest of the result
+//
+// Returns:
+//   - string: the key
+func resultKey(resType helper.ResultType, arg1, arg2 []ResultElem, subtest string) string {
+	res := string(resType)
+	for _, arg := range append(append([]ResultElem{}, arg1...), arg2...) {
+		if arg.isInvalid() {
+			continue
+		}
+		res += fmt.Sprintf(",%s%s%d", arg.getFile(), consts.PosSep, arg.getLine())
+	}
+	if subtest != "" {
+		res += "," + subtest
+	}
+	return res
+}
+
+// GetResultKeys returns a key for each result found since the last reset.
+// The key only contains the type and the positions of the result and can
+// therefore be used to compare results between runs.
+//
+// Returns:
+//   - []string: the keys of the results
+func GetResultKeys() []string {
+	return append([]string{}, resultKeys...)
+}
+
 // GetBugWasFound returns if since the last reset, a bug was found
 //
 // Returns:
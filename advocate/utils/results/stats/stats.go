@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -77,7 +78,7 @@ func CreateStats(testName string, traceID, fuzzing int) error {
 		log.Error("Failed to create fuzzing statistics: ", err.Error())
 	}
 
-	statsAnalyzerTotal, statsAnalyzerUnique, err := statsAnalyzer(fuzzing)
+	statsAnalyzerTotal, statsAnalyzerUnique, statsSubtests, err := statsAnalyzer(fuzzing)
 	if err != nil {
 		log.Error("Failed to create analysis statistics: ", err.Error())
 	}
@@ -92,6 +93,8 @@ func CreateStats(testName string, traceID, fuzzing int) error {
 		return err
 	}
 
+	writeStatsSubtests(testName, statsSubtests)
+
 	return nil
 
 }
@@ -257,6 +260,39 @@ func writeStatsToFile(testName string, statsTraces map[statsType]int, statsFuzz
 	return nil
 }
 
+// writeStatsSubtests writes the number of detected bugs for each subtest
+// of a test into a csv file
+//
+// Parameter:
+//   - testName string: name of the test
+//   - statsSubtests map[string]map[helper.ResultType]int: detected bugs per subtest
+func writeStatsSubtests(testName string, statsSubtests map[string]map[helper.ResultType]int) {
+	if len(statsSubtests) == 0 {
+		return
+	}
+
+	fileSubtestsPath := filepath.Join(paths.ResultStats, "statsSubtests_"+flags.ProgName+".csv")
+
+	header := "TestName,Subtest"
+	for _, code := range helper.ResultTypes {
+		header += ",NrDetected" + string(code)
+	}
+
+	subtests := make([]string, 0, len(statsSubtests))
+	for subtest := range statsSubtests {
+		subtests = append(subtests, subtest)
+	}
+	sort.Strings(subtests)
+
+	for _, subtest := range subtests {
+		data := testName + "," + subtest
+		for _, code := range helper.ResultTypes {
+			data += "," + strconv.Itoa(statsSubtests[subtest][code])
+		}
+		writeStatsFile(fileSubtestsPath, header, data)
+	}
+}
+
 // writeStatsFile writes the collected stats to a csv file
 //
 // Parameter:
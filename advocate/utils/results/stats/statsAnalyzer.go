@@ -61,8 +61,9 @@ func getNewDataMapMap() map[statsType]map[helper.ResultType]int {
 // Returns:
 //   - map[statsType]map[helper.ResultType]int: map with total information
 //   - map[statsType]map[helper.ResultType]int: map with unique information
+//   - map[string]map[helper.ResultType]int: for each subtest, the number of detected bugs
 //   - error
-func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[statsType]map[helper.ResultType]int, error) {
+func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[statsType]map[helper.ResultType]int, map[string]map[helper.ResultType]int, error) {
 	// reset foundBugs
 	foundBugs := make(map[string]processedBug)
 
@@ -70,10 +71,12 @@ func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[st
 
 	resTotal := getNewDataMapMap()
 
+	resSubtest := make(map[string]map[helper.ResultType]int)
+
 	bugs := filepath.Join(paths.CurrentResult, "bugs")
 	_, err := os.Stat(bugs)
 	if os.IsNotExist(err) {
-		return resUnique, nil, nil
+		return resUnique, nil, resSubtest, nil
 	}
 
 	err = filepath.Walk(bugs, func(path string, info os.FileInfo, err error) error {
@@ -89,7 +92,7 @@ func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[st
 			if strings.HasPrefix(info.Name(), "bug_") ||
 				strings.HasPrefix(info.Name(), "diagnostics_") ||
 				strings.HasPrefix(info.Name(), "leak_") {
-				err := processBugFile(path, foundBugs, resTotal, resUnique)
+				err := processBugFile(path, foundBugs, resTotal, resUnique, resSubtest)
 				if err != nil {
 					log.Error(err)
 				}
@@ -98,7 +101,7 @@ func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[st
 			if strings.HasPrefix(info.Name(), "bug_"+strconv.Itoa(fuzzing)+"_") ||
 				strings.HasPrefix(info.Name(), "diagnostics_"+strconv.Itoa(fuzzing)+"_") ||
 				strings.HasPrefix(info.Name(), "leak_"+strconv.Itoa(fuzzing)+"_") {
-				err := processBugFile(path, foundBugs, resTotal, resUnique)
+				err := processBugFile(path, foundBugs, resTotal, resUnique, resSubtest)
 				if err != nil {
 					log.Error(err)
 				}
@@ -125,7 +128,7 @@ func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[st
 		return nil
 	})
 
-	return resTotal, resUnique, err
+	return resTotal, resUnique, resSubtest, err
 }
 
 // Store a bug that has been processed in the statistics.
@@ -137,12 +140,14 @@ func statsAnalyzer(fuzzing int) (map[statsType]map[helper.ResultType]int, map[st
 //   - replayWritten bool: true if a replay trace was created for the bug
 //   - replaySuc bool: true if the replay of the bug was successful
 //   - falsePos bool: true if the bug is likely a false positive
+//   - subtest string: subtest in which the bug was found, empty if not in a subtest
 type processedBug struct {
 	paths         []string
 	bugType       helper.ResultType
 	replayWritten bool
 	replaySuc     bool
 	falsePos      bool
+	subtest       string
 }
 
 // Get a string representation of a bug
@@ -163,11 +168,13 @@ func (this *processedBug) getKey() string {
 //   - filePath string: path to the bug file
 //   - resTotal map[string]map[string]int: total results
 //   - resUnique map[string]map[string]int: unique results
+//   - resSubtest map[string]map[helper.ResultType]int: detected bugs per subtest
 //
 // Returns:
 //   - error
 func processBugFile(filePath string, foundBugs map[string]processedBug,
-	resTotal map[statsType]map[helper.ResultType]int, resUnique map[statsType]map[helper.ResultType]int) error {
+	resTotal map[statsType]map[helper.ResultType]int, resUnique map[statsType]map[helper.ResultType]int,
+	resSubtest map[string]map[helper.ResultType]int) error {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return err
@@ -196,6 +203,8 @@ func processBugFile(filePath string, foundBugs map[string]processedBug,
 			bugType = helper.ResultType(textSplit2[0])
 			line = textSplit2[1]
 			bug.bugType = bugType
+		} else if strings.HasPrefix(line, "- Subtest: ") {
+			bug.subtest = strings.TrimPrefix(line, "- Subtest: ")
 		} else if strings.HasPrefix(line, "-> ") { // get paths
 			bug.paths = append(bug.paths, strings.TrimPrefix(line, "-> "))
 		} else if strings.Contains(line, "The analyzer found a way to resolve the leak") {
@@ -229,6 +238,13 @@ func processBugFile(filePath string, foundBugs map[string]processedBug,
 		return fmt.Errorf("Invalid bug file")
 	}
 
+	if bug.subtest != "" && resSubtest != nil {
+		if _, ok := resSubtest[bug.subtest]; !ok {
+			resSubtest[bug.subtest] = make(map[helper.ResultType]int)
+		}
+		resSubtest[bug.subtest][bugType]++
+	}
+
 	if resTotal != nil {
 		(resTotal)[detected][bugType]++
 
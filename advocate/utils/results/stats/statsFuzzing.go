@@ -110,7 +110,7 @@ func CreateStatsFuzzing(pathFolder string) error {
 		res := getNewDataMapMap()
 
 		for _, bug := range bugDir {
-			processBugFile(filepath.Join(paths.ResultBugs, bug.Name()), foundBugs, nil, res)
+			processBugFile(filepath.Join(paths.ResultBugs, bug.Name()), foundBugs, nil, res, nil)
 		}
 
 		for _, bug := range foundBugs {
@@ -85,7 +85,7 @@ func CreateStatsTotal(pathFolder string) error {
 			return nil
 		}
 
-		return processBugFile(path, foundBugs, nil, data)
+		return processBugFile(path, foundBugs, nil, data, nil)
 	})
 
 	for _, bug := range foundBugs {
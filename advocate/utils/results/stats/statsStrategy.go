@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: statsStrategy.go
+// Brief: Create stats about the yield of the strategies in adaptive fuzzing
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package stats
+
+import (
+	"advocate/utils/flags"
+	"advocate/utils/log"
+	"advocate/utils/paths"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StrategyYield is the yield of one fuzzing strategy in adaptive fuzzing
+//
+// Fields:
+//   - Strategy string: name of the strategy
+//   - Runs int: number of runs of mutations created by the strategy
+//   - NewBugs int: number of bugs first found in those runs
+//   - NewSelectCases int: number of select cases first selected in those runs
+//   - NewHBPairs int: number of hb pairs first observed in those runs
+//   - Reward float64: sum of the rewards of those runs
+type StrategyYield struct {
+	Strategy       string
+	Runs           int
+	NewBugs        int
+	NewSelectCases int
+	NewHBPairs     int
+	Reward         float64
+}
+
+// CreateStatsStrategies writes the yield of the strategies used in adaptive
+// fuzzing of a test into statsStrategies_[progName].csv
+//
+// Parameter:
+//   - testName string: name of the fuzzed test
+//   - yields []StrategyYield: the yield of each strategy
+func CreateStatsStrategies(testName string, yields []StrategyYield) {
+	if len(yields) == 0 {
+		return
+	}
+
+	err := os.MkdirAll(paths.ResultStats, os.ModePerm)
+	if err != nil {
+		log.Error("Could not create stats folder")
+		return
+	}
+
+	fileStrategiesPath := filepath.Join(paths.ResultStats, "statsStrategies_"+flags.ProgName+".csv")
+
+	header := "TestName,Strategy,NrRuns,NrNewBugs,NrNewSelectCases,NrNewHBPairs,MeanReward"
+
+	for _, y := range yields {
+		meanReward := 0.0
+		if y.Runs > 0 {
+			meanReward = y.Reward / float64(y.Runs)
+		}
+
+		data := fmt.Sprintf("%s,%s,%d,%d,%d,%d,%.4f", testName, y.Strategy, y.Runs,
+			y.NewBugs, y.NewSelectCases, y.NewHBPairs, meanReward)
+		writeStatsFile(fileStrategiesPath, header, data)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_annotation.go
+// Brief: Annotation api to record user events, declare custom happens
+//    before edges and exclude regions from the recording
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package advocatego
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// key -> id of the key in the trace
+var annotationKeys = make(map[any]uint64)
+var annotationKeysLock sync.Mutex
+
+// Event records a user event marker, e.g. the start of a phase of the
+// program. The key value pairs are given as alternating keys and values,
+// e.g. Event("request", "id", 5). Bugs found by the analysis are reported
+// with the last event before them. Without tracing, Event does nothing.
+//
+// Parameter:
+//   - name string: name of the event
+//   - kv ...any: key value pairs
+func Event(name string, kv ...any) {
+	if runtime.AdvocateTracingDisabled {
+		return
+	}
+
+	pairs := make([]string, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 < len(kv) {
+			pairs = append(pairs, fmt.Sprintf("%v=%v", kv[i], kv[i+1]))
+		} else {
+			pairs = append(pairs, fmt.Sprintf("%v=", kv[i]))
+		}
+	}
+
+	runtime.AdvocateEvent(name, strings.Join(pairs, ";"))
+}
+
+// Release declares that all operations executed before it happen before
+// all operations executed after a later Acquire on the same key. It can be
+// used to describe the synchronization of lock-free data structures that is
+// otherwise not visible in the trace. Without tracing, Release does nothing.
+//
+// Parameter:
+//   - key any: the key, must be comparable
+func Release(key any) {
+	if runtime.AdvocateTracingDisabled {
+		return
+	}
+
+	runtime.AdvocateCustomSync(annotationKeyID(key), true)
+}
+
+// Acquire declares that all operations executed before the last Release on
+// the same key happen before all operations executed after it. Without
+// tracing, Acquire does nothing.
+//
+// Parameter:
+//   - key any: the key, must be comparable
+func Acquire(key any) {
+	if runtime.AdvocateTracingDisabled {
+		return
+	}
+
+	runtime.AdvocateCustomSync(annotationKeyID(key), false)
+}
+
+// Ignore runs f and excludes all operations executed in it and in routines
+// started in it from the recording. The synchronization of the excluded
+// operations is kept in the trace as summaries. Without tracing, Ignore
+// only runs f.
+//
+// Parameter:
+//   - f func(): the function to run
+func Ignore(f func()) {
+	runtime.AdvocateIgnoreStart()
+	defer runtime.AdvocateIgnoreEnd()
+
+	f()
+}
+
+// annotationKeyID returns the id of a key used in Release and Acquire.
+// A new id is created if the key is used for the first time.
+//
+// Parameter:
+//   - key any: the key
+//
+// Returns:
+//   - uint64: the id of the key
+func annotationKeyID(key any) uint64 {
+	annotationKeysLock.Lock()
+	defer annotationKeysLock.Unlock()
+
+	if id, ok := annotationKeys[key]; ok {
+		return id
+	}
+
+	id := runtime.GetAdvocateObjectID()
+	annotationKeys[key] = id
+	return id
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_flightrecorder.go
+// Brief: Flight recorder mode, where only the last elements of each routine
+//    are recorded and the trace is dumped on request
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package advocatego
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "unsafe"
+)
+
+// Reasons for a dump of the flight recorder
+const (
+	DumpDeadlock = "deadlock"
+	DumpPanic    = "panic"
+	DumpSignal   = "signal"
+	DumpCall     = "call"
+	DumpExit     = "exit"
+)
+
+// interval in which the flight recorder checks for a global deadlock
+const flightRecorderCheckInterval = 200 * time.Millisecond
+
+var flightRecorderTimeout time.Duration
+var dumpLock sync.Mutex
+
+// InitFlightRecorder enables the flight recorder mode. Each routine only
+// keeps the last size trace elements. The trace is dumped on a global
+// deadlock, on a panic, at the end of the program, on SIGUSR1 or when
+// Dump is called. Must be called before InitTracing.
+//
+// Parameter:
+//   - size int: number of elements kept per routine
+//
+//go:linkname InitFlightRecorder runtime.AdvocateInitFlightRecorder
+func InitFlightRecorder(size int) {
+	if size <= 0 || runtime.IsFlightRecorderEnabled() {
+		return
+	}
+
+	runtime.InitFlightRecorder(size)
+
+	// started before the tracing is enabled, therefore not recorded
+	go watchGlobalDeadlock()
+	notifyDumpSignal()
+}
+
+// Dump writes the current content of the flight recorder into the trace
+// folder. Previously dumped traces are replaced. If the flight recorder
+// is not enabled, Dump does nothing.
+func Dump() {
+	dump(DumpCall)
+}
+
+// dump writes the current content of the flight recorder while the program
+// is still running. Operations executed while the trace is written are
+// not recorded.
+//
+// Parameter:
+//   - reason string: reason for the dump
+func dump(reason string) {
+	if !initTracing || !runtime.IsFlightRecorderEnabled() {
+		return
+	}
+
+	dumpLock.Lock()
+	defer dumpLock.Unlock()
+
+	if hasFinished {
+		return
+	}
+
+	runtime.DisableTracing()
+	defer runtime.EnableTracing()
+
+	runtime.BuildOAT()
+
+	if timerStarted {
+		duration = time.Since(startTime)
+	}
+
+	writeFlightRecorder(reason)
+}
+
+// watchGlobalDeadlock periodically checks if all recorded routines are
+// blocked. In this case, the trace is dumped and the program is terminated
+// like the go runtime does for a global deadlock. It also terminates the
+// program if the timeout is reached.
+func watchGlobalDeadlock() {
+	foundBefore := false
+
+	for {
+		time.Sleep(flightRecorderCheckInterval)
+
+		if hasFinished {
+			return
+		}
+
+		if flightRecorderTimeout > 0 && timerStarted && time.Since(startTime) > flightRecorderTimeout {
+			panic("Timeout")
+		}
+
+		// only report the deadlock if it has been found in two consecutive checks
+		found := initTracing && runtime.AdvocateGlobalDeadlock()
+		if found && foundBefore {
+			finishFlightRecorder(DumpDeadlock)
+			println("fatal error: all goroutines are asleep - deadlock!")
+			os.Exit(2)
+		}
+		foundBefore = found
+	}
+}
+
+// finishFlightRecorder writes the final dump of the flight recorder at the
+// end of the program
+//
+// Parameter:
+//   - reason string: reason for the dump, if empty it is set based on the exit code
+func finishFlightRecorder(reason string) {
+	dumpLock.Lock()
+	defer dumpLock.Unlock()
+
+	if hasFinished {
+		return
+	}
+	hasFinished = true
+
+	runtime.DisableTracing()
+	runtime.BuildOAT()
+
+	if timerStarted {
+		duration = time.Since(startTime)
+	}
+
+	if reason == "" {
+		reason = DumpExit
+		if exitCode, _ := runtime.GetExitCode(); exitCode != 0 {
+			reason = DumpPanic
+		}
+	}
+
+	writeFlightRecorder(reason)
+}
+
+// writeFlightRecorder writes the traces stored in the flight recorder. The
+// routines are renumbered to 1, ..., n in the order of their ids, because
+// terminated routines may have been removed from the flight recorder.
+// Spawn elements of routines that are no longer stored are removed.
+//
+// Parameter:
+//   - reason string: reason for the dump
+func writeFlightRecorder(reason string) {
+	removeDumpedTrace()
+
+	ids := runtime.GetAdvocateRoutineIDs()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	newIDs := make(map[uint64]int)
+	for i, id := range ids {
+		newIDs[id] = i + 1
+	}
+
+	writeToTraceFileInfo(len(ids))
+	writeFlightRecorderInfo(reason)
+	writeStackTable()
+	writeIdentityTable()
+
+	for _, id := range ids {
+		fileName := filepath.Join(tracePathRecorded, "trace_"+strconv.Itoa(newIDs[id])+".log")
+		file, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			println("Cannot write dump: ", err.Error())
+			return
+		}
+
+		for res := range runtime.TraceToChanByID(id) {
+			file.WriteString(renumberSpawns(res, newIDs))
+		}
+
+		file.Close()
+	}
+}
+
+// renumberSpawns replaces the ids of the spawned routines in spawn elements
+// by the new ids and removes spawns of routines that are not stored
+//
+// Parameter:
+//   - elems string: trace elements separated by new lines
+//   - newIDs map[uint64]int: old routine id -> new routine id
+//
+// Returns:
+//   - string: the elements with renumbered spawns
+func renumberSpawns(elems string, newIDs map[uint64]int) string {
+	var b strings.Builder
+
+	for line := range strings.SplitSeq(elems, "\n") {
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "G,") {
+			fields := strings.SplitN(line, ",", 4)
+			if len(fields) == 4 {
+				id, err := strconv.ParseUint(fields[2], 10, 64)
+				newID, ok := newIDs[id]
+				if err != nil || !ok {
+					continue
+				}
+				line = fields[0] + "," + fields[1] + "," + strconv.Itoa(newID) + "," + fields[3]
+			}
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// removeDumpedTrace removes the files of a previous dump from the trace folder
+func removeDumpedTrace() {
+	files, err := os.ReadDir(tracePathRecorded)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), "trace_") {
+			os.Remove(filepath.Join(tracePathRecorded, file.Name()))
+		}
+	}
+}
+
+// writeFlightRecorderInfo adds the flight recorder information to the
+// trace info file
+//
+// Parameter:
+//   - reason string: reason for the dump
+func writeFlightRecorderInfo(reason string) {
+	fileName := filepath.Join(tracePathRecorded, "trace_info.log")
+
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	file.WriteString(fmt.Sprintf("FlightRecorder!%d\n", runtime.GetFlightRecorderSize()))
+	file.WriteString(fmt.Sprintf("Dump!%s\n", reason))
+}
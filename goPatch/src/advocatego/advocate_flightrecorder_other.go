@@ -0,0 +1,16 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_flightrecorder_other.go
+// Brief: Systems without SIGUSR1, the flight recorder can only be dumped
+//    with Dump
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+//go:build !unix
+
+package advocatego
+
+// notifyDumpSignal does nothing on systems without SIGUSR1
+func notifyDumpSignal() {}
@@ -0,0 +1,31 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_flightrecorder_unix.go
+// Brief: Dump the flight recorder on SIGUSR1
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+//go:build unix
+
+package advocatego
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyDumpSignal starts a routine that dumps the flight recorder each
+// time the program receives SIGUSR1
+func notifyDumpSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+
+	go func() {
+		for range c {
+			dump(DumpSignal)
+		}
+	}()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_fuzz_input.go
+// Brief: Record the inputs of go fuzz tests
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package advocatego
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// RecordFuzzInput writes an input of a fuzz target into the fuzz_input.log
+// file in the trace folder. Each input is written as its test name
+// followed by the input in the go corpus file format and an empty line.
+// It is called by the testing package before a corpus entry is run.
+//
+// Parameter:
+//   - name string: name of the test running the input, e.g. FuzzFoo/seed#0
+//   - values []any: the input values
+func RecordFuzzInput(name string, values []any) {
+	if !initTracing || len(values) == 0 {
+		return
+	}
+
+	fileName := filepath.Join(tracePathRecorded, "fuzz_input.log")
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	file.WriteString(name + "\n" + marshalFuzzInput(values) + "\n")
+}
+
+// marshalFuzzInput encodes fuzz input values in the go corpus file format
+// ("go test fuzz v1"), so that they can be stored in testdata/fuzz.
+// The encoding is the same as in internal/fuzz.
+//
+// Parameter:
+//   - values []any: the input values
+//
+// Returns:
+//   - string: the encoded values
+func marshalFuzzInput(values []any) string {
+	var b strings.Builder
+	b.WriteString("go test fuzz v1\n")
+
+	for _, val := range values {
+		switch t := val.(type) {
+		case int, int8, int16, int64, uint, uint16, uint32, uint64, bool:
+			fmt.Fprintf(&b, "%T(%v)\n", t, t)
+		case float32:
+			if math.IsNaN(float64(t)) && math.Float32bits(t) != math.Float32bits(float32(math.NaN())) {
+				fmt.Fprintf(&b, "math.Float32frombits(0x%x)\n", math.Float32bits(t))
+			} else {
+				fmt.Fprintf(&b, "%T(%v)\n", t, t)
+			}
+		case float64:
+			if math.IsNaN(t) && math.Float64bits(t) != math.Float64bits(math.NaN()) {
+				fmt.Fprintf(&b, "math.Float64frombits(0x%x)\n", math.Float64bits(t))
+			} else {
+				fmt.Fprintf(&b, "%T(%v)\n", t, t)
+			}
+		case string:
+			fmt.Fprintf(&b, "string(%q)\n", t)
+		case rune:
+			if utf8.ValidRune(t) {
+				fmt.Fprintf(&b, "rune(%q)\n", t)
+			} else {
+				fmt.Fprintf(&b, "int32(%v)\n", t)
+			}
+		case byte:
+			fmt.Fprintf(&b, "byte(%q)\n", t)
+		case []byte:
+			fmt.Fprintf(&b, "[]byte(%q)\n", t)
+		default:
+			fmt.Fprintf(&b, "%T(%v)\n", t, t)
+		}
+	}
+
+	return b.String()
+}
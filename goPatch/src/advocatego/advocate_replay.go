@@ -12,6 +12,8 @@ package advocatego
 
 import (
 	"bufio"
+	"encoding/hex"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -24,8 +26,17 @@ import (
 )
 
 const (
-	activeFile = "replay_active.log"
-	posSep     = "#"
+	activeFile     = "replay_active.log"
+	minimalFile    = "minimal_schedule.log"
+	divergenceFile = "replay_divergence.log"
+	stacksFile     = "trace_stacks.log"
+	nondetFile     = "trace_nondet.log"
+	identitiesFile = "object_identities.log"
+	posSep         = "#"
+
+	// header line of the active file, written by the minimization, if only the
+	// active elements should be read from the trace
+	onlyActiveMarker = "onlyActive"
 )
 
 var timeout = false
@@ -58,6 +69,8 @@ func InitReplay(tracePath string, timeout int, atomic bool, init bool) {
 
 	tracePathRewritten = tracePath
 
+	runtime.SetReplayDivergenceFunc(writeDivergence)
+
 	startReplay(timeout)
 }
 
@@ -75,7 +88,7 @@ func startReplay(timeout int) {
 	}
 
 	// check for and if exists, read the rewrite_active.log file
-	activeStartTime, active, _, numberActive := readReplayActive(tracePathRewritten)
+	activeStartTime, active, _, numberActive, onlyActive := readReplayActive(tracePathRewritten)
 
 	// if activeStartTime == 0 && numberActive == 0, add later
 	if active != nil && !(activeStartTime == 0 && numberActive == 0) {
@@ -96,12 +109,19 @@ func startReplay(timeout int) {
 			continue
 		}
 
-		if file.Name() == "times.log" || file.Name() == "trace_info.log" {
+		if file.Name() == "times.log" || file.Name() == "trace_info.log" || file.Name() == minimalFile ||
+			file.Name() == divergenceFile || file.Name() == stacksFile {
+			continue
+		}
+
+		if file.Name() == nondetFile {
+			readNondetFile(filepath.Join(tracePathRewritten, nondetFile))
 			continue
 		}
 
-		// if the file is a trace file, read the trace
-		if strings.HasSuffix(file.Name(), ".log") &&
+		// if the file is a trace file, read the trace. Other files, e.g. the
+		// inputs of the program, are stored next to the traces
+		if strings.HasPrefix(file.Name(), "trace_") && strings.HasSuffix(file.Name(), ".log") &&
 			file.Name() != "rewrite_info.log" &&
 			file.Name() != activeFile {
 			readTraceFile(tracePathRewritten+"/"+file.Name(), replayData, spawns, selects)
@@ -122,6 +142,9 @@ func startReplay(timeout int) {
 	if activeStartTime == 0 && numberActive == 0 {
 		active, numberActive = allTraceElemToRuntimeActive(replayData)
 		runtime.AddActiveTrace(activeStartTime, active, numberActive)
+	} else if activeStartTime == 0 && onlyActive {
+		// partial replay from the start: only the active elements are ordered
+		filterActiveTraceElem(replayData, active)
 	}
 
 	if timeout > 0 {
@@ -151,12 +174,14 @@ func startReplay(timeout int) {
 //   - map[string][]int: the map from key to counter for the active elements
 //   - map[int]struct{}: the map containing the time of all elements that are active
 //   - int: number of active operations
-func readReplayActive(tracePathRewritten string) (int, map[string][]int, map[int]struct{}, int) {
+//   - bool: true if the file contains the onlyActive header, i.e. elements
+//     of the trace that are not active should not be replayed
+func readReplayActive(tracePathRewritten string) (int, map[string][]int, map[int]struct{}, int, bool) {
 	path := filepath.Join(tracePathRewritten, activeFile)
 	file, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return -1, nil, nil, 0
+			return -1, nil, nil, 0, false
 		}
 		panic(err)
 	}
@@ -166,6 +191,7 @@ func readReplayActive(tracePathRewritten string) (int, map[string][]int, map[int
 	activeTPre := make(map[int]struct{})
 	numberActive := 0
 	firstTime := -1
+	onlyActive := false
 
 	// The elements in the active file should be separated by new line and have
 	// the following form:
@@ -186,6 +212,11 @@ func readReplayActive(tracePathRewritten string) (int, map[string][]int, map[int
 			}
 		}
 
+		if elem == onlyActiveMarker {
+			onlyActive = true
+			continue
+		}
+
 		fields := strings.Split(elem, ",")
 		if len(fields) != 3 {
 			continue
@@ -207,7 +238,80 @@ func readReplayActive(tracePathRewritten string) (int, map[string][]int, map[int
 		activeTPre[tPre] = struct{}{}
 	}
 
-	return firstTime, active, activeTPre, numberActive
+	return firstTime, active, activeTPre, numberActive, onlyActive
+}
+
+// readNondetFile reads the recorded results of nondeterministic calls,
+// e.g. time.Now, and adds them to the runtime to be returned in replay.
+// Each line has the form
+//
+//	[routine],[op],[int].[int]...,[hex data],[file#line]
+//
+// Parameter:
+//   - fileName string: path to the trace_nondet.log file
+func readNondetFile(fileName string) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		println("Cannot read nondeterministic calls: ", err.Error())
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) < 5 {
+			println("Invalid nondeterministic call: ", line)
+			continue
+		}
+
+		routine, err := strconv.Atoi(fields[0])
+		if err != nil {
+			println("Invalid routine in nondeterministic call: ", line)
+			continue
+		}
+
+		call := runtime.NondetCall{Op: runtime.Operation(fields[1])}
+
+		if fields[2] != "" {
+			for _, v := range strings.Split(fields[2], ".") {
+				i, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					println("Invalid value in nondeterministic call: ", line)
+					break
+				}
+				call.Ints = append(call.Ints, i)
+			}
+		}
+
+		data, err := hex.DecodeString(fields[3])
+		if err != nil {
+			println("Invalid data in nondeterministic call: ", line)
+			continue
+		}
+		call.Data = string(data)
+
+		pos := strings.Join(fields[4:], ",")
+		sep := strings.LastIndex(pos, posSep)
+		if sep == -1 {
+			println("Invalid position in nondeterministic call: ", line)
+			continue
+		}
+		call.File = pos[:sep]
+		call.Line, err = strconv.Atoi(pos[sep+1:])
+		if err != nil {
+			println("Invalid line in nondeterministic call: ", line)
+			continue
+		}
+
+		runtime.AddNondetReplay(routine, call)
+	}
 }
 
 // Import the trace.
@@ -257,6 +361,7 @@ func readTraceFile(fileName string,
 		var blocked = false
 		var suc = true
 		var index int
+		var objID int
 
 		// for some elements, the tPre and tPost are the same
 		// we here set the time value as the tPre as a default. If the element
@@ -291,6 +396,7 @@ func readTraceFile(fileName string,
 			default:
 				panic("Unknown channel operation " + fields[4] + " in line " + elem + " in file " + fileName + ".")
 			}
+			objID, _ = strconv.Atoi(fields[3])
 			time, _ = strconv.Atoi(fields[2])
 			if time == 0 {
 				blocked = true
@@ -303,6 +409,7 @@ func readTraceFile(fileName string,
 			if fields[4] == "R" {
 				rw = true
 			}
+			objID, _ = strconv.Atoi(fields[3])
 			time, _ = strconv.Atoi(fields[2])
 			if fields[6] == "f" {
 				suc = false
@@ -344,6 +451,7 @@ func readTraceFile(fileName string,
 
 		case "O":
 			op = runtime.OperationOnceDo
+			objID, _ = strconv.Atoi(fields[3])
 			// time, _ = strconv.Atoi(fields[1]) // read tpre to prevent false order
 			if time == 0 {
 				blocked = true
@@ -363,6 +471,7 @@ func readTraceFile(fileName string,
 			default:
 				panic("Unknown waitgroup operation")
 			}
+			objID, _ = strconv.Atoi(fields[3])
 			time, _ = strconv.Atoi(fields[2])
 			if time == 0 {
 				blocked = true
@@ -382,6 +491,7 @@ func readTraceFile(fileName string,
 				blocked = true
 			}
 			index, _ = strconv.Atoi(fields[5])
+			objID = selectCaseObjID(cases, index)
 			pos := strings.Split(fields[6], posSep)
 			file = pos[0]
 			line, _ = strconv.Atoi(pos[1])
@@ -396,6 +506,7 @@ func readTraceFile(fileName string,
 			default:
 				panic("Unknown cond operation: " + fields[4])
 			}
+			objID, _ = strconv.Atoi(fields[3])
 			pos := strings.Split(fields[5], posSep)
 			file = pos[0]
 			line, _ = strconv.Atoi(pos[1])
@@ -422,6 +533,7 @@ func readTraceFile(fileName string,
 			case "O":
 				op = runtime.OperationAtomicOr
 			}
+			objID, _ = strconv.Atoi(fields[2])
 			pos := strings.Split(fields[4], posSep)
 			if len(pos) < 2 {
 				runtime.SetReplayAtomic(false)
@@ -449,7 +561,7 @@ func readTraceFile(fileName string,
 			newElem := runtime.ReplayElement{
 				Op: op, Routine: routineID, Time: time, TimePre: tPre, File: file, Line: line,
 				Blocked: blocked, Suc: suc,
-				Index: index}
+				Index: index, ObjID: objID}
 			*replayData = append(*replayData, newElem)
 
 			if op == runtime.OperationSelect || op == runtime.OperationSelectCase || op == runtime.OperationSelectDefault {
@@ -464,6 +576,28 @@ func readTraceFile(fileName string,
 	}
 }
 
+// selectCaseObjID returns the id of the channel of the chosen case of a select
+//
+// Parameter:
+//   - cases []string: the cases of the select
+//   - index int: index of the chosen case
+//
+// Returns:
+//   - int: the id of the channel, 0 if the default case was chosen or the channel is nil
+func selectCaseObjID(cases []string, index int) int {
+	if index < 0 || index >= len(cases) {
+		return 0
+	}
+
+	caseFields := strings.Split(cases[index], ".")
+	if len(caseFields) < 2 {
+		return 0
+	}
+
+	id, _ := strconv.Atoi(caseFields[1])
+	return id
+}
+
 // FinishReplay waits for the replay to finish.
 //
 //go:linkname FinishReplay runtime.AdvocateFinishReplay
@@ -503,3 +637,77 @@ func allTraceElemToRuntimeActive(replayTrace *runtime.AdvocateReplayTrace) (map[
 	return res, resCounter
 
 }
+
+// filterActiveTraceElem removes all elements from the replay trace that are
+// not active. This way, the replay only enforces the order of the active
+// elements, while all other elements can run freely.
+// Elements that are not executed (e.g. the replay end marker) are kept.
+//
+// Parameter:
+//   - replayTrace *runtime.AdvocateReplayTrace: the sorted replay trace
+//   - active map[string][]int: the active elements with their counter
+func filterActiveTraceElem(replayTrace *runtime.AdvocateReplayTrace, active map[string][]int) {
+	counter := make(map[string]int)
+	res := make(runtime.AdvocateReplayTrace, 0, len(*replayTrace))
+
+	for _, elem := range *replayTrace {
+		if elem.NotExec() {
+			res = append(res, elem)
+			continue
+		}
+
+		pos := elem.Key()
+		counter[pos] += 1
+
+		if runtime.IsInSlice(active[pos], counter[pos]) {
+			res = append(res, elem)
+		}
+	}
+
+	*replayTrace = res
+}
+
+// writeDivergence writes the divergence of the replay from the trace into the
+// trace folder. Each line has the form key!value(s).
+//
+// Parameter:
+//   - div runtime.ReplayDivergence: the divergence
+func writeDivergence(div runtime.ReplayDivergence) {
+	file, err := os.Create(filepath.Join(tracePathRewritten, divergenceFile))
+	if err != nil {
+		println("Could not write divergence: ", err.Error())
+		return
+	}
+	defer file.Close()
+
+	file.WriteString(fmt.Sprintf("Kind!%s\n", div.Kind))
+	file.WriteString(fmt.Sprintf("Routine!%d\n", div.Routine))
+	file.WriteString(fmt.Sprintf("Expected!%s\n", divergenceElem(div.Expected)))
+	file.WriteString(fmt.Sprintf("Actual!%s\n", divergenceElem(div.Actual)))
+
+	for _, w := range div.WaitingOps {
+		file.WriteString(fmt.Sprintf("Waiting!%s!%d\n", w.Key, w.WaitMs))
+	}
+
+	sort.Slice(div.Routines, func(i, j int) bool {
+		return div.Routines[i].Routine < div.Routines[j].Routine
+	})
+	for _, r := range div.Routines {
+		file.WriteString(fmt.Sprintf("RoutineState!%d!%d!%d!%s!%s\n",
+			r.Routine, r.Executed, r.Total, divergenceElem(r.Next), r.State))
+	}
+}
+
+// divergenceElem returns the representation of a replay element in the divergence file
+//
+// Parameter:
+//   - elem runtime.ReplayElement: the element
+//
+// Returns:
+//   - string: key!operation, or ! if the element does not exist
+func divergenceElem(elem runtime.ReplayElement) string {
+	if elem.Op == runtime.OperationNone {
+		return "!"
+	}
+	return elem.Key() + "!" + string(elem.Op)
+}
@@ -65,7 +65,11 @@ func InitTracing(timeout int, init bool) {
 		}
 	}
 
-	if timeout > 0 {
+	if timeout > 0 && runtime.IsFlightRecorderEnabled() {
+		// a sleeping routine would prevent the detection of global deadlocks,
+		// the timeout is therefore checked by the flight recorder
+		flightRecorderTimeout = time.Duration(timeout) * time.Second
+	} else if timeout > 0 {
 		// start time timeout
 		go func() {
 			time.Sleep(time.Duration(timeout) * time.Second)
@@ -86,6 +90,12 @@ func InitTracing(timeout int, init bool) {
 //
 //go:linkname FinishTracing runtime.AdvocateFinishTracing
 func FinishTracing() {
+	if runtime.IsFlightRecorderEnabled() {
+		runtime.AdvocatRoutineExit()
+		finishFlightRecorder("")
+		return
+	}
+
 	if hasFinished {
 		// needed to prevent program stop while still writing
 		// otherwise, trace may be empty
@@ -120,6 +130,9 @@ func writeToTraceFiles() {
 	startWriting = true
 	numRout := runtime.GetNumberOfRoutines()
 	writeToTraceFileInfo(numRout)
+	writeStackTable()
+	writeNondetTable()
+	writeIdentityTable()
 
 	currentlyWriting := make([]int, 0)
 
@@ -217,11 +230,89 @@ func writeToTraceFileInfo(numberRoutines int) {
 	file.WriteString(fmt.Sprintf("ActiveReached!%d\n", reachedActive))
 	file.WriteString(fmt.Sprintf("AllActiveReleased!%d\n", allActiveReleased))
 	if timerStarted {
-		file.WriteString(fmt.Sprintf("Runtime!%d", int(duration.Seconds())))
+		file.WriteString(fmt.Sprintf("Runtime!%d\n", int(duration.Seconds())))
 	} else {
-		file.WriteString("Runtime:0")
+		file.WriteString("Runtime:0\n")
+	}
+	if filter := runtime.GetFilter(); filter != "" {
+		file.WriteString(fmt.Sprintf("Filter!%s\n", filter))
+	}
+	if runtime.IsIgnoreRegionUsed() {
+		file.WriteString("Ignore!1\n")
+	}
+
+}
+
+// Write the side table with the recorded call stacks into the
+// trace_stacks.log file. Nothing is written if stacks are not recorded.
+func writeStackTable() {
+	if !runtime.IsStackRecordingEnabled() {
+		return
+	}
+
+	fileName := filepath.Join(tracePathRecorded, stacksFile)
+
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		println("Cannot write stacks: ", err.Error())
+		return
+	}
+	defer file.Close()
+
+	for res := range runtime.StackTableToChan() {
+		if _, err := file.WriteString(res); err != nil {
+			println("Cannot write stacks: ", err.Error())
+			return
+		}
+	}
+}
+
+// Write the recorded results of nondeterministic calls, e.g. time.Now, into
+// the trace_nondet.log file. Nothing is written if no such call was recorded.
+func writeNondetTable() {
+	if !runtime.IsNondetRecorded() {
+		return
 	}
 
+	fileName := filepath.Join(tracePathRecorded, nondetFile)
+
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		println("Cannot write nondeterministic calls: ", err.Error())
+		return
+	}
+	defer file.Close()
+
+	for res := range runtime.NondetTableToChan() {
+		if _, err := file.WriteString(res); err != nil {
+			println("Cannot write nondeterministic calls: ", err.Error())
+			return
+		}
+	}
+}
+
+// Write the stable identities of the recorded objects into the
+// object_identities.log file. Nothing is written if no identity was recorded.
+func writeIdentityTable() {
+	if !runtime.IsIdentityRecorded() {
+		return
+	}
+
+	fileName := filepath.Join(tracePathRecorded, identitiesFile)
+
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		println("Cannot write object identities: ", err.Error())
+		return
+	}
+	defer file.Close()
+
+	for res := range runtime.IdentityTableToChan() {
+		if _, err := file.WriteString(res); err != nil {
+			println("Cannot write object identities: ", err.Error())
+			return
+		}
+	}
 }
 
 // Delete empty files in the trace folder.
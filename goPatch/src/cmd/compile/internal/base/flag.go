@@ -135,6 +135,12 @@ type CmdFlags struct {
 	AdvocatePath    string "help:\"set the advocate replay path\""
 	AdvocateTimeout int    "help:\"set the advocate tinmeout in s\""
 	AdvocateAtomics bool   "help:\"set if advocate should use atomics\""
+
+	AdvocateFlightRecorder int    "help:\"only keep the last n trace elements of each routine when recording\""
+	AdvocateFilter         string "help:\"comma separated package `patterns` to record, patterns starting with - are excluded\""
+	AdvocateSyncConfig     string "help:\"`file` mapping functions of user defined synchronization primitives to roles\""
+	AdvocateStacks         int    "help:\"record call stacks with at most n frames for channel, mutex, wait group and cond operations when recording\""
+	AdvocatePartialOrder   bool   "help:\"only enforce the order of operations on the same object when replaying\""
 	// ADVOCATE-END
 
 	// Configuration derived from flags; not a flag itself.
@@ -125,6 +125,42 @@ func MakeTask() {
 					)
 
 					fn.Body = append([]ir.Node{call}, fn.Body...)
+
+					if base.Flag.AdvocateFlightRecorder > 0 {
+						callFR := ir.NewCallExpr(
+							base.AutogeneratedPos,
+							ir.OCALL,
+							typecheck.LookupRuntime("AdvocateInitFlightRecorder"),
+							nil,
+						)
+
+						callFR.Args.Append(
+							typecheck.DefaultLit(
+								ir.NewInt(base.AutogeneratedPos, int64(base.Flag.AdvocateFlightRecorder)),
+								types.Types[types.TINT],
+							),
+						)
+
+						fn.Body = append([]ir.Node{callFR}, fn.Body...)
+					}
+
+					if base.Flag.AdvocateStacks > 0 {
+						callStacks := ir.NewCallExpr(
+							base.AutogeneratedPos,
+							ir.OCALL,
+							typecheck.LookupRuntime("AdvocateInitStacks"),
+							nil,
+						)
+
+						callStacks.Args.Append(
+							typecheck.DefaultLit(
+								ir.NewInt(base.AutogeneratedPos, int64(base.Flag.AdvocateStacks)),
+								types.Types[types.TINT],
+							),
+						)
+
+						fn.Body = append([]ir.Node{callStacks}, fn.Body...)
+					}
 				} else if base.Flag.AdvocateReplay {
 					call := ir.NewCallExpr(
 						base.AutogeneratedPos,
@@ -150,6 +186,18 @@ func MakeTask() {
 					)
 
 					fn.Body = append([]ir.Node{call}, fn.Body...)
+
+					// the mode must be set before the replay is started
+					if base.Flag.AdvocatePartialOrder {
+						callPO := ir.NewCallExpr(
+							base.AutogeneratedPos,
+							ir.OCALL,
+							typecheck.LookupRuntime("AdvocateInitReplayPartialOrder"),
+							nil,
+						)
+
+						fn.Body = append([]ir.Node{callPO}, fn.Body...)
+					}
 				} else if base.Flag.AdvocateFuzzing {
 					call := ir.NewCallExpr(
 						base.AutogeneratedPos,
@@ -173,6 +221,22 @@ func MakeTask() {
 
 					fn.Body = append([]ir.Node{call}, fn.Body...)
 				}
+
+				// the filter must be set before the recording, replay or fuzzing starts
+				if base.Flag.AdvocateFilter != "" {
+					callFilter := ir.NewCallExpr(
+						base.AutogeneratedPos,
+						ir.OCALL,
+						typecheck.LookupRuntime("AdvocateInitFilter"),
+						nil,
+					)
+
+					callFilter.Args.Append(
+						ir.NewString(base.AutogeneratedPos, base.Flag.AdvocateFilter),
+					)
+
+					fn.Body = append([]ir.Node{callFilter}, fn.Body...)
+				}
 			}
 			// ADVOCATE-END
 
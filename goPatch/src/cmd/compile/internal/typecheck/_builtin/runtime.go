@@ -305,9 +305,15 @@ func AdvocateAllocMutex(unsafe.Pointer)
 func AdvocateAllocCondVar(unsafe.Pointer)
 func AdvocateAllocWG(unsafe.Pointer)
 func advocateControllFlow(string, int, int)
+func AdvocateUserSyncPre(unsafe.Pointer, int)
+func AdvocateUserSyncPost()
 
 func AdvocateInitTracing(int, bool)
 func AdvocateFinishTracing()
+func AdvocateInitFlightRecorder(int)
+func AdvocateInitFilter(string)
+func AdvocateInitStacks(int)
+func AdvocateInitReplayPartialOrder()
 func AdvocateInitReplay(string, int, bool, bool)
 func AdvocateFinishReplay()
 func AdvocateInitFuzzing(string, int, bool)
@@ -48,7 +48,44 @@ func instrumentBody(fn *ir.Func) {
 func addInit(body ir.Nodes, pos src.XPos) ir.Nodes {
 	out := make(ir.Nodes, 0)
 
+	// the filter must be set before the recording, replay or fuzzing starts
+	if base.Flag.AdvocateFilter != "" {
+		fnFilter := typecheck.LookupRuntime("AdvocateInitFilter")
+		out.Append(typecheck.Call(
+			pos,
+			fnFilter,
+			[]ir.Node{
+				ir.NewString(pos, base.Flag.AdvocateFilter),
+			},
+			false,
+		))
+	}
+
 	if base.Flag.AdvocateTrace {
+		if base.Flag.AdvocateFlightRecorder > 0 {
+			fnFR := typecheck.LookupRuntime("AdvocateInitFlightRecorder")
+			out.Append(typecheck.Call(
+				pos,
+				fnFR,
+				[]ir.Node{
+					ir.NewInt(pos, int64(base.Flag.AdvocateFlightRecorder)),
+				},
+				false,
+			))
+		}
+
+		if base.Flag.AdvocateStacks > 0 {
+			fnStacks := typecheck.LookupRuntime("AdvocateInitStacks")
+			out.Append(typecheck.Call(
+				pos,
+				fnStacks,
+				[]ir.Node{
+					ir.NewInt(pos, int64(base.Flag.AdvocateStacks)),
+				},
+				false,
+			))
+		}
+
 		fn := typecheck.LookupRuntime("AdvocateInitTracing")
 		out.Append(typecheck.Call(
 			pos,
@@ -60,6 +97,11 @@ func addInit(body ir.Nodes, pos src.XPos) ir.Nodes {
 			false,
 		))
 	} else if base.Flag.AdvocateReplay {
+		if base.Flag.AdvocatePartialOrder {
+			fnPO := typecheck.LookupRuntime("AdvocateInitReplayPartialOrder")
+			out.Append(typecheck.Call(pos, fnPO, nil, false))
+		}
+
 		fn := typecheck.LookupRuntime("AdvocateInitReplay")
 		out.Append(typecheck.Call(
 			pos,
@@ -99,7 +141,12 @@ func instrumentStmtList(body ir.Nodes) ir.Nodes {
 	for _, stmt := range body {
 		instrumentStmtRecursive(stmt)
 
-		out.Append(stmt)
+		pre, post := addUserSync(stmt)
+		if pre != nil {
+			out.Append(pre, stmt, post)
+		} else {
+			out.Append(stmt)
+		}
 
 		if n := addAlloc(stmt); n != nil {
 			out.Append(n)
@@ -506,7 +553,9 @@ func isAdvocateCall(n ir.Node) bool {
 		(fmt.Sprint(name.Sym()) == "AdvocateAllocMutex" ||
 			fmt.Sprint(name.Sym()) == "AdvocateAllocCondVar" ||
 			fmt.Sprint(name.Sym()) == "AdvocateAllocWG" ||
-			fmt.Sprint(name.Sym()) == "advocateTraceControllFlow")
+			fmt.Sprint(name.Sym()) == "advocateTraceControllFlow" ||
+			fmt.Sprint(name.Sym()) == "AdvocateUserSyncPre" ||
+			fmt.Sprint(name.Sym()) == "AdvocateUserSyncPost")
 }
 
 // ==================================================
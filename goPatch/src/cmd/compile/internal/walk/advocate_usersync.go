@@ -0,0 +1,215 @@
+// ADVOCATE-FILE-START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_usersync.go
+// Brief: Insert recording for calls of user defined synchronization
+//    primitives that are mapped to roles in a sync config file
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package walk
+
+import (
+	"bufio"
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Roles of user defined synchronization functions. The values must match
+// the roles in runtime/advocate_trace_usersync.go
+var userSyncRoles = map[string]int{
+	"lock":   0,
+	"unlock": 1,
+	"send":   2,
+	"recv":   3,
+	"signal": 4,
+	"wait":   5,
+}
+
+// function name -> role
+var userSyncConfig map[string]int
+var userSyncConfigOnce sync.Once
+
+// ==================================================
+// MARK: Config
+// ==================================================
+
+// loadUserSyncConfig reads the sync config file set with -advocatesyncconfig.
+// Each line contains a function and its role, e.g.
+//
+//	mypkg.(*Sem).Acquire lock
+//
+// Empty lines and lines starting with # are ignored.
+func loadUserSyncConfig() {
+	userSyncConfig = make(map[string]int)
+
+	if base.Flag.AdvocateSyncConfig == "" {
+		return
+	}
+
+	file, err := os.Open(base.Flag.AdvocateSyncConfig)
+	if err != nil {
+		base.Fatalf("cannot open advocate sync config: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			base.Fatalf("invalid line %d in advocate sync config: %s", lineNumber, line)
+		}
+
+		role, ok := userSyncRoles[strings.ToLower(fields[1])]
+		if !ok {
+			base.Fatalf("invalid role %s in line %d of advocate sync config", fields[1], lineNumber)
+		}
+
+		userSyncConfig[fields[0]] = role
+	}
+}
+
+// userSyncRole returns the role of a function in the sync config.
+// A function matches an entry if its full name is equal to the entry or
+// ends with / followed by the entry, e.g. example.com/mypkg.(*Sem).Acquire
+// matches mypkg.(*Sem).Acquire.
+//
+// Parameter:
+//   - name string: the full name of the function, [pkg path].[name]
+//
+// Returns:
+//   - int: the role
+//   - bool: true if the function is in the config
+func userSyncRole(name string) (int, bool) {
+	userSyncConfigOnce.Do(loadUserSyncConfig)
+
+	if len(userSyncConfig) == 0 {
+		return 0, false
+	}
+
+	if role, ok := userSyncConfig[name]; ok {
+		return role, true
+	}
+
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' {
+			if role, ok := userSyncConfig[name[i+1:]]; ok {
+				return role, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// ==================================================
+// MARK: Instrument
+// ==================================================
+
+// addUserSync returns the calls that must be inserted before and after a
+// statement that calls a user defined synchronization function.
+// Only calls that are statements or the right side of an assignment are
+// instrumented. The first argument, e.g. the receiver, must be a pointer
+// that can be evaluated twice without side effects.
+//
+// Parameter:
+//   - n ir.Node: the statement
+//
+// Returns:
+//   - ir.Node: call to insert before the statement, nil if not instrumented
+//   - ir.Node: call to insert after the statement, nil if not instrumented
+func addUserSync(n ir.Node) (ir.Node, ir.Node) {
+	if base.Flag.AdvocateSyncConfig == "" {
+		return nil, nil
+	}
+
+	var call *ir.CallExpr
+	switch x := n.(type) {
+	case *ir.CallExpr:
+		call = x
+	case *ir.AssignStmt:
+		call, _ = x.Y.(*ir.CallExpr)
+	case *ir.AssignListStmt:
+		if len(x.Rhs) == 1 {
+			call, _ = x.Rhs[0].(*ir.CallExpr)
+		}
+	}
+
+	if call == nil || call.Op() != ir.OCALLFUNC || len(call.Args) == 0 {
+		return nil, nil
+	}
+
+	fn := ir.StaticCalleeName(call.Fun)
+	if fn == nil || fn.Sym() == nil || fn.Sym().Pkg == nil {
+		return nil, nil
+	}
+
+	role, ok := userSyncRole(fn.Sym().Pkg.Path + "." + fn.Sym().Name)
+	if !ok {
+		return nil, nil
+	}
+
+	obj := call.Args[0]
+	if obj.Type() == nil || !obj.Type().IsPtr() || !isSideEffectFree(obj) {
+		return nil, nil
+	}
+
+	pos := call.Pos()
+
+	pre := typecheck.Call(
+		pos,
+		typecheck.LookupRuntime("AdvocateUserSyncPre"),
+		[]ir.Node{
+			makeUnsafePointer(ir.DeepCopy(pos, obj), pos),
+			ir.NewInt(pos, int64(role)),
+		},
+		false,
+	)
+
+	post := typecheck.Call(
+		pos,
+		typecheck.LookupRuntime("AdvocateUserSyncPost"),
+		nil,
+		false,
+	)
+
+	return pre, post
+}
+
+// isSideEffectFree checks if an expression only consists of variables,
+// field accesses and address operations
+//
+// Parameter:
+//   - n ir.Node: the expression
+//
+// Returns:
+//   - bool: true if the expression can be evaluated multiple times
+func isSideEffectFree(n ir.Node) bool {
+	switch x := n.(type) {
+	case *ir.Name:
+		return true
+	case *ir.AddrExpr:
+		return isSideEffectFree(x.X)
+	case *ir.SelectorExpr:
+		return (x.Op() == ir.ODOT || x.Op() == ir.ODOTPTR) && isSideEffectFree(x.X)
+	case *ir.ConvExpr:
+		return x.Op() == ir.OCONVNOP && isSideEffectFree(x.X)
+	}
+	return false
+}
+
+// ADVOCATE-FILE-END
@@ -12,6 +12,10 @@ import (
 	"crypto/internal/fips140/drbg"
 	"crypto/internal/sysrand"
 	"io"
+
+	// ADVOCATE-START
+	"runtime"
+	// ADVOCATE-END
 	_ "unsafe"
 )
 
@@ -79,5 +83,8 @@ func Read(b []byte) (n int, err error) {
 		fatal("crypto/rand: failed to read random data (see https://go.dev/issue/66821): " + err.Error())
 		panic("unreachable") // To be sure.
 	}
+	// ADVOCATE-START
+	runtime.AdvocateNondetCryptoRand(b)
+	// ADVOCATE-END
 	return len(b), nil
 }
@@ -22,6 +22,10 @@ import (
 	"sync"
 	"sync/atomic"
 	_ "unsafe" // for go:linkname
+
+	// ADVOCATE-START
+	"runtime"
+	// ADVOCATE-END
 )
 
 // A Source represents a source of uniformly-distributed
@@ -360,7 +364,9 @@ type runtimeSource struct {
 }
 
 func (*runtimeSource) Int63() int64 {
-	return int64(runtime_rand() & rngMask)
+	// ADVOCATE-START
+	return int64(runtime.AdvocateNondetRand(runtime.OperationNondetRand, runtime_rand()) & rngMask)
+	// ADVOCATE-END
 }
 
 func (*runtimeSource) Seed(int64) {
@@ -368,7 +374,9 @@ func (*runtimeSource) Seed(int64) {
 }
 
 func (*runtimeSource) Uint64() uint64 {
-	return runtime_rand()
+	// ADVOCATE-START
+	return runtime.AdvocateNondetRand(runtime.OperationNondetRand, runtime_rand())
+	// ADVOCATE-END
 }
 
 func (fs *runtimeSource) read(p []byte, readVal *int64, readPos *int8) (n int, err error) {
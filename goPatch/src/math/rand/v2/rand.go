@@ -20,6 +20,10 @@ package rand
 import (
 	"math/bits"
 	_ "unsafe" // for go:linkname
+
+	// ADVOCATE-START
+	"runtime"
+	// ADVOCATE-END
 )
 
 // A Source is a source of uniformly-distributed
@@ -262,7 +266,9 @@ func runtime_rand() uint64
 type runtimeSource struct{}
 
 func (runtimeSource) Uint64() uint64 {
-	return runtime_rand()
+	// ADVOCATE-START
+	return runtime.AdvocateNondetRand(runtime.OperationNondetRandV2, runtime_rand())
+	// ADVOCATE-END
 }
 
 // Int64 returns a non-negative pseudo-random 63-bit integer as an int64
@@ -9,6 +9,10 @@ package os
 import (
 	"internal/testlog"
 	"syscall"
+
+	// ADVOCATE-START
+	"runtime"
+	// ADVOCATE-END
 )
 
 // Expand replaces ${var} or $var in the string based on the mapping function.
@@ -101,6 +105,9 @@ func getShellName(s string) (string, int) {
 func Getenv(key string) string {
 	testlog.Getenv(key)
 	v, _ := syscall.Getenv(key)
+	// ADVOCATE-START
+	v = runtime.AdvocateNondetGetenv(v)
+	// ADVOCATE-END
 	return v
 }
 
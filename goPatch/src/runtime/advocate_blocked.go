@@ -102,11 +102,5 @@ func (self *AdvocateG) LastTraceElem() traceElem {
 		return nil
 	}
 
-	l := len(self.rout.advocateRoutineInfo.Trace)
-
-	if l == 0 {
-		return nil
-	}
-
-	return self.rout.advocateRoutineInfo.Trace[l-1]
+	return self.rout.advocateRoutineInfo.getLastElement()
 }
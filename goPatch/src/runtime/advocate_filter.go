@@ -0,0 +1,574 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_filter.go
+// Brief: Package filters for the recording and summaries of the filtered
+//    operations
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+import (
+	"internal/runtime/atomic"
+	"unsafe"
+)
+
+// package filter as given with the -advocatefilter build flag
+var advocateFilter = ""
+
+// package patterns of the operations that should be recorded, if empty all
+// not internal operations are recorded
+var advocateFilterInclude = make([]string, 0)
+
+// package patterns of the operations that should not be recorded
+var advocateFilterExclude = make([]string, 0)
+
+// number of entries in the cache of the filter decisions
+const advocateFilterCacheSize = 1024
+
+// advocateFilterCacheEntry is the cached filter decision for a file
+//
+// Fields:
+//   - file string: the file
+//   - ignored bool: true if the operations in the file are ignored
+type advocateFilterCacheEntry struct {
+	file    string
+	ignored bool
+}
+
+// cache of the filter decisions, indexed by the address of the file name.
+// The file names of the call sites are stored in the binary, so each call site
+// always uses the same entry. Entries are only replaced as a whole, so the
+// cache can be read and written without a lock. The patterns are only
+// written by AdvocateInitFilter before the recording starts.
+var advocateFilterCache [advocateFilterCacheSize]atomic.Pointer[advocateFilterCacheEntry]
+
+// AdvocateInitFilter sets the package filters for the recording. It is
+// called at the start of the program with the value of the -advocatefilter
+// build flag.
+// The filter is a comma separated list of package patterns. Patterns with
+// a leading - are excluded, all others are included. A pattern can contain
+// * for any string without / and ... for any string, e.g. google.golang.org/grpc/...
+//
+// Parameter:
+//   - filter string: the filter
+func AdvocateInitFilter(filter string) {
+	advocateFilter = filter
+
+	start := 0
+	for i := 0; i <= len(filter); i++ {
+		if i != len(filter) && filter[i] != ',' {
+			continue
+		}
+
+		pattern := trimSpaces(filter[start:i])
+		start = i + 1
+
+		if pattern == "" {
+			continue
+		}
+
+		if pattern[0] == '-' {
+			if len(pattern) > 1 {
+				advocateFilterExclude = append(advocateFilterExclude, pattern[1:])
+			}
+		} else if pattern[0] == '+' {
+			if len(pattern) > 1 {
+				advocateFilterInclude = append(advocateFilterInclude, pattern[1:])
+			}
+		} else {
+			advocateFilterInclude = append(advocateFilterInclude, pattern)
+		}
+	}
+}
+
+// GetFilter returns the package filter of the recording
+//
+// Returns:
+//   - string: the filter as given with the -advocatefilter build flag, empty if not set
+func GetFilter() string {
+	return advocateFilter
+}
+
+// isFilterActive returns if a package filter has been set
+//
+// Returns:
+//   - bool: true if at least one include or exclude pattern is set
+func isFilterActive() bool {
+	return len(advocateFilterInclude) != 0 || len(advocateFilterExclude) != 0
+}
+
+// advocateIgnoreInternal checks if an operation is an internal operation
+// of the go runtime, the standard library or a dependency
+//
+// Parameter:
+//   - file: file in which the operation is executed
+//
+// Returns:
+//   - bool: true if the operation is internal, false otherwise
+func advocateIgnoreInternal(file string) bool {
+	return (containsStr(file, "goPatch/src/") || containsStr(file, "go/pkg/mod")) &&
+		!containsStr(file, "goPatch/src/time/tick.go") &&
+		!containsStr(file, "goPatch/src/context/context.go")
+}
+
+// advocateIgnoreFiltered checks if an operation is ignored based on the
+// package filters. Operations in the go runtime and the standard library are
+// always ignored. Operations that match an exclude pattern are ignored.
+// If include patterns are set, all operations that do not match one of them
+// are ignored. Dependencies in the module cache are recorded, if they match
+// an include pattern. Without patterns, only the internal operations are
+// ignored. The decision is cached for each file without taking a lock.
+//
+// Parameter:
+//   - file: file in which the operation is executed
+//
+// Returns:
+//   - bool: true if the operation should be ignored, false otherwise
+func advocateIgnoreFiltered(file string) bool {
+	if !isFilterActive() || containsStr(file, "goPatch/src/") {
+		return advocateIgnoreInternal(file)
+	}
+
+	slot := &advocateFilterCache[(uintptr(unsafe.Pointer(unsafe.StringData(file)))>>3)%advocateFilterCacheSize]
+	if entry := slot.Load(); entry != nil && entry.file == file {
+		return entry.ignored
+	}
+
+	dir := filterPackageDir(file)
+
+	res := false
+	if filterMatchAny(advocateFilterExclude, dir) {
+		res = true
+	} else if len(advocateFilterInclude) != 0 {
+		res = !filterMatchAny(advocateFilterInclude, dir)
+	} else {
+		res = advocateIgnoreInternal(file)
+	}
+
+	slot.Store(&advocateFilterCacheEntry{file: file, ignored: res})
+	return res
+}
+
+// IsFilteredByUser checks if an operation is not recorded because of the
+// package filters or an ignored region, but is not an internal operation of
+// the runtime or the standard library. Those operations are recorded as summaries.
+//
+// Parameter:
+//   - file: file in which the operation is executed
+//
+// Returns:
+//   - bool: true if the operation is removed by the package filter or an ignored region
+func IsFilteredByUser(file string) bool {
+	if containsStr(file, "goPatch/src/") {
+		return false
+	}
+	if isInIgnoredRegion() {
+		return !advocateIgnoreInternal(file)
+	}
+	if !isFilterActive() {
+		return false
+	}
+	return advocateIgnoreFiltered(file)
+}
+
+// filterPackageDir returns the directory of a file with the module versions
+// removed, e.g. /home/u/go/pkg/mod/google.golang.org/grpc@v1.2.3/internal/a.go
+// is turned into /home/u/go/pkg/mod/google.golang.org/grpc/internal
+//
+// Parameter:
+//   - file string: the file
+//
+// Returns:
+//   - string: the package directory
+func filterPackageDir(file string) string {
+	end := len(file)
+	for end > 0 && file[end-1] != '/' {
+		end--
+	}
+	if end > 0 {
+		end--
+	}
+
+	res := make([]byte, 0, end)
+	inVersion := false
+	for i := 0; i < end; i++ {
+		switch file[i] {
+		case '@':
+			inVersion = true
+		case '/':
+			inVersion = false
+			res = append(res, '/')
+		default:
+			if !inVersion {
+				res = append(res, file[i])
+			}
+		}
+	}
+
+	return string(res)
+}
+
+// filterMatchAny checks if a package directory matches one of the patterns.
+// A pattern matches, if it matches the directory or a suffix of the
+// directory starting after a /. A pattern ending in /... also matches
+// the directory without the /...
+//
+// Parameter:
+//   - patterns []string: the patterns
+//   - dir string: the package directory
+//
+// Returns:
+//   - bool: true if at least one pattern matches
+func filterMatchAny(patterns []string, dir string) bool {
+	for _, pattern := range patterns {
+		for i := 0; i <= len(dir); i++ {
+			if i != 0 && dir[i-1] != '/' {
+				continue
+			}
+
+			suffix := dir[i:]
+			if filterMatch(pattern, suffix) {
+				return true
+			}
+
+			if hasSuffix(pattern, "/...") && filterMatch(pattern[:len(pattern)-4], suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterMatch checks if a string matches a pattern, where * matches any
+// string without / and ... matches any string
+//
+// Parameter:
+//   - pattern string: the pattern
+//   - s string: the string
+//
+// Returns:
+//   - bool: true if s matches pattern
+func filterMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		if hasPrefix(pattern, "...") {
+			for i := 0; i <= len(s); i++ {
+				if filterMatch(pattern[3:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if pattern[0] == '*' {
+			for i := 0; i <= len(s); i++ {
+				if filterMatch(pattern[1:], s[i:]) {
+					return true
+				}
+				if i < len(s) && s[i] == '/' {
+					break
+				}
+			}
+			return false
+		}
+
+		if len(s) == 0 || s[0] != pattern[0] {
+			return false
+		}
+		pattern = pattern[1:]
+		s = s[1:]
+	}
+
+	return len(s) == 0
+}
+
+// trimSpaces removes leading and trailing spaces
+//
+// Parameter:
+//   - s string: the string
+//
+// Returns:
+//   - string: s without leading and trailing spaces
+func trimSpaces(s string) string {
+	for len(s) > 0 && s[0] == ' ' {
+		s = s[1:]
+	}
+	for len(s) > 0 && s[len(s)-1] == ' ' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// ========================================================
+// MARK: Summary
+// ========================================================
+
+type summaryKind uint8
+
+const (
+	summaryRelease summaryKind = iota
+	summaryAcquire
+	summaryAcquireRelease
+)
+
+// AdvocateTraceSummary summarizes operations that are removed by the package
+// filter or an ignored region. It only stores on which objects the operations synchronized, so
+// that happens before relations through filtered code are kept.
+// Releases are recorded at the start of an operation, acquires at its end.
+//
+// Fields:
+//   - t int64: time of the release or acquire, 0 if the acquire did not finish
+//   - kind summaryKind: release, acquire or both
+//   - ids []uint64: ids of the objects
+//   - tPre int64: time of the start of the operation, only used for selects
+//   - nsends int: number of send cases, only used for selects
+type AdvocateTraceSummary struct {
+	t      int64
+	kind   summaryKind
+	ids    []uint64
+	tPre   int64
+	nsends int
+}
+
+// summaryKindOf returns how an operation synchronizes if it is summarized.
+// Operations that allow other operations to continue are releases,
+// operations that wait for other operations are acquires.
+//
+// Parameter:
+//   - op Operation: the operation
+//
+// Returns:
+//   - summaryKind: the kind of the summary
+func summaryKindOf(op Operation) summaryKind {
+	switch op {
+	case OperationChannelSend, OperationChannelClose,
+		OperationMutexUnlock, OperationRWMutexUnlock, OperationRWMutexRUnlock,
+		OperationWaitgroupAddDone, OperationCondSignal, OperationCondBroadcast,
+		OperationAtomicStore, OperationAtomicAdd, OperationAtomicAnd, OperationAtomicOr:
+		return summaryRelease
+	case OperationOnceDo, OperationAtomicSwap, OperationAtomicCompareAndSwap:
+		return summaryAcquireRelease
+	}
+	return summaryAcquire
+}
+
+// advocateSummarize records the summary of an operation that is removed
+// by the package filter. A release is recorded with the time of the start
+// of the operation. An acquire is completed with completeSummary, when the
+// operation finishes.
+//
+// Parameter:
+//   - file string: file of the operation
+//   - kind summaryKind: release, acquire or both
+//   - t int64: time of the start of the operation
+//   - ids ...uint64: ids of the objects
+//
+// Returns:
+//   - int: index of the summary, -1 if no summary is recorded
+func advocateSummarize(file string, kind summaryKind, t int64, ids ...uint64) int {
+	if len(ids) == 0 || !IsFilteredByUser(file) {
+		return -1
+	}
+
+	elem := AdvocateTraceSummary{
+		kind: kind,
+		ids:  ids,
+	}
+
+	if kind == summaryRelease {
+		elem.t = t
+	}
+
+	return insertIntoTrace(elem)
+}
+
+// completeSummary sets the time of an acquire summary when the operation
+// finishes. If the element at index is not a summary or already has
+// a time, nothing is done.
+//
+// Parameter:
+//   - index int: index of the element
+//   - t int64: time of the end of the operation
+func (gi *AdvocateRoutine) completeSummary(index int, t int64) {
+	elem, ok := gi.getElement(index).(AdvocateTraceSummary)
+	if !ok || elem.t != 0 {
+		return
+	}
+
+	elem.t = t
+	gi.updateElement(index, elem)
+}
+
+// advocateSummarizeSelect records the summary of a select that is removed by
+// the package filter. The channel and the kind of the summary are only known
+// when a case has been selected and are set by completeSelectSummary.
+//
+// Parameter:
+//   - file string: file of the select
+//   - t int64: time of the start of the select
+//   - nsends int: number of send cases, the send cases are the first cases
+//
+// Returns:
+//   - int: index of the summary, -1 if no summary is recorded
+func advocateSummarizeSelect(file string, t int64, nsends int) int {
+	if !IsFilteredByUser(file) {
+		return -1
+	}
+
+	return insertIntoTrace(AdvocateTraceSummary{kind: summaryAcquire, tPre: t, nsends: nsends})
+}
+
+// completeSelectSummary sets the selected channel of a summarized select.
+// A send is a release at the start of the select, a receive is an acquire
+// at the end of the select. If the default case was selected, the summary
+// stays incomplete and is removed when the trace is written. The operation
+// counters of the channel are still increased, so that the oId of the
+// recorded operations match.
+//
+// Parameter:
+//   - index int: index of the summary
+//   - c *hchan: channel of the selected case
+//   - selIndex int: index of the selected case, -1 for the default case
+//   - t int64: time of the end of the select
+func (gi *AdvocateRoutine) completeSelectSummary(index int, c *hchan, selIndex int, t int64) {
+	elem, ok := gi.getElement(index).(AdvocateTraceSummary)
+	if !ok || c == nil || selIndex == -1 {
+		return
+	}
+
+	elem.ids = []uint64{c.id}
+	if selIndex < elem.nsends {
+		elem.kind = summaryRelease
+		elem.t = elem.tPre
+		c.numberSend++
+	} else {
+		elem.t = t
+		c.numberRecv++
+	}
+
+	gi.updateElement(index, elem)
+}
+
+// Get a string representation of the summary
+//
+// Returns:
+//   - string: the string representation of the form
+//     H,[t],[kind],[ids]
+//     kind is R (release), A (acquire) or B (both), ids are separated by .
+func (self AdvocateTraceSummary) toString() string {
+	kind := "R"
+	switch self.kind {
+	case summaryAcquire:
+		kind = "A"
+	case summaryAcquireRelease:
+		kind = "B"
+	}
+
+	ids := ""
+	for i, id := range self.ids {
+		if i != 0 {
+			ids += "."
+		}
+		ids += uint64ToString(id)
+	}
+
+	return buildTraceElemString("H", self.t, kind, ids)
+}
+
+// getOperation is a getter for the operation
+//
+// Returns:
+//   - Operation: the operation
+func (self AdvocateTraceSummary) getOperation() Operation {
+	return OperationSummary
+}
+
+// hasCommit returns if the summary has been completed
+//
+// Returns:
+//   - bool: true if the summary has been completed
+func (self AdvocateTraceSummary) hasCommit() bool {
+	return self.t != 0
+}
+
+// resource returns the resources for the operation
+//
+// Returns:
+//   - []AdvocateTraceResource: the resources of the operation, summaries have none
+func (self AdvocateTraceSummary) resource() []AdvocateTraceResource {
+	return []AdvocateTraceResource{}
+}
+
+// foldSummaries merges consecutive summaries of the same kind, to reduce the
+// size of the written trace. Consecutive releases are merged into the first,
+// consecutive acquires into the last release. Summaries that have not been
+// completed are removed.
+//
+// Parameter:
+//   - elems []traceElem: the elements of a routine
+//
+// Returns:
+//   - []traceElem: the elements with folded summaries
+func foldSummaries(elems []traceElem) []traceElem {
+	if !isFilterActive() && !advocateIgnoreRegionUsed {
+		return elems
+	}
+
+	res := make([]traceElem, 0, len(elems))
+	for _, elem := range elems {
+		sum, ok := elem.(AdvocateTraceSummary)
+		if !ok {
+			res = append(res, elem)
+			continue
+		}
+
+		if sum.t == 0 {
+			continue
+		}
+
+		if len(res) != 0 {
+			if last, ok := res[len(res)-1].(AdvocateTraceSummary); ok && last.kind == sum.kind && sum.kind != summaryAcquireRelease {
+				ids := make([]uint64, 0, len(last.ids)+len(sum.ids))
+				ids = append(ids, last.ids...)
+				for _, id := range sum.ids {
+					if !containsUint64(ids, id) {
+						ids = append(ids, id)
+					}
+				}
+				last.ids = ids
+				if sum.kind == summaryAcquire {
+					last.t = sum.t
+				}
+				res[len(res)-1] = last
+				continue
+			}
+		}
+
+		res = append(res, sum)
+	}
+
+	return res
+}
+
+// containsUint64 checks if a slice contains a value
+//
+// Parameter:
+//   - s []uint64: the slice
+//   - v uint64: the value
+//
+// Returns:
+//   - bool: true if v is in s
+func containsUint64(s []uint64, v uint64) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ADVOCATE-FILE-END
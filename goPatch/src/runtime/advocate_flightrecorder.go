@@ -0,0 +1,158 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_flightrecorder.go
+// Brief: Flight recorder mode, where each routine only keeps the last
+//    elements of its trace
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// number of elements kept per routine in flight recorder mode, 0 if disabled
+var advocateFlightRecorderSize = 0
+
+// maximum number of terminated routines kept in flight recorder mode
+const flightRecorderMaxExited = 1000
+
+// ids of the terminated routines in the order in which they terminated
+var flightRecorderExited = make([]uint64, 0)
+
+// InitFlightRecorder enables the flight recorder mode. In this mode, the trace
+// of each routine is a ring buffer that only contains the last size elements.
+// Must be called before the tracing is enabled.
+//
+// Parameter:
+//   - size int: number of elements kept per routine
+func InitFlightRecorder(size int) {
+	if size < 0 {
+		size = 0
+	}
+	advocateFlightRecorderSize = size
+}
+
+// IsFlightRecorderEnabled returns whether the flight recorder mode is enabled
+//
+// Returns:
+//   - bool: true if enabled, false otherwise
+func IsFlightRecorderEnabled() bool {
+	return advocateFlightRecorderSize > 0
+}
+
+// GetFlightRecorderSize returns the number of elements kept per routine
+//
+// Returns:
+//   - int: the size of the ring buffers, 0 if the flight recorder is disabled
+func GetFlightRecorderSize() int {
+	return advocateFlightRecorderSize
+}
+
+// EnableTracing enables the trace recording after it has been disabled with
+// DisableTracing
+func EnableTracing() {
+	AdvocateTracingDisabled = false
+}
+
+// flightRecorderRoutineExit is called instead of writing the trace of a
+// terminated routine to file in flight recorder mode. The trace is kept
+// until the trace is dumped. If too many terminated routines are stored,
+// the oldest one is removed.
+//
+// Parameter:
+//   - routine *AdvocateRoutine: the terminated routine
+func flightRecorderRoutineExit(routine *AdvocateRoutine) {
+	lock(&AdvocateRoutinesLock)
+	defer unlock(&AdvocateRoutinesLock)
+
+	flightRecorderExited = append(flightRecorderExited, routine.id)
+
+	if len(flightRecorderExited) > flightRecorderMaxExited {
+		delete(AdvocateRoutines, flightRecorderExited[0])
+		flightRecorderExited = flightRecorderExited[1:]
+	}
+}
+
+// GetAdvocateRoutineIDs returns the ids of all routines with a stored trace
+//
+// Returns:
+//   - []uint64: the ids, not sorted
+func GetAdvocateRoutineIDs() []uint64 {
+	lock(&AdvocateRoutinesLock)
+	defer unlock(&AdvocateRoutinesLock)
+
+	res := make([]uint64, 0, len(AdvocateRoutines))
+	for id := range AdvocateRoutines {
+		res = append(res, id)
+	}
+	return res
+}
+
+// AdvocateGlobalDeadlock checks if the program is in a global deadlock, meaning
+// all recorded routines except the current one are blocked on a concurrency
+// operation and no timer is pending that could wake them up. Routines that
+// have been started before the tracing was enabled, e.g. the routine calling
+// this function in the flight recorder, are ignored.
+//
+// Returns:
+//   - bool: true if the program is in a global deadlock, false otherwise
+func AdvocateGlobalDeadlock() bool {
+	current := getg()
+	blocked := 0
+	running := false
+
+	forEachG(func(gp *g) {
+		if running || gp == current || gp.advocateRoutineInfo == nil ||
+			gp.advocateRoutineInfo.id == 0 || isSystemGoroutine(gp, false) {
+			return
+		}
+
+		status := readgstatus(gp) &^ _Gscan
+		if status == _Gdead {
+			return
+		}
+
+		if status != _Gwaiting || !isBlockedConcurrencyReason(gp.waitreason) {
+			running = true
+			return
+		}
+
+		blocked++
+	})
+
+	if running || blocked == 0 {
+		return false
+	}
+
+	// a pending timer can still wake up a routine, e.g. with time.After
+	lock(&allpLock)
+	defer unlock(&allpLock)
+	for _, pp := range allp {
+		if pp != nil && pp.timers.len.Load() > uint32(pp.timers.zombies.Load()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isBlockedConcurrencyReason checks if a wait reason is a block on a
+// concurrency operation
+//
+// Parameter:
+//   - reason WaitReason: the wait reason
+//
+// Returns:
+//   - bool: true if the reason is a concurrency operation
+func isBlockedConcurrencyReason(reason WaitReason) bool {
+	for _, r := range blockedConcurrencyReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// ADVOCATE-FILE-END
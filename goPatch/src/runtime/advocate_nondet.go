@@ -0,0 +1,315 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_nondet.go
+// Brief: Recording and replay of the results of nondeterministic calls
+//    that are not concurrency operations, e.g. time.Now or math/rand
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// NondetCall is the recorded result of a call of a nondeterministic function
+//
+// Fields:
+//   - Op Operation: the called function
+//   - File string: file of the call
+//   - Line int: line of the call
+//   - Ints []int64: integer results, e.g. sec, nsec and mono for time.Now
+//   - Data string: byte or string result, e.g. the bytes of crypto/rand.Read
+type NondetCall struct {
+	Op   Operation
+	File string
+	Line int
+	Ints []int64
+	Data string
+}
+
+// routine id -> recorded calls of the routine in the order of execution
+var nondetRecorded = make(map[uint64][]NondetCall)
+var nondetRecordedLock mutex
+
+// replay id of the routine -> calls to replay in the order of execution
+var nondetReplay = make(map[int][]NondetCall)
+var nondetReplayIndex = make(map[int]int)
+var nondetReplayLock mutex
+var nondetReplayUsed = false
+
+// AddNondetReplay adds a recorded call that should be returned during replay
+//
+// Parameter:
+//   - routine int: id of the routine that executed the call
+//   - call NondetCall: the recorded call
+func AddNondetReplay(routine int, call NondetCall) {
+	lock(&nondetReplayLock)
+	nondetReplay[routine] = append(nondetReplay[routine], call)
+	nondetReplayUsed = true
+	unlock(&nondetReplayLock)
+}
+
+// AdvocateNondetTime records or replays the result of time.Now
+//
+// Parameter:
+//   - sec int64: the wall clock seconds
+//   - nsec int32: the wall clock nanoseconds
+//   - mono int64: the monotonic time relative to the start of the program
+//
+// Returns:
+//   - int64: the seconds to use
+//   - int32: the nanoseconds to use
+//   - int64: the monotonic time to use
+func AdvocateNondetTime(sec int64, nsec int32, mono int64) (int64, int32, int64) {
+	if !nondetActive() {
+		return sec, nsec, mono
+	}
+
+	call := NondetCall{Op: OperationNondetTime, Ints: []int64{sec, int64(nsec), mono}}
+	call = advocateNondet(call, "time.")
+	if len(call.Ints) != 3 {
+		return sec, nsec, mono
+	}
+	return call.Ints[0], int32(call.Ints[1]), call.Ints[2]
+}
+
+// AdvocateNondetRand records or replays a value of the global source of
+// math/rand or math/rand/v2
+//
+// Parameter:
+//   - op Operation: OperationNondetRand or OperationNondetRandV2
+//   - v uint64: the value returned by the source
+//
+// Returns:
+//   - uint64: the value to use
+func AdvocateNondetRand(op Operation, v uint64) uint64 {
+	if !nondetActive() {
+		return v
+	}
+
+	pkg := "math/rand."
+	if op == OperationNondetRandV2 {
+		pkg = "math/rand/v2."
+	}
+
+	call := advocateNondet(NondetCall{Op: op, Ints: []int64{int64(v)}}, pkg)
+	if len(call.Ints) != 1 {
+		return v
+	}
+	return uint64(call.Ints[0])
+}
+
+// AdvocateNondetCryptoRand records or replays the bytes of crypto/rand.Read.
+// In replay, b is overwritten with the recorded bytes.
+//
+// Parameter:
+//   - b []byte: the bytes read
+func AdvocateNondetCryptoRand(b []byte) {
+	if !nondetActive() {
+		return
+	}
+
+	call := advocateNondet(NondetCall{Op: OperationNondetCryptoRand, Data: string(b)}, "crypto/rand.")
+	if len(call.Data) == len(b) {
+		copy(b, call.Data)
+	}
+}
+
+// AdvocateNondetGetenv records or replays the result of os.Getenv
+//
+// Parameter:
+//   - v string: the value of the environment variable
+//
+// Returns:
+//   - string: the value to use
+func AdvocateNondetGetenv(v string) string {
+	if !nondetActive() {
+		return v
+	}
+
+	return advocateNondet(NondetCall{Op: OperationNondetGetenv, Data: v}, "os.").Data
+}
+
+// nondetActive returns whether nondeterministic calls are recorded or replayed
+//
+// Returns:
+//   - bool: true if recording is enabled or recorded calls are replayed
+func nondetActive() bool {
+	return (!AdvocateTracingDisabled || nondetReplayUsed) && currentGoRoutineInfo() != nil
+}
+
+// advocateNondet replays and records a call of a nondeterministic function.
+// If the routine has a recorded call left, its result replaces the real one.
+// If the recorded call is a different call, the replay diverged. If all
+// recorded calls of the routine have been replayed, the real result is used.
+//
+// Parameter:
+//   - call NondetCall: the call with the real result
+//   - pkg string: prefix of the functions of the package that contains the called function
+//
+// Returns:
+//   - NondetCall: the call with the result to use
+func advocateNondet(call NondetCall, pkg string) NondetCall {
+	call.File, call.Line = nondetCaller(pkg)
+	if call.File == "" || AdvocateIgnore(call.File) {
+		return call
+	}
+
+	gi := currentGoRoutineInfo()
+
+	if nondetReplayUsed {
+		call = nondetReplayCall(int(gi.replayID), call)
+	}
+
+	if !AdvocateTracingDisabled {
+		lock(&nondetRecordedLock)
+		nondetRecorded[gi.id] = append(nondetRecorded[gi.id], call)
+		unlock(&nondetRecordedLock)
+	}
+
+	return call
+}
+
+// nondetReplayCall returns the next recorded call of a routine
+//
+// Parameter:
+//   - routine int: replay id of the routine
+//   - call NondetCall: the executed call with the real result
+//
+// Returns:
+//   - NondetCall: the recorded call, or call if no matching call was recorded
+func nondetReplayCall(routine int, call NondetCall) NondetCall {
+	lock(&nondetReplayLock)
+	calls := nondetReplay[routine]
+	index := nondetReplayIndex[routine]
+	if index >= len(calls) {
+		unlock(&nondetReplayLock)
+		return call
+	}
+	nondetReplayIndex[routine]++
+	expected := calls[index]
+	unlock(&nondetReplayLock)
+
+	if expected.Op == call.Op && expected.File == call.File && expected.Line == call.Line {
+		return expected
+	}
+
+	if IsReplayEnabled() && !PartialReplay && startTimeActive == -1 {
+		replayDiverged(DivergenceNondet, routine,
+			ReplayElement{Routine: routine, Op: expected.Op, File: expected.File, Line: expected.Line},
+			ReplayElement{Routine: routine, Op: call.Op, File: call.File, Line: call.Line})
+	}
+
+	return call
+}
+
+// nondetCaller returns the position of the call of a nondeterministic function,
+// which is the first frame outside the runtime and the package of the function
+//
+// Parameter:
+//   - pkg string: prefix of the functions of the package
+//
+// Returns:
+//   - string: file of the call, empty if not found
+//   - int: line of the call
+func nondetCaller(pkg string) (string, int) {
+	pcs := make([]uintptr, 16)
+	n := Callers(2, pcs)
+	if n == 0 {
+		return "", 0
+	}
+
+	frames := CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !hasPrefix(frame.Function, "runtime.") && !hasPrefix(frame.Function, pkg) {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+// NondetTableToChan returns the recorded nondeterministic calls.
+// The table contains one line per call in the form
+//
+//	[routine],[op],[int].[int]...,[hex data],[file#line]
+//
+// The calls of a routine are in the order of execution.
+//
+// Returns:
+//   - chan string: the channel the table is send over in blocks of lines
+func NondetTableToChan() chan string {
+	lock(&nondetRecordedLock)
+	lines := make([]string, 0)
+	for routine, calls := range nondetRecorded {
+		for _, call := range calls {
+			ints := ""
+			for i, v := range call.Ints {
+				if i != 0 {
+					ints += "."
+				}
+				if v < 0 {
+					// uint64(-v) is also correct for the minimal int64
+					ints += "-" + uint64ToString(uint64(-v))
+				} else {
+					ints += uint64ToString(uint64(v))
+				}
+			}
+			lines = append(lines, buildTraceElemString(routine, string(call.Op), ints,
+				nondetHexEncode(call.Data), posToString(call.File, call.Line)))
+		}
+	}
+	unlock(&nondetRecordedLock)
+
+	c := make(chan string, 20)
+	go func() {
+		res := ""
+		blockSize := 1000
+		for i, line := range lines {
+			res += line + "\n"
+
+			if (i+1)%blockSize == 0 {
+				c <- res
+				res = ""
+			}
+		}
+
+		if res != "" {
+			c <- res
+		}
+		close(c)
+	}()
+
+	return c
+}
+
+// IsNondetRecorded returns whether nondeterministic calls have been recorded
+//
+// Returns:
+//   - bool: true if at least one call has been recorded
+func IsNondetRecorded() bool {
+	lock(&nondetRecordedLock)
+	defer unlock(&nondetRecordedLock)
+	return len(nondetRecorded) > 0
+}
+
+// nondetHexEncode encodes a string as hex
+//
+// Parameter:
+//   - s string: the string
+//
+// Returns:
+//   - string: the hex encoding of s
+func nondetHexEncode(s string) string {
+	const digits = "0123456789abcdef"
+	res := make([]byte, 0, 2*len(s))
+	for i := 0; i < len(s); i++ {
+		res = append(res, digits[s[i]>>4], digits[s[i]&0x0f])
+	}
+	return string(res)
+}
+
+// ADVOCATE-FILE-END
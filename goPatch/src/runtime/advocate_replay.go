@@ -197,6 +197,7 @@ var (
 // Enable the replay by starting the replay manager
 func EnableReplay() {
 	numberElementsInTrace = len(replayData)
+	initReplayDivergence()
 
 	if printDebug {
 		println("\nTRACE\n")
@@ -204,10 +205,17 @@ func EnableReplay() {
 		println("\n\n")
 	}
 
-	replayEnabled = true
+	if replayPartialOrder {
+		initReplayPartialOrder()
+	}
 
-	go ReplayManager()
+	replayEnabled = true
 
+	if replayPartialOrder {
+		go replayManagerPartialOrder()
+	} else {
+		go ReplayManager()
+	}
 }
 
 /*
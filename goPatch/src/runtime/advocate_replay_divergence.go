@@ -0,0 +1,311 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_replay_divergence.go
+// Brief: Detect if the execution diverges from the replayed trace
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// Kinds of replay divergences
+const (
+	DivergenceWrongPosition = "wrong position"
+	DivergenceWrongRoutine  = "wrong routine"
+	DivergenceUnexpectedOp  = "unexpected operation"
+	DivergenceMissingElem   = "missing element"
+	DivergenceNondet        = "nondeterministic call"
+)
+
+// ReplayDivergence describes the first point at which the execution of a
+// replay diverged from the replayed trace
+//
+// Fields:
+//   - Kind string: kind of the divergence
+//   - Routine int: replay id of the routine in which the divergence occurred
+//   - Expected ReplayElement: the element that was expected, Op is OperationNone if no element was expected
+//   - Actual ReplayElement: the operation that was executed, Op is OperationNone if no operation was executed
+//   - WaitingOps []DivergenceWaiting: the operations waiting to be released by the replay
+//   - Routines []DivergenceRoutine: the state of each routine
+type ReplayDivergence struct {
+	Kind       string
+	Routine    int
+	Expected   ReplayElement
+	Actual     ReplayElement
+	WaitingOps []DivergenceWaiting
+	Routines   []DivergenceRoutine
+}
+
+// DivergenceWaiting is an operation waiting to be released by the replay
+//
+// Fields:
+//   - Key string: replay key of the operation
+//   - WaitMs int64: time the operation has been waiting in ms
+type DivergenceWaiting struct {
+	Key    string
+	WaitMs int64
+}
+
+// DivergenceRoutine is the state of a routine when the divergence was detected
+//
+// Fields:
+//   - Routine int: replay id of the routine
+//   - Executed int: number of operations of the routine in the trace that have been executed
+//   - Total int: number of operations of the routine in the trace
+//   - Next ReplayElement: next expected element of the routine, Op is OperationNone if all have been executed
+//   - State string: current state of the routine
+type DivergenceRoutine struct {
+	Routine  int
+	Executed int
+	Total    int
+	Next     ReplayElement
+	State    string
+}
+
+var (
+	// for each routine, the elements in the order in which they are executed
+	divergenceRoutineTrace = make(map[int][]ReplayElement)
+	// for each routine, the index of the next expected element
+	divergenceRoutineIndex = make(map[int]int)
+	divergenceLock         mutex
+	divergenceFound        = false
+
+	divergenceWriteFunc func(ReplayDivergence)
+)
+
+// SetReplayDivergenceFunc sets the function that writes the divergence
+// if the replay diverges from the trace
+//
+// Parameter:
+//   - f func(ReplayDivergence): the function
+func SetReplayDivergenceFunc(f func(ReplayDivergence)) {
+	divergenceWriteFunc = f
+}
+
+// initReplayDivergence splits the replay trace into the routine local traces
+// used to detect divergences
+func initReplayDivergence() {
+	lock(&divergenceLock)
+	defer unlock(&divergenceLock)
+
+	for _, elem := range replayData {
+		if elem.NotExec() {
+			continue
+		}
+		divergenceRoutineTrace[elem.Routine] = append(divergenceRoutineTrace[elem.Routine], elem)
+	}
+}
+
+// checkReplayDivergence compares an operation that is about to be executed
+// with the next expected element of its routine. If they do not match,
+// the divergence is written and the replay continues. Rewritten traces
+// reorder or drop elements on purpose, so the divergence must not end the
+// replay before the bug is reached. Only the first divergence is recorded.
+// Divergences are only checked for the full replay. Operations in routines
+// that have already executed all elements in the trace are not checked,
+// since the trace may end before the program.
+//
+// Parameter:
+//   - op Operation: the executed operation
+//   - routine int: replay id of the routine executing the operation
+//   - file string: file of the operation
+//   - line int: line of the operation
+func checkReplayDivergence(op Operation, routine int, file string, line int) {
+	if PartialReplay || startTimeActive != -1 {
+		return
+	}
+
+	actual := ReplayElement{Routine: routine, Op: op, File: file, Line: line}
+
+	lock(&divergenceLock)
+
+	if divergenceFound {
+		unlock(&divergenceLock)
+		return
+	}
+
+	trace := divergenceRoutineTrace[routine]
+	index := divergenceRoutineIndex[routine]
+
+	if index >= len(trace) {
+		// the routine has executed all its elements or is not in the trace.
+		// In the second case, report if the operation was expected in another routine
+		if len(trace) == 0 {
+			if expected, ok := expectedInOtherRoutine(routine, file, line); ok {
+				divergenceFound = true
+				unlock(&divergenceLock)
+				replayDiverged(DivergenceWrongRoutine, routine, expected, actual)
+				return
+			}
+		}
+		unlock(&divergenceLock)
+		return
+	}
+
+	expected := trace[index]
+
+	if expected.File == file && expected.Line == line {
+		divergenceRoutineIndex[routine]++
+
+		if getOperationObjectString(expected.Op) == getOperationObjectString(op) {
+			unlock(&divergenceLock)
+			return
+		}
+
+		divergenceFound = true
+		unlock(&divergenceLock)
+		replayDiverged(DivergenceUnexpectedOp, routine, expected, actual)
+		return
+	}
+
+	kind := DivergenceWrongPosition
+
+	// the expected element was skipped
+	for i := index + 1; i < len(trace); i++ {
+		if trace[i].File == file && trace[i].Line == line {
+			kind = DivergenceMissingElem
+			break
+		}
+	}
+
+	// the operation was expected in another routine
+	if kind == DivergenceWrongPosition {
+		if _, ok := expectedInOtherRoutine(routine, file, line); ok {
+			kind = DivergenceWrongRoutine
+		}
+	}
+
+	divergenceFound = true
+	unlock(&divergenceLock)
+
+	replayDiverged(kind, routine, expected, actual)
+}
+
+// expectedInOtherRoutine checks if an operation is the next expected element
+// of another routine. Must be called with divergenceLock held.
+//
+// Parameter:
+//   - routine int: replay id of the routine executing the operation
+//   - file string: file of the operation
+//   - line int: line of the operation
+//
+// Returns:
+//   - ReplayElement: the expected element in the other routine
+//   - bool: true if such an element exists
+func expectedInOtherRoutine(routine int, file string, line int) (ReplayElement, bool) {
+	for r, trace := range divergenceRoutineTrace {
+		if r == routine || divergenceRoutineIndex[r] >= len(trace) {
+			continue
+		}
+		next := trace[divergenceRoutineIndex[r]]
+		if next.File == file && next.Line == line {
+			return next, true
+		}
+	}
+	return ReplayElement{}, false
+}
+
+// checkReplayDivergenceTimeout is called if the replay timed out. If the replay
+// waited for an element that was never executed, this element is reported
+// as missing. The replay is not terminated by this function.
+func checkReplayDivergenceTimeout() {
+	if PartialReplay || startTimeActive != -1 || numberElementsInTrace == 0 {
+		return
+	}
+
+	lock(&divergenceLock)
+	if divergenceFound {
+		unlock(&divergenceLock)
+		return
+	}
+	divergenceFound = true
+	unlock(&divergenceLock)
+
+	var next ReplayElement
+	if replayPartialOrder {
+		next = orderNextOpenElement()
+	} else {
+		_, next = getNextReplayElement()
+	}
+	if next.Op == OperationNone || next.Op == OperationReplayEnd {
+		return
+	}
+
+	writeReplayDivergence(DivergenceMissingElem, next.Routine, next, ReplayElement{Op: OperationNone})
+}
+
+// replayDiverged prints and writes the divergence. The replay is not
+// terminated and its exit code is not changed
+//
+// Parameter:
+//   - kind string: kind of the divergence
+//   - routine int: replay id of the routine in which the divergence occurred
+//   - expected ReplayElement: the expected element
+//   - actual ReplayElement: the executed operation
+func replayDiverged(kind string, routine int, expected, actual ReplayElement) {
+	println("Replay diverged from trace: ", kind, " expected ", expected.Key(), " got ", actual.Key())
+
+	writeReplayDivergence(kind, routine, expected, actual)
+}
+
+// writeReplayDivergence collects the state of the replay and writes the divergence
+//
+// Parameter:
+//   - kind string: kind of the divergence
+//   - routine int: replay id of the routine in which the divergence occurred
+//   - expected ReplayElement: the expected element
+//   - actual ReplayElement: the executed operation
+func writeReplayDivergence(kind string, routine int, expected, actual ReplayElement) {
+	if divergenceWriteFunc == nil {
+		return
+	}
+
+	div := ReplayDivergence{
+		Kind:       kind,
+		Routine:    routine,
+		Expected:   expected,
+		Actual:     actual,
+		WaitingOps: make([]DivergenceWaiting, 0),
+		Routines:   make([]DivergenceRoutine, 0),
+	}
+
+	now := currentTime()
+	lock(&waitingOpsMutex)
+	for key, op := range waitingOps {
+		div.WaitingOps = append(div.WaitingOps, DivergenceWaiting{key, (now - op.startTime) / 1000000})
+	}
+	unlock(&waitingOpsMutex)
+
+	states := make(map[int]string)
+	lock(&AdvocateRoutinesLock)
+	for _, r := range AdvocateRoutines {
+		if r.hasReturned {
+			states[r.replayID] = "finished"
+		} else if r.G != nil && r.G.waitreason != waitReasonZero {
+			states[r.replayID] = waitReasonStrings[r.G.waitreason]
+		} else {
+			states[r.replayID] = "running"
+		}
+	}
+	unlock(&AdvocateRoutinesLock)
+
+	lock(&divergenceLock)
+	for r, trace := range divergenceRoutineTrace {
+		state, ok := states[r]
+		if !ok {
+			state = "not started"
+		}
+
+		index := divergenceRoutineIndex[r]
+		next := ReplayElement{Op: OperationNone}
+		if index < len(trace) {
+			next = trace[index]
+		}
+
+		div.Routines = append(div.Routines, DivergenceRoutine{r, index, len(trace), next, state})
+	}
+	unlock(&divergenceLock)
+
+	divergenceWriteFunc(div)
+}
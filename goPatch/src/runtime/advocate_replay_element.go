@@ -37,6 +37,7 @@ func BuildReplayKey(routine int, file string, line int) string {
 //     for once: true if the once was chosen (was the first), false otherwise
 //     for others: always true
 //   - Index: Index of the select case (only for select) or index of the new routine (only for spawn), otherwise 0
+//   - ObjID: id of the primitive (for select the channel of the chosen case), 0 if the operation is not on a primitive
 type ReplayElement struct {
 	Routine int
 	Op      Operation
@@ -47,6 +48,7 @@ type ReplayElement struct {
 	Blocked bool
 	Suc     bool
 	Index   int
+	ObjID   int
 }
 
 // Get the Key (id) of a replay element
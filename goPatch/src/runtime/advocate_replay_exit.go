@@ -113,6 +113,8 @@ func ExitReplayTimeout() {
 
 	// println("ExitPosition:" + top)
 
+	checkReplayDivergenceTimeout()
+
 	advocateExitCode = ExitCodeTimeout
 	AdvocatePanic("Timeout")
 }
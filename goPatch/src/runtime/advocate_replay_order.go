@@ -0,0 +1,464 @@
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_replay_order.go
+// Brief: Partial order replay. Only the operations on the same primitive are
+//    executed in the order of the trace, all other operations run freely
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// States of an element in the partial order replay
+const (
+	orderPending  = iota // the operation has not been called yet
+	orderWaiting         // the operation waits to be released
+	orderReleased        // the operation was released and the replay waits for the acknowledgement
+	orderDone            // the operation was executed or skipped
+)
+
+var (
+	// only enforce the order of operations on the same primitive
+	replayPartialOrder bool
+
+	// state of each element in replayData
+	orderState []int
+	// index in replayData -> waiting or released operation
+	orderChan map[int]replayChan
+	// object id -> indices of the elements on the object in replayData
+	orderQueues map[int][]int
+	// object id -> position in orderQueues of the first element that is not done
+	orderQueueHead map[int]int
+	// routine -> indices of the elements of the routine in replayData
+	orderRoutineElems map[int][]int
+	// routine -> position in orderRoutineElems of the next element of the routine
+	orderRoutineIndex map[int]int
+	// indices of the released elements that wait for the acknowledgement
+	orderWaitAck []int
+
+	// replay end marker, orderEndTime is -1 if the trace does not contain one
+	orderEndTime = -1
+	orderEndElem ReplayElement
+	// number of elements before the end marker that are not done
+	orderNumberBeforeEnd int
+	// number of elements that are not done
+	orderNumberOpen int
+
+	orderLock mutex
+)
+
+// orderRelease is a release of a waiting operation. The release is collected
+// while orderLock is held and send after it has been unlocked.
+//
+// Fields:
+//   - ch chan ReplayElement: channel the operation waits on
+//   - elem ReplayElement: the replay element of the operation
+type orderRelease struct {
+	ch   chan ReplayElement
+	elem ReplayElement
+}
+
+// AdvocateInitReplayPartialOrder enables the partial order replay. It is
+// called at the start of the program if the -advocatepartialorder build flag
+// is set, before the replay is started.
+// In the partial order replay, an operation on a primitive is released as soon
+// as all operations on the same primitive that are before it in the trace
+// have been executed. Operations that are not on a primitive, e.g. spawns,
+// are released immediately. This keeps the happens before relation and
+// the communication partners of the trace, but does not serialize
+// independent operations.
+func AdvocateInitReplayPartialOrder() {
+	replayPartialOrder = true
+}
+
+// initReplayPartialOrder splits the replay trace into the queues of the
+// objects and the routines
+func initReplayPartialOrder() {
+	lock(&orderLock)
+	defer unlock(&orderLock)
+
+	orderState = make([]int, len(replayData))
+	orderChan = make(map[int]replayChan)
+	orderQueues = make(map[int][]int)
+	orderQueueHead = make(map[int]int)
+	orderRoutineElems = make(map[int][]int)
+	orderRoutineIndex = make(map[int]int)
+	orderWaitAck = make([]int, 0)
+
+	for _, elem := range replayData {
+		if elem.Op == OperationReplayEnd {
+			orderEndTime = elem.Time
+			orderEndElem = elem
+			break
+		}
+	}
+
+	for i, elem := range replayData {
+		if elem.NotExec() {
+			orderState[i] = orderDone
+			foundReplayElement()
+			continue
+		}
+
+		orderNumberOpen++
+		if orderEndTime != -1 && elem.Time < orderEndTime {
+			orderNumberBeforeEnd++
+		}
+
+		orderRoutineElems[elem.Routine] = append(orderRoutineElems[elem.Routine], i)
+		if elem.ObjID != 0 {
+			orderQueues[elem.ObjID] = append(orderQueues[elem.ObjID], i)
+		}
+	}
+}
+
+// waitForReplayPartialOrder is called by WaitForReplayPath in the partial
+// order replay. If the operation is the next operation on its object,
+// it is released directly, otherwise it is stored into the waiting operations.
+//
+// Parameter:
+//   - routine int: replay id of the routine executing the operation
+//   - key string: replay key of the operation
+//   - waitForResponse bool: whether the wait should wait for a response after finished
+//   - chWait chan ReplayElement: channel the operation waits on
+//   - chAck chan struct{}: channel the operation reports back on when finished
+//
+// Returns:
+//   - bool: true if the operation should wait, false if it can run freely
+//   - chan ReplayElement: channel to wait on
+//   - chan struct{}: chan to report back on when finished
+//   - bool: always false
+func waitForReplayPartialOrder(routine int, key string, waitForResponse bool,
+	chWait chan ReplayElement, chAck chan struct{}) (bool, chan ReplayElement, chan struct{}, bool) {
+	rel := make([]orderRelease, 0)
+
+	lock(&orderLock)
+
+	index, rel := orderNextElem(routine, key, rel)
+
+	// the operation is not in the trace or was skipped after a timeout
+	if index == -1 || orderState[index] == orderDone {
+		unlock(&orderLock)
+		orderSend(rel)
+		return false, nil, nil, false
+	}
+
+	waiting := replayChan{chWait, chAck, counter, waitForResponse, false, currentTime()}
+	orderState[index] = orderWaiting
+	orderChan[index] = waiting
+
+	lock(&waitingOpsMutex)
+	waitingOps[key] = waiting
+	unlock(&waitingOpsMutex)
+
+	if orderIsNext(index) {
+		if printDebug {
+			println("ReleaseDir: ", key, waitForResponse)
+		}
+		rel = orderReleaseElem(index, rel)
+	}
+
+	unlock(&orderLock)
+
+	orderSend(rel)
+
+	return true, chWait, chAck, false
+}
+
+// Function to run in the background in the partial order replay. It
+// collects the acknowledgements of the released operations, skips elements
+// that block the replay for too long and detects the end of the replay.
+func replayManagerPartialOrder() {
+	lastTime = currentTime()
+
+	for {
+		if !replayEnabled {
+			return
+		}
+
+		rel := make([]orderRelease, 0)
+
+		lock(&orderLock)
+		rel = orderCheckAck(rel)
+		rel = orderCheckTimeout(rel)
+		endFound := orderEndTime != -1 && orderNumberBeforeEnd == 0
+		finished := orderNumberOpen == 0
+		unlock(&orderLock)
+
+		orderSend(rel)
+
+		if endFound {
+			replayEndFound(orderEndElem)
+			return
+		}
+
+		if finished {
+			return
+		}
+
+		Gosched()
+	}
+}
+
+// orderNextElem returns the element in the trace that corresponds to an
+// operation. This is the next element of the routine with the same key.
+// Elements of the routine before this element have not been executed and
+// are skipped. Must be called with orderLock held.
+//
+// Parameter:
+//   - routine int: replay id of the routine
+//   - key string: replay key of the operation
+//   - rel []orderRelease: the releases collected so far
+//
+// Returns:
+//   - int: index of the element in replayData, -1 if the routine has no such element
+//   - []orderRelease: the releases collected so far
+func orderNextElem(routine int, key string, rel []orderRelease) (int, []orderRelease) {
+	elems := orderRoutineElems[routine]
+	start := orderRoutineIndex[routine]
+
+	for i := start; i < len(elems); i++ {
+		if replayData[elems[i]].Key() != key {
+			continue
+		}
+
+		for j := start; j < i; j++ {
+			rel = orderFinish(elems[j], rel)
+		}
+		orderRoutineIndex[routine] = i + 1
+		return elems[i], rel
+	}
+
+	return -1, rel
+}
+
+// orderIsNext checks if all elements before an element on the same object
+// are done. Must be called with orderLock held.
+//
+// Parameter:
+//   - index int: index of the element in replayData
+//
+// Returns:
+//   - bool: true if the element can be released
+func orderIsNext(index int) bool {
+	obj := replayData[index].ObjID
+	if obj == 0 {
+		return true
+	}
+
+	queue := orderQueues[obj]
+	head := orderQueueHead[obj]
+	return head < len(queue) && queue[head] == index
+}
+
+// orderReleaseElem releases a waiting element. If the operation does not send
+// an acknowledgement, it is done immediately. Must be called with orderLock held.
+//
+// Parameter:
+//   - index int: index of the element in replayData
+//   - rel []orderRelease: the releases collected so far
+//
+// Returns:
+//   - []orderRelease: the releases collected so far
+func orderReleaseElem(index int, rel []orderRelease) []orderRelease {
+	elem := replayData[index]
+	waiting := orderChan[index]
+
+	// the operation may have been released by ReleaseAllWaiting
+	lock(&waitingOpsMutex)
+	_, ok := waitingOps[elem.Key()]
+	delete(waitingOps, elem.Key())
+	unlock(&waitingOpsMutex)
+
+	if ok {
+		rel = append(rel, orderRelease{waiting.chWait, elem})
+	}
+
+	lastTime = currentTime()
+
+	if ok && waiting.waitAck {
+		waiting.released = true
+		waiting.startTime = currentTime()
+		orderChan[index] = waiting
+		orderState[index] = orderReleased
+		orderWaitAck = append(orderWaitAck, index)
+		return rel
+	}
+
+	return orderFinish(index, rel)
+}
+
+// orderFinish marks an element as done and releases the next element on the
+// same object if it is already waiting. Must be called with orderLock held.
+//
+// Parameter:
+//   - index int: index of the element in replayData
+//   - rel []orderRelease: the releases collected so far
+//
+// Returns:
+//   - []orderRelease: the releases collected so far
+func orderFinish(index int, rel []orderRelease) []orderRelease {
+	if orderState[index] == orderDone {
+		return rel
+	}
+
+	elem := replayData[index]
+
+	orderState[index] = orderDone
+	delete(orderChan, index)
+	orderNumberOpen--
+	if orderEndTime != -1 && elem.Time < orderEndTime {
+		orderNumberBeforeEnd--
+	}
+	foundReplayElement()
+
+	if elem.ObjID == 0 {
+		return rel
+	}
+
+	queue := orderQueues[elem.ObjID]
+	head := orderQueueHead[elem.ObjID]
+	for head < len(queue) && orderState[queue[head]] == orderDone {
+		head++
+	}
+	orderQueueHead[elem.ObjID] = head
+
+	if head < len(queue) && orderState[queue[head]] == orderWaiting {
+		rel = orderReleaseElem(queue[head], rel)
+	}
+
+	return rel
+}
+
+// orderCheckAck checks the released elements for their acknowledgement.
+// If the acknowledgement does not arrive in time, the element is done anyway.
+// Must be called with orderLock held.
+//
+// Parameter:
+//   - rel []orderRelease: the releases collected so far
+//
+// Returns:
+//   - []orderRelease: the releases collected so far
+func orderCheckAck(rel []orderRelease) []orderRelease {
+	if len(orderWaitAck) == 0 {
+		return rel
+	}
+
+	// finishing an element can release new elements that wait for an acknowledgement
+	released := orderWaitAck
+	orderWaitAck = make([]int, 0, len(released))
+
+	for _, index := range released {
+		if orderState[index] != orderReleased {
+			continue
+		}
+
+		waiting := orderChan[index]
+
+		acknowledged := false
+		select {
+		case <-waiting.chAck:
+			acknowledged = true
+		default:
+		}
+
+		if !acknowledged && currentTime()-waiting.startTime >= sToNs(acknowledgementMaxWaitSec) {
+			if tPostWhenAckFirstTimeout == 0 {
+				tPostWhenAckFirstTimeout = replayData[index].Time
+			}
+			acknowledged = true
+		}
+
+		if acknowledged {
+			rel = orderFinish(index, rel)
+		} else {
+			orderWaitAck = append(orderWaitAck, index)
+		}
+	}
+
+	return rel
+}
+
+// orderCheckTimeout checks if an element has been waiting for too long.
+// In this case, the elements on the same object before it that have not been
+// called yet are skipped. Must be called with orderLock held.
+//
+// Parameter:
+//   - rel []orderRelease: the releases collected so far
+//
+// Returns:
+//   - []orderRelease: the releases collected so far
+func orderCheckTimeout(rel []orderRelease) []orderRelease {
+	timeouts := make([]int, 0)
+	for index, waiting := range orderChan {
+		if orderState[index] == orderWaiting && hasTimePast(waiting.startTime, releaseOldestWaitLastMax) {
+			timeouts = append(timeouts, index)
+		}
+	}
+
+	for _, index := range timeouts {
+		if orderState[index] != orderWaiting {
+			continue
+		}
+
+		elem := replayData[index]
+
+		if printDebug {
+			println("TIMEOUT: ", elem.Key())
+		}
+
+		if tPostWhenFirstTimeout == 0 {
+			tPostWhenFirstTimeout = elem.Time
+		}
+
+		queue := orderQueues[elem.ObjID]
+		for _, i := range queue[orderQueueHead[elem.ObjID]:] {
+			if i == index {
+				break
+			}
+			if orderState[i] == orderPending {
+				rel = orderFinish(i, rel)
+			}
+		}
+
+		// restart the timeout if the element still waits for an earlier element
+		if orderState[index] == orderWaiting {
+			waiting := orderChan[index]
+			waiting.startTime = currentTime()
+			orderChan[index] = waiting
+		}
+	}
+
+	return rel
+}
+
+// orderSend sends the collected releases to the waiting operations
+//
+// Parameter:
+//   - rel []orderRelease: the releases
+func orderSend(rel []orderRelease) {
+	for _, r := range rel {
+		if printDebug {
+			println("Release: ", r.elem.Key())
+		}
+		r.ch <- r.elem
+	}
+}
+
+// orderNextOpenElement returns the first element in the trace that is not done
+//
+// Returns:
+//   - ReplayElement: the element, Op is OperationNone if all elements are done
+func orderNextOpenElement() ReplayElement {
+	lock(&orderLock)
+	defer unlock(&orderLock)
+
+	for i, state := range orderState {
+		if state != orderDone {
+			return replayData[i]
+		}
+	}
+
+	return ReplayElement{Op: OperationNone}
+}
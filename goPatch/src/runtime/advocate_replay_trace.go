@@ -100,7 +100,8 @@ func AdvocateIgnoreReplay(operation Operation, file string) bool {
 		return true
 	}
 
-	if containsStr(file, "go/pkg/mod/") {
+	// dependencies are only replayed if they are recorded because of a package filter
+	if !isFilterActive() && containsStr(file, "go/pkg/mod/") {
 		return true
 	}
 
@@ -79,6 +79,8 @@ func WaitForReplayPath(op Operation, file string, line int, waitForResponse bool
 
 	routine := GetReplayRoutineID()
 
+	checkReplayDivergence(op, routine, file, line)
+
 	key := BuildReplayKey(routine, file, line)
 
 	lock(&partialReplayMutex)
@@ -126,6 +128,11 @@ func WaitForReplayPath(op Operation, file string, line int, waitForResponse bool
 
 	}
 
+	// only wait for the operations on the same object
+	if replayPartialOrder {
+		return waitForReplayPartialOrder(routine, key, waitForResponse, chWait, chAck)
+	}
+
 	replayElem := replayChan{chWait, chAck, counter, waitForResponse, false, currentTime()}
 
 	_, nextElem := getNextReplayElement()
@@ -34,6 +34,17 @@ var projectPath string
 //   - parkForeverReplay bool: if true, routine parks forever based on replay
 //   - wokenByTimeout bool: in replay block was woken up by timeout
 //   - hasReturned bool: true if the routine has terminated
+//   - subtest string: name of the subtest the routine belongs to, empty if not in a subtest
+//   - numberElems int: number of elements added to the trace, in flight recorder mode
+//     this can be larger than the number of stored elements
+//   - ignoreDepth int: number of nested ignored regions the routine is in
+//   - userSyncIndex []int: trace indices of the currently executed user defined
+//     synchronization functions
+//   - forkPath string: path of spawns that created the routine, empty if it was not created by a recorded spawn
+//   - forkOrdinals map[string]int: spawn position -> number of routines created at the position by the routine
+//   - siteOrdinals map[string]int: allocation site -> number of objects created at the site by the routine
+//   - knownIdentities map[uint64]struct{}: ids of the objects whose identity has been recorded,
+//     that have been used by the routine
 type AdvocateRoutine struct {
 	id                   uint64
 	maxObjectId          uint64
@@ -48,6 +59,14 @@ type AdvocateRoutine struct {
 	wokenButTimeout      bool
 	wokenNoTimeout       bool
 	startedWritingToFile bool
+	subtest              string
+	numberElems          int
+	ignoreDepth          int
+	userSyncIndex        []int
+	forkPath             string
+	forkOrdinals         map[string]int
+	siteOrdinals         map[string]int
+	knownIdentities      map[uint64]struct{}
 }
 
 // Create a new advocate routine
@@ -119,6 +138,8 @@ func setCurrentRoutineToActive() {
 }
 
 // Add an element to the trace of the current routine
+// In flight recorder mode, the trace is a ring buffer and the element
+// overwrites the oldest element if the buffer is full.
 // Params:
 //   - elem: the element to add
 //
@@ -130,12 +151,63 @@ func (gi *AdvocateRoutine) addToTrace(elem traceElem) int {
 		return -1
 	}
 
+	index := gi.numberElems
+	gi.numberElems++
+
+	if advocateFlightRecorderSize > 0 && len(gi.Trace) >= advocateFlightRecorderSize {
+		gi.Trace[index%advocateFlightRecorderSize] = elem
+		return index
+	}
+
 	gi.Trace = append(gi.Trace, elem)
-	return len(gi.Trace) - 1
+	return index
 }
 
+// Get the position of an element in the stored trace
+// Params:
+//   - index: the index of the element as returned by addToTrace
+//
+// Return:
+//   - int: the position in gi.Trace, -1 if the element has been overwritten
+//     in flight recorder mode
+func (gi *AdvocateRoutine) tracePos(index int) int {
+	if index < 0 || index >= gi.numberElems || index < gi.numberElems-len(gi.Trace) {
+		return -1
+	}
+
+	if advocateFlightRecorderSize > 0 {
+		return index % advocateFlightRecorderSize
+	}
+	return index
+}
+
+// Get an element of the trace
+// Params:
+//   - index: the index of the element as returned by addToTrace
+//
+// Return:
+//   - traceElem: the element, nil if it has been overwritten in flight recorder mode
 func (gi *AdvocateRoutine) getElement(index int) traceElem {
-	return gi.Trace[index]
+	pos := gi.tracePos(index)
+	if pos == -1 {
+		return nil
+	}
+	return gi.Trace[pos]
+}
+
+// Get the stored elements of the trace in the order in which they were added
+//
+// Return:
+//   - []traceElem: the elements
+func (gi *AdvocateRoutine) elements() []traceElem {
+	if advocateFlightRecorderSize <= 0 || len(gi.Trace) < advocateFlightRecorderSize {
+		return gi.Trace
+	}
+
+	start := gi.numberElems % advocateFlightRecorderSize
+	res := make([]traceElem, 0, len(gi.Trace))
+	res = append(res, gi.Trace[start:]...)
+	return append(res, gi.Trace[:start]...)
 }
 
 func (gi *AdvocateRoutine) getPosCreated() string {
@@ -143,7 +215,7 @@ func (gi *AdvocateRoutine) getPosCreated() string {
 }
 
 func (gi *AdvocateRoutine) getLastElement() traceElem {
-	return gi.Trace[len(gi.Trace)-1]
+	return gi.getElement(gi.numberElems - 1)
 }
 
 func (gi *AdvocateRoutine) GetForkPos() string {
@@ -167,11 +239,16 @@ func (gi *AdvocateRoutine) updateElement(index int, elem traceElem) {
 		panic("Tried to update element in nil trace")
 	}
 
-	if index >= len(gi.Trace) {
+	if index >= gi.numberElems {
 		panic("Tried to update element out of bounds")
 	}
 
-	gi.Trace[index] = elem
+	pos := gi.tracePos(index)
+	if pos == -1 { // overwritten in flight recorder mode
+		return
+	}
+
+	gi.Trace[pos] = elem
 }
 
 // Get the current routine
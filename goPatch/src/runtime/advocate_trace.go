@@ -68,6 +68,21 @@ const (
 
 	OperationControllIf     Operation = "controllIf"
 	OperationControllSwitch Operation = "controllSwitch"
+
+	OperationSubtestStart Operation = "subtestStart"
+	OperationSubtestEnd   Operation = "subtestEnd"
+
+	OperationSummary Operation = "summary"
+
+	OperationEvent         Operation = "event"
+	OperationCustomRelease Operation = "customRelease"
+	OperationCustomAcquire Operation = "customAcquire"
+
+	OperationNondetTime       Operation = "nondetTime"
+	OperationNondetRand       Operation = "nondetRand"
+	OperationNondetRandV2     Operation = "nondetRandV2"
+	OperationNondetCryptoRand Operation = "nondetCryptoRand"
+	OperationNondetGetenv     Operation = "nondetGetenv"
 )
 
 const posSep = "#"
@@ -116,6 +131,16 @@ func getOperationObjectString(op Operation) string {
 		return "Replay"
 	case OperationControllIf, OperationControllSwitch:
 		return "Controll"
+	case OperationSubtestStart, OperationSubtestEnd:
+		return "Subtest"
+	case OperationSummary:
+		return "Summary"
+	case OperationEvent:
+		return "Event"
+	case OperationCustomRelease, OperationCustomAcquire:
+		return "CustomSync"
+	case OperationNondetTime, OperationNondetRand, OperationNondetRandV2, OperationNondetCryptoRand, OperationNondetGetenv:
+		return "Nondet"
 	}
 	return "Unknown"
 }
@@ -134,7 +159,7 @@ type traceElem interface {
 //   - string representation of the trace
 func CurrentTraceToString() string {
 	res := ""
-	for i, elem := range currentGoRoutineInfo().Trace {
+	for i, elem := range currentGoRoutineInfo().elements() {
 		if i != 0 {
 			res += "\n"
 		}
@@ -181,7 +206,7 @@ func TraceToChanByID(id uint64) chan string {
 			res := ""
 			blockSize := 1000
 			// if atomic recording is disabled
-			for i, elem := range routine.Trace {
+			for i, elem := range foldSummaries(routine.elements()) {
 				res += elem.toString() + "\n"
 
 				if i%blockSize == 0 {
@@ -282,6 +307,7 @@ func DeleteTrace() {
 	defer unlock(&AdvocateRoutinesLock)
 	for i := range AdvocateRoutines {
 		AdvocateRoutines[i].Trace = AdvocateRoutines[i].Trace[:0]
+		AdvocateRoutines[i].numberElems = 0
 	}
 }
 
@@ -289,7 +315,9 @@ func DeleteTrace() {
 // of the internal implementation.
 // Additionally, some operations, like garbage collection and internal operations, can
 // cause the replay to get stuck or are not needed.
-// For this reason, we ignore all internal operations
+// For this reason, we ignore all internal operations.
+// If package filters are set, the operations are additionally filtered
+// by them. Operations in a region ignored by the user are always ignored.
 //
 // Parameter:
 //   - file: file in which the operation is executed
@@ -297,9 +325,13 @@ func DeleteTrace() {
 // Returns:
 //   - bool: true if the operation should be ignored, false otherwise
 func AdvocateIgnore(file string) bool {
-	return (containsStr(file, "goPatch/src/") || containsStr(file, "go/pkg/mod")) &&
-		!containsStr(file, "goPatch/src/time/tick.go") &&
-		!containsStr(file, "goPatch/src/context/context.go")
+	if isInIgnoredRegion() {
+		return true
+	}
+	if isFilterActive() {
+		return advocateIgnoreFiltered(file)
+	}
+	return advocateIgnoreInternal(file)
 }
 
 func RemoveActive(id uint64) {
@@ -345,7 +377,13 @@ func AdvocateWriteTraceToFile() {
 		return
 	}
 
-	if AdvocateIgnore(g.forkFile) {
+	if advocateIgnoreInternal(g.forkFile) {
+		return
+	}
+
+	// in flight recorder mode, the trace is only written when it is dumped
+	if advocateFlightRecorderSize > 0 {
+		flightRecorderRoutineExit(g)
 		return
 	}
 
@@ -54,6 +54,8 @@ func AdvocateAlloc(objType string, qSize int) uint64 {
 		return id
 	}
 
+	advocateRecordIdentity(id, file, line)
+
 	op := OperationNone
 	switch objType {
 	case "C":
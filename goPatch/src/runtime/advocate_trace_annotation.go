@@ -0,0 +1,256 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_trace_annotation.go
+// Brief: Functionality for recording user annotations, meaning user events,
+//    custom happens before edges and ignored regions
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// set to true if an ignored region has been entered at least once
+var advocateIgnoreRegionUsed = false
+
+// Struct to store a user event marker
+//
+// Fields
+//   - t int64: time
+//   - name string: name of the event
+//   - kv string: key value pairs of the event in the form k=v;k=v
+//   - file string: file where the event was recorded
+//   - line int: line where the event was recorded
+type AdvocateTraceEvent struct {
+	t    int64
+	name string
+	kv   string
+	file string
+	line int
+}
+
+// Struct to store a user declared release or acquire on a key
+//
+// Fields
+//   - t int64: time
+//   - op Operation: OperationCustomRelease or OperationCustomAcquire
+//   - id uint64: id of the key
+//   - file string: file where the operation was executed
+//   - line int: line where the operation was executed
+type AdvocateTraceCustomSync struct {
+	t    int64
+	op   Operation
+	id   uint64
+	file string
+	line int
+}
+
+// AdvocateEvent records a user event marker. It is called by
+// advocatego.Event.
+//
+// Parameter:
+//   - name string: name of the event
+//   - kv string: key value pairs of the event in the form k=v;k=v
+func AdvocateEvent(name string, kv string) {
+	if AdvocateTracingDisabled {
+		return
+	}
+
+	timer := GetNextTimeStep()
+
+	_, file, line, _ := Caller(2)
+
+	if AdvocateIgnore(file) {
+		return
+	}
+
+	elem := AdvocateTraceEvent{
+		t:    timer,
+		name: sanitizeAnnotation(name),
+		kv:   sanitizeAnnotation(kv),
+		file: file,
+		line: line,
+	}
+
+	insertIntoTrace(elem)
+}
+
+// AdvocateCustomSync records a user declared release or acquire on a key.
+// It is called by advocatego.Release and advocatego.Acquire. If the
+// operation is ignored, it is recorded as a summary, so that the happens
+// before relation is kept.
+//
+// Parameter:
+//   - id uint64: id of the key
+//   - release bool: true for a release, false for an acquire
+func AdvocateCustomSync(id uint64, release bool) {
+	if AdvocateTracingDisabled {
+		return
+	}
+
+	timer := GetNextTimeStep()
+
+	_, file, line, _ := Caller(2)
+
+	op := OperationCustomAcquire
+	kind := summaryAcquire
+	if release {
+		op = OperationCustomRelease
+		kind = summaryRelease
+	}
+
+	if AdvocateIgnore(file) {
+		index := advocateSummarize(file, kind, timer, id)
+		if index != -1 {
+			currentGoRoutineInfo().completeSummary(index, timer)
+		}
+		return
+	}
+
+	elem := AdvocateTraceCustomSync{
+		t:    timer,
+		op:   op,
+		id:   id,
+		file: file,
+		line: line,
+	}
+
+	insertIntoTrace(elem)
+}
+
+// AdvocateIgnoreStart starts an ignored region in the current routine.
+// Operations in the region and in routines started in it are not recorded,
+// but are summarized to keep the happens before relation. Regions can be
+// nested.
+func AdvocateIgnoreStart() {
+	gi := currentGoRoutineInfo()
+	if gi == nil {
+		return
+	}
+
+	gi.ignoreDepth++
+	advocateIgnoreRegionUsed = true
+}
+
+// AdvocateIgnoreEnd ends an ignored region in the current routine
+func AdvocateIgnoreEnd() {
+	gi := currentGoRoutineInfo()
+	if gi == nil || gi.ignoreDepth == 0 {
+		return
+	}
+
+	gi.ignoreDepth--
+}
+
+// IsIgnoreRegionUsed returns if an ignored region has been entered
+//
+// Returns:
+//   - bool: true if at least one ignored region has been entered
+func IsIgnoreRegionUsed() bool {
+	return advocateIgnoreRegionUsed
+}
+
+// isInIgnoredRegion returns if the current routine is in an ignored region
+//
+// Returns:
+//   - bool: true if the current routine is in an ignored region
+func isInIgnoredRegion() bool {
+	gi := currentGoRoutineInfo()
+	return gi != nil && gi.ignoreDepth > 0
+}
+
+// sanitizeAnnotation replaces the characters that are used as separators
+// in the trace
+//
+// Parameter:
+//   - s string: the string
+//
+// Returns:
+//   - string: the string with , replaced by ; and new lines replaced by spaces
+func sanitizeAnnotation(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		switch c {
+		case ',':
+			b[i] = ';'
+		case '\n', '\r':
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// Get a string representation of the event marker. The key value pairs are
+// the last field.
+//
+// Returns:
+//   - string: the string representation of the form
+//     V,[t],[name],[file:line],[kv]
+func (self AdvocateTraceEvent) toString() string {
+	return buildTraceElemString("V", self.t, self.name, posToString(self.file, self.line), self.kv)
+}
+
+// getOperation is a getter for the operation
+//
+// Returns:
+//   - Operation: the operation
+func (self AdvocateTraceEvent) getOperation() Operation {
+	return OperationEvent
+}
+
+// hasCommit returns if the event has committed
+//
+// Returns:
+//   - bool: true if committed, false if only request
+func (self AdvocateTraceEvent) hasCommit() bool {
+	return true
+}
+
+// resource returns the resources for the operation. Can only be greater 1 for select
+//
+// Returns:
+//   - []AdvocateTraceResource: recources
+func (self AdvocateTraceEvent) resource() []AdvocateTraceResource {
+	return []AdvocateTraceResource{}
+}
+
+// Get a string representation of the custom release or acquire
+//
+// Returns:
+//   - string: the string representation of the form
+//     K,[t],[R|A],[id],[file:line]
+func (self AdvocateTraceCustomSync) toString() string {
+	opString := "A"
+	if self.op == OperationCustomRelease {
+		opString = "R"
+	}
+	return buildTraceElemString("K", self.t, opString, self.id, posToString(self.file, self.line))
+}
+
+// getOperation is a getter for the operation
+//
+// Returns:
+//   - Operation: the operation
+func (self AdvocateTraceCustomSync) getOperation() Operation {
+	return self.op
+}
+
+// hasCommit returns if the event has committed
+//
+// Returns:
+//   - bool: true if committed, false if only request
+func (self AdvocateTraceCustomSync) hasCommit() bool {
+	return true
+}
+
+// resource returns the resources for the operation. Can only be greater 1 for select
+//
+// Returns:
+//   - []AdvocateTraceResource: recources
+func (self AdvocateTraceCustomSync) resource() []AdvocateTraceResource {
+	return []AdvocateTraceResource{}
+}
+
+// ADVOCATE-FILE-END
@@ -44,14 +44,18 @@ func AdvocateAtomic[T int32 | int64 | uint32 | uint64 | uintptr | unsafe.Pointer
 
 	_, file, line, _ := Caller(skip)
 
-	if AdvocateIgnore(file) {
-		return
-	}
-
 	unsafeAddr := unsafe.Pointer(addr)
 
 	id := uint64(uintptr(unsafeAddr))
 
+	if AdvocateIgnore(file) {
+		// atomic operations have no post, the summary is completed directly
+		if index := advocateSummarize(file, summaryKindOf(op), timer, id); index != -1 {
+			currentGoRoutineInfo().completeSummary(index, timer)
+		}
+		return
+	}
+
 	res := AdvocateTraceResource{id: id, addr: unsafeAddr}
 
 	elem := AdvocateTraceAtomic{
@@ -66,9 +66,16 @@ func AdvocateChanPre(c *hchan, op Operation, isNil bool) int {
 	_, file, line, _ := Caller(CallerSkipChanSendRecv)
 
 	if AdvocateIgnore(file) {
-		return -1
+		if isNil {
+			return -1
+		}
+		return advocateSummarize(file, summaryKindOf(op), timer, c.id)
 	}
 
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(c.id, file, line)
+
 	res := AdvocateTraceResource{id: c.id, addr: unsafe.Pointer(c)}
 
 	elem := AdvocateTraceChannel{
@@ -101,9 +108,13 @@ func AdvocateChanClose(c *hchan) int {
 
 	_, file, line, _ := Caller(CallerSkipChanClose)
 	if AdvocateIgnore(file) {
-		return -1
+		return advocateSummarize(file, summaryRelease, timer, c.id)
 	}
 
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(c.id, file, line)
+
 	res := AdvocateTraceResource{id: c.id, addr: unsafe.Pointer(c)}
 
 	elem := AdvocateTraceChannel{
@@ -137,7 +148,11 @@ func AdvocateChanPost(index int, c *hchan, op Operation) {
 		return
 	}
 
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceChannel)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceChannel)
+	if !ok { // summarized or overwritten in flight recorder mode
+		advocateChanPostSummary(index, c, op, time)
+		return
+	}
 
 	set := false
 
@@ -187,6 +202,42 @@ func AdvocateChanPost(index int, c *hchan, op Operation) {
 	currentGoRoutineInfo().updateElement(index, elem)
 }
 
+// advocateChanPostSummary finishes a channel operation that has been removed
+// by the package filter. The operation counter of the channel is still
+// increased, so that the oId of the recorded operations match.
+//
+// Parameters:
+//   - index: index of the summary in the trace
+//   - c: the channel
+//   - op: the operation
+//   - time: time of the end of the operation
+func advocateChanPostSummary(index int, c *hchan, op Operation, time int64) {
+	if _, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceSummary); !ok || c == nil {
+		return
+	}
+
+	currentGoRoutineInfo().completeSummary(index, time)
+
+	// remove the partner of a recorded operation on an unbuffered channel
+	if c.dataqsiz == 0 {
+		lock(&unbufferedChannelComRecvMutex)
+		lock(&unbufferedChannelComSendMutex)
+		if op == OperationChannelSend {
+			delete(unbufferedChannelComRecv, c.id)
+		} else if op == OperationChannelRecv {
+			delete(unbufferedChannelComSend, c.id)
+		}
+		unlock(&unbufferedChannelComSendMutex)
+		unlock(&unbufferedChannelComRecvMutex)
+	}
+
+	if op == OperationChannelSend {
+		c.numberSend++
+	} else if op == OperationChannelRecv {
+		c.numberRecv++
+	}
+}
+
 // AdvocateChanPostCausedByClose sets the operation as successfully finished
 // Args:
 //   - index: index of the operation in the trace
@@ -201,7 +252,10 @@ func AdvocateChanPostCausedByClose(index int) {
 		return
 	}
 
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceChannel)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceChannel)
+	if !ok { // overwritten in flight recorder mode
+		return
+	}
 
 	elem.tCom = time
 	elem.cl = true
@@ -49,9 +49,13 @@ func AdvocateCondPre(mem unsafe.Pointer, id uint64, op Operation) int {
 	_, file, line, _ := Caller(CallerSkipCond)
 
 	if AdvocateIgnore(file) {
-		return -1
+		return advocateSummarize(file, summaryKindOf(op), timer, id)
 	}
 
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(id, file, line)
+
 	res := AdvocateTraceResource{id: id, addr: mem}
 
 	elem := AdvocateTraceCond{
@@ -79,7 +83,11 @@ func AdvocateCondPost(index int) {
 	if index == -1 {
 		return
 	}
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceCond)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceCond)
+	if !ok { // summarized or overwritten in flight recorder mode
+		currentGoRoutineInfo().completeSummary(index, timer)
+		return
+	}
 
 	elem.tCom = timer
 
@@ -0,0 +1,191 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_trace_identity.go
+// Brief: Stable identities of the recorded objects, based on the allocation
+//    site, the fork path of the creating routine and an ordinal. The
+//    identities are stored in a side table next to the trace
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// obj id -> stable identity of the object
+var advocateIdentities = make(map[uint64]string)
+var advocateIdentityLock mutex
+
+// advocateSetForkPath sets the fork path of a new routine. The fork path of
+// a routine that was not created by a recorded spawn is its id. Every other
+// routine is identified by the fork path of its parent and the position and
+// ordinal of the spawn in its parent, e.g. 1>main.go:12@0>main.go:20@1.
+// Must be called by the parent routine.
+//
+// Parameter:
+//   - parent *AdvocateRoutine: routine that created the new routine
+//   - child *AdvocateRoutine: the new routine
+//   - file string: file where the routine was created
+//   - line int32: line where the routine was created
+func advocateSetForkPath(parent, child *AdvocateRoutine, file string, line int32) {
+	if AdvocateTracingDisabled || parent == nil || child == nil || advocateIgnoreInternal(file) {
+		return
+	}
+
+	pos := shortPosToString(file, int(line))
+
+	if parent.forkOrdinals == nil {
+		parent.forkOrdinals = make(map[string]int)
+	}
+	ordinal := parent.forkOrdinals[pos]
+	parent.forkOrdinals[pos]++
+
+	child.forkPath = parent.getForkPath() + ">" + pos + "@" + intToString(ordinal)
+}
+
+// getForkPath returns the fork path of a routine
+//
+// Returns:
+//   - string: the fork path
+func (gi *AdvocateRoutine) getForkPath() string {
+	if gi.forkPath == "" {
+		return uint64ToString(gi.id)
+	}
+	return gi.forkPath
+}
+
+// advocateRecordIdentity records the stable identity of an object, if it has
+// not been recorded before. It is called with the allocation of the object or,
+// for objects without a recorded allocation, e.g. onces, with the operations
+// on the object, so that the first operation is used as allocation site.
+// The identity has the form site/ordinal/hash, where site is the position of
+// the allocation, ordinal the number of objects created at the same site by
+// the same routine before and hash a hash of the fork path of the routine.
+// The objects that have already been seen by a routine are stored in the
+// routine, so the global table is only locked once per routine and object.
+//
+// Parameter:
+//   - id uint64: id of the object
+//   - file string: file of the allocation or operation
+//   - line int: line of the allocation or operation
+func advocateRecordIdentity(id uint64, file string, line int) {
+	routine := currentGoRoutineInfo()
+	if id == 0 || routine == nil {
+		return
+	}
+
+	if _, ok := routine.knownIdentities[id]; ok {
+		return
+	}
+	if routine.knownIdentities == nil {
+		routine.knownIdentities = make(map[uint64]struct{})
+	}
+	routine.knownIdentities[id] = struct{}{}
+
+	lock(&advocateIdentityLock)
+	defer unlock(&advocateIdentityLock)
+
+	if _, ok := advocateIdentities[id]; ok {
+		return
+	}
+
+	site := shortPosToString(file, line)
+
+	if routine.siteOrdinals == nil {
+		routine.siteOrdinals = make(map[string]int)
+	}
+	ordinal := routine.siteOrdinals[site]
+	routine.siteOrdinals[site]++
+
+	advocateIdentities[id] = site + "/" + intToString(ordinal) + "/" + identityHash(routine.getForkPath())
+}
+
+// shortPosToString returns a position in the form file:line, where file
+// is only the name of the file without its folder
+//
+// Parameter:
+//   - file string: the file
+//   - line int: the line
+//
+// Returns:
+//   - string: the position
+func shortPosToString(file string, line int) string {
+	start := len(file)
+	for start > 0 && file[start-1] != '/' {
+		start--
+	}
+	return file[start:] + ":" + intToString(line)
+}
+
+// identityHash returns the 32 bit FNV-1a hash of a string as 8 hex digits
+//
+// Parameter:
+//   - s string: the string
+//
+// Returns:
+//   - string: the hash
+func identityHash(s string) string {
+	hash := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= 16777619
+	}
+
+	const digits = "0123456789abcdef"
+	res := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		res[i] = digits[hash&0xf]
+		hash >>= 4
+	}
+	return string(res)
+}
+
+// IsIdentityRecorded returns whether the identity of at least one object has been recorded
+//
+// Returns:
+//   - bool: true if at least one identity has been recorded
+func IsIdentityRecorded() bool {
+	lock(&advocateIdentityLock)
+	defer unlock(&advocateIdentityLock)
+	return len(advocateIdentities) != 0
+}
+
+// IdentityTableToChan returns the side table with the stable identities of
+// the objects. The table contains one line per object:
+//
+//	[id]![identity]
+//
+// Returns:
+//   - chan string: the channel the table is send over in blocks of lines
+func IdentityTableToChan() chan string {
+	lock(&advocateIdentityLock)
+	lines := make([]string, 0, len(advocateIdentities))
+	for id, identity := range advocateIdentities {
+		lines = append(lines, uint64ToString(id)+"!"+identity)
+	}
+	unlock(&advocateIdentityLock)
+
+	c := make(chan string, 20)
+	go func() {
+		res := ""
+		blockSize := 1000
+		for i, line := range lines {
+			res += line + "\n"
+
+			if (i+1)%blockSize == 0 {
+				c <- res
+				res = ""
+			}
+		}
+
+		if res != "" {
+			c <- res
+		}
+		close(c)
+	}()
+
+	return c
+}
+
+// ADVOCATE-FILE-END
@@ -56,9 +56,13 @@ func AdvocateMutexPre(mem unsafe.Pointer, id uint64, op Operation) int {
 	_, file, line, _ := Caller(CallerSkipMutex)
 
 	if AdvocateIgnore(file) {
-		return -1
+		return advocateSummarize(file, summaryKindOf(op), timer, id)
 	}
 
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(id, file, line)
+
 	res := AdvocateTraceResource{id: id, addr: mem}
 
 	elem := AdvocateTraceMutex{
@@ -97,7 +101,13 @@ func AdvocateMutexPost(index int, suc bool) {
 		return
 	}
 
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceMutex)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceMutex)
+	if !ok { // summarized or overwritten in flight recorder mode
+		if suc {
+			currentGoRoutineInfo().completeSummary(index, timer)
+		}
+		return
+	}
 	routine := currentGoRoutineInfo().id
 
 	lock(&lastRWOpLock)
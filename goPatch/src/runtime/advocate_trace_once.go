@@ -50,9 +50,11 @@ func AdvocateOncePre(mem unsafe.Pointer, id uint64) int {
 	_, file, line, _ := Caller(2)
 
 	if AdvocateIgnore(file) {
-		return -1
+		return advocateSummarize(file, summaryAcquireRelease, timer, id)
 	}
 
+	advocateRecordIdentity(id, file, line)
+
 	res := AdvocateTraceResource{id: id, addr: mem}
 
 	elem := AdvocateTraceOnce{
@@ -80,7 +82,11 @@ func AdvocateOncePost(index int, suc bool) {
 	if index == -1 {
 		return
 	}
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceOnce)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceOnce)
+	if !ok { // summarized or overwritten in flight recorder mode
+		currentGoRoutineInfo().completeSummary(index, timer)
+		return
+	}
 
 	elem.tCom = timer
 	elem.suc = suc
@@ -49,7 +49,9 @@ func AdvocateSpawnCaller(callerRoutine *AdvocateRoutine, newID uint64, file stri
 
 	timer := GetNextTimeStep()
 
-	if AdvocateIgnore(file) {
+	// spawns are not removed by the package filter, because the traces of
+	// the spawned routines are still recorded
+	if advocateIgnoreInternal(file) {
 		return
 	}
 
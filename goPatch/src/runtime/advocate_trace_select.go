@@ -58,9 +58,11 @@ func AdvocateSelectPre(cases *[]scase, nsends int, ncases int, block bool) int {
 
 	_, file, line, _ := Caller(CallerSkipSelect)
 	if AdvocateIgnore(file) {
-		return -1
+		return advocateSummarizeSelect(file, timer, nsends)
 	}
 
+	advocateRecordStack(timer, file, line)
+
 	id := GetAdvocateObjectID()
 	caseElements := make([]AdvocateTraceChannel, ncases)
 
@@ -80,6 +82,7 @@ func AdvocateSelectPre(cases *[]scase, nsends int, ncases int, block bool) int {
 				isNil: true,
 			}
 		} else {
+			advocateRecordIdentity(c.id, file, line)
 			res := AdvocateTraceResource{id: c.id, addr: unsafe.Pointer(c)}
 			caseElements[casi] = AdvocateTraceChannel{
 				tReq:  timer,
@@ -123,7 +126,11 @@ func AdvocateSelectPost(index int, c *hchan, selIndex int, rClosed bool) {
 		return
 	}
 
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceSelect)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceSelect)
+	if !ok { // summarized or overwritten in flight recorder mode
+		currentGoRoutineInfo().completeSelectSummary(index, c, selIndex, timer)
+		return
+	}
 	elem.tCom = timer
 	elem.selIndex = selIndex
 
@@ -193,7 +200,17 @@ func AdvocateSelectPreOneNonDef(c *hchan, send bool) int {
 
 	_, file, line, _ := Caller(CallerSkipSelectOneDef)
 	if AdvocateIgnore(file) {
-		return -1
+		nsends := 0
+		if send {
+			nsends = 1
+		}
+		return advocateSummarizeSelect(file, timer, nsends)
+	}
+
+	advocateRecordStack(timer, file, line)
+
+	if c != nil {
+		advocateRecordIdentity(c.id, file, line)
 	}
 
 	cases := make([]AdvocateTraceChannel, 1)
@@ -229,7 +246,13 @@ func AdvocateSelectPostOneNonDef(index int, res bool, c *hchan) {
 		return
 	}
 
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceSelect)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceSelect)
+	if !ok { // summarized or overwritten in flight recorder mode
+		if res {
+			currentGoRoutineInfo().completeSelectSummary(index, c, 0, timer)
+		}
+		return
+	}
 
 	elem.tCom = timer
 
@@ -0,0 +1,189 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_trace_stack.go
+// Brief: Recording of call stacks for blocking operations. The stacks are
+//    stored in a side table with interned frames and stacks
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// maximum number of frames recorded per stack, 0 if stacks are not recorded
+var advocateStackDepth = 0
+
+// Struct to store a frame of a recorded stack
+//
+// Fields
+//   - function string: name of the function
+//   - file string: file of the frame
+//   - line int: line of the frame
+type advocateStackFrame struct {
+	function string
+	file     string
+	line     int
+}
+
+// frame -> id of the frame, the ids start at 1
+var advocateStackFrameIDs = make(map[advocateStackFrame]int)
+var advocateStackFrames = make([]advocateStackFrame, 0)
+
+// frame ids of a stack as string -> id of the stack, the ids start at 1
+var advocateStackIDs = make(map[string]int)
+var advocateStacks = make([]string, 0)
+
+// tPre of an operation -> id of its stack
+var advocateStackOfOp = make(map[int64]int)
+
+var advocateStackLock mutex
+
+// AdvocateInitStacks enables the recording of call stacks for channel,
+// select, mutex, wait group and cond operations. It is called at the start
+// of the program with the value of the -advocatestacks build flag.
+//
+// Parameter:
+//   - depth int: maximum number of frames recorded per stack
+func AdvocateInitStacks(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	advocateStackDepth = depth
+}
+
+// IsStackRecordingEnabled returns whether call stacks are recorded
+//
+// Returns:
+//   - bool: true if enabled, false otherwise
+func IsStackRecordingEnabled() bool {
+	return advocateStackDepth > 0
+}
+
+// advocateRecordStack records the call stack of an operation. The stack
+// starts at the frame of the operation, given by its file and line, so that
+// the frames of the runtime and the recording are not part of it.
+//
+// Parameter:
+//   - tPre int64: tPre of the operation
+//   - file string: file of the operation
+//   - line int: line of the operation
+func advocateRecordStack(tPre int64, file string, line int) {
+	if advocateStackDepth == 0 {
+		return
+	}
+
+	// the frames above the operation are in the runtime and the recording
+	pcs := make([]uintptr, advocateStackDepth+32)
+	n := Callers(2, pcs)
+	if n == 0 {
+		return
+	}
+
+	frames := CallersFrames(pcs[:n])
+	stack := make([]advocateStackFrame, 0, advocateStackDepth)
+	found := false
+	for len(stack) < advocateStackDepth {
+		frame, more := frames.Next()
+
+		if !found && frame.File == file && frame.Line == line {
+			found = true
+		}
+
+		if found {
+			if len(stack) > 0 && hasPrefix(frame.Function, "runtime.") {
+				break
+			}
+			stack = append(stack, advocateStackFrame{
+				function: frame.Function,
+				file:     frame.File,
+				line:     frame.Line,
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	if len(stack) == 0 {
+		return
+	}
+
+	lock(&advocateStackLock)
+	defer unlock(&advocateStackLock)
+
+	key := ""
+	for i, frame := range stack {
+		id, ok := advocateStackFrameIDs[frame]
+		if !ok {
+			advocateStackFrames = append(advocateStackFrames, frame)
+			id = len(advocateStackFrames)
+			advocateStackFrameIDs[frame] = id
+		}
+
+		if i != 0 {
+			key += "."
+		}
+		key += intToString(id)
+	}
+
+	stackID, ok := advocateStackIDs[key]
+	if !ok {
+		advocateStacks = append(advocateStacks, key)
+		stackID = len(advocateStacks)
+		advocateStackIDs[key] = stackID
+	}
+
+	advocateStackOfOp[tPre] = stackID
+}
+
+// StackTableToChan returns the side table with the recorded stacks.
+// The table contains one line per frame, stack and operation:
+//
+//	F,[id],[function],[file:line]
+//	S,[id],[frame id].[frame id]...
+//	E,[tPre],[stack id]
+//
+// The frames of a stack start with the frame of the operation.
+//
+// Returns:
+//   - chan string: the channel the table is send over in blocks of lines
+func StackTableToChan() chan string {
+	lock(&advocateStackLock)
+	lines := make([]string, 0, len(advocateStackFrames)+len(advocateStacks)+len(advocateStackOfOp))
+	for i, frame := range advocateStackFrames {
+		lines = append(lines, buildTraceElemString("F", i+1, frame.function, posToString(frame.file, frame.line)))
+	}
+	for i, stack := range advocateStacks {
+		lines = append(lines, buildTraceElemString("S", i+1, stack))
+	}
+	for tPre, stackID := range advocateStackOfOp {
+		lines = append(lines, buildTraceElemString("E", tPre, stackID))
+	}
+	unlock(&advocateStackLock)
+
+	c := make(chan string, 20)
+	go func() {
+		res := ""
+		blockSize := 1000
+		for i, line := range lines {
+			res += line + "\n"
+
+			if (i+1)%blockSize == 0 {
+				c <- res
+				res = ""
+			}
+		}
+
+		if res != "" {
+			c <- res
+		}
+		close(c)
+	}()
+
+	return c
+}
+
+// ADVOCATE-FILE-END
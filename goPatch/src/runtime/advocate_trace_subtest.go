@@ -0,0 +1,136 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_trace_subtest.go
+// Brief: Functionality for recording the start and end of subtests
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+// Struct to store the start or end of a subtest (t.Run)
+//
+// Fields
+//   - t int64: time
+//   - op Operation: OperationSubtestStart or OperationSubtestEnd
+//   - name string: full name of the subtest, e.g. TestFoo/case_3
+type AdvocateTraceSubtest struct {
+	t    int64
+	op   Operation
+	name string
+}
+
+// AdvocateSubtestStart records the start of a subtest. It is called by
+// the routine running the subtest. This routine and all routines created
+// by it are tagged with the subtest.
+//
+// Parameter:
+//   - name string: full name of the subtest
+func AdvocateSubtestStart(name string) {
+	gi := currentGoRoutineInfo()
+	if gi == nil {
+		return
+	}
+
+	gi.subtest = name
+
+	advocateSubtest(OperationSubtestStart, name)
+}
+
+// AdvocateSubtestEnd records the end of a subtest. The routine is
+// tagged with the parent subtest again.
+//
+// Parameter:
+//   - name string: full name of the subtest
+func AdvocateSubtestEnd(name string) {
+	gi := currentGoRoutineInfo()
+	if gi == nil {
+		return
+	}
+
+	// the parent of a nested subtest is again a subtest, the parent of a
+	// subtest of a top level test is not
+	gi.subtest = ""
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] == '/' {
+			if containsStr(name[:i], "/") {
+				gi.subtest = name[:i]
+			}
+			break
+		}
+	}
+
+	advocateSubtest(OperationSubtestEnd, name)
+}
+
+// advocateSubtest inserts a subtest marker into the trace
+//
+// Parameter:
+//   - op Operation: OperationSubtestStart or OperationSubtestEnd
+//   - name string: full name of the subtest
+func advocateSubtest(op Operation, name string) {
+	if AdvocateTracingDisabled {
+		return
+	}
+
+	elem := AdvocateTraceSubtest{
+		t:    GetNextTimeStep(),
+		op:   op,
+		name: name,
+	}
+
+	insertIntoTrace(elem)
+}
+
+// GetSubtest returns the subtest the current routine belongs to
+//
+// Returns:
+//   - string: name of the subtest, empty if the routine is not in a subtest
+func GetSubtest() string {
+	gi := currentGoRoutineInfo()
+	if gi == nil {
+		return ""
+	}
+	return gi.subtest
+}
+
+// Get a string representation of the subtest marker. The name is the
+// last field, since it may contain commas.
+//
+// Returns:
+//   - string: the string representation of the form
+//     T,[t],[S|E],[name]
+func (self AdvocateTraceSubtest) toString() string {
+	opString := "S"
+	if self.op == OperationSubtestEnd {
+		opString = "E"
+	}
+	return buildTraceElemString("T", self.t, opString, self.name)
+}
+
+// getOperation is a getter for the operation
+//
+// Returns:
+//   - Operation: the operation
+func (self AdvocateTraceSubtest) getOperation() Operation {
+	return self.op
+}
+
+// hasCommit returns if the event has committed
+//
+// Returns:
+//   - bool: true if committed, false if only request
+func (self AdvocateTraceSubtest) hasCommit() bool {
+	return true
+}
+
+// resource returns the resources for the operation. Can only be greater 1 for select
+//
+// Returns:
+//   - []AdvocateTraceResource: recources
+func (self AdvocateTraceSubtest) resource() []AdvocateTraceResource {
+	return []AdvocateTraceResource{}
+}
@@ -0,0 +1,248 @@
+// ADVOCATE-FILE_START
+
+// Copyright (c) 2026 Erik Kassubek
+//
+// File: advocate_trace_usersync.go
+// Brief: Functionality for recording calls of user defined synchronization
+//    primitives as operations on the built-in primitives
+//
+// Author: Erik Kassubek
+//
+// License: BSD-3-Clause
+
+package runtime
+
+import "unsafe"
+
+// Roles of user defined synchronization functions. The values must match
+// the roles in cmd/compile/internal/walk/advocate_usersync.go
+const (
+	userSyncLock = iota
+	userSyncUnlock
+	userSyncSend
+	userSyncRecv
+	userSyncSignal
+	userSyncWait
+)
+
+// size of the buffer used for send and recv functions. User defined queues
+// are treated as buffered channels with an unlimited buffer
+const userSyncQueueSize uint = 1 << 30
+
+// Struct to store the state of an object of a user defined synchronization
+// primitive
+//
+// Fields
+//   - id uint64: id of the object
+//   - numberSend uint64: number of executed send functions
+//   - numberRecv uint64: number of executed recv functions
+type userSyncObject struct {
+	id         uint64
+	numberSend uint64
+	numberRecv uint64
+}
+
+// address -> user defined synchronization object
+var userSyncObjects = make(map[uintptr]*userSyncObject)
+var userSyncObjectsLock mutex
+
+// AdvocateUserSyncPre is inserted by the compiler before the call of a
+// function that is mapped to a synchronization role in the sync config.
+// The call is recorded as the corresponding operation on a built-in primitive,
+// e.g. a lock function as a mutex lock. The operations executed inside the
+// function are ignored, but summarized to keep the happens before relation.
+// In replay, the call waits until it is its turn.
+//
+// Parameter:
+//   - mem unsafe.Pointer: pointer to the object, e.g. the receiver
+//   - role int: the role of the function
+func AdvocateUserSyncPre(mem unsafe.Pointer, role int) {
+	gi := currentGoRoutineInfo()
+	if gi == nil {
+		return
+	}
+
+	op := userSyncOperation(role)
+
+	_, file, line, _ := Caller(1)
+
+	wait, ch, chAck, _ := WaitForReplayPath(op, file, line, false)
+	if wait {
+		replayElem := <-ch
+		if replayElem.Blocked {
+			_ = advocateUserSyncRecord(mem, role, op, file, line)
+			BlockForever()
+		}
+		chAck <- struct{}{}
+	}
+
+	index := advocateUserSyncRecord(mem, role, op, file, line)
+
+	gi.userSyncIndex = append(gi.userSyncIndex, index)
+	gi.ignoreDepth++
+	advocateIgnoreRegionUsed = true
+}
+
+// AdvocateUserSyncPost is inserted by the compiler after the call of a
+// function that is mapped to a synchronization role in the sync config.
+// It ends the ignored region and adds the end counter to the operation.
+func AdvocateUserSyncPost() {
+	gi := currentGoRoutineInfo()
+	if gi == nil || len(gi.userSyncIndex) == 0 {
+		return
+	}
+
+	index := gi.userSyncIndex[len(gi.userSyncIndex)-1]
+	gi.userSyncIndex = gi.userSyncIndex[:len(gi.userSyncIndex)-1]
+	if gi.ignoreDepth > 0 {
+		gi.ignoreDepth--
+	}
+
+	if AdvocateTracingDisabled || index == -1 {
+		return
+	}
+
+	timer := GetNextTimeStep()
+
+	switch elem := gi.getElement(index).(type) {
+	case AdvocateTraceMutex:
+		elem.tCom = timer
+		gi.updateElement(index, elem)
+	case AdvocateTraceCond:
+		elem.tCom = timer
+		gi.updateElement(index, elem)
+	case AdvocateTraceChannel:
+		if elem.op == OperationChannelRecv {
+			lock(&userSyncObjectsLock)
+			if obj, ok := userSyncObjects[uintptr(elem.res.addr)]; ok {
+				obj.numberRecv++
+				elem.oId = obj.numberRecv
+				elem.qCount = userSyncQueueCount(obj)
+			}
+			unlock(&userSyncObjectsLock)
+		}
+		elem.tCom = timer
+		gi.updateElement(index, elem)
+	default: // summarized or overwritten in flight recorder mode
+		gi.completeSummary(index, timer)
+	}
+}
+
+// advocateUserSyncRecord records the start of a call of a user defined
+// synchronization function
+//
+// Parameter:
+//   - mem unsafe.Pointer: pointer to the object
+//   - role int: the role of the function
+//   - op Operation: the operation of the role
+//   - file string: file of the call
+//   - line int: line of the call
+//
+// Returns:
+//   - int: index of the operation in the trace, -1 if not recorded
+func advocateUserSyncRecord(mem unsafe.Pointer, role int, op Operation, file string, line int) int {
+	if AdvocateTracingDisabled || mem == nil {
+		return -1
+	}
+
+	timer := GetNextTimeStep()
+
+	lock(&userSyncObjectsLock)
+	obj, ok := userSyncObjects[uintptr(mem)]
+	if !ok {
+		obj = &userSyncObject{id: GetAdvocateObjectID()}
+		userSyncObjects[uintptr(mem)] = obj
+	}
+
+	// a send is visible to receivers as soon as it starts, like the
+	// release of a summary
+	var oId uint64
+	var qCount uint
+	if role == userSyncSend {
+		obj.numberSend++
+		oId = obj.numberSend
+		qCount = max(userSyncQueueCount(obj), 1)
+	}
+	unlock(&userSyncObjectsLock)
+
+	if AdvocateIgnore(file) {
+		return advocateSummarize(file, summaryKindOf(op), timer, obj.id)
+	}
+
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(obj.id, file, line)
+
+	res := AdvocateTraceResource{id: obj.id, addr: mem}
+
+	switch role {
+	case userSyncLock, userSyncUnlock:
+		return insertIntoTrace(AdvocateTraceMutex{
+			tReq: timer,
+			res:  res,
+			op:   op,
+			suc:  true,
+			file: file,
+			line: line,
+		})
+	case userSyncSend, userSyncRecv:
+		return insertIntoTrace(AdvocateTraceChannel{
+			tReq:   timer,
+			res:    res,
+			op:     op,
+			oId:    oId,
+			qSize:  userSyncQueueSize,
+			qCount: qCount,
+			file:   file,
+			line:   line,
+		})
+	default:
+		return insertIntoTrace(AdvocateTraceCond{
+			tReq: timer,
+			res:  res,
+			op:   op,
+			file: file,
+			line: line,
+		})
+	}
+}
+
+// userSyncOperation returns the operation a role is recorded as
+//
+// Parameter:
+//   - role int: the role
+//
+// Returns:
+//   - Operation: the operation
+func userSyncOperation(role int) Operation {
+	switch role {
+	case userSyncLock:
+		return OperationMutexLock
+	case userSyncUnlock:
+		return OperationMutexUnlock
+	case userSyncSend:
+		return OperationChannelSend
+	case userSyncRecv:
+		return OperationChannelRecv
+	case userSyncSignal:
+		return OperationCondSignal
+	}
+	return OperationCondWait
+}
+
+// userSyncQueueCount returns the number of elements in a user defined queue.
+// Must be called with userSyncObjectsLock held.
+//
+// Parameter:
+//   - obj *userSyncObject: the object
+//
+// Returns:
+//   - uint: number of sends that have not been received yet
+func userSyncQueueCount(obj *userSyncObject) uint {
+	if obj.numberRecv >= obj.numberSend {
+		return 0
+	}
+	return uint(obj.numberSend - obj.numberRecv)
+}
+
+// ADVOCATE-FILE-END
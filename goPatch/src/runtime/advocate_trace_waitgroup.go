@@ -63,9 +63,17 @@ func AdvocateWaitGroupAdd(mem unsafe.Pointer, id uint64, delta int, val int32) i
 	}
 
 	if AdvocateIgnore(file) {
-		return -1
+		// only a done allows a wait to continue
+		if delta >= 0 {
+			return -1
+		}
+		return advocateSummarize(file, summaryRelease, timer, id)
 	}
 
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(id, file, line)
+
 	res := AdvocateTraceResource{id: id, addr: mem}
 
 	elem := AdvocateTraceWaitGroup{
@@ -99,9 +107,13 @@ func AdvocateWaitGroupWait(mem unsafe.Pointer, id uint64) int {
 	_, file, line, _ := Caller(CallerSkipWaitGroupAddWait)
 
 	if AdvocateIgnore(file) {
-		return -1
+		return advocateSummarize(file, summaryAcquire, timer, id)
 	}
 
+	advocateRecordStack(timer, file, line)
+
+	advocateRecordIdentity(id, file, line)
+
 	res := AdvocateTraceResource{id: id, addr: mem}
 
 	elem := AdvocateTraceWaitGroup{
@@ -138,7 +150,11 @@ func AdvocateWaitGroupPost(index int) {
 		return
 	}
 
-	elem := currentGoRoutineInfo().getElement(index).(AdvocateTraceWaitGroup)
+	elem, ok := currentGoRoutineInfo().getElement(index).(AdvocateTraceWaitGroup)
+	if !ok { // summarized or overwritten in flight recorder mode
+		currentGoRoutineInfo().completeSummary(index, timer)
+		return
+	}
 
 	elem.tCom = timer
 
@@ -5260,6 +5260,9 @@ func newproc(fn *funcval) {
 
 		if gp != nil && gp.advocateRoutineInfo != nil {
 			AdvocateSpawnCaller(gp.advocateRoutineInfo, newg.advocateRoutineInfo.id, file, line)
+			advocateSetForkPath(gp.advocateRoutineInfo, newg.advocateRoutineInfo, file, line)
+			newg.advocateRoutineInfo.subtest = gp.advocateRoutineInfo.subtest
+			newg.advocateRoutineInfo.ignoreDepth = gp.advocateRoutineInfo.ignoreDepth
 		}
 		// ADVOCATE-END
 
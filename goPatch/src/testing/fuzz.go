@@ -5,6 +5,7 @@
 package testing
 
 import (
+	"advocatego"
 	"context"
 	"errors"
 	"flag"
@@ -324,6 +325,11 @@ func (f *F) Fuzz(ff any) {
 		if t.chatty != nil {
 			t.chatty.Updatef(t.name, "=== RUN   %s\n", t.name)
 		}
+		// ADVOCATE-START
+		if !f.tstate.isFuzzing {
+			advocatego.RecordFuzzInput(testName, e.Values)
+		}
+		// ADVOCATE-END
 		f.common.inFuzzFn, f.inFuzzFn = true, true
 		go tRunner(t, func(t *T) {
 			args := []reflect.Value{reflect.ValueOf(t)}
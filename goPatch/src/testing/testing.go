@@ -1930,6 +1930,13 @@ func tRunner(t *T, fn func(t *T)) {
 		}
 	}()
 
+	// ADVOCATE-START
+	if t.level > 1 {
+		runtime.AdvocateSubtestStart(t.name)
+		defer runtime.AdvocateSubtestEnd(t.name)
+	}
+	// ADVOCATE-END
+
 	t.start = highPrecisionTimeNow()
 	t.resetRaces()
 	fn(t)
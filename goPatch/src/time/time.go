@@ -94,6 +94,10 @@ import (
 	"errors"
 	"math/bits"
 	_ "unsafe" // for go:linkname
+
+	// ADVOCATE-START
+	"runtime"
+	// ADVOCATE-END
 )
 
 // A Time represents an instant in time with nanosecond precision.
@@ -1342,6 +1346,10 @@ var startNano int64 = runtimeNano() - 1
 // Now returns the current local time.
 func Now() Time {
 	sec, nsec, mono := runtimeNow()
+	// ADVOCATE-START
+	sec, nsec, mono = runtime.AdvocateNondetTime(sec, nsec, mono-startNano)
+	mono += startNano
+	// ADVOCATE-END
 	if mono == 0 {
 		return Time{uint64(nsec), sec + unixToInternal, Local}
 	}